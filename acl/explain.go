@@ -0,0 +1,208 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExplainedRule is a single rule of a policy that applies to an
+// authorization request, along with the decision it makes on its own.
+type ExplainedRule struct {
+	// Rule is the rule in policy syntax, for example
+	// `service_prefix "web" { policy = "read" }`.
+	Rule string
+
+	// Decision is the decision the rule makes on its own.
+	Decision EnforcementDecision
+
+	// access is the access level of the rule for the resource being
+	// checked. It's used to pick between rules for the same segment the
+	// same way the policy merger does.
+	access string
+
+	// specificity orders rules that apply to the same request. Exact
+	// matches beat prefix matches, longer prefixes beat shorter ones and
+	// rules for the resource itself beat the ones it falls back to.
+	specificity int
+}
+
+const (
+	specificityFallback = -1
+	specificityPrefix   = 1 << 20
+	specificityExact    = 2 << 20
+)
+
+// MoreSpecificThan returns whether the rule takes precedence over the other
+// one when both apply to the same request.
+func (r ExplainedRule) MoreSpecificThan(other ExplainedRule) bool {
+	if r.specificity != other.specificity {
+		return r.specificity > other.specificity
+	}
+	return r.access != other.access && takesPrecedenceOver(r.access, other.access)
+}
+
+// explainCandidate is a policy consisting of a single rule.
+type explainCandidate struct {
+	rule        string
+	access      string
+	specificity int
+	rules       PolicyRules
+}
+
+// ExplainPolicy returns the rules of the policy that apply to the given
+// authorization request. Each rule is enforced on its own, rules that leave
+// the decision to the default are omitted.
+func ExplainPolicy(policy *Policy, rsc Resource, segment string, access string, conf *Config, ctx *AuthorizerContext) ([]ExplainedRule, error) {
+	var explained []ExplainedRule
+	for _, c := range explainCandidates(&policy.PolicyRules, rsc) {
+		authz, err := newPolicyAuthorizerFromRules(&c.rules, conf)
+		if err != nil {
+			return nil, err
+		}
+
+		decision, err := Enforce(authz, rsc, segment, access, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if decision == Default {
+			continue
+		}
+
+		explained = append(explained, ExplainedRule{
+			Rule:        c.rule,
+			Decision:    decision,
+			access:      c.access,
+			specificity: c.specificity,
+		})
+	}
+	return explained, nil
+}
+
+// explainCandidates splits the rules up into policies with a single rule
+// each, so they can be enforced independently.
+func explainCandidates(rules *PolicyRules, rsc Resource) []explainCandidate {
+	var out []explainCandidate
+
+	scalar := func(name, level string, specificity int, set func(*PolicyRules)) {
+		if level == "" {
+			return
+		}
+		c := explainCandidate{
+			rule:        fmt.Sprintf("%s = %q", name, level),
+			access:      level,
+			specificity: specificity,
+		}
+		set(&c.rules)
+		out = append(out, c)
+	}
+	segment := func(kind, name, level string, prefix bool, set func(*PolicyRules)) {
+		specificity := specificityExact + len(name)
+		if prefix {
+			kind += "_prefix"
+			specificity = specificityPrefix + len(name)
+		}
+		c := explainCandidate{
+			rule:        fmt.Sprintf("%s %q { policy = %q }", kind, name, level),
+			access:      level,
+			specificity: specificity,
+		}
+		set(&c.rules)
+		out = append(out, c)
+	}
+
+	// Mesh and peering rules fall back to the operator rule when they
+	// aren't set.
+	operatorSpecificity := 0
+	if rsc == ResourceMesh || rsc == ResourcePeering {
+		operatorSpecificity = specificityFallback
+	}
+
+	scalar("acl", rules.ACL, 0, func(r *PolicyRules) { r.ACL = rules.ACL })
+	scalar("keyring", rules.Keyring, 0, func(r *PolicyRules) { r.Keyring = rules.Keyring })
+	scalar("operator", rules.Operator, operatorSpecificity, func(r *PolicyRules) { r.Operator = rules.Operator })
+	scalar("mesh", rules.Mesh, 0, func(r *PolicyRules) { r.Mesh = rules.Mesh })
+	scalar("peering", rules.Peering, 0, func(r *PolicyRules) { r.Peering = rules.Peering })
+
+	for _, rule := range rules.Agents {
+		rule := rule
+		segment("agent", rule.Node, rule.Policy, false, func(r *PolicyRules) { r.Agents = []*AgentRule{rule} })
+	}
+	for _, rule := range rules.AgentPrefixes {
+		rule := rule
+		segment("agent", rule.Node, rule.Policy, true, func(r *PolicyRules) { r.AgentPrefixes = []*AgentRule{rule} })
+	}
+	for _, rule := range rules.Keys {
+		rule := rule
+		segment("key", rule.Prefix, rule.Policy, false, func(r *PolicyRules) { r.Keys = []*KeyRule{rule} })
+	}
+	for _, rule := range rules.KeyPrefixes {
+		rule := rule
+		segment("key", rule.Prefix, rule.Policy, true, func(r *PolicyRules) { r.KeyPrefixes = []*KeyRule{rule} })
+	}
+	for _, rule := range rules.Nodes {
+		rule := rule
+		segment("node", rule.Name, rule.Policy, false, func(r *PolicyRules) { r.Nodes = []*NodeRule{rule} })
+	}
+	for _, rule := range rules.NodePrefixes {
+		rule := rule
+		segment("node", rule.Name, rule.Policy, true, func(r *PolicyRules) { r.NodePrefixes = []*NodeRule{rule} })
+	}
+	for _, rule := range rules.Services {
+		rule := rule
+		segment("service", rule.Name, rule.Policy, false, func(r *PolicyRules) { r.Services = []*ServiceRule{rule} })
+		explainServiceIntentions(&out[len(out)-1], rule, rsc)
+	}
+	for _, rule := range rules.ServicePrefixes {
+		rule := rule
+		segment("service", rule.Name, rule.Policy, true, func(r *PolicyRules) { r.ServicePrefixes = []*ServiceRule{rule} })
+		explainServiceIntentions(&out[len(out)-1], rule, rsc)
+	}
+	for _, rule := range rules.Sessions {
+		rule := rule
+		segment("session", rule.Node, rule.Policy, false, func(r *PolicyRules) { r.Sessions = []*SessionRule{rule} })
+	}
+	for _, rule := range rules.SessionPrefixes {
+		rule := rule
+		segment("session", rule.Node, rule.Policy, true, func(r *PolicyRules) { r.SessionPrefixes = []*SessionRule{rule} })
+	}
+	for _, rule := range rules.Events {
+		rule := rule
+		segment("event", rule.Event, rule.Policy, false, func(r *PolicyRules) { r.Events = []*EventRule{rule} })
+	}
+	for _, rule := range rules.EventPrefixes {
+		rule := rule
+		segment("event", rule.Event, rule.Policy, true, func(r *PolicyRules) { r.EventPrefixes = []*EventRule{rule} })
+	}
+	for _, rule := range rules.PreparedQueries {
+		rule := rule
+		segment("query", rule.Prefix, rule.Policy, false, func(r *PolicyRules) { r.PreparedQueries = []*PreparedQueryRule{rule} })
+	}
+	for _, rule := range rules.PreparedQueryPrefixes {
+		rule := rule
+		segment("query", rule.Prefix, rule.Policy, true, func(r *PolicyRules) { r.PreparedQueryPrefixes = []*PreparedQueryRule{rule} })
+	}
+
+	return out
+}
+
+// explainServiceIntentions adjusts the candidate for a service rule to show
+// the intentions policy, which is what's enforced for intention requests.
+func explainServiceIntentions(c *explainCandidate, rule *ServiceRule, rsc Resource) {
+	if rule.Intentions != "" {
+		c.rule = strings.TrimSuffix(c.rule, " }") + fmt.Sprintf(" intentions = %q }", rule.Intentions)
+	}
+	if rsc != ResourceIntention {
+		return
+	}
+
+	// This mirrors how the intentions policy is derived when loading rules.
+	c.access = rule.Intentions
+	if c.access == "" {
+		switch rule.Policy {
+		case PolicyRead, PolicyWrite:
+			c.access = PolicyRead
+		default:
+			c.access = PolicyDeny
+		}
+	}
+}
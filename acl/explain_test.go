@@ -0,0 +1,139 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainPolicy(t *testing.T) {
+	type rule struct {
+		Rule     string
+		Decision EnforcementDecision
+	}
+
+	type testCase struct {
+		rules    string
+		resource Resource
+		segment  string
+		access   string
+		expected []rule
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		policy, err := NewPolicyFromSource(tc.rules, nil, nil)
+		require.NoError(t, err)
+
+		explained, err := ExplainPolicy(policy, tc.resource, tc.segment, tc.access, nil, nil)
+		require.NoError(t, err)
+
+		var actual []rule
+		for _, r := range explained {
+			actual = append(actual, rule{Rule: r.Rule, Decision: r.Decision})
+		}
+		require.Equal(t, tc.expected, actual)
+	}
+
+	cases := map[string]testCase{
+		"service exact and prefix": {
+			rules: `
+				service_prefix "" { policy = "read" }
+				service "web" { policy = "write" }
+				service "db" { policy = "deny" }
+			`,
+			resource: ResourceService,
+			segment:  "web",
+			access:   "write",
+			expected: []rule{
+				{Rule: `service "web" { policy = "write" }`, Decision: Allow},
+				{Rule: `service_prefix "" { policy = "read" }`, Decision: Deny},
+			},
+		},
+		"no matching rules": {
+			rules: `
+				service "db" { policy = "write" }
+				key_prefix "" { policy = "read" }
+			`,
+			resource: ResourceService,
+			segment:  "web",
+			access:   "read",
+		},
+		"intentions": {
+			rules: `
+				service "web" {
+					policy = "read"
+					intentions = "write"
+				}
+			`,
+			resource: ResourceIntention,
+			segment:  "web",
+			access:   "write",
+			expected: []rule{
+				{Rule: `service "web" { policy = "read" intentions = "write" }`, Decision: Allow},
+			},
+		},
+		"mesh falls back to operator": {
+			rules: `
+				operator = "write"
+				mesh = "read"
+			`,
+			resource: ResourceMesh,
+			access:   "write",
+			expected: []rule{
+				{Rule: `operator = "write"`, Decision: Allow},
+				{Rule: `mesh = "read"`, Decision: Deny},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			run(t, tc)
+		})
+	}
+}
+
+func TestExplainedRule_MoreSpecificThan(t *testing.T) {
+	explain := func(rules string, rsc Resource, segment, access string) []ExplainedRule {
+		policy, err := NewPolicyFromSource(rules, nil, nil)
+		require.NoError(t, err)
+		explained, err := ExplainPolicy(policy, rsc, segment, access, nil, nil)
+		require.NoError(t, err)
+		return explained
+	}
+
+	// exact beats prefix
+	rules := explain(`
+		service_prefix "" { policy = "write" }
+		service "web" { policy = "deny" }
+	`, ResourceService, "web", "read")
+	require.Len(t, rules, 2)
+	require.Equal(t, `service "web" { policy = "deny" }`, rules[0].Rule)
+	require.True(t, rules[0].MoreSpecificThan(rules[1]))
+
+	// longer prefixes beat shorter ones
+	rules = explain(`
+		key_prefix "foo/" { policy = "read" }
+		key_prefix "foo/bar/" { policy = "deny" }
+	`, ResourceKey, "foo/bar/baz", "read")
+	require.Len(t, rules, 2)
+	require.True(t, rules[1].MoreSpecificThan(rules[0]))
+	require.False(t, rules[0].MoreSpecificThan(rules[1]))
+
+	// the resource's own rule beats the fallback
+	rules = explain(`
+		operator = "write"
+		peering = "read"
+	`, ResourcePeering, "", "read")
+	require.Len(t, rules, 2)
+	require.True(t, rules[1].MoreSpecificThan(rules[0]))
+
+	// rules for the same segment are merged, deny wins and write beats read
+	deny := explain(`service "web" { policy = "deny" }`, ResourceService, "web", "read")
+	write := explain(`service "web" { policy = "write" }`, ResourceService, "web", "read")
+	read := explain(`service "web" { policy = "read" }`, ResourceService, "web", "read")
+	require.True(t, deny[0].MoreSpecificThan(write[0]))
+	require.True(t, write[0].MoreSpecificThan(read[0]))
+	require.False(t, read[0].MoreSpecificThan(write[0]))
+	require.False(t, read[0].MoreSpecificThan(read[0]))
+}
@@ -13,6 +13,13 @@ var (
 	validPolicyName          = regexp.MustCompile(`^[A-Za-z0-9\-_]{1,128}$`)
 	validRoleName            = regexp.MustCompile(`^[A-Za-z0-9\-_]{1,256}$`)
 	validAuthMethodName      = regexp.MustCompile(`^[A-Za-z0-9\-_]{1,128}$`)
+
+	validTemplateVariableName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+	// Values of template variables are interpolated into quoted strings of
+	// the rules, so they must not be able to end the string or start another
+	// interpolation.
+	validTemplateVariableValue = regexp.MustCompile(`^[^"\\${}\x00-\x1f\x7f]{1,512}$`)
 )
 
 // IsValidServiceIdentityName returns true if the provided name can be used as
@@ -54,3 +61,18 @@ func IsValidRoleName(name string) bool {
 func IsValidAuthMethodName(name string) bool {
 	return validAuthMethodName.MatchString(name)
 }
+
+// IsValidTemplateVariableName returns true if the provided name can be used
+// as the name of a variable of an ACLPolicy template.
+func IsValidTemplateVariableName(name string) bool {
+	return validTemplateVariableName.MatchString(name)
+}
+
+// IsValidTemplateVariableValue returns true if the provided value can be
+// given to a variable of an ACLPolicy template. Values can't be empty, which
+// would widen the prefix rules they are interpolated into to every resource,
+// and can't contain quotes, backslashes, braces, dollar signs or control
+// characters, so that they can't change the structure of the rules.
+func IsValidTemplateVariableValue(value string) bool {
+	return validTemplateVariableValue.MatchString(value)
+}
@@ -2,8 +2,10 @@ package agent
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/agent/structs"
@@ -323,6 +325,14 @@ func (s *HTTPHandlers) ACLTokenCRUD(resp http.ResponseWriter, req *http.Request)
 		tokenAccessorID = tokenAccessorID[:len(tokenAccessorID)-6]
 		fn = s.ACLTokenClone
 	}
+	if strings.HasSuffix(tokenAccessorID, "/rotate") && req.Method == "PUT" {
+		tokenAccessorID = tokenAccessorID[:len(tokenAccessorID)-7]
+		fn = s.ACLTokenRotate
+	}
+	if strings.HasSuffix(tokenAccessorID, "/renew") && req.Method == "PUT" {
+		tokenAccessorID = tokenAccessorID[:len(tokenAccessorID)-6]
+		fn = s.ACLTokenRenew
+	}
 	if tokenAccessorID == "" && req.Method != "PUT" {
 		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "Missing token AccessorID"}
 	}
@@ -500,6 +510,95 @@ func (s *HTTPHandlers) ACLTokenClone(resp http.ResponseWriter, req *http.Request
 	return &out, nil
 }
 
+func (s *HTTPHandlers) ACLTokenRotate(resp http.ResponseWriter, req *http.Request, tokenAccessorID string) (interface{}, error) {
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	args := structs.ACLTokenRotateRequest{
+		Datacenter: s.agent.config.Datacenter,
+		AccessorID: tokenAccessorID,
+	}
+
+	if err := s.parseEntMeta(req, &args.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	// The body is optional, without it the current SecretID is invalidated
+	// immediately.
+	var body struct {
+		GracePeriod interface{}
+	}
+	if err := decodeBody(req.Body, &body); err != nil && err != io.EOF {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Request decode failed: %v", err)}
+	}
+	grace, err := decodeDurationField(body.GracePeriod)
+	if err != nil {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Invalid GracePeriod: %v", err)}
+	}
+	args.GracePeriod = grace
+	s.parseToken(req, &args.Token)
+
+	var out structs.ACLToken
+	if err := s.agent.RPC(req.Context(), "ACL.TokenRotate", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (s *HTTPHandlers) ACLTokenRenew(resp http.ResponseWriter, req *http.Request, tokenAccessorID string) (interface{}, error) {
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	args := structs.ACLTokenRenewRequest{
+		Datacenter: s.agent.config.Datacenter,
+		AccessorID: tokenAccessorID,
+	}
+
+	if err := s.parseEntMeta(req, &args.EnterpriseMeta); err != nil {
+		return nil, err
+	}
+
+	// The body is optional, without it the token is renewed by its
+	// RenewalTTL.
+	var body struct {
+		Increment interface{}
+	}
+	if err := decodeBody(req.Body, &body); err != nil && err != io.EOF {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Request decode failed: %v", err)}
+	}
+	increment, err := decodeDurationField(body.Increment)
+	if err != nil {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Invalid Increment: %v", err)}
+	}
+	args.Increment = increment
+	s.parseToken(req, &args.Token)
+
+	var out structs.ACLToken
+	if err := s.agent.RPC(req.Context(), "ACL.TokenRenew", &args, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// decodeDurationField converts a duration decoded from a JSON body, which may
+// be given as a string like "1h" or as a number of nanoseconds.
+func decodeDurationField(raw interface{}) (time.Duration, error) {
+	switch v := raw.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return time.ParseDuration(v)
+	case float64:
+		return time.Duration(v), nil
+	default:
+		return 0, fmt.Errorf("unexpected type %T", raw)
+	}
+}
+
 func (s *HTTPHandlers) ACLRoleList(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
 	if s.checkACLDisabled() {
 		return nil, aclDisabled
@@ -1114,3 +1213,40 @@ func (s *HTTPHandlers) ACLAuthorize(resp http.ResponseWriter, req *http.Request)
 
 	return responses, nil
 }
+
+func (s *HTTPHandlers) ACLAuthorizeExplain(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	// The same limit as the authorize endpoint, since each request is
+	// evaluated against every rule of every policy.
+	const maxRequests = 64
+
+	if s.checkACLDisabled() {
+		return nil, aclDisabled
+	}
+
+	var args structs.ACLAuthorizeExplainRequest
+	if err := decodeBody(req.Body, &args); err != nil {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Failed to decode request body: %v", err)}
+	}
+
+	args.Datacenter = s.agent.config.Datacenter
+	args.QueryOptions = structs.QueryOptions{}
+	s.parseDC(req, &args.Datacenter)
+	s.parseToken(req, &args.Token)
+
+	if len(args.Requests) > maxRequests {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: fmt.Sprintf("Refusing to explain more than %d authorizations at once", maxRequests)}
+	}
+	if args.PoliciesOnly && len(args.Policies) == 0 {
+		return nil, HTTPError{StatusCode: http.StatusBadRequest, Reason: "At least one policy must be simulated when only evaluating simulated policies"}
+	}
+
+	var out structs.ACLAuthorizeExplainResponse
+	if err := s.agent.RPC(req.Context(), "ACL.AuthorizeExplain", &args, &out); err != nil {
+		return nil, err
+	}
+
+	if out.Explanations == nil {
+		out.Explanations = make([]structs.ACLAuthorizationExplanation, 0)
+	}
+	return &out, nil
+}
@@ -861,6 +861,111 @@ func TestACL_HTTP(t *testing.T) {
 			idMap["token-cloned"] = token.AccessorID
 			tokenMap[token.AccessorID] = token
 		})
+		t.Run("Rotate", func(t *testing.T) {
+			baseToken := tokenMap[idMap["token-test"]]
+
+			req, _ := http.NewRequest("PUT", "/v1/acl/token/"+baseToken.AccessorID+"/clone", jsonBody(&structs.ACLToken{}))
+			req.Header.Add("X-Consul-Token", "root")
+			obj, err := a.srv.ACLTokenCRUD(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+			original := obj.(*structs.ACLToken)
+
+			req, _ = http.NewRequest("PUT", "/v1/acl/token/"+original.AccessorID+"/rotate", jsonBody(map[string]interface{}{
+				"GracePeriod": "1h",
+			}))
+			req.Header.Add("X-Consul-Token", "root")
+			obj, err = a.srv.ACLTokenCRUD(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+			token, ok := obj.(*structs.ACLToken)
+			require.True(t, ok)
+
+			require.Equal(t, original.AccessorID, token.AccessorID)
+			require.NotEqual(t, original.SecretID, token.SecretID)
+			require.Equal(t, original.SecretID, token.PreviousSecretID)
+			require.NotNil(t, token.PreviousSecretExpirationTime)
+			require.Equal(t, original.Policies, token.Policies)
+			require.True(t, token.ModifyIndex > original.ModifyIndex)
+
+			// The previous secret expires at the end of the grace period.
+			req, _ = http.NewRequest("GET", "/v1/acl/token/self", nil)
+			req.Header.Add("X-Consul-Token", original.SecretID)
+			obj, err = a.srv.ACLTokenSelf(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+			self := obj.(*structs.ACLToken)
+			require.Equal(t, original.SecretID, self.SecretID)
+			require.Equal(t, token.PreviousSecretExpirationTime.Unix(), self.ExpirationTime.Unix())
+
+			// Without a body the previous secret is invalidated immediately.
+			req, _ = http.NewRequest("PUT", "/v1/acl/token/"+original.AccessorID+"/rotate", nil)
+			req.Header.Add("X-Consul-Token", "root")
+			obj, err = a.srv.ACLTokenCRUD(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+			rotated := obj.(*structs.ACLToken)
+			require.NotEqual(t, token.SecretID, rotated.SecretID)
+			require.Empty(t, rotated.PreviousSecretID)
+
+			req, _ = http.NewRequest("PUT", "/v1/acl/token/"+original.AccessorID+"/rotate", jsonBody(map[string]interface{}{
+				"GracePeriod": "forever",
+			}))
+			req.Header.Add("X-Consul-Token", "root")
+			_, err = a.srv.ACLTokenCRUD(httptest.NewRecorder(), req)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "Invalid GracePeriod")
+
+			req, _ = http.NewRequest("DELETE", "/v1/acl/token/"+original.AccessorID, nil)
+			req.Header.Add("X-Consul-Token", "root")
+			_, err = a.srv.ACLTokenCRUD(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+		})
+		t.Run("Renew", func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", "/v1/acl/token", jsonBody(map[string]interface{}{
+				"Description":      "renewable",
+				"ExpirationTTL":    "10m",
+				"Renewable":        true,
+				"MaxExpirationTTL": "2h",
+			}))
+			req.Header.Add("X-Consul-Token", "root")
+			obj, err := a.srv.ACLTokenCreate(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+			original := obj.(*structs.ACLToken)
+			require.True(t, original.Renewable)
+			require.Equal(t, 10*time.Minute, original.RenewalTTL)
+			require.NotNil(t, original.MaxExpirationTime)
+
+			req, _ = http.NewRequest("PUT", "/v1/acl/token/"+original.AccessorID+"/renew", jsonBody(map[string]interface{}{
+				"Increment": "1h",
+			}))
+			req.Header.Add("X-Consul-Token", "root")
+			obj, err = a.srv.ACLTokenCRUD(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+			token, ok := obj.(*structs.ACLToken)
+			require.True(t, ok)
+
+			require.Equal(t, original.AccessorID, token.AccessorID)
+			require.WithinDuration(t, time.Now().Add(time.Hour), *token.ExpirationTime, time.Minute)
+			require.True(t, token.ModifyIndex > original.ModifyIndex)
+
+			// Without a body the token is renewed by its renewal TTL, which
+			// doesn't shorten its expiration time.
+			req, _ = http.NewRequest("PUT", "/v1/acl/token/"+original.AccessorID+"/renew", nil)
+			req.Header.Add("X-Consul-Token", original.SecretID)
+			obj, err = a.srv.ACLTokenCRUD(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+			require.Equal(t, token.ExpirationTime, obj.(*structs.ACLToken).ExpirationTime)
+
+			req, _ = http.NewRequest("PUT", "/v1/acl/token/"+original.AccessorID+"/renew", jsonBody(map[string]interface{}{
+				"Increment": "forever",
+			}))
+			req.Header.Add("X-Consul-Token", "root")
+			_, err = a.srv.ACLTokenCRUD(httptest.NewRecorder(), req)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "Invalid Increment")
+
+			req, _ = http.NewRequest("DELETE", "/v1/acl/token/"+original.AccessorID, nil)
+			req.Header.Add("X-Consul-Token", "root")
+			_, err = a.srv.ACLTokenCRUD(httptest.NewRecorder(), req)
+			require.NoError(t, err)
+		})
 		t.Run("Update", func(t *testing.T) {
 			originalToken := tokenMap[idMap["token-cloned"]]
 
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/lib"
+)
+
+const (
+	// tokenUsageSyncInterval is how often the agent reports the tokens that
+	// were presented to it to the servers.
+	tokenUsageSyncInterval = 1 * time.Minute
+
+	// tokenUsageMaxPending is the maximum number of distinct tokens the agent
+	// keeps track of between reports. Tokens beyond this are only picked up
+	// in a later report.
+	tokenUsageMaxPending = 4096
+)
+
+// tokenUsageTracker keeps the latest usage of each token presented to the
+// agent's HTTP API until it's reported to the servers. Only the latest usage
+// of each token is kept, so a token that's used on every request still only
+// results in a single entry per report.
+type tokenUsageTracker struct {
+	lock    sync.Mutex
+	pending map[string]structs.ACLTokenUsage
+}
+
+func newTokenUsageTracker() *tokenUsageTracker {
+	return &tokenUsageTracker{
+		pending: make(map[string]structs.ACLTokenUsage),
+	}
+}
+
+// record notes that the token with the given secret was used by a client
+// with the given address.
+func (t *tokenUsageTracker) record(secretID, sourceAddr string) {
+	if secretID == "" {
+		return
+	}
+
+	now := time.Now()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.pending[secretID]; !ok && len(t.pending) >= tokenUsageMaxPending {
+		return
+	}
+	t.pending[secretID] = structs.ACLTokenUsage{
+		LastUsedTime: &now,
+		SourceAddr:   sourceAddr,
+	}
+}
+
+// drain returns the pending token usage and resets it.
+func (t *tokenUsageTracker) drain() map[string]structs.ACLTokenUsage {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	pending := t.pending
+	t.pending = make(map[string]structs.ACLTokenUsage)
+	return pending
+}
+
+// sendTokenUsage is a long-running loop that periodically reports the tokens
+// presented to the agent to the servers, which record their usage.
+func (a *Agent) sendTokenUsage() {
+	for {
+		intv := tokenUsageSyncInterval + lib.RandomStagger(tokenUsageSyncInterval)
+
+		select {
+		case <-time.After(intv):
+			usage := a.tokenUsage.drain()
+			if len(usage) == 0 {
+				continue
+			}
+
+			agentToken := a.tokens.AgentToken()
+			req := structs.ACLTokenUsageUpdateRequest{
+				Datacenter:   a.config.Datacenter,
+				Node:         a.config.NodeName,
+				Usage:        usage,
+				WriteRequest: structs.WriteRequest{Token: agentToken},
+			}
+			var reply struct{}
+			if err := a.RPC(context.Background(), "ACL.TokenUsageUpdate", &req, &reply); err != nil {
+				if acl.IsErrPermissionDenied(err) {
+					accessorID := a.aclAccessorID(agentToken)
+					a.logger.Warn("Token usage update blocked by ACLs", "accessorID", acl.AliasIfAnonymousToken(accessorID))
+				} else {
+					a.logger.Error("Token usage update error", "error", err)
+				}
+			}
+		case <-a.shutdownCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenUsageTracker(t *testing.T) {
+	tracker := newTokenUsageTracker()
+
+	tracker.record("", "10.0.0.1")
+	tracker.record("secret-1", "10.0.0.1")
+	tracker.record("secret-1", "10.0.0.2")
+	tracker.record("secret-2", "10.0.0.3")
+
+	usage := tracker.drain()
+	require.Len(t, usage, 2)
+	require.Equal(t, "10.0.0.2", usage["secret-1"].SourceAddr)
+	require.NotNil(t, usage["secret-1"].LastUsedTime)
+	require.Equal(t, "10.0.0.3", usage["secret-2"].SourceAddr)
+
+	require.Empty(t, tracker.drain())
+
+	// Once the limit is hit new tokens are dropped, but the ones already
+	// being tracked are still updated.
+	for i := 0; i < tokenUsageMaxPending; i++ {
+		tracker.record(fmt.Sprintf("token-%d", i), "10.0.0.1")
+	}
+	tracker.record("secret-1", "10.0.0.1")
+	tracker.record("token-1", "10.0.0.4")
+	usage = tracker.drain()
+	require.Len(t, usage, tokenUsageMaxPending)
+	require.NotContains(t, usage, "secret-1")
+	require.Equal(t, "10.0.0.4", usage["token-1"].SourceAddr)
+}
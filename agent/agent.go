@@ -33,6 +33,7 @@ import (
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/acl/resolver"
 	"github.com/hashicorp/consul/agent/ae"
+	"github.com/hashicorp/consul/agent/audit"
 	"github.com/hashicorp/consul/agent/cache"
 	cachetype "github.com/hashicorp/consul/agent/cache-types"
 	"github.com/hashicorp/consul/agent/checks"
@@ -341,6 +342,10 @@ type Agent struct {
 	// again.
 	httpHandlers *HTTPHandlers
 
+	// auditLogger records the requests to the HTTP API, it is nil unless
+	// audit logging is enabled.
+	auditLogger *audit.Logger
+
 	// wgServers is the wait group for all HTTP and DNS servers
 	// TODO: remove once dnsServers are handled by apiServers
 	wgServers sync.WaitGroup
@@ -354,6 +359,10 @@ type Agent struct {
 	// the configuration directly.
 	tokens *token.Store
 
+	// tokenUsage holds the tokens presented to the HTTP API until they are
+	// reported to the servers, when token usage tracking is enabled.
+	tokenUsage *tokenUsageTracker
+
 	// proxyConfig is the manager for proxy service (Kind = connect-proxy)
 	// configuration state. This ensures all state needed by a proxy registration
 	// is maintained in cache and handles pushing updates to that state into XDS
@@ -742,6 +751,12 @@ func (a *Agent) Start(ctx context.Context) error {
 		go a.sendCoordinate()
 	}
 
+	// Start reporting token usage to the servers.
+	if c.ACLsEnabled && c.ACLEnableTokenUsageTracking {
+		a.tokenUsage = newTokenUsageTracker()
+		go a.sendTokenUsage()
+	}
+
 	// Write out the PID file if necessary.
 	if err := a.storePid(); err != nil {
 		return err
@@ -757,6 +772,13 @@ func (a *Agent) Start(ctx context.Context) error {
 		MaxConnsPerClientIP: a.config.HTTPMaxConnsPerClient,
 	})
 
+	if a.config.Audit.Enabled {
+		a.auditLogger, err = audit.NewLogger(a.logger.Named(logging.Audit), a.config.Audit)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create listeners and unstarted servers; see comment on listenHTTP why
 	// we are doing this.
 	servers, err := a.listenHTTP()
@@ -1422,6 +1444,11 @@ func newConsulConfig(runtimeCfg *config.RuntimeConfig, logger hclog.Logger) (*co
 	cfg.RPCHoldTimeout = runtimeCfg.RPCHoldTimeout
 	cfg.RPCClientTimeout = runtimeCfg.RPCClientTimeout
 
+	if runtimeCfg.ConsistentReadMode != "" {
+		cfg.ConsistentReadMode = runtimeCfg.ConsistentReadMode
+	}
+	cfg.LeaseMaxClockDrift = runtimeCfg.LeaseMaxClockDrift
+
 	cfg.RPCConfig = runtimeCfg.RPCConfig
 
 	if runtimeCfg.LeaveDrainTime > 0 {
@@ -1493,6 +1520,13 @@ func newConsulConfig(runtimeCfg *config.RuntimeConfig, logger hclog.Logger) (*co
 	cfg.RequestLimitsMode = runtimeCfg.RequestLimitsMode.String()
 	cfg.RequestLimitsReadRate = runtimeCfg.RequestLimitsReadRate
 	cfg.RequestLimitsWriteRate = runtimeCfg.RequestLimitsWriteRate
+	cfg.RequestLimitsIPMode = runtimeCfg.RequestLimitsIPMode.String()
+	cfg.RequestLimitsIPReadRate = runtimeCfg.RequestLimitsIPReadRate
+	cfg.RequestLimitsIPWriteRate = runtimeCfg.RequestLimitsIPWriteRate
+	cfg.RequestLimitsAccessorMode = runtimeCfg.RequestLimitsAccessorMode.String()
+	cfg.RequestLimitsAccessorReadRate = runtimeCfg.RequestLimitsAccessorReadRate
+	cfg.RequestLimitsAccessorWriteRate = runtimeCfg.RequestLimitsAccessorWriteRate
+	cfg.RequestLimitsAdaptive = runtimeCfg.RequestLimitsAdaptive
 
 	enterpriseConsulConfig(cfg, runtimeCfg)
 	return cfg, nil
@@ -1708,6 +1742,12 @@ func (a *Agent) ShutdownEndpoints() {
 		a.logger.Error(err.Error())
 	}
 	a.logger.Info("Endpoints down")
+
+	if a.auditLogger != nil {
+		if err := a.auditLogger.Close(); err != nil {
+			a.logger.Error("failed to close audit log", "error", err)
+		}
+	}
 }
 
 // RetryJoinCh is a channel that transports errors
@@ -4168,9 +4208,16 @@ func (a *Agent) reloadConfigInternal(newCfg *config.RuntimeConfig) error {
 
 	cc := consul.ReloadableConfig{
 		RequestLimits: &consul.RequestLimits{
-			Mode:      newCfg.RequestLimitsMode,
-			ReadRate:  newCfg.RequestLimitsReadRate,
-			WriteRate: newCfg.RequestLimitsWriteRate,
+			Mode:              newCfg.RequestLimitsMode,
+			ReadRate:          newCfg.RequestLimitsReadRate,
+			WriteRate:         newCfg.RequestLimitsWriteRate,
+			IPMode:            newCfg.RequestLimitsIPMode,
+			IPReadRate:        newCfg.RequestLimitsIPReadRate,
+			IPWriteRate:       newCfg.RequestLimitsIPWriteRate,
+			AccessorMode:      newCfg.RequestLimitsAccessorMode,
+			AccessorReadRate:  newCfg.RequestLimitsAccessorReadRate,
+			AccessorWriteRate: newCfg.RequestLimitsAccessorWriteRate,
+			Adaptive:          newCfg.RequestLimitsAdaptive,
 		},
 		RPCClientTimeout:      newCfg.RPCClientTimeout,
 		RPCRateLimit:          newCfg.RPCRateLimit,
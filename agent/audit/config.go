@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// SinkTypeFile writes events to a file, rotating it like the agent log.
+	SinkTypeFile = "file"
+
+	// FormatJSON writes each event as a JSON object on its own line.
+	FormatJSON = "json"
+
+	// DeliveryBlocking makes each request wait until its event was written.
+	DeliveryBlocking = "blocking"
+
+	// DeliveryBestEffort queues events to be written in the background and
+	// drops them when the queue is full, so a slow sink never delays requests.
+	DeliveryBestEffort = "best-effort"
+)
+
+// Config configures the audit log of the HTTP API.
+type Config struct {
+	// Enabled turns on the audit log.
+	Enabled bool
+
+	// Sinks are the destinations events are written to.
+	Sinks []SinkConfig
+}
+
+// SinkConfig configures a destination of audit events.
+type SinkConfig struct {
+	// Name identifies the sink in logs and metrics.
+	Name string
+
+	// Type of the sink, only "file" is supported.
+	Type string
+
+	// Format of the events, only "json" is supported.
+	Format string
+
+	// Path of the file events are written to.
+	Path string
+
+	// Mode is the permission of the files created by the sink.
+	Mode os.FileMode
+
+	// DeliveryGuarantee is either "blocking" or "best-effort".
+	DeliveryGuarantee string
+
+	// RotateBytes, RotateDuration and RotateMaxFiles control the rotation of
+	// the file, like the log_rotate options of the agent log.
+	RotateBytes    int
+	RotateDuration time.Duration
+	RotateMaxFiles int
+
+	// IncludePaths limits the sink to requests for paths with one of these
+	// prefixes. All paths are included when it's empty.
+	IncludePaths []string
+
+	// ExcludePaths skips requests for paths with one of these prefixes.
+	ExcludePaths []string
+
+	// LogRequestBody records the bodies of write requests, with secrets
+	// redacted.
+	LogRequestBody bool
+}
+
+// Validate checks the configuration of the audit log.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if len(c.Sinks) == 0 {
+		return fmt.Errorf("at least one sink is required when the audit log is enabled")
+	}
+	for _, sink := range c.Sinks {
+		if err := sink.Validate(); err != nil {
+			return fmt.Errorf("sink %q: %w", sink.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the configuration of the sink.
+func (c SinkConfig) Validate() error {
+	if c.Type != SinkTypeFile {
+		return fmt.Errorf("type must be %q, got %q", SinkTypeFile, c.Type)
+	}
+	if c.Format != FormatJSON {
+		return fmt.Errorf("format must be %q, got %q", FormatJSON, c.Format)
+	}
+	if c.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	switch c.DeliveryGuarantee {
+	case DeliveryBlocking, DeliveryBestEffort:
+	default:
+		return fmt.Errorf("delivery_guarantee must be %q or %q, got %q",
+			DeliveryBlocking, DeliveryBestEffort, c.DeliveryGuarantee)
+	}
+	if c.RotateBytes < 0 {
+		return fmt.Errorf("rotate_bytes must not be negative")
+	}
+	if c.RotateDuration < 0 {
+		return fmt.Errorf("rotate_duration must not be negative")
+	}
+	for _, path := range append(append([]string{}, c.IncludePaths...), c.ExcludePaths...) {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("paths must start with a slash, got %q", path)
+		}
+	}
+	return nil
+}
+
+// matches returns whether requests for the given path are recorded by the
+// sink.
+func (c SinkConfig) matches(path string) bool {
+	for _, prefix := range c.ExcludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	if len(c.IncludePaths) == 0 {
+		return true
+	}
+	for _, prefix := range c.IncludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
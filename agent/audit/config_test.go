@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	validSink := func() SinkConfig {
+		return SinkConfig{
+			Name:              "main",
+			Type:              SinkTypeFile,
+			Format:            FormatJSON,
+			Path:              "/var/log/consul/audit.json",
+			DeliveryGuarantee: DeliveryBestEffort,
+		}
+	}
+
+	cases := map[string]struct {
+		config Config
+		err    string
+	}{
+		"disabled": {
+			config: Config{},
+		},
+		"valid": {
+			config: Config{Enabled: true, Sinks: []SinkConfig{validSink()}},
+		},
+		"no sinks": {
+			config: Config{Enabled: true},
+			err:    "at least one sink is required",
+		},
+		"bad type": {
+			config: Config{Enabled: true, Sinks: []SinkConfig{func() SinkConfig {
+				s := validSink()
+				s.Type = "syslog"
+				return s
+			}()}},
+			err: `sink "main": type must be "file"`,
+		},
+		"bad format": {
+			config: Config{Enabled: true, Sinks: []SinkConfig{func() SinkConfig {
+				s := validSink()
+				s.Format = "text"
+				return s
+			}()}},
+			err: `format must be "json"`,
+		},
+		"no path": {
+			config: Config{Enabled: true, Sinks: []SinkConfig{func() SinkConfig {
+				s := validSink()
+				s.Path = ""
+				return s
+			}()}},
+			err: "path is required",
+		},
+		"bad delivery guarantee": {
+			config: Config{Enabled: true, Sinks: []SinkConfig{func() SinkConfig {
+				s := validSink()
+				s.DeliveryGuarantee = "sometimes"
+				return s
+			}()}},
+			err: "delivery_guarantee must be",
+		},
+		"negative rotate bytes": {
+			config: Config{Enabled: true, Sinks: []SinkConfig{func() SinkConfig {
+				s := validSink()
+				s.RotateBytes = -1
+				return s
+			}()}},
+			err: "rotate_bytes must not be negative",
+		},
+		"relative path filter": {
+			config: Config{Enabled: true, Sinks: []SinkConfig{func() SinkConfig {
+				s := validSink()
+				s.ExcludePaths = []string{"v1/agent/"}
+				return s
+			}()}},
+			err: "paths must start with a slash",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestSinkConfig_matches(t *testing.T) {
+	sink := SinkConfig{
+		IncludePaths: []string{"/v1/acl/", "/v1/kv/"},
+		ExcludePaths: []string{"/v1/acl/token/self"},
+	}
+	require.True(t, sink.matches("/v1/acl/tokens"))
+	require.True(t, sink.matches("/v1/kv/foo"))
+	require.False(t, sink.matches("/v1/acl/token/self"))
+	require.False(t, sink.matches("/v1/agent/self"))
+
+	require.True(t, SinkConfig{}.matches("/v1/agent/self"))
+	require.False(t, SinkConfig{ExcludePaths: []string{"/v1/agent/"}}.matches("/v1/agent/self"))
+}
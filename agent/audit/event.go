@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventTypeHTTP is the type of the events recorded for HTTP API requests.
+const EventTypeHTTP = "http"
+
+// Event records a request to the HTTP API.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Auth      Auth      `json:"auth"`
+	Request   Request   `json:"request"`
+	Response  Response  `json:"response"`
+}
+
+// Auth identifies the token a request was made with. The secret of the token
+// is never recorded.
+type Auth struct {
+	// AccessorID of the token, empty if it couldn't be resolved.
+	AccessorID string `json:"accessor_id,omitempty"`
+
+	// AuthMethod that created the token, if any.
+	AuthMethod string `json:"auth_method,omitempty"`
+}
+
+// Request describes the request.
+type Request struct {
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+
+	// Query is the query string, with tokens hidden.
+	Query string `json:"query,omitempty"`
+
+	// SourceIP is the address the request was received from, ForwardedFor
+	// the X-Forwarded-For header it was sent with.
+	SourceIP     string `json:"source_ip"`
+	ForwardedFor string `json:"forwarded_for,omitempty"`
+	UserAgent    string `json:"user_agent,omitempty"`
+
+	// Body of write requests, with secrets redacted, when the sink records
+	// request bodies. Only JSON bodies are recorded, the size of other bodies
+	// is recorded in BodyBytes.
+	Body      json.RawMessage `json:"body,omitempty"`
+	BodyBytes int64           `json:"body_bytes,omitempty"`
+}
+
+// Response describes the response to the request.
+type Response struct {
+	Status int `json:"status"`
+
+	// Latency of the request in milliseconds.
+	LatencyMS float64 `json:"latency_ms"`
+}
@@ -0,0 +1,201 @@
+// Package audit records an event for each request to the HTTP API, with the
+// token it was made with, so operators can tell who did what.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/armon/go-metrics"
+	"github.com/armon/go-metrics/prometheus"
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/logging"
+)
+
+// queueSize is the number of events a best-effort sink buffers before
+// dropping them.
+const queueSize = 4096
+
+var Counters = []prometheus.CounterDefinition{
+	{
+		Name: []string{"audit", "event", "dropped"},
+		Help: "Increments for each audit event dropped because the queue of a best-effort sink was full.",
+	},
+	{
+		Name: []string{"audit", "event", "failed"},
+		Help: "Increments for each audit event that couldn't be written to a sink.",
+	},
+}
+
+// Logger writes audit events to the configured sinks.
+type Logger struct {
+	logger hclog.Logger
+	sinks  []*sink
+
+	// lock protects closed, events are written under a read lock so that
+	// Close waits for in-flight writes.
+	lock   sync.RWMutex
+	closed bool
+}
+
+// NewLogger opens the sinks of the config. The config is expected to be
+// validated.
+func NewLogger(logger hclog.Logger, config Config) (*Logger, error) {
+	l := &Logger{logger: logger}
+	for _, sinkConfig := range config.Sinks {
+		s, err := newSink(logger.With("sink", sinkConfig.Name), sinkConfig)
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to open audit sink %q: %w", sinkConfig.Name, err)
+		}
+		l.sinks = append(l.sinks, s)
+	}
+	return l, nil
+}
+
+// Match returns whether requests for the path are recorded by any sink, and
+// whether any of those sinks records request bodies.
+func (l *Logger) Match(path string) (record, body bool) {
+	for _, s := range l.sinks {
+		if s.config.matches(path) {
+			record = true
+			body = body || s.config.LogRequestBody
+		}
+	}
+	return record, body
+}
+
+// Log writes the event to the sinks recording requests for its endpoint.
+func (l *Logger) Log(event *Event) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	if l.closed {
+		return
+	}
+	for _, s := range l.sinks {
+		if s.config.matches(event.Request.Endpoint) {
+			s.log(event)
+		}
+	}
+}
+
+// Close flushes the queued events and closes the sinks.
+func (l *Logger) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+
+	var firstErr error
+	for _, s := range l.sinks {
+		if err := s.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// sink writes events to a rotated file, either directly or from a queue
+// depending on its delivery guarantee.
+type sink struct {
+	config SinkConfig
+	logger hclog.Logger
+	labels []metrics.Label
+
+	// lock serializes writes to file.
+	lock sync.Mutex
+	file *logging.LogFile
+
+	// queue and done are only set for best-effort sinks.
+	queue chan []byte
+	done  chan struct{}
+}
+
+func newSink(logger hclog.Logger, config SinkConfig) (*sink, error) {
+	file, err := logging.NewLogFile(logging.Config{
+		LogFilePath:       config.Path,
+		LogRotateBytes:    config.RotateBytes,
+		LogRotateDuration: config.RotateDuration,
+		LogRotateMaxFiles: config.RotateMaxFiles,
+	}, config.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &sink{
+		config: config,
+		logger: logger,
+		labels: []metrics.Label{{Name: "sink", Value: config.Name}},
+		file:   file,
+	}
+	if config.DeliveryGuarantee == DeliveryBestEffort {
+		s.queue = make(chan []byte, queueSize)
+		s.done = make(chan struct{})
+		go s.run()
+	}
+	return s, nil
+}
+
+func (s *sink) log(event *Event) {
+	e := *event
+	if !s.config.LogRequestBody {
+		e.Request.Body = nil
+		e.Request.BodyBytes = 0
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(&e); err != nil {
+		s.logger.Error("failed to encode audit event", "error", err)
+		metrics.IncrCounterWithLabels([]string{"audit", "event", "failed"}, 1, s.labels)
+		return
+	}
+	buf := out.Bytes()
+
+	if s.queue == nil {
+		s.write(buf)
+		return
+	}
+
+	select {
+	case s.queue <- buf:
+	default:
+		metrics.IncrCounterWithLabels([]string{"audit", "event", "dropped"}, 1, s.labels)
+	}
+}
+
+func (s *sink) write(buf []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := s.file.Write(buf); err != nil {
+		s.logger.Error("failed to write audit event", "error", err)
+		metrics.IncrCounterWithLabels([]string{"audit", "event", "failed"}, 1, s.labels)
+	}
+}
+
+func (s *sink) run() {
+	defer close(s.done)
+	for buf := range s.queue {
+		s.write(buf)
+	}
+}
+
+func (s *sink) close() error {
+	if s.queue != nil {
+		close(s.queue)
+		<-s.done
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.file.Close()
+}
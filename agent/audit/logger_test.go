@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	for _, delivery := range []string{DeliveryBlocking, DeliveryBestEffort} {
+		t.Run(delivery, func(t *testing.T) {
+			dir := t.TempDir()
+			config := Config{
+				Enabled: true,
+				Sinks: []SinkConfig{
+					{
+						Name:              "all",
+						Type:              SinkTypeFile,
+						Format:            FormatJSON,
+						Path:              filepath.Join(dir, "all.json"),
+						DeliveryGuarantee: delivery,
+						ExcludePaths:      []string{"/v1/agent/"},
+					},
+					{
+						Name:              "acl",
+						Type:              SinkTypeFile,
+						Format:            FormatJSON,
+						Path:              filepath.Join(dir, "acl.json"),
+						Mode:              0600,
+						DeliveryGuarantee: delivery,
+						IncludePaths:      []string{"/v1/acl/"},
+						LogRequestBody:    true,
+					},
+				},
+			}
+			require.NoError(t, config.Validate())
+
+			l, err := NewLogger(hclog.NewNullLogger(), config)
+			require.NoError(t, err)
+
+			record, body := l.Match("/v1/agent/self")
+			require.False(t, record)
+			require.False(t, body)
+			record, body = l.Match("/v1/kv/foo")
+			require.True(t, record)
+			require.False(t, body)
+			record, body = l.Match("/v1/acl/token")
+			require.True(t, record)
+			require.True(t, body)
+
+			newEvent := func(endpoint string) *Event {
+				return &Event{
+					ID:        "e1",
+					Type:      EventTypeHTTP,
+					Timestamp: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+					Auth:      Auth{AccessorID: "a1"},
+					Request: Request{
+						Method:    "PUT",
+						Endpoint:  endpoint,
+						SourceIP:  "127.0.0.1",
+						Body:      json.RawMessage(`{"SecretID":"<redacted>"}`),
+						BodyBytes: 20,
+					},
+					Response: Response{Status: 200, LatencyMS: 1.5},
+				}
+			}
+			l.Log(newEvent("/v1/acl/token"))
+			l.Log(newEvent("/v1/kv/foo"))
+			l.Log(newEvent("/v1/agent/self"))
+			require.NoError(t, l.Close())
+
+			// Events logged after closing are ignored.
+			l.Log(newEvent("/v1/kv/foo"))
+
+			all := readEvents(t, filepath.Join(dir, "all-*.json"))
+			require.Len(t, all, 2)
+			require.Equal(t, "/v1/acl/token", all[0].Request.Endpoint)
+			require.Equal(t, "/v1/kv/foo", all[1].Request.Endpoint)
+			require.Nil(t, all[0].Request.Body)
+			require.Zero(t, all[0].Request.BodyBytes)
+
+			acl := readEvents(t, filepath.Join(dir, "acl-*.json"))
+			require.Len(t, acl, 1)
+			require.Equal(t, *newEvent("/v1/acl/token"), acl[0])
+
+			files, err := filepath.Glob(filepath.Join(dir, "acl-*.json"))
+			require.NoError(t, err)
+			info, err := os.Stat(files[0])
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		})
+	}
+}
+
+// readEvents reads the events of the single file matching the pattern, the
+// sink adds the creation time to the name of the files.
+func readEvents(t *testing.T, pattern string) []Event {
+	t.Helper()
+
+	files, err := filepath.Glob(pattern)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
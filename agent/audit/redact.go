@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/hashicorp/consul/lib/redact"
+)
+
+// Redacted replaces the values of sensitive fields in request bodies.
+const Redacted = redact.Redacted
+
+// sensitiveFields are the names of the fields whose values are redacted from
+// request bodies, wherever they are nested. Values are included since the KV
+// values in transactions commonly hold secrets.
+var sensitiveFields = redact.NewFields(
+	"bearertoken",
+	"clientsecret",
+	"jwt",
+	"password",
+	"privatekey",
+	"secret",
+	"secretid",
+	"serviceaccountjwt",
+	"token",
+	"value",
+)
+
+// RedactBody returns the JSON request body with the values of sensitive
+// fields replaced. It returns nil if the body isn't valid JSON, since secrets
+// can't be told apart in other bodies.
+func RedactBody(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(sensitiveFields.Value(v)); err != nil {
+		return nil
+	}
+	return bytes.TrimRight(out.Bytes(), "\n")
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactBody(t *testing.T) {
+	cases := map[string]struct {
+		body     string
+		expected string
+	}{
+		"empty": {
+			body: "  ",
+		},
+		"not json": {
+			body: "some value",
+		},
+		"multiple values": {
+			body: `{} {}`,
+		},
+		"no secrets": {
+			body:     `{"Name":"web","Port":8080}`,
+			expected: `{"Name":"web","Port":8080}`,
+		},
+		"top level": {
+			body:     `{"AccessorID":"a1","SecretID":"s1","Description":"x"}`,
+			expected: `{"AccessorID":"a1","Description":"x","SecretID":"<redacted>"}`,
+		},
+		"nested": {
+			body:     `{"Config":{"client_secret":"cs","private-key":"pk","Issuer":"i"}}`,
+			expected: `{"Config":{"Issuer":"i","client_secret":"<redacted>","private-key":"<redacted>"}}`,
+		},
+		"arrays": {
+			body:     `[{"Verb":"set","Key":"k","Value":"djE="},{"Verb":"get","Key":"k"}]`,
+			expected: `[{"Key":"k","Value":"<redacted>","Verb":"set"},{"Key":"k","Verb":"get"}]`,
+		},
+		"empty secret": {
+			body:     `{"Token":"","Password":null}`,
+			expected: `{"Password":null,"Token":""}`,
+		},
+		"large numbers": {
+			body:     `{"Index":18446744073709551615}`,
+			expected: `{"Index":18446744073709551615}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			out := RedactBody([]byte(tc.body))
+			if tc.expected == "" {
+				require.Nil(t, out)
+				return
+			}
+			require.JSONEq(t, tc.expected, string(out))
+		})
+	}
+}
@@ -27,6 +27,7 @@ import (
 
 	hcpconfig "github.com/hashicorp/consul/agent/hcp/config"
 
+	"github.com/hashicorp/consul/agent/audit"
 	"github.com/hashicorp/consul/agent/cache"
 	"github.com/hashicorp/consul/agent/checks"
 	"github.com/hashicorp/consul/agent/connect/ca"
@@ -402,6 +403,19 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		}
 	}
 
+	var dnsViews []RuntimeDNSView
+	for i, v := range c.DNS.Views {
+		name := fmt.Sprintf("dns_config.views[%d]", i)
+		dnsViews = append(dnsViews, RuntimeDNSView{
+			Name:          stringVal(v.Name),
+			SourceCIDRs:   b.cidrsVal(name+".source_cidrs", v.SourceCIDRs),
+			TaggedAddress: stringVal(v.TaggedAddress),
+			Datacenters:   v.Datacenters,
+			Peers:         v.Peers,
+			Rewrites:      v.Rewrites,
+		})
+	}
+
 	leaveOnTerm := !boolVal(c.ServerMode)
 	if c.LeaveOnTerm != nil {
 		leaveOnTerm = boolVal(c.LeaveOnTerm)
@@ -857,8 +871,9 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 			ACLDefaultPolicy: stringVal(c.ACL.DefaultPolicy),
 		},
 
-		ACLEnableKeyListPolicy:    boolVal(c.ACL.EnableKeyListPolicy),
-		ACLInitialManagementToken: stringVal(c.ACL.Tokens.InitialManagement),
+		ACLEnableKeyListPolicy:      boolVal(c.ACL.EnableKeyListPolicy),
+		ACLEnableTokenUsageTracking: boolVal(c.ACL.EnableTokenUsageTracking),
+		ACLInitialManagementToken:   stringVal(c.ACL.Tokens.InitialManagement),
 
 		ACLTokenReplication: boolVal(c.ACL.TokenReplication),
 
@@ -903,6 +918,18 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		DNSNodeMetaTXT:        boolValWithDefault(c.DNS.NodeMetaTXT, true),
 		DNSUseCache:           boolVal(c.DNS.UseCache),
 		DNSCacheMaxAge:        b.durationVal("dns_config.cache_max_age", c.DNS.CacheMaxAge),
+		DNSViews:              dnsViews,
+		DNSFilters:            c.DNS.Filters,
+
+		// DNS rate limiting
+		DNSRateLimitMode:          b.requestsLimitsModeVal("dns_config.rate_limit.mode", stringVal(c.DNS.RateLimit.Mode)),
+		DNSClientRate:             limitVal(c.DNS.RateLimit.ClientRate),
+		DNSClientBurst:            intVal(c.DNS.RateLimit.ClientBurst),
+		DNSResponseRate:           limitVal(c.DNS.RateLimit.ResponseRate),
+		DNSResponseBurst:          intVal(c.DNS.RateLimit.ResponseBurst),
+		DNSRateLimitIPv4PrefixLen: intVal(c.DNS.RateLimit.IPv4PrefixLen),
+		DNSRateLimitIPv6PrefixLen: intVal(c.DNS.RateLimit.IPv6PrefixLen),
+		DNSRateLimitSlip:          intVal(c.DNS.RateLimit.Slip),
 
 		// HTTP
 		HTTPPort:            httpPort,
@@ -967,6 +994,7 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		Checks:                                 checks,
 		ClientAddrs:                            clientAddrs,
 		ConfigEntryBootstrap:                   configEntries,
+		Audit:                                  b.auditVal(c.Audit),
 		AutoEncryptTLS:                         boolVal(c.AutoEncrypt.TLS),
 		AutoEncryptDNSSAN:                      autoEncryptDNSSAN,
 		AutoEncryptIPSAN:                       autoEncryptIPSAN,
@@ -1041,6 +1069,8 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		RPCBindAddr:                       rpcBindAddr,
 		RPCHandshakeTimeout:               b.durationVal("limits.rpc_handshake_timeout", c.Limits.RPCHandshakeTimeout),
 		RPCHoldTimeout:                    b.durationVal("performance.rpc_hold_timeout", c.Performance.RPCHoldTimeout),
+		ConsistentReadMode:                stringVal(c.Performance.ConsistentReadMode),
+		LeaseMaxClockDrift:                b.durationVal("performance.lease_max_clock_drift", c.Performance.LeaseMaxClockDrift),
 		RPCClientTimeout:                  b.durationVal("limits.rpc_client_timeout", c.Limits.RPCClientTimeout),
 		RPCMaxBurst:                       intVal(c.Limits.RPCMaxBurst),
 		RPCMaxConnsPerClient:              intVal(c.Limits.RPCMaxConnsPerClient),
@@ -1055,9 +1085,16 @@ func (b *builder) build() (rt RuntimeConfig, err error) {
 		ReconnectTimeoutLAN:               b.durationVal("reconnect_timeout", c.ReconnectTimeoutLAN),
 		ReconnectTimeoutWAN:               b.durationVal("reconnect_timeout_wan", c.ReconnectTimeoutWAN),
 		RejoinAfterLeave:                  boolVal(c.RejoinAfterLeave),
-		RequestLimitsMode:                 b.requestsLimitsModeVal(stringVal(c.Limits.RequestLimits.Mode)),
+		RequestLimitsMode:                 b.requestsLimitsModeVal("limits.request_limits.mode", stringVal(c.Limits.RequestLimits.Mode)),
 		RequestLimitsReadRate:             limitVal(c.Limits.RequestLimits.ReadRate),
 		RequestLimitsWriteRate:            limitVal(c.Limits.RequestLimits.WriteRate),
+		RequestLimitsIPMode:               b.requestsLimitsModeVal("limits.request_limits.ip.mode", stringVal(c.Limits.RequestLimits.IP.Mode)),
+		RequestLimitsIPReadRate:           limitVal(c.Limits.RequestLimits.IP.ReadRate),
+		RequestLimitsIPWriteRate:          limitVal(c.Limits.RequestLimits.IP.WriteRate),
+		RequestLimitsAccessorMode:         b.requestsLimitsModeVal("limits.request_limits.accessor.mode", stringVal(c.Limits.RequestLimits.Accessor.Mode)),
+		RequestLimitsAccessorReadRate:     limitVal(c.Limits.RequestLimits.Accessor.ReadRate),
+		RequestLimitsAccessorWriteRate:    limitVal(c.Limits.RequestLimits.Accessor.WriteRate),
+		RequestLimitsAdaptive:             b.requestLimitsAdaptiveVal(c.Limits.RequestLimits.Adaptive),
 		RetryJoinIntervalLAN:              b.durationVal("retry_interval", c.RetryJoinIntervalLAN),
 		RetryJoinIntervalWAN:              b.durationVal("retry_interval_wan", c.RetryJoinIntervalWAN),
 		RetryJoinLAN:                      b.expandAllOptionalAddrs("retry_join", c.RetryJoinLAN),
@@ -1211,6 +1248,9 @@ func (b *builder) validate(rt RuntimeConfig) error {
 	if err := validateBasicName("ui_config.metrics_provider", rt.UIConfig.MetricsProvider, true); err != nil {
 		return err
 	}
+	if err := rt.Audit.Validate(); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
 	if rt.UIConfig.MetricsProviderOptionsJSON != "" {
 		// Attempt to parse the JSON to ensure it's valid, parsing into a map
 		// ensures we get an object.
@@ -1336,6 +1376,34 @@ func (b *builder) validate(rt RuntimeConfig) error {
 	if rt.DNSARecordLimit < 0 {
 		return fmt.Errorf("dns_config.a_record_limit cannot be %d. Must be greater than or equal to zero", rt.DNSARecordLimit)
 	}
+	if rt.DNSClientBurst < 0 {
+		return fmt.Errorf("dns_config.rate_limit.client_burst cannot be %d. Must be greater than or equal to zero", rt.DNSClientBurst)
+	}
+	if rt.DNSResponseBurst < 0 {
+		return fmt.Errorf("dns_config.rate_limit.response_burst cannot be %d. Must be greater than or equal to zero", rt.DNSResponseBurst)
+	}
+	if rt.DNSRateLimitIPv4PrefixLen < 0 || rt.DNSRateLimitIPv4PrefixLen > 32 {
+		return fmt.Errorf("dns_config.rate_limit.ipv4_prefix_len cannot be %d. Must be between 0 and 32", rt.DNSRateLimitIPv4PrefixLen)
+	}
+	if rt.DNSRateLimitIPv6PrefixLen < 0 || rt.DNSRateLimitIPv6PrefixLen > 128 {
+		return fmt.Errorf("dns_config.rate_limit.ipv6_prefix_len cannot be %d. Must be between 0 and 128", rt.DNSRateLimitIPv6PrefixLen)
+	}
+	if rt.DNSRateLimitSlip < 0 {
+		return fmt.Errorf("dns_config.rate_limit.slip cannot be %d. Must be greater than or equal to zero", rt.DNSRateLimitSlip)
+	}
+	for name, expr := range rt.DNSFilters {
+		if err := dns.ValidateLabel(name); err != nil {
+			return fmt.Errorf("dns_config.filters: invalid filter name %q: %v", name, err)
+		}
+		if _, err := bexpr.CreateFilter(expr, nil, structs.CheckServiceNode{}); err != nil {
+			return fmt.Errorf("dns_config.filters[%q]: invalid filter: %v", name, err)
+		}
+	}
+	for i, v := range rt.DNSViews {
+		if len(v.SourceCIDRs) == 0 {
+			return fmt.Errorf("dns_config.views[%d].source_cidrs must contain at least one network", i)
+		}
+	}
 	if err := structs.ValidateNodeMetadata(rt.NodeMeta, false); err != nil {
 		return fmt.Errorf("node_meta invalid: %v", err)
 	}
@@ -1394,6 +1462,30 @@ func (b *builder) validate(rt RuntimeConfig) error {
 		if rt.RaftLogStoreConfig.WAL.SegmentSize > 1024*1024*1024 {
 			return fmt.Errorf("raft_logstore.wal.segment_size_mb cannot be greater than 1024 (1GiB)")
 		}
+
+		if rt.ConsistentReadMode != consul.ConsistentReadModeBarrier &&
+			rt.ConsistentReadMode != consul.ConsistentReadModeLease {
+			return fmt.Errorf("performance.consistent_read_mode must be one of '%s' or '%s'",
+				consul.ConsistentReadModeBarrier, consul.ConsistentReadModeLease)
+		}
+		if rt.ConsistentReadMode == consul.ConsistentReadModeLease && rt.LeaseMaxClockDrift >= rt.ConsulRaftHeartbeatTimeout {
+			return fmt.Errorf("performance.lease_max_clock_drift (%s) must be lower than the Raft heartbeat timeout (%s)",
+				rt.LeaseMaxClockDrift, rt.ConsulRaftHeartbeatTimeout)
+		}
+
+		if rt.RequestLimitsAdaptive.Enabled {
+			if rt.RequestLimitsWriteRate == rate.Inf {
+				return fmt.Errorf("limits.request_limits.write_rate must be set when limits.request_limits.adaptive.enabled is true")
+			}
+			if rt.RequestLimitsAdaptive.CommitTimeTarget < 0 ||
+				rt.RequestLimitsAdaptive.ApplyQueueTarget < 0 ||
+				rt.RequestLimitsAdaptive.FSMApplyTimeTarget < 0 {
+				return fmt.Errorf("limits.request_limits.adaptive targets cannot be negative")
+			}
+			if rt.RequestLimitsMode == consulrate.ModeDisabled {
+				b.warn("limits.request_limits.adaptive.enabled has no effect when limits.request_limits.mode is %q", rt.RequestLimitsMode)
+			}
+		}
 	}
 
 	inuse := map[string]string{}
@@ -1798,12 +1890,12 @@ func (b *builder) dnsRecursorStrategyVal(v string) dns.RecursorStrategy {
 	return out
 }
 
-func (b *builder) requestsLimitsModeVal(v string) consulrate.Mode {
+func (b *builder) requestsLimitsModeVal(name, v string) consulrate.Mode {
 	var out consulrate.Mode
 
 	mode, ok := consulrate.RequestLimitsModeFromName(v)
 	if !ok {
-		b.err = multierror.Append(b.err, fmt.Errorf("limits.request_limits.mode: invalid mode: %q", v))
+		b.err = multierror.Append(b.err, fmt.Errorf("%s: invalid mode: %q", name, v))
 	} else {
 		out = mode
 	}
@@ -2515,6 +2607,46 @@ func validateAutoConfigAuthorizer(rt RuntimeConfig) error {
 	return nil
 }
 
+func (b *builder) auditVal(v Audit) audit.Config {
+	val := audit.Config{
+		Enabled: boolVal(v.Enabled),
+	}
+
+	// Sort the sinks so the config is stable.
+	names := make([]string, 0, len(v.Sinks))
+	for name := range v.Sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sink := v.Sinks[name]
+		var mode os.FileMode
+		if m := stringVal(sink.Mode); m != "" {
+			parsed, err := strconv.ParseUint(m, 8, 32)
+			if err != nil {
+				b.err = multierror.Append(b.err, fmt.Errorf("audit.sink[%s].mode: invalid file mode: %q", name, m))
+			}
+			mode = os.FileMode(parsed)
+		}
+		val.Sinks = append(val.Sinks, audit.SinkConfig{
+			Name:              name,
+			Type:              stringVal(sink.Type),
+			Format:            stringValWithDefault(sink.Format, audit.FormatJSON),
+			Path:              stringVal(sink.Path),
+			Mode:              mode,
+			DeliveryGuarantee: stringValWithDefault(sink.DeliveryGuarantee, audit.DeliveryBestEffort),
+			RotateBytes:       intVal(sink.RotateBytes),
+			RotateDuration:    b.durationVal(fmt.Sprintf("audit.sink[%s].rotate_duration", name), sink.RotateDuration),
+			RotateMaxFiles:    intVal(sink.RotateMaxFiles),
+			IncludePaths:      sink.IncludePaths,
+			ExcludePaths:      sink.ExcludePaths,
+			LogRequestBody:    boolVal(sink.LogRequestBody),
+		})
+	}
+	return val
+}
+
 func (b *builder) cloudConfigVal(v *CloudConfigRaw) (val hcpconfig.CloudConfig) {
 	if v == nil {
 		return val
@@ -2740,3 +2872,12 @@ func (b *builder) raftLogStoreConfigVal(raw *RaftLogStoreRaw) consul.RaftLogStor
 	}
 	return cfg
 }
+
+func (b *builder) requestLimitsAdaptiveVal(raw AdaptiveRequestLimits) consulrate.AdaptiveConfig {
+	return consulrate.AdaptiveConfig{
+		Enabled:            boolVal(raw.Enabled),
+		CommitTimeTarget:   b.durationVal("limits.request_limits.adaptive.commit_time_target", raw.CommitTimeTarget),
+		ApplyQueueTarget:   intVal(raw.ApplyQueueTarget),
+		FSMApplyTimeTarget: b.durationVal("limits.request_limits.adaptive.fsm_apply_time_target", raw.FSMApplyTimeTarget),
+	}
+}
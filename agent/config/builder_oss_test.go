@@ -21,12 +21,6 @@ func TestValidateEnterpriseConfigKeys(t *testing.T) {
 	stringVal := "string"
 
 	cases := map[string]testCase{
-		"read_replica": {
-			config: Config{
-				ReadReplica: &boolVal,
-			},
-			badKeys: []string{"read_replica (or the deprecated non_voting_server)"},
-		},
 		"segment": {
 			config: Config{
 				SegmentName: &stringVal,
@@ -39,14 +33,6 @@ func TestValidateEnterpriseConfigKeys(t *testing.T) {
 			},
 			badKeys: []string{"segments"},
 		},
-		"autopilot.redundancy_zone_tag": {
-			config: Config{
-				Autopilot: Autopilot{
-					RedundancyZoneTag: &stringVal,
-				},
-			},
-			badKeys: []string{"autopilot.redundancy_zone_tag"},
-		},
 		"autopilot.upgrade_version_tag": {
 			config: Config{
 				Autopilot: Autopilot{
@@ -117,7 +103,7 @@ func TestValidateEnterpriseConfigKeys(t *testing.T) {
 					},
 				},
 			},
-			badKeys: []string{"read_replica (or the deprecated non_voting_server)", "segment"},
+			badKeys: []string{"segment"},
 		},
 	}
 
@@ -640,11 +640,38 @@ type DNS struct {
 	SOA                *SOA              `mapstructure:"soa"`
 	UseCache           *bool             `mapstructure:"use_cache"`
 	CacheMaxAge        *string           `mapstructure:"cache_max_age"`
+	Filters            map[string]string `mapstructure:"filters"`
+	Views              []DNSView         `mapstructure:"views"`
+	RateLimit          DNSRateLimit      `mapstructure:"rate_limit"`
 
 	// Enterprise Only
 	PreferNamespace *bool `mapstructure:"prefer_namespace"`
 }
 
+// DNSRateLimit configures per-client and response rate limiting of DNS
+// queries.
+type DNSRateLimit struct {
+	Mode          *string  `mapstructure:"mode"`
+	ClientRate    *float64 `mapstructure:"client_rate"`
+	ClientBurst   *int     `mapstructure:"client_burst"`
+	ResponseRate  *float64 `mapstructure:"response_rate"`
+	ResponseBurst *int     `mapstructure:"response_burst"`
+	IPv4PrefixLen *int     `mapstructure:"ipv4_prefix_len"`
+	IPv6PrefixLen *int     `mapstructure:"ipv6_prefix_len"`
+	Slip          *int     `mapstructure:"slip"`
+}
+
+// DNSView configures how DNS answers are presented to clients whose source
+// address falls within one of the view's networks.
+type DNSView struct {
+	Name          *string           `mapstructure:"name"`
+	SourceCIDRs   []string          `mapstructure:"source_cidrs"`
+	TaggedAddress *string           `mapstructure:"tagged_address"`
+	Datacenters   []string          `mapstructure:"datacenters"`
+	Peers         []string          `mapstructure:"peers"`
+	Rewrites      map[string]string `mapstructure:"rewrites"`
+}
+
 type HTTPConfig struct {
 	BlockEndpoints     []string          `mapstructure:"block_endpoints"`
 	AllowWriteHTTPFrom []string          `mapstructure:"allow_write_http_from"`
@@ -654,9 +681,11 @@ type HTTPConfig struct {
 }
 
 type Performance struct {
-	LeaveDrainTime *string `mapstructure:"leave_drain_time"`
-	RaftMultiplier *int    `mapstructure:"raft_multiplier"` // todo(fs): validate as uint
-	RPCHoldTimeout *string `mapstructure:"rpc_hold_timeout"`
+	ConsistentReadMode *string `mapstructure:"consistent_read_mode"`
+	LeaseMaxClockDrift *string `mapstructure:"lease_max_clock_drift"`
+	LeaveDrainTime     *string `mapstructure:"leave_drain_time"`
+	RaftMultiplier     *int    `mapstructure:"raft_multiplier"` // todo(fs): validate as uint
+	RPCHoldTimeout     *string `mapstructure:"rpc_hold_timeout"`
 }
 
 type Telemetry struct {
@@ -709,11 +738,27 @@ type UnixSocket struct {
 }
 
 type RequestLimits struct {
+	Mode      *string               `mapstructure:"mode"`
+	ReadRate  *float64              `mapstructure:"read_rate"`
+	WriteRate *float64              `mapstructure:"write_rate"`
+	IP        KeyedRequestLimits    `mapstructure:"ip"`
+	Accessor  KeyedRequestLimits    `mapstructure:"accessor"`
+	Adaptive  AdaptiveRequestLimits `mapstructure:"adaptive"`
+}
+
+type KeyedRequestLimits struct {
 	Mode      *string  `mapstructure:"mode"`
 	ReadRate  *float64 `mapstructure:"read_rate"`
 	WriteRate *float64 `mapstructure:"write_rate"`
 }
 
+type AdaptiveRequestLimits struct {
+	Enabled            *bool   `mapstructure:"enabled"`
+	CommitTimeTarget   *string `mapstructure:"commit_time_target"`
+	ApplyQueueTarget   *int    `mapstructure:"apply_queue_target"`
+	FSMApplyTimeTarget *string `mapstructure:"fsm_apply_time_target"`
+}
+
 type Limits struct {
 	HTTPMaxConnsPerClient *int          `mapstructure:"http_max_conns_per_client"`
 	HTTPSHandshakeTimeout *string       `mapstructure:"https_handshake_timeout"`
@@ -747,6 +792,8 @@ type ACL struct {
 	Tokens                 Tokens  `mapstructure:"tokens"`
 	EnableTokenPersistence *bool   `mapstructure:"enable_token_persistence"`
 
+	EnableTokenUsageTracking *bool `mapstructure:"enable_token_usage_tracking"`
+
 	// Enterprise Only
 	MSPDisableBootstrap *bool `mapstructure:"msp_disable_bootstrap"`
 }
@@ -803,6 +850,10 @@ type AuditSink struct {
 	RotateBytes       *int    `mapstructure:"rotate_bytes"`
 	RotateDuration    *string `mapstructure:"rotate_duration"`
 	RotateMaxFiles    *int    `mapstructure:"rotate_max_files"`
+
+	IncludePaths   []string `mapstructure:"include_paths"`
+	ExcludePaths   []string `mapstructure:"exclude_paths"`
+	LogRequestBody *bool    `mapstructure:"log_request_body"`
 }
 
 type AutoConfigRaw struct {
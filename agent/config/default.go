@@ -93,6 +93,16 @@ func DefaultSource() Source {
 			udp_answer_limit = 3
 			max_stale = "87600h"
 			recursor_timeout = "2s"
+			rate_limit = {
+				mode = "disabled"
+				client_rate = -1
+				client_burst = 100
+				response_rate = -1
+				response_burst = 10
+				ipv4_prefix_len = 24
+				ipv6_prefix_len = 56
+				slip = 2
+			}
 		}
 		limits = {
 			http_max_conns_per_client = 200
@@ -101,6 +111,22 @@ func DefaultSource() Source {
 				mode = "disabled"
 				read_rate = -1
 				write_rate = -1
+				ip = {
+					mode = "disabled"
+					read_rate = -1
+					write_rate = -1
+				}
+				accessor = {
+					mode = "disabled"
+					read_rate = -1
+					write_rate = -1
+				}
+				adaptive = {
+					enabled = false
+					commit_time_target = "100ms"
+					apply_queue_target = 128
+					fsm_apply_time_target = "20ms"
+				}
 			}
 			rpc_handshake_timeout = "5s"
 			rpc_client_timeout = "60s"
@@ -111,6 +137,8 @@ func DefaultSource() Source {
 			txn_max_req_len = ` + strconv.FormatInt(raft.SuggestedMaxDataSize, 10) + `
 		}
 		performance = {
+			consistent_read_mode = "` + consul.ConsistentReadModeBarrier + `"
+			lease_max_clock_drift = "500ms"
 			leave_drain_time = "5s"
 			raft_multiplier = ` + strconv.Itoa(int(consul.DefaultRaftMultiplier)) + `
 			rpc_hold_timeout = "7s"
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/go-uuid"
 	"golang.org/x/time/rate"
 
+	"github.com/hashicorp/consul/agent/audit"
 	"github.com/hashicorp/consul/agent/cache"
 	"github.com/hashicorp/consul/agent/consul"
 	consulrate "github.com/hashicorp/consul/agent/consul/rate"
@@ -31,6 +32,32 @@ type RuntimeSOAConfig struct {
 	Minttl  uint32 // 0,
 }
 
+// RuntimeDNSView is a DNS view selected by the source address of a client.
+// The first view whose SourceCIDRs contains the client address is used.
+type RuntimeDNSView struct {
+	// Name identifies the view in logs.
+	Name string
+
+	// SourceCIDRs are the client networks the view applies to.
+	SourceCIDRs []*net.IPNet
+
+	// TaggedAddress is the tagged address (e.g. "lan", "wan", "lan_ipv4" or a
+	// custom tag) preferred for service and node addresses. If the service or
+	// node has no such tagged address the regular address is used.
+	TaggedAddress string
+
+	// Datacenters limits the datacenters visible through the view. An empty
+	// list makes every datacenter visible.
+	Datacenters []string
+
+	// Peers limits the cluster peers visible through the view. An empty
+	// list makes every peer visible.
+	Peers []string
+
+	// Rewrites maps addresses to the address returned in their place.
+	Rewrites map[string]string
+}
+
 // StaticRuntimeConfig specifies the subset of configuration the consul agent actually
 // uses and that are not reloadable by configuration auto reload.
 type StaticRuntimeConfig struct {
@@ -93,6 +120,13 @@ type RuntimeConfig struct {
 	// hcl: acl.enable_key_list_policy = (true|false)
 	ACLEnableKeyListPolicy bool
 
+	// ACLEnableTokenUsageTracking makes the agent report the tokens presented
+	// to its HTTP API to the servers, so they can record when, from where and
+	// through which agent each token was last used.
+	//
+	// hcl: acl.enable_token_usage_tracking = (true|false)
+	ACLEnableTokenUsageTracking bool
+
 	// ACLInitialManagementToken is used to bootstrap the ACL system. It should be specified
 	// on the servers in the PrimaryDatacenter. When the leader comes online, it ensures
 	// that the initial management token is available. This provides the initial token.
@@ -139,7 +173,6 @@ type RuntimeConfig struct {
 
 	// AutopilotRedundancyZoneTag is the Meta tag to use for separating servers
 	// into zones for redundancy. If left blank, this feature will be disabled.
-	// (Enterprise-only)
 	//
 	// hcl: autopilot { redundancy_zone_tag = string }
 	AutopilotRedundancyZoneTag string
@@ -298,6 +331,69 @@ type RuntimeConfig struct {
 	// hcl: dns_config { cache_max_age = "duration" }
 	DNSCacheMaxAge time.Duration
 
+	// DNSFilters are named bexpr filter expressions over service health
+	// results that DNS service lookups can reference by name, as in
+	// <name>.filter.<service>.service.consul.
+	//
+	// hcl: dns_config { filters = map[string]string }
+	DNSFilters map[string]string
+
+	// DNSRateLimitMode will disable or enable DNS rate limiting. If not
+	// disabled, it controls whether queries over DNSClientRate or
+	// DNSResponseRate are dropped or truncated ("enforcing") or only logged
+	// and counted ("permissive").
+	//
+	// hcl: dns_config { rate_limit { mode = "permissive" } }
+	DNSRateLimitMode consulrate.Mode
+
+	// DNSClientRate is the number of queries per second allowed from a
+	// single client address.
+	//
+	// hcl: dns_config { rate_limit { client_rate = (float64|MaxFloat64) } }
+	DNSClientRate rate.Limit
+
+	// DNSClientBurst is the number of queries a client may burst above
+	// DNSClientRate.
+	//
+	// hcl: dns_config { rate_limit { client_burst = int } }
+	DNSClientBurst int
+
+	// DNSResponseRate is the number of identical responses per second sent to
+	// a client network, as in BIND's response rate limiting. Responses are
+	// identical when they answer the same name and type with the same rcode.
+	//
+	// hcl: dns_config { rate_limit { response_rate = (float64|MaxFloat64) } }
+	DNSResponseRate rate.Limit
+
+	// DNSResponseBurst is the number of identical responses that may burst
+	// above DNSResponseRate.
+	//
+	// hcl: dns_config { rate_limit { response_burst = int } }
+	DNSResponseBurst int
+
+	// DNSRateLimitIPv4PrefixLen and DNSRateLimitIPv6PrefixLen are the prefix
+	// lengths used to group client addresses into networks for response rate
+	// limiting.
+	//
+	// hcl: dns_config { rate_limit { ipv4_prefix_len = int ipv6_prefix_len = int } }
+	DNSRateLimitIPv4PrefixLen int
+	DNSRateLimitIPv6PrefixLen int
+
+	// DNSRateLimitSlip controls how rate-limited UDP queries are answered in
+	// enforcing mode: every DNSRateLimitSlip-th limited query is answered with
+	// an empty truncated response, prompting legitimate clients to retry over
+	// TCP, and the others are dropped. 0 drops every limited query and 1
+	// truncates every one.
+	//
+	// hcl: dns_config { rate_limit { slip = int } }
+	DNSRateLimitSlip int
+
+	// DNSViews select per client network which addresses, datacenters and
+	// peers are visible in DNS answers.
+	//
+	// hcl: dns_config { views = [{ name = string source_cidrs = []string ... }] }
+	DNSViews []RuntimeDNSView
+
 	// HTTPUseCache whether or not to use cache for http queries. Defaults
 	// to true.
 	//
@@ -471,6 +567,12 @@ type RuntimeConfig struct {
 	// If entries of the same Kind/Name exist already these will not update them.
 	ConfigEntryBootstrap []structs.ConfigEntry
 
+	// Audit configures the audit log, which records an event for each
+	// request to the HTTP API.
+	//
+	// hcl: audit { enabled = (true|false) sink "name" { type = "file" ... } }
+	Audit audit.Config
+
 	// AutoEncryptTLS requires the client to acquire TLS certificates from
 	// servers.
 	AutoEncryptTLS bool
@@ -530,6 +632,13 @@ type RuntimeConfig struct {
 	// deterministic again.
 	ConnectTestCALeafRootChangeSpread time.Duration
 
+	// ConsistentReadMode is how the leader verifies its leadership before
+	// serving a consistent read: "barrier" verifies it with a quorum of
+	// servers for each read, "lease" verifies it once per leader lease.
+	//
+	// hcl: performance { consistent_read_mode = ("barrier"|"lease") }
+	ConsistentReadMode string
+
 	// DNSAddrs contains the list of TCP and UDP addresses the DNS server will
 	// bind to. If the DNS endpoint is disabled (ports.dns <= 0) the list is
 	// empty.
@@ -784,6 +893,13 @@ type RuntimeConfig struct {
 	// hcl: limits { kv_max_value_size = uint64 }
 	KVMaxValueSize uint64
 
+	// LeaseMaxClockDrift is the maximum amount the clocks of the servers can
+	// drift apart during a Raft heartbeat timeout. The leader lease used by
+	// the "lease" consistent read mode is shortened by this amount.
+	//
+	// hcl: performance { lease_max_clock_drift = "duration" }
+	LeaseMaxClockDrift time.Duration
+
 	// LeaveDrainTime is used to wait after a server has left the LAN Serf
 	// pool for RPCs to drain and new requests to be sent to other servers.
 	//
@@ -826,7 +942,7 @@ type RuntimeConfig struct {
 	NodeMeta map[string]string
 
 	// ReadReplica is whether this server will act as a non-voting member
-	// of the cluster to help provide read scalability. (Enterprise-only)
+	// of the cluster to help provide read scalability.
 	//
 	// hcl: non_voting_server = (true|false)
 	// flag: -non-voting-server
@@ -1041,6 +1157,63 @@ type RuntimeConfig struct {
 	// hcl: limits { request_limits { write_rate = (float64|MaxFloat64) } }
 	RequestLimitsWriteRate rate.Limit
 
+	// RequestLimitsIPMode will disable or enable rate limiting by source IP
+	// address. It takes the same values as RequestLimitsMode, applied when
+	// RequestLimitsIPReadRate or RequestLimitsIPWriteRate is exceeded by a
+	// source IP address. The RPCs forwarded by other servers are not limited
+	// by IP address.
+	//
+	// hcl: limits { request_limits { ip { mode = "permissive" } } }
+	RequestLimitsIPMode consulrate.Mode
+
+	// RequestLimitsIPReadRate controls how frequently the RPC and gRPC queries
+	// of each source IP address are allowed to happen.
+	//
+	// hcl: limits { request_limits { ip { read_rate = (float64|MaxFloat64) } } }
+	RequestLimitsIPReadRate rate.Limit
+
+	// RequestLimitsIPWriteRate controls how frequently the RPC and gRPC writes
+	// of each source IP address are allowed to happen.
+	//
+	// hcl: limits { request_limits { ip { write_rate = (float64|MaxFloat64) } } }
+	RequestLimitsIPWriteRate rate.Limit
+
+	// RequestLimitsAccessorMode will disable or enable rate limiting by ACL
+	// token accessor ID. It takes the same values as RequestLimitsMode,
+	// applied when RequestLimitsAccessorReadRate or
+	// RequestLimitsAccessorWriteRate is exceeded by the requests made with an
+	// ACL token.
+	//
+	// hcl: limits { request_limits { accessor { mode = "permissive" } } }
+	RequestLimitsAccessorMode consulrate.Mode
+
+	// RequestLimitsAccessorReadRate controls how frequently the RPC queries
+	// made with each ACL token are allowed to happen.
+	//
+	// hcl: limits { request_limits { accessor { read_rate = (float64|MaxFloat64) } } }
+	RequestLimitsAccessorReadRate rate.Limit
+
+	// RequestLimitsAccessorWriteRate controls how frequently the RPC writes
+	// made with each ACL token are allowed to happen.
+	//
+	// hcl: limits { request_limits { accessor { write_rate = (float64|MaxFloat64) } } }
+	RequestLimitsAccessorWriteRate rate.Limit
+
+	// RequestLimitsAdaptive configures the adaptive write limit, which reduces
+	// RequestLimitsWriteRate while the health signals of the leader exceed
+	// their targets and recovers it gradually once they are back under them.
+	// The low priority writes (e.g. catalog registrations and KV writes) are
+	// shed first, the high priority ones (e.g. session renewals and ACL
+	// logins) last.
+	//
+	// hcl: limits { request_limits { adaptive {
+	//   enabled = (true|false)
+	//   commit_time_target = "duration"
+	//   apply_queue_target = int
+	//   fsm_apply_time_target = "duration"
+	// } } }
+	RequestLimitsAdaptive consulrate.AdaptiveConfig
+
 	// RetryJoinIntervalLAN specifies the amount of time to wait in between join
 	// attempts on agent start. The minimum allowed value is 1 second and
 	// the default is 30s.
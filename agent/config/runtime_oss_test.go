@@ -16,18 +16,15 @@ var testRuntimeConfigSanitizeExpectedFilename = "TestRuntimeConfig_Sanitize.gold
 
 func entFullRuntimeConfig(rt *RuntimeConfig) {}
 
-var enterpriseReadReplicaWarnings = []string{enterpriseConfigKeyError{key: "read_replica (or the deprecated non_voting_server)"}.Error()}
+var enterpriseReadReplicaWarnings []string
 
 var enterpriseConfigKeyWarnings = []string{
 	enterpriseConfigKeyError{key: "license_path"}.Error(),
-	enterpriseConfigKeyError{key: "read_replica (or the deprecated non_voting_server)"}.Error(),
-	enterpriseConfigKeyError{key: "autopilot.redundancy_zone_tag"}.Error(),
 	enterpriseConfigKeyError{key: "autopilot.upgrade_version_tag"}.Error(),
 	enterpriseConfigKeyError{key: "autopilot.disable_upgrade_migration"}.Error(),
 	enterpriseConfigKeyError{key: "dns_config.prefer_namespace"}.Error(),
 	enterpriseConfigKeyError{key: "acl.msp_disable_bootstrap"}.Error(),
 	enterpriseConfigKeyError{key: "acl.tokens.managed_service_provider"}.Error(),
-	enterpriseConfigKeyError{key: "audit"}.Error(),
 }
 
 // OSS-only equivalent of TestConfigFlagsAndEdgecases
@@ -25,6 +25,7 @@ import (
 	hcpconfig "github.com/hashicorp/consul/agent/hcp/config"
 
 	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/audit"
 	"github.com/hashicorp/consul/agent/cache"
 	"github.com/hashicorp/consul/agent/checks"
 	"github.com/hashicorp/consul/agent/consul"
@@ -2081,6 +2082,60 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 		hcl:         []string{`dns_config = { a_record_limit = -1 }`},
 		expectedErr: "dns_config.a_record_limit cannot be -1. Must be greater than or equal to zero",
 	})
+	run(t, testCase{
+		desc: "dns_config.views without source_cidrs",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "dns_config": { "views": [ { "name": "internal", "tagged_address": "lan" } ] } }`},
+		hcl:         []string{`dns_config = { views = [ { name = "internal" tagged_address = "lan" } ] }`},
+		expectedErr: "dns_config.views[0].source_cidrs must contain at least one network",
+	})
+	run(t, testCase{
+		desc: "dns_config.views invalid source_cidrs",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "dns_config": { "views": [ { "source_cidrs": [ "10.0.0.1" ] } ] } }`},
+		hcl:         []string{`dns_config = { views = [ { source_cidrs = [ "10.0.0.1" ] } ] }`},
+		expectedErr: "dns_config.views[0].source_cidrs: invalid cidr: 10.0.0.1",
+	})
+	run(t, testCase{
+		desc: "dns_config.filters invalid name",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "dns_config": { "filters": { "v_2": "Service.Port == 80" } } }`},
+		hcl:         []string{`dns_config = { filters = { "v_2" = "Service.Port == 80" } }`},
+		expectedErr: `dns_config.filters: invalid filter name "v_2"`,
+	})
+	run(t, testCase{
+		desc: "dns_config.filters invalid expression",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "dns_config": { "filters": { "v2": "Service.Bogus == 80" } } }`},
+		hcl:         []string{`dns_config = { filters = { "v2" = "Service.Bogus == 80" } }`},
+		expectedErr: `dns_config.filters["v2"]: invalid filter`,
+	})
+	run(t, testCase{
+		desc: "dns_config.rate_limit.mode invalid",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "dns_config": { "rate_limit": { "mode": "strict" } } }`},
+		hcl:         []string{`dns_config = { rate_limit = { mode = "strict" } }`},
+		expectedErr: `dns_config.rate_limit.mode: invalid mode: "strict"`,
+	})
+	run(t, testCase{
+		desc: "dns_config.rate_limit.ipv4_prefix_len invalid",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json:        []string{`{ "dns_config": { "rate_limit": { "ipv4_prefix_len": 33 } } }`},
+		hcl:         []string{`dns_config = { rate_limit = { ipv4_prefix_len = 33 } }`},
+		expectedErr: "dns_config.rate_limit.ipv4_prefix_len cannot be 33. Must be between 0 and 32",
+	})
 	run(t, testCase{
 		desc: "performance.raft_multiplier < 0",
 		args: []string{
@@ -4644,6 +4699,18 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 			rt.RequestLimitsMode = consulrate.ModeDisabled
 			rt.RequestLimitsReadRate = rate.Inf
 			rt.RequestLimitsWriteRate = rate.Inf
+			rt.RequestLimitsIPMode = consulrate.ModeDisabled
+			rt.RequestLimitsIPReadRate = rate.Inf
+			rt.RequestLimitsIPWriteRate = rate.Inf
+			rt.RequestLimitsAccessorMode = consulrate.ModeDisabled
+			rt.RequestLimitsAccessorReadRate = rate.Inf
+			rt.RequestLimitsAccessorWriteRate = rate.Inf
+			rt.RequestLimitsAdaptive = consulrate.AdaptiveConfig{
+				Enabled:            false,
+				CommitTimeTarget:   100 * time.Millisecond,
+				ApplyQueueTarget:   128,
+				FSMApplyTimeTarget: 20 * time.Millisecond,
+			}
 			rt.SegmentLimit = 64
 			rt.XDSUpdateRateLimit = 250
 			rt.RPCRateLimit = rate.Inf
@@ -5857,6 +5924,180 @@ func TestLoad_IntegrationWithFlags(t *testing.T) {
 			}`},
 		expectedErr: "raft_logstore.backend must be one of 'boltdb' or 'wal'",
 	})
+	run(t, testCase{
+		desc: "invalid consistent read mode",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json: []string{`
+			{
+				"server": true,
+				"performance": {
+					"consistent_read_mode": "stale"
+				}
+			}`},
+		hcl: []string{`
+			server = true
+			performance {
+				consistent_read_mode = "stale"
+			}`},
+		expectedErr: "performance.consistent_read_mode must be one of 'barrier' or 'lease'",
+	})
+	run(t, testCase{
+		desc: "lease clock drift higher than the heartbeat timeout",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json: []string{`
+			{
+				"server": true,
+				"performance": {
+					"consistent_read_mode": "lease",
+					"lease_max_clock_drift": "10s"
+				}
+			}`},
+		hcl: []string{`
+			server = true
+			performance {
+				consistent_read_mode = "lease"
+				lease_max_clock_drift = "10s"
+			}`},
+		expectedErr: "performance.lease_max_clock_drift (10s) must be lower than the Raft heartbeat timeout (5s)",
+	})
+	run(t, testCase{
+		desc: "lease consistent read mode",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json: []string{`
+			{
+				"server": true,
+				"performance": {
+					"consistent_read_mode": "lease"
+				}
+			}`},
+		hcl: []string{`
+			server = true
+			performance {
+				consistent_read_mode = "lease"
+			}`},
+		expected: func(rt *RuntimeConfig) {
+			rt.DataDir = dataDir
+			rt.ServerMode = true
+			rt.TLS.ServerMode = true
+			rt.LeaveOnTerm = false
+			rt.SkipLeaveOnInt = true
+			rt.RPCConfig.EnableStreaming = true
+			rt.GRPCTLSPort = 8503
+			rt.GRPCTLSAddrs = []net.Addr{defaultGrpcTlsAddr}
+			rt.ConsistentReadMode = "lease"
+		},
+	})
+	run(t, testCase{
+		desc: "adaptive request limits without a write rate",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json: []string{`
+			{
+				"server": true,
+				"limits": {
+					"request_limits": {
+						"mode": "enforcing",
+						"adaptive": {
+							"enabled": true
+						}
+					}
+				}
+			}`},
+		hcl: []string{`
+			server = true
+			limits {
+				request_limits {
+					mode = "enforcing"
+					adaptive {
+						enabled = true
+					}
+				}
+			}`},
+		expectedErr: "limits.request_limits.write_rate must be set when limits.request_limits.adaptive.enabled is true",
+	})
+	run(t, testCase{
+		desc: "adaptive request limits with negative targets",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json: []string{`
+			{
+				"server": true,
+				"limits": {
+					"request_limits": {
+						"mode": "enforcing",
+						"write_rate": 100,
+						"adaptive": {
+							"enabled": true,
+							"apply_queue_target": -1
+						}
+					}
+				}
+			}`},
+		hcl: []string{`
+			server = true
+			limits {
+				request_limits {
+					mode = "enforcing"
+					write_rate = 100
+					adaptive {
+						enabled = true
+						apply_queue_target = -1
+					}
+				}
+			}`},
+		expectedErr: "limits.request_limits.adaptive targets cannot be negative",
+	})
+	run(t, testCase{
+		desc: "adaptive request limits with request limits disabled",
+		args: []string{
+			`-data-dir=` + dataDir,
+		},
+		json: []string{`
+			{
+				"server": true,
+				"limits": {
+					"request_limits": {
+						"write_rate": 100,
+						"adaptive": {
+							"enabled": true
+						}
+					}
+				}
+			}`},
+		hcl: []string{`
+			server = true
+			limits {
+				request_limits {
+					write_rate = 100
+					adaptive {
+						enabled = true
+					}
+				}
+			}`},
+		expected: func(rt *RuntimeConfig) {
+			rt.DataDir = dataDir
+			rt.ServerMode = true
+			rt.TLS.ServerMode = true
+			rt.LeaveOnTerm = false
+			rt.SkipLeaveOnInt = true
+			rt.RPCConfig.EnableStreaming = true
+			rt.GRPCTLSPort = 8503
+			rt.GRPCTLSAddrs = []net.Addr{defaultGrpcTlsAddr}
+			rt.RequestLimitsWriteRate = 100
+			rt.RequestLimitsAdaptive.Enabled = true
+		},
+		expectedWarnings: []string{
+			`limits.request_limits.adaptive.enabled has no effect when limits.request_limits.mode is "disabled"`,
+		},
+	})
 	run(t, testCase{
 		desc: "raft_logstore merging",
 		args: []string{
@@ -6055,6 +6296,7 @@ func TestLoad_FullConfig(t *testing.T) {
 			ACLRoleTTL:       9876 * time.Second,
 		},
 		ACLEnableKeyListPolicy:           true,
+		ACLEnableTokenUsageTracking:      true,
 		ACLInitialManagementToken:        "3820e09a",
 		ACLTokenReplication:              true,
 		AdvertiseAddrLAN:                 ipAddr("17.99.29.16"),
@@ -6178,6 +6420,25 @@ func TestLoad_FullConfig(t *testing.T) {
 				},
 			},
 		},
+		Audit: audit.Config{
+			Enabled: true,
+			Sinks: []audit.SinkConfig{
+				{
+					Name:              "main",
+					Type:              "file",
+					Format:            "json",
+					Path:              "/var/log/consul/audit.json",
+					Mode:              0600,
+					DeliveryGuarantee: "blocking",
+					RotateBytes:       1048576,
+					RotateDuration:    12 * time.Hour,
+					RotateMaxFiles:    7,
+					IncludePaths:      []string{"/v1/acl/", "/v1/kv/"},
+					ExcludePaths:      []string{"/v1/acl/token/self"},
+					LogRequestBody:    true,
+				},
+			},
+		},
 		AutoEncryptTLS:      false,
 		AutoEncryptDNSSAN:   []string{"a.com", "b.com"},
 		AutoEncryptIPSAN:    []net.IP{net.ParseIP("192.168.4.139"), net.ParseIP("192.168.4.140")},
@@ -6241,26 +6502,46 @@ func TestLoad_FullConfig(t *testing.T) {
 			AuthURL:      "332nCdR2",
 			ScadaAddress: "aoeusth232",
 		},
-		DNSAddrs:                         []net.Addr{tcpAddr("93.95.95.81:7001"), udpAddr("93.95.95.81:7001")},
-		DNSARecordLimit:                  29907,
-		DNSAllowStale:                    true,
-		DNSDisableCompression:            true,
-		DNSDomain:                        "7W1xXSqd",
-		DNSAltDomain:                     "1789hsd",
-		DNSEnableTruncate:                true,
-		DNSMaxStale:                      29685 * time.Second,
-		DNSNodeTTL:                       7084 * time.Second,
-		DNSOnlyPassing:                   true,
-		DNSPort:                          7001,
-		DNSRecursorStrategy:              "sequential",
-		DNSRecursorTimeout:               4427 * time.Second,
-		DNSRecursors:                     []string{"63.38.39.58", "92.49.18.18"},
-		DNSSOA:                           RuntimeSOAConfig{Refresh: 3600, Retry: 600, Expire: 86400, Minttl: 0},
-		DNSServiceTTL:                    map[string]time.Duration{"*": 32030 * time.Second},
-		DNSUDPAnswerLimit:                29909,
-		DNSNodeMetaTXT:                   true,
-		DNSUseCache:                      true,
-		DNSCacheMaxAge:                   5 * time.Minute,
+		ConsistentReadMode:    "lease",
+		DNSAddrs:              []net.Addr{tcpAddr("93.95.95.81:7001"), udpAddr("93.95.95.81:7001")},
+		DNSARecordLimit:       29907,
+		DNSAllowStale:         true,
+		DNSDisableCompression: true,
+		DNSDomain:             "7W1xXSqd",
+		DNSAltDomain:          "1789hsd",
+		DNSEnableTruncate:     true,
+		DNSMaxStale:           29685 * time.Second,
+		DNSNodeTTL:            7084 * time.Second,
+		DNSOnlyPassing:        true,
+		DNSPort:               7001,
+		DNSRecursorStrategy:   "sequential",
+		DNSRecursorTimeout:    4427 * time.Second,
+		DNSRecursors:          []string{"63.38.39.58", "92.49.18.18"},
+		DNSSOA:                RuntimeSOAConfig{Refresh: 3600, Retry: 600, Expire: 86400, Minttl: 0},
+		DNSServiceTTL:         map[string]time.Duration{"*": 32030 * time.Second},
+		DNSUDPAnswerLimit:     29909,
+		DNSNodeMetaTXT:        true,
+		DNSUseCache:           true,
+		DNSCacheMaxAge:        5 * time.Minute,
+		DNSViews: []RuntimeDNSView{
+			{
+				Name:          "dZs5Mhtx",
+				SourceCIDRs:   []*net.IPNet{cidr("10.18.0.0/16")},
+				TaggedAddress: "wan",
+				Datacenters:   []string{"rzo029wg"},
+				Peers:         []string{"ekjA1k3W"},
+				Rewrites:      map[string]string{"10.18.7.2": "198.18.7.2"},
+			},
+		},
+		DNSFilters:                       map[string]string{"v2": `Service.Meta.version == "2"`},
+		DNSRateLimitMode:                 consulrate.ModeEnforcing,
+		DNSClientRate:                    231.0,
+		DNSClientBurst:                   462,
+		DNSResponseRate:                  17.0,
+		DNSResponseBurst:                 34,
+		DNSRateLimitIPv4PrefixLen:        20,
+		DNSRateLimitIPv6PrefixLen:        48,
+		DNSRateLimitSlip:                 3,
 		DataDir:                          dataDir,
 		Datacenter:                       "rzo029wg",
 		DefaultQueryTime:                 16743 * time.Second,
@@ -6300,6 +6581,7 @@ func TestLoad_FullConfig(t *testing.T) {
 		HTTPSPort:             15127,
 		HTTPUseCache:          false,
 		KVMaxValueSize:        1234567800,
+		LeaseMaxClockDrift:    317 * time.Millisecond,
 		LeaveDrainTime:        8265 * time.Second,
 		LeaveOnTerm:           true,
 		Logging: logging.Config{
@@ -6308,33 +6590,45 @@ func TestLoad_FullConfig(t *testing.T) {
 			EnableSyslog:   true,
 			SyslogFacility: "hHv79Uia",
 		},
-		MaxQueryTime:            18237 * time.Second,
-		NodeID:                  types.NodeID("AsUIlw99"),
-		NodeMeta:                map[string]string{"5mgGQMBk": "mJLtVMSG", "A7ynFMJB": "0Nx6RGab"},
-		NodeName:                "otlLxGaI",
-		ReadReplica:             true,
-		PeeringEnabled:          true,
-		PidFile:                 "43xN80Km",
-		PrimaryGateways:         []string{"aej8eeZo", "roh2KahS"},
-		PrimaryGatewaysInterval: 18866 * time.Second,
-		RPCAdvertiseAddr:        tcpAddr("17.99.29.16:3757"),
-		RPCBindAddr:             tcpAddr("16.99.34.17:3757"),
-		RPCHandshakeTimeout:     1932 * time.Millisecond,
-		RPCClientTimeout:        62 * time.Second,
-		RPCHoldTimeout:          15707 * time.Second,
-		RPCProtocol:             30793,
-		RPCRateLimit:            12029.43,
-		RPCMaxBurst:             44848,
-		RPCMaxConnsPerClient:    2954,
-		RaftProtocol:            3,
-		RaftSnapshotThreshold:   16384,
-		RaftSnapshotInterval:    30 * time.Second,
-		RaftTrailingLogs:        83749,
-		ReconnectTimeoutLAN:     23739 * time.Second,
-		ReconnectTimeoutWAN:     26694 * time.Second,
-		RequestLimitsMode:       consulrate.ModePermissive,
-		RequestLimitsReadRate:   99.0,
-		RequestLimitsWriteRate:  101.0,
+		MaxQueryTime:                   18237 * time.Second,
+		NodeID:                         types.NodeID("AsUIlw99"),
+		NodeMeta:                       map[string]string{"5mgGQMBk": "mJLtVMSG", "A7ynFMJB": "0Nx6RGab"},
+		NodeName:                       "otlLxGaI",
+		ReadReplica:                    true,
+		PeeringEnabled:                 true,
+		PidFile:                        "43xN80Km",
+		PrimaryGateways:                []string{"aej8eeZo", "roh2KahS"},
+		PrimaryGatewaysInterval:        18866 * time.Second,
+		RPCAdvertiseAddr:               tcpAddr("17.99.29.16:3757"),
+		RPCBindAddr:                    tcpAddr("16.99.34.17:3757"),
+		RPCHandshakeTimeout:            1932 * time.Millisecond,
+		RPCClientTimeout:               62 * time.Second,
+		RPCHoldTimeout:                 15707 * time.Second,
+		RPCProtocol:                    30793,
+		RPCRateLimit:                   12029.43,
+		RPCMaxBurst:                    44848,
+		RPCMaxConnsPerClient:           2954,
+		RaftProtocol:                   3,
+		RaftSnapshotThreshold:          16384,
+		RaftSnapshotInterval:           30 * time.Second,
+		RaftTrailingLogs:               83749,
+		ReconnectTimeoutLAN:            23739 * time.Second,
+		ReconnectTimeoutWAN:            26694 * time.Second,
+		RequestLimitsMode:              consulrate.ModePermissive,
+		RequestLimitsReadRate:          99.0,
+		RequestLimitsWriteRate:         101.0,
+		RequestLimitsIPMode:            consulrate.ModeEnforcing,
+		RequestLimitsIPReadRate:        23.0,
+		RequestLimitsIPWriteRate:       19.0,
+		RequestLimitsAccessorMode:      consulrate.ModePermissive,
+		RequestLimitsAccessorReadRate:  31.0,
+		RequestLimitsAccessorWriteRate: 29.0,
+		RequestLimitsAdaptive: consulrate.AdaptiveConfig{
+			Enabled:            true,
+			CommitTimeTarget:   83 * time.Millisecond,
+			ApplyQueueTarget:   47,
+			FSMApplyTimeTarget: 13 * time.Millisecond,
+		},
 		RejoinAfterLeave:        true,
 		RetryJoinIntervalLAN:    8067 * time.Second,
 		RetryJoinIntervalWAN:    28866 * time.Second,
@@ -14,6 +14,7 @@ acl = {
     default_policy = "72c2e7a0"
     enable_key_list_policy = true
     enable_token_persistence = true
+    enable_token_usage_tracking = true
     policy_ttl = "1123s"
     role_ttl = "9876s"
     token_ttl = "3321s"
@@ -51,6 +52,19 @@ advertise_addr_wan = "78.63.37.19"
 advertise_reconnect_timeout = "0s"
 audit = {
     enabled = true
+    sink "main" {
+        type = "file"
+        format = "json"
+        path = "/var/log/consul/audit.json"
+        delivery_guarantee = "blocking"
+        mode = "0600"
+        rotate_bytes = 1048576
+        rotate_duration = "12h"
+        rotate_max_files = 7
+        include_paths = ["/v1/acl/", "/v1/kv/"]
+        exclude_paths = ["/v1/acl/token/self"]
+        log_request_body = true
+    }
 }
 auto_config = {
     enabled = false
@@ -271,6 +285,31 @@ dns_config {
     use_cache = true
     cache_max_age = "5m"
     prefer_namespace = true
+    filters = {
+        "v2" = "Service.Meta.version == \"2\""
+    }
+    rate_limit {
+        mode = "enforcing"
+        client_rate = 231.0
+        client_burst = 462
+        response_rate = 17.0
+        response_burst = 34
+        ipv4_prefix_len = 20
+        ipv6_prefix_len = 48
+        slip = 3
+    }
+    views = [
+        {
+            name = "dZs5Mhtx"
+            source_cidrs = [ "10.18.0.0/16" ]
+            tagged_address = "wan"
+            datacenters = [ "rzo029wg" ]
+            peers = [ "ekjA1k3W" ]
+            rewrites = {
+                "10.18.7.2" = "198.18.7.2"
+            }
+        }
+    ]
 }
 enable_acl_replication = true
 enable_agent_tls_for_checks = true
@@ -309,6 +348,22 @@ limits {
         mode = "permissive"
         read_rate = 99.0
         write_rate = 101.0
+        ip {
+            mode = "enforcing"
+            read_rate = 23.0
+            write_rate = 19.0
+        }
+        accessor {
+            mode = "permissive"
+            read_rate = 31.0
+            write_rate = 29.0
+        }
+        adaptive {
+            enabled = true
+            commit_time_target = "83ms"
+            apply_queue_target = 47
+            fsm_apply_time_target = "13ms"
+        }
     }
 }
 log_level = "k1zo9Spt"
@@ -326,6 +381,8 @@ peering {
     enabled = true
 }
 performance {
+    consistent_read_mode = "lease"
+    lease_max_clock_drift = "317ms"
     leave_drain_time = "8265s"
     raft_multiplier = 5
     rpc_hold_timeout = "15707s"
@@ -99,6 +99,10 @@ func (id *missingIdentity) NodeIdentityList() []*structs.ACLNodeIdentity {
 	return nil
 }
 
+func (id *missingIdentity) TemplatedPolicyList() []*structs.ACLTemplatedPolicy {
+	return nil
+}
+
 func (id *missingIdentity) IsExpired(asOf time.Time) bool {
 	return false
 }
@@ -396,9 +400,12 @@ func (r *ACLResolver) resolveIdentityFromToken(token string) (structs.ACLIdentit
 		return identity, err
 	}
 
-	// Check the cache before making any RPC requests
+	// Check the cache before making any RPC requests. Renewable tokens may
+	// have been renewed since they were cached, so expired identities are
+	// fetched again.
 	cacheEntry := r.cache.GetIdentityWithSecretToken(token)
-	if cacheEntry != nil && cacheEntry.Age() <= r.config.ACLTokenTTL {
+	if cacheEntry != nil && cacheEntry.Age() <= r.config.ACLTokenTTL &&
+		(cacheEntry.Identity == nil || !cacheEntry.Identity.IsExpired(time.Now())) {
 		metrics.IncrCounter([]string{"acl", "token", "cache_hit"}, 1)
 		return cacheEntry.Identity, nil
 	}
@@ -593,9 +600,10 @@ func (r *ACLResolver) resolvePoliciesForIdentity(identity structs.ACLIdentity) (
 		roleIDs           = identity.RoleIDs()
 		serviceIdentities = structs.ACLServiceIdentities(identity.ServiceIdentityList())
 		nodeIdentities    = structs.ACLNodeIdentities(identity.NodeIdentityList())
+		templatedPolicies = structs.ACLTemplatedPolicies(identity.TemplatedPolicyList())
 	)
 
-	if len(policyIDs) == 0 && len(serviceIdentities) == 0 && len(roleIDs) == 0 && len(nodeIdentities) == 0 {
+	if len(policyIDs) == 0 && len(serviceIdentities) == 0 && len(roleIDs) == 0 && len(nodeIdentities) == 0 && len(templatedPolicies) == 0 {
 		// In this case the default policy will be all that is in effect.
 		return nil, nil
 	}
@@ -613,23 +621,35 @@ func (r *ACLResolver) resolvePoliciesForIdentity(identity structs.ACLIdentity) (
 		}
 		serviceIdentities = append(serviceIdentities, role.ServiceIdentities...)
 		nodeIdentities = append(nodeIdentities, role.NodeIdentityList()...)
+		templatedPolicies = append(templatedPolicies, role.TemplatedPolicyList()...)
 	}
 
 	// Now deduplicate any policies or service identities that occur more than once.
 	policyIDs = dedupeStringSlice(policyIDs)
 	serviceIdentities = serviceIdentities.Deduplicate()
 	nodeIdentities = nodeIdentities.Deduplicate()
+	templatedPolicies = templatedPolicies.Deduplicate()
 
 	// Generate synthetic policies for all service identities in effect.
 	syntheticPolicies := r.synthesizePoliciesForServiceIdentities(serviceIdentities, identity.EnterpriseMetadata())
 	syntheticPolicies = append(syntheticPolicies, r.synthesizePoliciesForNodeIdentities(nodeIdentities, identity.EnterpriseMetadata())...)
 
+	// Policy templates are fetched along with the policies linked directly,
+	// and rendered into synthetic policies afterwards.
+	fetchIDs := policyIDs
+	if len(templatedPolicies) > 0 {
+		fetchIDs = mergeStringSlice(policyIDs, templatedPolicies.PolicyIDs())
+	}
+
 	// For the new ACLs policy replication is mandatory for correct operation on servers. Therefore
 	// we only attempt to resolve policies locally
-	policies, err := r.collectPoliciesForIdentity(identity, policyIDs, len(syntheticPolicies))
+	policies, err := r.collectPoliciesForIdentity(identity, fetchIDs, len(syntheticPolicies)+len(templatedPolicies))
 	if err != nil {
 		return nil, err
 	}
+	if len(templatedPolicies) > 0 {
+		policies = r.renderTemplatedPolicies(identity, policies, policyIDs, templatedPolicies)
+	}
 
 	policies = append(policies, syntheticPolicies...)
 	filtered := r.filterPoliciesByScope(policies)
@@ -666,6 +686,49 @@ func (r *ACLResolver) synthesizePoliciesForNodeIdentities(nodeIdentities []*stru
 	return syntheticPolicies
 }
 
+// renderTemplatedPolicies returns the fetched policies that are linked
+// directly, along with the synthetic policies rendered from the templates
+// linked with variables. Templates can't be linked directly, and links to
+// templates that no longer render with the given variables are ignored.
+func (r *ACLResolver) renderTemplatedPolicies(identity structs.ACLIdentity, fetched []*structs.ACLPolicy, policyIDs []string, templatedPolicies structs.ACLTemplatedPolicies) []*structs.ACLPolicy {
+	linked := make(map[string]struct{}, len(policyIDs))
+	for _, id := range policyIDs {
+		linked[id] = struct{}{}
+	}
+
+	policies := make([]*structs.ACLPolicy, 0, len(fetched)+len(templatedPolicies))
+	templates := make(map[string]*structs.ACLPolicy)
+	for _, policy := range fetched {
+		if policy.IsTemplate() {
+			templates[policy.ID] = policy
+			continue
+		}
+		if _, ok := linked[policy.ID]; ok {
+			policies = append(policies, policy)
+		}
+	}
+
+	for _, link := range templatedPolicies {
+		template, ok := templates[link.ID]
+		if !ok {
+			continue
+		}
+
+		policy, err := link.SyntheticPolicy(template)
+		if err != nil {
+			r.logger.Warn("failed to render policy template for identity",
+				"policy", link.ID,
+				"accessorID", acl.AliasIfAnonymousToken(identity.ID()),
+				"error", err,
+			)
+			continue
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies
+}
+
 func mergeStringSlice(a, b []string) []string {
 	out := make([]string, 0, len(a)+len(b))
 	out = append(out, a...)
@@ -349,6 +349,24 @@ func (a *ACL) lookupExpandedTokenInfo(ws memdb.WatchSet, state *state.Store, tok
 	identityPolicies := make(map[string]*structs.ACLPolicy)
 	tokenInfo := structs.ExpandedTokenInfo{}
 
+	addTemplatedPolicies := func(links []*structs.ACLTemplatedPolicy, entMeta *acl.EnterpriseMeta) error {
+		for _, link := range links {
+			_, template, err := state.ACLPolicyGetByID(ws, link.ID, entMeta)
+			if err != nil {
+				return err
+			}
+			if template == nil || !template.IsTemplate() {
+				continue
+			}
+			policy, err := link.SyntheticPolicy(template)
+			if err != nil {
+				continue
+			}
+			identityPolicies[policy.ID] = policy
+		}
+		return nil
+	}
+
 	// Add the token's policies and node/service identity policies
 	for _, policy := range token.Policies {
 		policyIDs[policy.ID] = struct{}{}
@@ -365,6 +383,9 @@ func (a *ACL) lookupExpandedTokenInfo(ws memdb.WatchSet, state *state.Store, tok
 		policy := identity.SyntheticPolicy(&token.EnterpriseMeta)
 		identityPolicies[policy.ID] = policy
 	}
+	if err := addTemplatedPolicies(token.TemplatedPolicies, &token.EnterpriseMeta); err != nil {
+		return tokenInfo, err
+	}
 
 	// Get any namespace default roles/policies to look up
 	nsPolicies, nsRoles, err := getTokenNamespaceDefaults(ws, state, &token.EnterpriseMeta)
@@ -402,6 +423,9 @@ func (a *ACL) lookupExpandedTokenInfo(ws memdb.WatchSet, state *state.Store, tok
 			policy := identity.SyntheticPolicy(&role.EnterpriseMeta)
 			identityPolicies[policy.ID] = policy
 		}
+		if err := addTemplatedPolicies(role.TemplatedPolicies, &role.EnterpriseMeta); err != nil {
+			return tokenInfo, err
+		}
 
 		tokenInfo.ExpandedRoles = append(tokenInfo.ExpandedRoles, role)
 	}
@@ -483,6 +507,7 @@ func (a *ACL) TokenClone(args *structs.ACLTokenSetRequest, reply *structs.ACLTok
 		Roles:             token.Roles,
 		ServiceIdentities: token.ServiceIdentities,
 		NodeIdentities:    token.NodeIdentities,
+		TemplatedPolicies: token.TemplatedPolicies,
 		Local:             token.Local,
 		Description:       token.Description,
 		ExpirationTime:    token.ExpirationTime,
@@ -500,6 +525,114 @@ func (a *ACL) TokenClone(args *structs.ACLTokenSetRequest, reply *structs.ACLTok
 	return err
 }
 
+func (a *ACL) TokenRotate(args *structs.ACLTokenRotateRequest, reply *structs.ACLToken) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.EnterpriseMeta, true); err != nil {
+		return err
+	}
+
+	// clients will not know whether the server has local token store. In the case
+	// where it doesn't we will transparently forward requests.
+	if !a.srv.LocalTokensEnabled() {
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.TokenRotate", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"acl", "token", "rotate"}, time.Now())
+
+	var authzContext acl.AuthorizerContext
+	authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, &authzContext)
+	if err != nil {
+		return err
+	} else if err := authz.ToAllowAuthorizer().ACLWriteAllowed(&authzContext); err != nil {
+		return err
+	}
+
+	_, token, err := a.srv.fsm.State().ACLTokenGetByAccessor(nil, args.AccessorID, &args.EnterpriseMeta)
+	if err != nil {
+		return err
+	} else if token == nil {
+		if ns := args.EnterpriseMeta.NamespaceOrEmpty(); ns != "" {
+			return fmt.Errorf("token not found in namespace %s: %w", ns, acl.ErrNotFound)
+		}
+		return fmt.Errorf("token does not exist: %w", acl.ErrNotFound)
+	} else if token.IsExpired(time.Now()) {
+		return fmt.Errorf("token is expired: %w", acl.ErrNotFound)
+	} else if !a.srv.InPrimaryDatacenter() && !token.Local {
+		// global token writes must be forwarded to the primary DC
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+		return a.srv.forwardDC("ACL.TokenRotate", a.srv.config.PrimaryDatacenter, args, reply)
+	}
+
+	updated, err := a.srv.aclTokenWriter().Rotate(args.AccessorID, &args.EnterpriseMeta, args.GracePeriod)
+	if err == nil {
+		*reply = *updated
+	}
+	return err
+}
+
+func (a *ACL) TokenRenew(args *structs.ACLTokenRenewRequest, reply *structs.ACLToken) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if err := a.srv.validateEnterpriseRequest(&args.EnterpriseMeta, true); err != nil {
+		return err
+	}
+
+	// clients will not know whether the server has local token store. In the case
+	// where it doesn't we will transparently forward requests.
+	if !a.srv.LocalTokensEnabled() {
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.TokenRenew", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"acl", "token", "renew"}, time.Now())
+
+	// Tokens may always renew themselves, renewing other tokens requires
+	// acl:write.
+	var authzContext acl.AuthorizerContext
+	authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, &args.EnterpriseMeta, &authzContext)
+	if err != nil {
+		return err
+	} else if authz.AccessorID() != args.AccessorID {
+		if err := authz.ToAllowAuthorizer().ACLWriteAllowed(&authzContext); err != nil {
+			return err
+		}
+	}
+
+	_, token, err := a.srv.fsm.State().ACLTokenGetByAccessor(nil, args.AccessorID, &args.EnterpriseMeta)
+	if err != nil {
+		return err
+	} else if token == nil {
+		if ns := args.EnterpriseMeta.NamespaceOrEmpty(); ns != "" {
+			return fmt.Errorf("token not found in namespace %s: %w", ns, acl.ErrNotFound)
+		}
+		return fmt.Errorf("token does not exist: %w", acl.ErrNotFound)
+	} else if token.IsExpired(time.Now()) {
+		return fmt.Errorf("token is expired: %w", acl.ErrNotFound)
+	} else if !a.srv.InPrimaryDatacenter() && !token.Local {
+		// global token writes must be forwarded to the primary DC
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+		return a.srv.forwardDC("ACL.TokenRenew", a.srv.config.PrimaryDatacenter, args, reply)
+	}
+
+	updated, err := a.srv.aclTokenWriter().Renew(args.AccessorID, &args.EnterpriseMeta, args.Increment)
+	if err == nil {
+		*reply = *updated
+	}
+	return err
+}
+
 func (a *ACL) TokenSet(args *structs.ACLTokenSetRequest, reply *structs.ACLToken) error {
 	if err := a.aclPreCheck(); err != nil {
 		return err
@@ -676,6 +809,11 @@ func (a *ACL) TokenList(args *structs.ACLTokenListRequest, reply *structs.ACLTok
 		methodMeta.Merge(&requestMeta)
 	}
 
+	filter, err := bexpr.CreateFilter(args.Filter, nil, structs.ACLTokenListStubs{})
+	if err != nil {
+		return err
+	}
+
 	return a.srv.blockingQuery(&args.QueryOptions, &reply.QueryMeta,
 		func(ws memdb.WatchSet, state *state.Store) error {
 			index, tokens, err := state.ACLTokenList(ws, args.IncludeLocal, args.IncludeGlobal, args.Policy, args.Role, args.AuthMethod, methodMeta, &args.EnterpriseMeta)
@@ -696,11 +834,70 @@ func (a *ACL) TokenList(args *structs.ACLTokenListRequest, reply *structs.ACLTok
 			// filter down to just the tokens that the requester has permissions to read
 			a.srv.filterACLWithAuthorizer(authz, &stubs)
 
-			reply.Index, reply.Tokens = index, stubs
+			raw, err := filter.Execute(structs.ACLTokenListStubs(stubs))
+			if err != nil {
+				return err
+			}
+
+			reply.Index, reply.Tokens = index, raw.(structs.ACLTokenListStubs)
 			return nil
 		})
 }
 
+// TokenUsageUpdate records the usage of the tokens that were presented to an
+// agent. The updates are batched and applied to the Raft log periodically.
+// Usage is tracked separately in each datacenter, so unlike token writes
+// this isn't forwarded to the primary datacenter.
+func (a *ACL) TokenUsageUpdate(args *structs.ACLTokenUsageUpdateRequest, reply *struct{}) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.TokenUsageUpdate", args, reply); done {
+		return err
+	}
+
+	var authzContext acl.AuthorizerContext
+	authz, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, nil, &authzContext)
+	if err != nil {
+		return err
+	}
+
+	// Only the agent itself can report what was presented to it.
+	if err := authz.ToAllowAuthorizer().NodeWriteAllowed(args.Node, &authzContext); err != nil {
+		return err
+	}
+
+	// Agents report the tokens they were presented with, so look up which
+	// tokens these are. Anything that doesn't exist is ignored. The times
+	// reported are clamped to the time of the server, a time in the future
+	// would otherwise prevent any later update from being applied.
+	now := time.Now()
+	state := a.srv.fsm.State()
+	updates := make([]*structs.ACLTokenUsageUpdate, 0, len(args.Usage))
+	for secretID, usage := range args.Usage {
+		_, token, err := state.ACLTokenGetBySecret(nil, secretID, nil)
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			continue
+		}
+
+		if usage.LastUsedTime == nil || usage.LastUsedTime.After(now) {
+			usage.LastUsedTime = &now
+		}
+		usage.Agent = args.Node
+		updates = append(updates, &structs.ACLTokenUsageUpdate{
+			AccessorID:     token.AccessorID,
+			Usage:          usage,
+			EnterpriseMeta: token.EnterpriseMeta,
+		})
+	}
+	a.srv.aclTokenUsage.record(updates)
+	return nil
+}
+
 func (a *ACL) TokenBatchRead(args *structs.ACLTokenBatchGetRequest, reply *structs.ACLTokenBatchResponse) error {
 	if err := a.aclPreCheck(); err != nil {
 		return err
@@ -826,6 +1023,28 @@ func (a *ACL) PolicyBatchRead(args *structs.ACLPolicyBatchGetRequest, reply *str
 		})
 }
 
+// validatePolicyTemplate checks the variables of a policy template and returns
+// its rules rendered with placeholder values, to validate them like the rules
+// of other policies.
+func validatePolicyTemplate(policy *structs.ACLPolicy) (string, error) {
+	vars := make(map[string]string, len(policy.TemplateVariables))
+	for _, name := range policy.TemplateVariables {
+		if !acl.IsValidTemplateVariableName(name) {
+			return "", fmt.Errorf("Invalid Policy: invalid template variable name %q. Only lowercase alphanumeric characters and '_' are allowed, starting with a letter", name)
+		}
+		if _, ok := vars[name]; ok {
+			return "", fmt.Errorf("Invalid Policy: duplicate template variable %q", name)
+		}
+		vars[name] = name
+	}
+
+	rules, err := policy.RenderTemplate(vars)
+	if err != nil {
+		return "", fmt.Errorf("Invalid Policy: %v", err)
+	}
+	return rules, nil
+}
+
 func (a *ACL) PolicySet(args *structs.ACLPolicySetRequest, reply *structs.ACLPolicy) error {
 	if err := a.aclPreCheck(); err != nil {
 		return err
@@ -921,10 +1140,21 @@ func (a *ACL) PolicySet(args *structs.ACLPolicySetRequest, reply *structs.ACLPol
 				return fmt.Errorf("Changing the Rules for the builtin global-management policy is not permitted")
 			}
 		}
+
+		// Tokens and roles link templates differently than other policies.
+		if idMatch.IsTemplate() != policy.IsTemplate() {
+			return fmt.Errorf("Invalid Policy: cannot change whether a policy is a template")
+		}
 	}
 
 	// validate the rules
-	_, err = acl.NewPolicyFromSource(policy.Rules, a.srv.aclConfig, policy.EnterprisePolicyMeta())
+	rules := policy.Rules
+	if policy.IsTemplate() {
+		if rules, err = validatePolicyTemplate(policy); err != nil {
+			return err
+		}
+	}
+	_, err = acl.NewPolicyFromSource(rules, a.srv.aclConfig, policy.EnterprisePolicyMeta())
 	if err != nil {
 		return err
 	}
@@ -1306,8 +1536,9 @@ func (a *ACL) RoleSet(args *structs.ACLRoleSetRequest, reply *structs.ACLRole) e
 
 	// Validate all the policy names and convert them to policy IDs
 	for _, link := range role.Policies {
+		var policy *structs.ACLPolicy
 		if link.ID == "" {
-			_, policy, err := state.ACLPolicyGetByName(nil, link.Name, &role.EnterpriseMeta)
+			_, policy, err = state.ACLPolicyGetByName(nil, link.Name, &role.EnterpriseMeta)
 			if err != nil {
 				return fmt.Errorf("Error looking up policy for name %q: %v", link.Name, err)
 			}
@@ -1315,6 +1546,14 @@ func (a *ACL) RoleSet(args *structs.ACLRoleSetRequest, reply *structs.ACLRole) e
 				return fmt.Errorf("No such ACL policy with name %q", link.Name)
 			}
 			link.ID = policy.ID
+		} else {
+			_, policy, err = state.ACLPolicyGetByID(nil, link.ID, &role.EnterpriseMeta)
+			if err != nil {
+				return fmt.Errorf("Error looking up policy for ID %q: %v", link.ID, err)
+			}
+		}
+		if policy != nil && policy.IsTemplate() {
+			return fmt.Errorf("ACL policy %q is a template and must be linked as a templated policy", policy.Name)
 		}
 
 		// Do not store the policy name within raft/memdb as the policy could be renamed in the future.
@@ -1351,6 +1590,36 @@ func (a *ACL) RoleSet(args *structs.ACLRoleSetRequest, reply *structs.ACLRole) e
 	}
 	role.NodeIdentities = role.NodeIdentities.Deduplicate()
 
+	// Validate the templated policies and convert template names to IDs
+	for _, link := range role.TemplatedPolicies {
+		var policy *structs.ACLPolicy
+		if link.ID == "" {
+			_, policy, err = state.ACLPolicyGetByName(nil, link.Name, &role.EnterpriseMeta)
+			if err != nil {
+				return fmt.Errorf("Error looking up policy template for name %q: %v", link.Name, err)
+			}
+			if policy == nil {
+				return fmt.Errorf("No such ACL policy template with name %q", link.Name)
+			}
+			link.ID = policy.ID
+		} else {
+			_, policy, err = state.ACLPolicyGetByID(nil, link.ID, &role.EnterpriseMeta)
+			if err != nil {
+				return fmt.Errorf("Error looking up policy template for ID %q: %v", link.ID, err)
+			}
+			if policy == nil {
+				return fmt.Errorf("No such ACL policy template with ID %q", link.ID)
+			}
+		}
+		if err := link.Validate(policy); err != nil {
+			return err
+		}
+
+		// Do not store the template name within raft/memdb as the template could be renamed in the future.
+		link.Name = ""
+	}
+	role.TemplatedPolicies = role.TemplatedPolicies.Deduplicate()
+
 	// calculate the hash for this role
 	role.SetHash(true)
 
@@ -1668,6 +1937,7 @@ func (a *ACL) BindingRuleSet(args *structs.ACLBindingRuleSetRequest, reply *stru
 	case structs.BindingRuleBindTypeService:
 	case structs.BindingRuleBindTypeNode:
 	case structs.BindingRuleBindTypeRole:
+	case structs.BindingRuleBindTypeTemplatedPolicy:
 	default:
 		return fmt.Errorf("Invalid Binding Rule: unknown BindType %q", rule.BindType)
 	}
@@ -1678,6 +1948,10 @@ func (a *ACL) BindingRuleSet(args *structs.ACLBindingRuleSetRequest, reply *stru
 		return fmt.Errorf("Invalid Binding Rule: invalid BindName")
 	}
 
+	if err := auth.IsValidBindVars(rule.BindType, rule.BindVars, blankID.ProjectedVarNames()); err != nil {
+		return fmt.Errorf("Invalid Binding Rule: invalid BindVars: %v", err)
+	}
+
 	req := &structs.ACLBindingRuleBatchSetRequest{
 		BindingRules: structs.ACLBindingRules{rule},
 	}
@@ -1901,6 +2175,18 @@ func (a *ACL) AuthMethodSet(args *structs.ACLAuthMethodSetRequest, reply *struct
 		}
 	}
 
+	if method.TokenRenewable && method.MaxTokenTTL == 0 {
+		return fmt.Errorf("Invalid Auth Method: TokenRenewable requires a MaxTokenTTL")
+	}
+	if method.TokenMaxExpirationTTL != 0 {
+		if !method.TokenRenewable {
+			return fmt.Errorf("Invalid Auth Method: TokenMaxExpirationTTL can only be set with TokenRenewable")
+		} else if method.TokenMaxExpirationTTL < method.MaxTokenTTL {
+			return fmt.Errorf("TokenMaxExpirationTTL %s cannot be less than MaxTokenTTL %s",
+				method.TokenMaxExpirationTTL, method.MaxTokenTTL)
+		}
+	}
+
 	switch method.TokenLocality {
 	case "local", "":
 	case "global":
@@ -2152,3 +2438,70 @@ func (a *ACL) Authorize(args *structs.RemoteACLAuthorizationRequest, reply *[]st
 	*reply = responses
 	return nil
 }
+
+// AuthorizeExplain explains the authorization decisions made for a token by
+// reporting the rules that apply to each request and the one that made the
+// decision. Policies that don't exist yet can be simulated on top of the
+// token's own policies, or on their own.
+func (a *ACL) AuthorizeExplain(args *structs.ACLAuthorizeExplainRequest, reply *structs.ACLAuthorizeExplainResponse) error {
+	if err := a.aclPreCheck(); err != nil {
+		return err
+	}
+
+	// Other tokens are looked up in the state store, so go to the primary
+	// datacenter if we don't have local tokens.
+	if args.AccessorID != "" && !args.PoliciesOnly && !a.srv.LocalTokensEnabled() {
+		args.Datacenter = a.srv.config.PrimaryDatacenter
+	}
+
+	if done, err := a.srv.ForwardRPC("ACL.AuthorizeExplain", args, reply); done {
+		return err
+	}
+
+	defer metrics.MeasureSince([]string{"acl", "authorize", "explain"}, time.Now())
+
+	var authzContext acl.AuthorizerContext
+	result, err := a.srv.ResolveTokenAndDefaultMeta(args.Token, nil, &authzContext)
+	if err != nil {
+		return err
+	}
+
+	var token *structs.ACLToken
+	switch {
+	case args.PoliciesOnly:
+	case args.AccessorID != "":
+		if err := result.ToAllowAuthorizer().ACLReadAllowed(&authzContext); err != nil {
+			return err
+		}
+
+		_, token, err = a.srv.fsm.State().ACLTokenGetByAccessor(nil, args.AccessorID, nil)
+		if err != nil {
+			return err
+		}
+		if token == nil || token.IsExpired(time.Now()) {
+			return fmt.Errorf("token does not exist: %w", acl.ErrNotFound)
+		}
+	default:
+		var ok bool
+		if token, ok = result.ACLIdentity.(*structs.ACLToken); !ok {
+			return fmt.Errorf("token is managed by the agent and can't be explained")
+		}
+	}
+
+	sources, err := a.srv.aclExplainSources(token, args.Policies)
+	if err != nil {
+		return err
+	}
+
+	explanations, err := a.srv.explainAuthorizations(token, sources, args.Requests)
+	if err != nil {
+		return err
+	}
+
+	if token != nil {
+		reply.AccessorID = token.AccessorID
+	}
+	reply.DefaultPolicy = a.srv.config.ACLResolverSettings.ACLDefaultPolicy
+	reply.Explanations = explanations
+	return nil
+}
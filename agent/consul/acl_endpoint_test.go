@@ -515,6 +515,214 @@ func TestACLEndpoint_TokenClone(t *testing.T) {
 	})
 }
 
+func TestACLEndpoint_TokenRotate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, func(c *Config) {
+		c.ACLTokenMinExpirationTTL = 10 * time.Millisecond
+		c.ACLTokenMaxExpirationTTL = time.Hour
+	}, false)
+	waitForLeaderEstablishment(t, srv)
+
+	p1, err := upsertTestPolicy(codec, TestDefaultInitialManagementToken, "dc1")
+	require.NoError(t, err)
+
+	endpoint := ACL{srv: srv}
+
+	rotate := func(t *testing.T, accessorID string, grace time.Duration) (*structs.ACLToken, error) {
+		req := structs.ACLTokenRotateRequest{
+			Datacenter:   "dc1",
+			AccessorID:   accessorID,
+			GracePeriod:  grace,
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		var out structs.ACLToken
+		if err := endpoint.TokenRotate(&req, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+
+	t.Run("with grace period", func(t *testing.T) {
+		t1, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+			t.Policies = []structs.ACLTokenPolicyLink{{ID: p1.ID}}
+		})
+		require.NoError(t, err)
+
+		t2, err := rotate(t, t1.AccessorID, 30*time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, t1.AccessorID, t2.AccessorID)
+		require.NotEqual(t, t1.SecretID, t2.SecretID)
+		require.Equal(t, t1.SecretID, t2.PreviousSecretID)
+		require.Equal(t, t1.Policies, t2.Policies)
+		require.NotEqual(t, t1.Hash, t2.Hash)
+		require.NotNil(t, t2.PreviousSecretExpirationTime)
+
+		// The new secret resolves to the token.
+		_, token, err := srv.fsm.State().ACLTokenGetBySecret(nil, t2.SecretID, nil)
+		require.NoError(t, err)
+		require.Equal(t, t2.AccessorID, token.AccessorID)
+		require.Nil(t, token.ExpirationTime)
+
+		// The old secret resolves to the token until the grace period ends,
+		// without revealing the new secret.
+		_, token, err = srv.fsm.State().ACLTokenGetBySecret(nil, t1.SecretID, nil)
+		require.NoError(t, err)
+		require.Equal(t, t2.AccessorID, token.AccessorID)
+		require.Equal(t, t1.SecretID, token.SecretID)
+		require.Equal(t, t2.PreviousSecretExpirationTime, token.ExpirationTime)
+
+		// Rotating again ends the previous grace period.
+		t3, err := rotate(t, t1.AccessorID, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, t2.SecretID, t3.PreviousSecretID)
+
+		_, token, err = srv.fsm.State().ACLTokenGetBySecret(nil, t1.SecretID, nil)
+		require.NoError(t, err)
+		require.Nil(t, token)
+	})
+
+	t.Run("without grace period", func(t *testing.T) {
+		t1, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", nil)
+		require.NoError(t, err)
+
+		t2, err := rotate(t, t1.AccessorID, 0)
+		require.NoError(t, err)
+		require.NotEqual(t, t1.SecretID, t2.SecretID)
+		require.Empty(t, t2.PreviousSecretID)
+		require.NotEqual(t, t1.Hash, t2.Hash)
+
+		_, token, err := srv.fsm.State().ACLTokenGetBySecret(nil, t1.SecretID, nil)
+		require.NoError(t, err)
+		require.Nil(t, token)
+
+		_, err = srv.ResolveToken(t1.SecretID)
+		require.True(t, acl.IsErrNotFound(err), "unexpected error: %v", err)
+	})
+
+	t.Run("grace period too long", func(t *testing.T) {
+		t1, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", nil)
+		require.NoError(t, err)
+
+		_, err = rotate(t, t1.AccessorID, 2*time.Hour)
+		require.ErrorContains(t, err, "Grace period cannot be more than")
+	})
+
+	t.Run("can't rotate expired token", func(t *testing.T) {
+		t1, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+			t.ExpirationTTL = 11 * time.Millisecond
+		})
+		require.NoError(t, err)
+
+		time.Sleep(30 * time.Millisecond)
+
+		_, err = rotate(t, t1.AccessorID, time.Minute)
+		require.ErrorContains(t, err, "token is expired")
+	})
+
+}
+
+func TestACLEndpoint_TokenRenew(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, func(c *Config) {
+		c.ACLTokenMinExpirationTTL = 10 * time.Millisecond
+		c.ACLTokenMaxExpirationTTL = time.Hour
+	}, false)
+	waitForLeaderEstablishment(t, srv)
+
+	endpoint := ACL{srv: srv}
+
+	renew := func(t *testing.T, token, accessorID string, increment time.Duration) (*structs.ACLToken, error) {
+		req := structs.ACLTokenRenewRequest{
+			Datacenter:   "dc1",
+			AccessorID:   accessorID,
+			Increment:    increment,
+			WriteRequest: structs.WriteRequest{Token: token},
+		}
+		var out structs.ACLToken
+		if err := endpoint.TokenRenew(&req, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+
+	t.Run("renew", func(t *testing.T) {
+		t1, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+			t.ExpirationTTL = 10 * time.Minute
+			t.Renewable = true
+			t.MaxExpirationTTL = 45 * time.Minute
+		})
+		require.NoError(t, err)
+		require.True(t, t1.Renewable)
+		require.Equal(t, 10*time.Minute, t1.RenewalTTL)
+
+		t2, err := renew(t, TestDefaultInitialManagementToken, t1.AccessorID, 30*time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, t1.AccessorID, t2.AccessorID)
+		require.WithinDuration(t, time.Now().Add(30*time.Minute), *t2.ExpirationTime, time.Minute)
+		require.NotEqual(t, t1.Hash, t2.Hash)
+
+		// Renewals are capped at the max expiration time.
+		t3, err := renew(t, TestDefaultInitialManagementToken, t1.AccessorID, time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, t1.MaxExpirationTime, t3.ExpirationTime)
+	})
+
+	t.Run("self", func(t *testing.T) {
+		// The token doesn't grant acl:write, but may renew itself.
+		t1, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+			t.ExpirationTTL = 10 * time.Minute
+			t.Renewable = true
+		})
+		require.NoError(t, err)
+
+		t2, err := renew(t, t1.SecretID, t1.AccessorID, 0)
+		require.NoError(t, err)
+		require.True(t, t2.ExpirationTime.After(*t1.ExpirationTime))
+
+		t3, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+			t.ExpirationTTL = 10 * time.Minute
+			t.Renewable = true
+		})
+		require.NoError(t, err)
+
+		_, err = renew(t, t1.SecretID, t3.AccessorID, 0)
+		require.True(t, acl.IsErrPermissionDenied(err), "unexpected error: %v", err)
+	})
+
+	t.Run("not renewable", func(t *testing.T) {
+		t1, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+			t.ExpirationTTL = 10 * time.Minute
+		})
+		require.NoError(t, err)
+
+		_, err = renew(t, TestDefaultInitialManagementToken, t1.AccessorID, 0)
+		require.ErrorContains(t, err, "is not renewable")
+	})
+
+	t.Run("can't renew expired token", func(t *testing.T) {
+		t1, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(t *structs.ACLToken) {
+			t.ExpirationTTL = 11 * time.Millisecond
+			t.Renewable = true
+		})
+		require.NoError(t, err)
+
+		time.Sleep(30 * time.Millisecond)
+
+		_, err = renew(t, TestDefaultInitialManagementToken, t1.AccessorID, time.Minute)
+		require.ErrorContains(t, err, "token is expired")
+	})
+}
+
 func TestACLEndpoint_TokenSet(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -1978,6 +2186,247 @@ func TestACLEndpoint_TokenBatchRead(t *testing.T) {
 	})
 }
 
+func TestACLEndpoint_TokenUsageUpdate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, func(c *Config) {
+		// Flushing is driven by the test.
+		c.ACLTokenUsageUpdatePeriod = time.Hour
+	}, false)
+	waitForLeaderEstablishment(t, srv)
+
+	aclEp := ACL{srv: srv}
+
+	token, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", nil)
+	require.NoError(t, err)
+
+	used := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("denied without node write", func(t *testing.T) {
+		req := structs.ACLTokenUsageUpdateRequest{
+			Datacenter: "dc1",
+			Node:       "node-1",
+			Usage: map[string]structs.ACLTokenUsage{
+				token.SecretID: {LastUsedTime: &used, SourceAddr: "10.0.0.1"},
+			},
+			WriteRequest: structs.WriteRequest{Token: token.SecretID},
+		}
+		err := aclEp.TokenUsageUpdate(&req, &struct{}{})
+		require.True(t, acl.IsErrPermissionDenied(err), "expected permission denied, got: %v", err)
+	})
+
+	t.Run("records usage", func(t *testing.T) {
+		req := structs.ACLTokenUsageUpdateRequest{
+			Datacenter: "dc1",
+			Node:       "node-1",
+			Usage: map[string]structs.ACLTokenUsage{
+				token.SecretID:                         {LastUsedTime: &used, SourceAddr: "10.0.0.1"},
+				"a0bfe8d4-b2f3-4b48-b387-f28afb820eab": {LastUsedTime: &used, SourceAddr: "10.0.0.2"},
+			},
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		require.NoError(t, aclEp.TokenUsageUpdate(&req, &struct{}{}))
+		require.NoError(t, srv.aclTokenUsage.batchApplyUpdates())
+
+		_, rtoken, err := srv.fsm.State().ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+		require.NoError(t, err)
+		require.NotNil(t, rtoken.Usage.LastUsedTime)
+		require.True(t, used.Equal(*rtoken.Usage.LastUsedTime))
+		require.Equal(t, "10.0.0.1", rtoken.Usage.SourceAddr)
+		require.Equal(t, "node-1", rtoken.Usage.Agent)
+		require.Equal(t, token.ModifyIndex, rtoken.ModifyIndex)
+	})
+
+	t.Run("updates within the granularity are dropped", func(t *testing.T) {
+		later := used.Add(time.Minute)
+		req := structs.ACLTokenUsageUpdateRequest{
+			Datacenter: "dc1",
+			Node:       "node-2",
+			Usage: map[string]structs.ACLTokenUsage{
+				token.SecretID: {LastUsedTime: &later, SourceAddr: "10.0.0.3"},
+			},
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		require.NoError(t, aclEp.TokenUsageUpdate(&req, &struct{}{}))
+		require.NoError(t, srv.aclTokenUsage.batchApplyUpdates())
+
+		_, rtoken, err := srv.fsm.State().ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+		require.NoError(t, err)
+		require.True(t, used.Equal(*rtoken.Usage.LastUsedTime))
+		require.Equal(t, "node-1", rtoken.Usage.Agent)
+	})
+
+	t.Run("times in the future are clamped", func(t *testing.T) {
+		future := time.Now().Add(365 * 24 * time.Hour)
+		req := structs.ACLTokenUsageUpdateRequest{
+			Datacenter: "dc1",
+			Node:       "node-3",
+			Usage: map[string]structs.ACLTokenUsage{
+				token.SecretID: {LastUsedTime: &future, SourceAddr: "10.0.0.4"},
+			},
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		require.NoError(t, aclEp.TokenUsageUpdate(&req, &struct{}{}))
+		require.NoError(t, srv.aclTokenUsage.batchApplyUpdates())
+
+		_, rtoken, err := srv.fsm.State().ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+		require.NoError(t, err)
+		require.False(t, rtoken.Usage.LastUsedTime.After(time.Now()))
+	})
+}
+
+func TestACLEndpoint_AuthorizeExplain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, srv)
+
+	aclEp := ACL{srv: srv}
+
+	readAll, err := upsertTestPolicyWithRules(codec, TestDefaultInitialManagementToken, "dc1", `service_prefix "" { policy = "read" }`)
+	require.NoError(t, err)
+	denyDB, err := upsertTestPolicyWithRules(codec, TestDefaultInitialManagementToken, "dc1", `service "db" { policy = "deny" }`)
+	require.NoError(t, err)
+
+	role, err := upsertTestCustomizedRole(codec, TestDefaultInitialManagementToken, "dc1", func(role *structs.ACLRole) {
+		role.Policies = []structs.ACLRolePolicyLink{{ID: denyDB.ID}}
+	})
+	require.NoError(t, err)
+
+	token, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+		token.Policies = []structs.ACLTokenPolicyLink{{ID: readAll.ID}}
+		token.Roles = []structs.ACLTokenRoleLink{{ID: role.ID}}
+		token.ServiceIdentities = []*structs.ACLServiceIdentity{{ServiceName: "web"}}
+	})
+	require.NoError(t, err)
+
+	requests := []structs.ACLAuthorizationRequest{
+		{Resource: "service", Segment: "web", Access: "write"},
+		{Resource: "service", Segment: "api", Access: "write"},
+		{Resource: "service", Segment: "db", Access: "read"},
+		{Resource: "key", Segment: "foo", Access: "read"},
+	}
+
+	t.Run("own token", func(t *testing.T) {
+		req := structs.ACLAuthorizeExplainRequest{
+			Datacenter:   "dc1",
+			Requests:     requests,
+			QueryOptions: structs.QueryOptions{Token: token.SecretID},
+		}
+		var resp structs.ACLAuthorizeExplainResponse
+		require.NoError(t, aclEp.AuthorizeExplain(&req, &resp))
+
+		require.Equal(t, token.AccessorID, resp.AccessorID)
+		require.Equal(t, "deny", resp.DefaultPolicy)
+		require.Len(t, resp.Explanations, 4)
+
+		// service identity
+		web := resp.Explanations[0]
+		require.True(t, web.Allow)
+		require.False(t, web.DefaultPolicy)
+		require.Equal(t, structs.ACLAuthorizationSourceServiceIdentity, web.Source.Type)
+		require.Equal(t, "web", web.Source.Name)
+		require.Equal(t, `service "web" { policy = "write" }`, web.Source.Rule)
+		// The service identity also grants read on all services.
+		require.Len(t, web.Matches, 3)
+		require.Equal(t, readAll.Name, web.Matches[1].Name)
+		require.Equal(t, "deny", web.Matches[1].Decision)
+		require.Equal(t, structs.ACLAuthorizationSourceServiceIdentity, web.Matches[2].Type)
+		require.Equal(t, `service_prefix "" { policy = "read" }`, web.Matches[2].Rule)
+
+		// policy linked to the token
+		api := resp.Explanations[1]
+		require.False(t, api.Allow)
+		require.Equal(t, structs.ACLAuthorizationSourcePolicy, api.Source.Type)
+		require.Equal(t, readAll.ID, api.Source.ID)
+		require.Equal(t, `service_prefix "" { policy = "read" }`, api.Source.Rule)
+
+		// policy linked through a role
+		db := resp.Explanations[2]
+		require.False(t, db.Allow)
+		require.Equal(t, denyDB.ID, db.Source.ID)
+		require.Equal(t, role.ID, db.Source.RoleID)
+		require.Equal(t, role.Name, db.Source.RoleName)
+		require.Equal(t, "deny", db.Source.Decision)
+
+		// default policy
+		key := resp.Explanations[3]
+		require.False(t, key.Allow)
+		require.True(t, key.DefaultPolicy)
+		require.Nil(t, key.Source)
+		require.Empty(t, key.Matches)
+	})
+
+	t.Run("other token requires acl:read", func(t *testing.T) {
+		req := structs.ACLAuthorizeExplainRequest{
+			Datacenter:   "dc1",
+			AccessorID:   token.AccessorID,
+			Requests:     requests,
+			QueryOptions: structs.QueryOptions{Token: token.SecretID},
+		}
+		var resp structs.ACLAuthorizeExplainResponse
+		err := aclEp.AuthorizeExplain(&req, &resp)
+		require.True(t, acl.IsErrPermissionDenied(err), "expected permission denied, got: %v", err)
+
+		req.Token = TestDefaultInitialManagementToken
+		require.NoError(t, aclEp.AuthorizeExplain(&req, &resp))
+		require.Equal(t, token.AccessorID, resp.AccessorID)
+		require.True(t, resp.Explanations[0].Allow)
+	})
+
+	t.Run("simulated policies", func(t *testing.T) {
+		req := structs.ACLAuthorizeExplainRequest{
+			Datacenter: "dc1",
+			Requests:   requests,
+			Policies: []*structs.ACLPolicy{
+				{Name: "proposed", Rules: `key_prefix "foo" { policy = "read" }`},
+			},
+			QueryOptions: structs.QueryOptions{Token: token.SecretID},
+		}
+		var resp structs.ACLAuthorizeExplainResponse
+		require.NoError(t, aclEp.AuthorizeExplain(&req, &resp))
+
+		key := resp.Explanations[3]
+		require.True(t, key.Allow)
+		require.Equal(t, structs.ACLAuthorizationSourceSimulated, key.Source.Type)
+		require.Equal(t, "proposed", key.Source.Name)
+
+		// The token's own policies still apply.
+		require.True(t, resp.Explanations[0].Allow)
+
+		req.PoliciesOnly = true
+		resp = structs.ACLAuthorizeExplainResponse{}
+		require.NoError(t, aclEp.AuthorizeExplain(&req, &resp))
+		require.Empty(t, resp.AccessorID)
+		require.True(t, resp.Explanations[3].Allow)
+		require.False(t, resp.Explanations[0].Allow)
+		require.True(t, resp.Explanations[0].DefaultPolicy)
+	})
+
+	t.Run("invalid simulated policy", func(t *testing.T) {
+		req := structs.ACLAuthorizeExplainRequest{
+			Datacenter: "dc1",
+			Requests:   requests,
+			Policies: []*structs.ACLPolicy{
+				{Name: "broken", Rules: `service "web" { policy = "nope" }`},
+			},
+			QueryOptions: structs.QueryOptions{Token: token.SecretID},
+		}
+		var resp structs.ACLAuthorizeExplainResponse
+		err := aclEp.AuthorizeExplain(&req, &resp)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `failed to parse policy "broken"`)
+	})
+}
+
 func TestACLEndpoint_PolicyRead(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -2180,6 +2629,116 @@ func TestACLEndpoint_PolicySet_CustomID(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestACLEndpoint_PolicyTemplates(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, srv)
+
+	template, err := upsertTestCustomizedPolicy(codec, TestDefaultInitialManagementToken, "dc1", func(policy *structs.ACLPolicy) {
+		policy.Rules = `key_prefix "${var.prefix}" { policy = "write" }`
+		policy.TemplateVariables = []string{"prefix"}
+	})
+	require.NoError(t, err)
+
+	t.Run("invalid variable name", func(t *testing.T) {
+		_, err := upsertTestCustomizedPolicy(codec, TestDefaultInitialManagementToken, "dc1", func(policy *structs.ACLPolicy) {
+			policy.Rules = `key_prefix "${var.Prefix}" { policy = "write" }`
+			policy.TemplateVariables = []string{"Prefix"}
+		})
+		require.ErrorContains(t, err, "invalid template variable name")
+	})
+
+	t.Run("undeclared variable", func(t *testing.T) {
+		_, err := upsertTestCustomizedPolicy(codec, TestDefaultInitialManagementToken, "dc1", func(policy *structs.ACLPolicy) {
+			policy.Rules = `key_prefix "${var.other}" { policy = "write" }`
+			policy.TemplateVariables = []string{"prefix"}
+		})
+		require.ErrorContains(t, err, "failed to render rules")
+	})
+
+	t.Run("cannot stop being a template", func(t *testing.T) {
+		_, err := upsertTestCustomizedPolicy(codec, TestDefaultInitialManagementToken, "dc1", func(policy *structs.ACLPolicy) {
+			policy.ID = template.ID
+			policy.Name = template.Name
+			policy.Rules = `key_prefix "" { policy = "write" }`
+		})
+		require.ErrorContains(t, err, "cannot change whether a policy is a template")
+	})
+
+	t.Run("token cannot link a template directly", func(t *testing.T) {
+		_, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+			token.Policies = []structs.ACLTokenPolicyLink{{ID: template.ID}}
+		})
+		require.ErrorContains(t, err, "must be linked as a templated policy")
+	})
+
+	t.Run("token with invalid variables", func(t *testing.T) {
+		_, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+			token.TemplatedPolicies = []*structs.ACLTemplatedPolicy{
+				{Name: template.Name, Variables: map[string]string{"prefix": `" {} key_prefix "`}},
+			}
+		})
+		require.ErrorContains(t, err, "invalid value for variable")
+	})
+
+	t.Run("token", func(t *testing.T) {
+		token, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+			token.TemplatedPolicies = []*structs.ACLTemplatedPolicy{
+				{Name: template.Name, Variables: map[string]string{"prefix": "apps/web"}},
+				{ID: template.ID, Variables: map[string]string{"prefix": "apps/web"}},
+			}
+		})
+		require.NoError(t, err)
+		require.Len(t, token.TemplatedPolicies, 1)
+		require.Equal(t, template.ID, token.TemplatedPolicies[0].ID)
+		require.Equal(t, template.Name, token.TemplatedPolicies[0].Name)
+
+		authz, err := srv.ResolveToken(token.SecretID)
+		require.NoError(t, err)
+		require.Equal(t, acl.Allow, authz.KeyWrite("apps/web/config", nil))
+		require.Equal(t, acl.Deny, authz.KeyWrite("apps/db/config", nil))
+	})
+
+	t.Run("role", func(t *testing.T) {
+		req := structs.ACLRoleSetRequest{
+			Datacenter: "dc1",
+			Role: structs.ACLRole{
+				Name: "kv-db",
+				TemplatedPolicies: []*structs.ACLTemplatedPolicy{
+					{Name: template.Name, Variables: map[string]string{"prefix": "apps/db"}},
+				},
+			},
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		var role structs.ACLRole
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "ACL.RoleSet", &req, &role))
+		require.Len(t, role.TemplatedPolicies, 1)
+		require.Equal(t, template.ID, role.TemplatedPolicies[0].ID)
+
+		token, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+			token.Roles = []structs.ACLTokenRoleLink{{ID: role.ID}}
+		})
+		require.NoError(t, err)
+
+		authz, err := srv.ResolveToken(token.SecretID)
+		require.NoError(t, err)
+		require.Equal(t, acl.Allow, authz.KeyWrite("apps/db/config", nil))
+		require.Equal(t, acl.Deny, authz.KeyWrite("apps/web/config", nil))
+
+		req.Role = structs.ACLRole{
+			Name:     "kv-direct",
+			Policies: []structs.ACLRolePolicyLink{{Name: template.Name}},
+		}
+		err = msgpackrpc.CallWithCodec(codec, "ACL.RoleSet", &req, &role)
+		require.ErrorContains(t, err, "template")
+	})
+}
+
 func TestACLEndpoint_PolicySet_globalManagement(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -3273,6 +3832,62 @@ func TestACLEndpoint_AuthMethodSet(t *testing.T) {
 		err := aclEp.AuthMethodSet(&req, &resp)
 		testutil.RequireErrorContains(t, err, "MaxTokenTTL 25h0m0s cannot be more than")
 	})
+
+	t.Run("Create with TokenRenewable", func(t *testing.T) {
+		reqMethod := newAuthMethod("test")
+		reqMethod.MaxTokenTTL = 5 * time.Minute
+		reqMethod.TokenRenewable = true
+		reqMethod.TokenMaxExpirationTTL = time.Hour
+
+		req := structs.ACLAuthMethodSetRequest{
+			Datacenter:   "dc1",
+			AuthMethod:   reqMethod,
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		resp := structs.ACLAuthMethod{}
+
+		err := aclEp.AuthMethodSet(&req, &resp)
+		require.NoError(t, err)
+
+		methodResp, err := retrieveTestAuthMethod(codec, TestDefaultInitialManagementToken, "dc1", resp.Name)
+		require.NoError(t, err)
+		method := methodResp.AuthMethod
+
+		require.True(t, method.TokenRenewable)
+		require.Equal(t, time.Hour, method.TokenMaxExpirationTTL)
+	})
+
+	t.Run("Create with TokenRenewable without MaxTokenTTL", func(t *testing.T) {
+		reqMethod := newAuthMethod("test")
+		reqMethod.TokenRenewable = true
+
+		req := structs.ACLAuthMethodSetRequest{
+			Datacenter:   "dc1",
+			AuthMethod:   reqMethod,
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		resp := structs.ACLAuthMethod{}
+
+		err := aclEp.AuthMethodSet(&req, &resp)
+		testutil.RequireErrorContains(t, err, "TokenRenewable requires a MaxTokenTTL")
+	})
+
+	t.Run("Create with TokenMaxExpirationTTL too small", func(t *testing.T) {
+		reqMethod := newAuthMethod("test")
+		reqMethod.MaxTokenTTL = 5 * time.Minute
+		reqMethod.TokenRenewable = true
+		reqMethod.TokenMaxExpirationTTL = time.Minute
+
+		req := structs.ACLAuthMethodSetRequest{
+			Datacenter:   "dc1",
+			AuthMethod:   reqMethod,
+			WriteRequest: structs.WriteRequest{Token: TestDefaultInitialManagementToken},
+		}
+		resp := structs.ACLAuthMethod{}
+
+		err := aclEp.AuthMethodSet(&req, &resp)
+		testutil.RequireErrorContains(t, err, "TokenMaxExpirationTTL 1m0s cannot be less than MaxTokenTTL")
+	})
 }
 
 func TestACLEndpoint_AuthMethodDelete(t *testing.T) {
@@ -0,0 +1,287 @@
+package consul
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// aclExplainSource is a policy that applies to a token, along with where it
+// came from.
+type aclExplainSource struct {
+	source structs.ACLAuthorizationSource
+	policy *structs.ACLPolicy
+	parsed *acl.Policy
+}
+
+// aclExplainSources collects the policies that apply to the token, including
+// the ones linked through roles and the ones synthesized for service and node
+// identities and templated policies, followed by the simulated policies. The
+// token may be nil when only the simulated policies are evaluated.
+func (s *Server) aclExplainSources(token *structs.ACLToken, simulated []*structs.ACLPolicy) ([]*aclExplainSource, error) {
+	var sources []*aclExplainSource
+	seen := make(map[string]struct{})
+
+	addPolicy := func(id string, role *structs.ACLRole) error {
+		if _, ok := seen[id]; ok {
+			return nil
+		}
+		seen[id] = struct{}{}
+
+		_, policy, err := s.fsm.State().ACLPolicyGetByID(nil, id, nil)
+		if err != nil {
+			return err
+		}
+		if policy == nil {
+			// Links to deleted policies are ignored by the resolver too.
+			return nil
+		}
+
+		source := &aclExplainSource{
+			source: structs.ACLAuthorizationSource{
+				Type: structs.ACLAuthorizationSourcePolicy,
+				ID:   policy.ID,
+				Name: policy.Name,
+			},
+			policy: policy,
+		}
+		if role != nil {
+			source.source.RoleID = role.ID
+			source.source.RoleName = role.Name
+		}
+		sources = append(sources, source)
+		return nil
+	}
+	addServiceIdentities := func(ids []*structs.ACLServiceIdentity, role *structs.ACLRole) {
+		for _, id := range ids {
+			source := &aclExplainSource{
+				source: structs.ACLAuthorizationSource{
+					Type: structs.ACLAuthorizationSourceServiceIdentity,
+					Name: id.ServiceName,
+				},
+				policy: id.SyntheticPolicy(&token.EnterpriseMeta),
+			}
+			if role != nil {
+				source.source.RoleID = role.ID
+				source.source.RoleName = role.Name
+			}
+			sources = append(sources, source)
+		}
+	}
+	addTemplatedPolicies := func(links []*structs.ACLTemplatedPolicy, role *structs.ACLRole) error {
+		for _, link := range links {
+			_, template, err := s.fsm.State().ACLPolicyGetByID(nil, link.ID, nil)
+			if err != nil {
+				return err
+			}
+			if template == nil || !template.IsTemplate() {
+				continue
+			}
+			policy, err := link.SyntheticPolicy(template)
+			if err != nil {
+				// Templates that no longer render are ignored by the resolver too.
+				continue
+			}
+
+			source := &aclExplainSource{
+				source: structs.ACLAuthorizationSource{
+					Type: structs.ACLAuthorizationSourceTemplatedPolicy,
+					ID:   template.ID,
+					Name: template.Name,
+				},
+				policy: policy,
+			}
+			if role != nil {
+				source.source.RoleID = role.ID
+				source.source.RoleName = role.Name
+			}
+			sources = append(sources, source)
+		}
+		return nil
+	}
+	addNodeIdentities := func(ids []*structs.ACLNodeIdentity, role *structs.ACLRole) {
+		for _, id := range ids {
+			source := &aclExplainSource{
+				source: structs.ACLAuthorizationSource{
+					Type: structs.ACLAuthorizationSourceNodeIdentity,
+					Name: id.NodeName,
+				},
+				policy: id.SyntheticPolicy(&token.EnterpriseMeta),
+			}
+			if role != nil {
+				source.source.RoleID = role.ID
+				source.source.RoleName = role.Name
+			}
+			sources = append(sources, source)
+		}
+	}
+
+	if token != nil {
+		for _, id := range token.PolicyIDs() {
+			if err := addPolicy(id, nil); err != nil {
+				return nil, err
+			}
+		}
+		addServiceIdentities(token.ServiceIdentityList(), nil)
+		addNodeIdentities(token.NodeIdentityList(), nil)
+		if err := addTemplatedPolicies(token.TemplatedPolicyList(), nil); err != nil {
+			return nil, err
+		}
+
+		for _, roleID := range token.RoleIDs() {
+			_, role, err := s.fsm.State().ACLRoleGetByID(nil, roleID, nil)
+			if err != nil {
+				return nil, err
+			}
+			if role == nil {
+				continue
+			}
+
+			for _, link := range role.Policies {
+				if err := addPolicy(link.ID, role); err != nil {
+					return nil, err
+				}
+			}
+			addServiceIdentities(role.ServiceIdentities, role)
+			addNodeIdentities(role.NodeIdentityList(), role)
+			if err := addTemplatedPolicies(role.TemplatedPolicyList(), role); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i, policy := range simulated {
+		name := policy.Name
+		if name == "" {
+			name = fmt.Sprintf("simulated-%d", i+1)
+		}
+		sources = append(sources, &aclExplainSource{
+			source: structs.ACLAuthorizationSource{
+				Type: structs.ACLAuthorizationSourceSimulated,
+				Name: name,
+			},
+			policy: policy,
+		})
+	}
+
+	return sources, nil
+}
+
+// explainAuthorizations evaluates the requests against the sources and
+// reports the rule that made each decision.
+func (s *Server) explainAuthorizations(token *structs.ACLToken, sources []*aclExplainSource, requests []structs.ACLAuthorizationRequest) ([]structs.ACLAuthorizationExplanation, error) {
+	var conf acl.Config
+	if s.aclConfig != nil {
+		conf = *s.aclConfig
+	}
+	if token != nil {
+		setEnterpriseConf(token.EnterpriseMetadata(), &conf)
+	}
+
+	// Parse the policies that are in scope for this datacenter.
+	var (
+		inScope []*aclExplainSource
+		parsed  []*acl.Policy
+	)
+	for _, source := range sources {
+		if !aclPolicyInScope(source.policy, s.config.Datacenter) {
+			continue
+		}
+
+		p, err := acl.NewPolicyFromSource(source.policy.Rules, &conf, source.policy.EnterprisePolicyMeta())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse policy %q: %v", source.source.Name, err)
+		}
+		source.parsed = p
+		inScope = append(inScope, source)
+		parsed = append(parsed, p)
+	}
+
+	// Build the authorizer the same way the resolver does.
+	policyAuthz, err := acl.NewPolicyAuthorizer(parsed, &conf)
+	if err != nil {
+		return nil, err
+	}
+	chain := []acl.Authorizer{policyAuthz}
+	if token != nil {
+		defaults, err := s.ACLResolver.resolveEnterpriseDefaultsForIdentity(token)
+		if err != nil {
+			return nil, err
+		} else if defaults != nil {
+			chain = append(chain, defaults)
+		}
+	}
+	chain = append(chain, acl.RootAuthorizer(s.config.ACLResolverSettings.ACLDefaultPolicy))
+	authz := acl.NewChainedAuthorizer(chain)
+
+	type match struct {
+		source *aclExplainSource
+		rule   acl.ExplainedRule
+	}
+
+	explanations := make([]structs.ACLAuthorizationExplanation, 0, len(requests))
+	for _, req := range requests {
+		var ctx acl.AuthorizerContext
+		req.FillAuthzContext(&ctx)
+
+		decision, err := acl.Enforce(authz, req.Resource, req.Segment, req.Access, &ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var matches []match
+		for _, source := range inScope {
+			rules, err := acl.ExplainPolicy(source.parsed, req.Resource, req.Segment, req.Access, &conf, &ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, rule := range rules {
+				matches = append(matches, match{source: source, rule: rule})
+			}
+		}
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].rule.MoreSpecificThan(matches[j].rule)
+		})
+
+		explanation := structs.ACLAuthorizationExplanation{
+			ACLAuthorizationRequest: req,
+			Allow:                   decision == acl.Allow,
+			Matches:                 make([]structs.ACLAuthorizationSource, 0, len(matches)),
+		}
+		for _, m := range matches {
+			source := m.source.source
+			source.Rule = m.rule.Rule
+			source.Decision = strings.ToLower(m.rule.Decision.String())
+			explanation.Matches = append(explanation.Matches, source)
+		}
+
+		// The most specific rule made the decision, unless nothing matched
+		// and it came from the default policy.
+		if len(matches) > 0 && matches[0].rule.Decision == decision {
+			source := explanation.Matches[0]
+			explanation.Source = &source
+		} else {
+			explanation.DefaultPolicy = true
+		}
+
+		explanations = append(explanations, explanation)
+	}
+
+	return explanations, nil
+}
+
+// aclPolicyInScope returns whether the policy applies in the datacenter.
+func aclPolicyInScope(policy *structs.ACLPolicy, datacenter string) bool {
+	if len(policy.Datacenters) == 0 {
+		return true
+	}
+	for _, dc := range policy.Datacenters {
+		if dc == datacenter {
+			return true
+		}
+	}
+	return false
+}
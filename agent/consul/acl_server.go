@@ -167,6 +167,30 @@ func (s *Server) ResolveIdentityFromToken(token string) (bool, structs.ACLIdenti
 	return s.InPrimaryDatacenter() || index > 0, nil, defaultErr
 }
 
+// ResolveAccessorID returns the accessor ID of the given token secret for the
+// accessor-based rate limits, or an empty string when ACLs are disabled. The
+// token is resolved the way the endpoints resolve it, including remotely in
+// the secondary datacenters, and the tokens resolved remotely are cached so
+// the endpoint handling the request doesn't fetch them again. Tokens that
+// can't be resolved share the limits of the anonymous token.
+func (s *Server) ResolveAccessorID(token string) string {
+	if !s.ACLResolver.ACLsEnabled() {
+		return ""
+	}
+	if token == "" {
+		token = acl.AnonymousTokenSecret
+	}
+
+	if ident, _, ok := s.ACLResolver.resolveLocallyManagedToken(token); ok {
+		return ident.ID()
+	}
+	ident, err := s.ACLResolver.resolveIdentityFromToken(token)
+	if err != nil || ident == nil {
+		return acl.AnonymousTokenID
+	}
+	return ident.ID()
+}
+
 func (s *serverACLResolverBackend) ResolvePolicyFromID(policyID string) (bool, *structs.ACLPolicy, error) {
 	index, policy, err := s.fsm.State().ACLPolicyGetByID(nil, policyID, nil)
 	if err != nil {
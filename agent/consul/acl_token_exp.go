@@ -21,12 +21,22 @@ func (s *Server) reapExpiredTokens(ctx context.Context) error {
 			if _, err := s.reapExpiredLocalACLTokens(); err != nil {
 				s.logger.Error("error reaping expired local ACL tokens", "error", err)
 			}
+			if _, err := s.reapExpiredPreviousSecrets(true); err != nil {
+				s.logger.Error("error reaping expired previous secrets of local ACL tokens", "error", err)
+			}
 		}
 		if s.InPrimaryDatacenter() {
 			if _, err := s.reapExpiredGlobalACLTokens(); err != nil {
 				s.logger.Error("error reaping expired global ACL tokens", "error", err)
 			}
 		}
+		// Clearing the previous secret doesn't change the hash of the token,
+		// so the secondary datacenters clear the replicated ones themselves.
+		if s.LocalTokensEnabled() {
+			if _, err := s.reapExpiredPreviousSecrets(false); err != nil {
+				s.logger.Error("error reaping expired previous secrets of global ACL tokens", "error", err)
+			}
+		}
 	}
 }
 
@@ -114,6 +124,69 @@ func (s *Server) reapExpiredACLTokens(local, global bool) (int, error) {
 	return len(req.TokenIDs), nil
 }
 
+// reapExpiredPreviousSecrets clears the previous SecretID of rotated tokens
+// once their grace period has ended. The secrets are already rejected at that
+// point, this only removes them from the state store. The end of the grace
+// period is kept, so the hash of the tokens doesn't change and the secondary
+// datacenters aren't sent the update. They clear the previous secrets of the
+// replicated tokens on their own instead.
+func (s *Server) reapExpiredPreviousSecrets(local bool) (int, error) {
+	if !s.config.ACLsEnabled {
+		return 0, nil
+	}
+
+	tokens, err := s.fsm.State().ACLTokenListExpiredPreviousSecrets(local, time.Now(), aclBatchDeleteSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tokens) == 0 {
+		return 0, nil
+	}
+
+	// The links of replicated tokens are not validated again, as is done by
+	// the replication.
+	replicated := !local && !s.InPrimaryDatacenter()
+
+	var (
+		secretIDs []string
+		batchSize int
+		req       = structs.ACLTokenBatchSetRequest{
+			CAS:               true,
+			AllowMissingLinks: replicated,
+			FromReplication:   replicated,
+		}
+	)
+	for _, token := range tokens {
+		// The remaining tokens are cleared on the next run.
+		if batchSize += token.EstimateSize(); batchSize > aclBatchUpsertSize {
+			break
+		}
+		secretIDs = append(secretIDs, token.PreviousSecretID)
+
+		cleared := token.Clone()
+		cleared.PreviousSecretID = ""
+		req.Tokens = append(req.Tokens, cleared)
+	}
+
+	s.logger.Info("clearing expired previous secrets of rotated ACL tokens",
+		"amount", len(req.Tokens),
+		"locality", localityName(local),
+	)
+
+	_, err = s.leaderRaftApply("ACL.TokenSet", structs.ACLTokenSetRequestType, &req)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to apply previous secret expirations: %v", err)
+	}
+
+	// Purge the identities from the cache
+	for _, secretID := range secretIDs {
+		s.ACLResolver.cache.RemoveIdentityWithSecretToken(secretID)
+	}
+
+	return len(req.Tokens), nil
+}
+
 func localityName(local bool) string {
 	if local {
 		return "local"
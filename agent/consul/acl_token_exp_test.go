@@ -9,6 +9,8 @@ import (
 
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/agent/structs"
+	tokenStore "github.com/hashicorp/consul/agent/token"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
 	"github.com/hashicorp/consul/testrpc"
 )
 
@@ -223,3 +225,146 @@ func testACLTokenReap_Primary(t *testing.T, local, global bool) {
 		})
 	})
 }
+
+func TestACLTokenReap_PreviousSecrets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, srv)
+
+	token, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", nil)
+	require.NoError(t, err)
+
+	rotated, err := srv.aclTokenWriter().Rotate(token.AccessorID, nil, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, token.SecretID, rotated.PreviousSecretID)
+
+	// Nothing to reap during the grace period.
+	n, err := srv.reapExpiredPreviousSecrets(false)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Local tokens are reaped separately.
+	n, err = srv.reapExpiredPreviousSecrets(true)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = srv.reapExpiredPreviousSecrets(false)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	_, reaped, err := srv.fsm.State().ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	require.Empty(t, reaped.PreviousSecretID)
+	require.Equal(t, rotated.SecretID, reaped.SecretID)
+	require.Equal(t, rotated.PreviousSecretExpirationTime, reaped.PreviousSecretExpirationTime)
+	require.Equal(t, rotated.Hash, reaped.Hash)
+
+	_, previous, err := srv.fsm.State().ACLTokenGetBySecret(nil, token.SecretID, nil)
+	require.NoError(t, err)
+	require.Nil(t, previous)
+}
+
+func TestACLTokenReap_PreviousSecrets_Secondary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, s1 := testServerWithConfig(t, func(c *Config) {
+		c.PrimaryDatacenter = "dc1"
+		c.ACLsEnabled = true
+		c.ACLInitialManagementToken = "root"
+	})
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+	codec := rpcClient(t, s1)
+
+	_, s2 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc2"
+		c.PrimaryDatacenter = "dc1"
+		c.ACLsEnabled = true
+		c.ACLTokenReplication = true
+		c.ACLReplicationRate = 100
+		c.ACLReplicationBurst = 100
+		c.ACLReplicationApplyLimit = 1000000
+	})
+	s2.tokens.UpdateReplicationToken("root", tokenStore.TokenSourceConfig)
+	testrpc.WaitForLeader(t, s2.RPC, "dc2")
+
+	joinWAN(t, s2, s1)
+	testrpc.WaitForLeader(t, s1.RPC, "dc2")
+
+	token, err := upsertTestToken(codec, "root", "dc1", nil)
+	require.NoError(t, err)
+
+	rotated, err := s1.aclTokenWriter().Rotate(token.AccessorID, nil, 2*time.Second)
+	require.NoError(t, err)
+
+	retry.Run(t, func(r *retry.R) {
+		_, replicated, err := s2.fsm.State().ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+		require.NoError(r, err)
+		require.NotNil(r, replicated)
+		require.Equal(r, rotated.SecretID, replicated.SecretID)
+		require.Equal(r, token.SecretID, replicated.PreviousSecretID)
+	})
+
+	// Clearing the previous secret in the primary datacenter isn't replicated,
+	// the reaping in the secondary datacenter clears it.
+	retry.Run(t, func(r *retry.R) {
+		_, reaped, err := s2.fsm.State().ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+		require.NoError(r, err)
+		require.Empty(r, reaped.PreviousSecretID)
+		require.Equal(r, rotated.Hash, reaped.Hash)
+
+		_, previous, err := s2.fsm.State().ACLTokenGetBySecret(nil, token.SecretID, nil)
+		require.NoError(r, err)
+		require.Nil(r, previous)
+	})
+}
+
+func TestACLTokenReap_Renewed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	_, srv, codec := testACLServerWithConfig(t, func(c *Config) {
+		c.ACLTokenMinExpirationTTL = 10 * time.Millisecond
+	}, false)
+	waitForLeaderEstablishment(t, srv)
+
+	// The expiration time index has a granularity of a second, see
+	// testACLTokenReap_Primary.
+	token, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", func(token *structs.ACLToken) {
+		token.ExpirationTTL = 1 * time.Second
+		token.Renewable = true
+	})
+	require.NoError(t, err)
+
+	renewed, err := srv.aclTokenWriter().Renew(token.AccessorID, nil, 5*time.Second)
+	require.NoError(t, err)
+	require.True(t, renewed.ExpirationTime.After(*token.ExpirationTime))
+
+	time.Sleep(token.ExpirationTime.Sub(time.Now()) + 1100*time.Millisecond)
+
+	// The token outlives its original expiration time.
+	n, err := srv.reapExpiredACLTokens(false, true)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	_, found, err := srv.fsm.State().ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	require.Equal(t, renewed.ExpirationTime, found.ExpirationTime)
+
+	_, err = srv.ResolveToken(token.SecretID)
+	require.NoError(t, err)
+}
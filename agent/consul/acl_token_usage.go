@@ -0,0 +1,136 @@
+package consul
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/armon/go-metrics/prometheus"
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/logging"
+)
+
+var ACLTokenUsageCounters = []prometheus.CounterDefinition{
+	{
+		Name: []string{"acl", "token", "usage", "discarded"},
+		Help: "Increments for each token usage update that was discarded because too many were pending.",
+	},
+}
+
+// aclTokenUsage batches the token usage reported by agents so that it can be
+// flushed to the Raft log periodically, rather than turning every request
+// into a write.
+type aclTokenUsage struct {
+	// srv is a pointer back to the server.
+	srv *Server
+
+	logger hclog.Logger
+
+	// updates holds pending usage updates. This is keyed by
+	// partition/accessor so we only track the latest usage of each token.
+	updates map[string]*structs.ACLTokenUsageUpdate
+
+	// updatesLock synchronizes access to the updates map.
+	updatesLock sync.Mutex
+}
+
+// newACLTokenUsage returns a new token usage tracker for the given server.
+func newACLTokenUsage(srv *Server) *aclTokenUsage {
+	return &aclTokenUsage{
+		srv:     srv,
+		logger:  srv.loggers.Named(logging.ACL),
+		updates: make(map[string]*structs.ACLTokenUsageUpdate),
+	}
+}
+
+// record adds the given usage updates to the pending ones, keeping only the
+// latest usage of each token.
+func (u *aclTokenUsage) record(updates []*structs.ACLTokenUsageUpdate) {
+	u.updatesLock.Lock()
+	defer u.updatesLock.Unlock()
+
+	for _, update := range updates {
+		if update.AccessorID == "" || update.Usage.LastUsedTime == nil {
+			continue
+		}
+
+		update.EnterpriseMeta.Normalize()
+		key := fmt.Sprintf("%s/%s", update.PartitionOrDefault(), update.AccessorID)
+		if existing, ok := u.updates[key]; ok && !update.Usage.LastUsedTime.After(*existing.Usage.LastUsedTime) {
+			continue
+		}
+		u.updates[key] = update
+	}
+}
+
+// batchUpdate is a long-running routine that flushes pending usage updates to
+// the Raft log in batches.
+func (u *aclTokenUsage) batchUpdate() {
+	for {
+		select {
+		case <-time.After(u.srv.config.ACLTokenUsageUpdatePeriod):
+			if err := u.batchApplyUpdates(); err != nil {
+				u.logger.Warn("Token usage batch update failed", "error", err)
+			}
+		case <-u.srv.shutdownCh:
+			return
+		}
+	}
+}
+
+// batchApplyUpdates applies all pending usage updates to the Raft log in a
+// series of batches. Updates for tokens whose recorded usage is still within
+// the configured granularity are dropped, since they wouldn't tell us much.
+func (u *aclTokenUsage) batchApplyUpdates() error {
+	// Grab the pending updates and release the lock so we can still handle
+	// incoming messages.
+	u.updatesLock.Lock()
+	pending := u.updates
+	u.updates = make(map[string]*structs.ACLTokenUsageUpdate)
+	u.updatesLock.Unlock()
+
+	state := u.srv.fsm.State()
+	updates := make([]*structs.ACLTokenUsageUpdate, 0, len(pending))
+	for _, update := range pending {
+		_, token, err := state.ACLTokenGetByAccessor(nil, update.AccessorID, &update.EnterpriseMeta)
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			continue
+		}
+		if last := token.Usage.LastUsedTime; last != nil && update.Usage.LastUsedTime.Sub(*last) < u.srv.config.ACLTokenUsageGranularity {
+			continue
+		}
+		updates = append(updates, update)
+	}
+
+	// Enforce the rate limit.
+	limit := u.srv.config.ACLTokenUsageUpdateBatchSize * u.srv.config.ACLTokenUsageUpdateMaxBatches
+	if size := len(updates); size > limit {
+		u.logger.Warn("Discarded token usage updates", "number_discarded", size-limit)
+		metrics.IncrCounter([]string{"acl", "token", "usage", "discarded"}, float32(size-limit))
+		updates = updates[:limit]
+	}
+
+	// Apply the updates to the Raft log in batches.
+	for start := 0; start < len(updates); start += u.srv.config.ACLTokenUsageUpdateBatchSize {
+		end := start + u.srv.config.ACLTokenUsageUpdateBatchSize
+		if end > len(updates) {
+			end = len(updates)
+		}
+
+		// We set the "safe to ignore" flag on this update type so old
+		// servers don't crash if they see one of these.
+		t := structs.ACLTokenUsageBatchUpdateType | structs.IgnoreUnknownTypeFlag
+
+		req := structs.ACLTokenUsageBatchUpdateRequest{Updates: updates[start:end]}
+		if _, err := u.srv.raftApply(t, &req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -13,8 +13,8 @@ import (
 )
 
 // Binder is responsible for collecting the ACL roles, service identities, node
-// identities, and enterprise metadata to be assigned to a token generated as a
-// result of "logging in" via an auth method.
+// identities, templated policies, and enterprise metadata to be assigned to a
+// token generated as a result of "logging in" via an auth method.
 //
 // It does so by applying the auth method's configured binding rules and in the
 // case of enterprise, namespace rules.
@@ -32,14 +32,16 @@ func NewBinder(store BinderStateStore, datacenter string) *Binder {
 type BinderStateStore interface {
 	ACLBindingRuleList(ws memdb.WatchSet, methodName string, entMeta *acl.EnterpriseMeta) (uint64, structs.ACLBindingRules, error)
 	ACLRoleGetByName(ws memdb.WatchSet, roleName string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLRole, error)
+	ACLPolicyGetByName(ws memdb.WatchSet, policyName string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLPolicy, error)
 }
 
-// Bindings contains the ACL roles, service identities, node identities and
-// enterprise meta to be assigned to the created token.
+// Bindings contains the ACL roles, service identities, node identities,
+// templated policies and enterprise meta to be assigned to the created token.
 type Bindings struct {
 	Roles             []structs.ACLTokenRoleLink
 	ServiceIdentities []*structs.ACLServiceIdentity
 	NodeIdentities    []*structs.ACLNodeIdentity
+	TemplatedPolicies []*structs.ACLTemplatedPolicy
 	EnterpriseMeta    acl.EnterpriseMeta
 }
 
@@ -52,6 +54,7 @@ func (b *Bindings) None() bool {
 
 	return len(b.ServiceIdentities) == 0 &&
 		len(b.NodeIdentities) == 0 &&
+		len(b.TemplatedPolicies) == 0 &&
 		len(b.Roles) == 0
 }
 
@@ -117,6 +120,29 @@ func (b *Binder) Bind(authMethod *structs.ACLAuthMethod, verifiedIdentity *authm
 					ID: role.ID,
 				})
 			}
+
+		case structs.BindingRuleBindTypeTemplatedPolicy:
+			_, policy, err := b.store.ACLPolicyGetByName(nil, bindName, &bindings.EnterpriseMeta)
+			if err != nil {
+				return nil, err
+			}
+			if policy == nil || !policy.IsTemplate() {
+				continue
+			}
+
+			bindVars, err := computeBindVars(rule.BindVars, verifiedIdentity.ProjectedVars)
+			if err != nil {
+				return nil, fmt.Errorf("cannot compute bind vars for bind target: %w", err)
+			}
+
+			templated := &structs.ACLTemplatedPolicy{
+				ID:        policy.ID,
+				Variables: bindVars,
+			}
+			if err := templated.Validate(policy); err != nil {
+				return nil, fmt.Errorf("computed bind vars for bind target are invalid: %w", err)
+			}
+			bindings.TemplatedPolicies = append(bindings.TemplatedPolicies, templated)
 		}
 	}
 
@@ -142,6 +168,49 @@ func IsValidBindName(bindType, bindName string, availableVariables []string) (bo
 	return valid, nil
 }
 
+// IsValidBindVars returns whether the given BindVars templates can be used with
+// the bind type, and interpolate the auth method's available variables.
+func IsValidBindVars(bindType string, bindVars map[string]string, availableVariables []string) error {
+	if len(bindVars) == 0 {
+		return nil
+	}
+	if bindType != structs.BindingRuleBindTypeTemplatedPolicy {
+		return fmt.Errorf("BindVars can only be set with BindType %q", structs.BindingRuleBindTypeTemplatedPolicy)
+	}
+
+	for name := range bindVars {
+		if !acl.IsValidTemplateVariableName(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+	}
+
+	fakeVarMap := make(map[string]string)
+	for _, v := range availableVariables {
+		fakeVarMap[v] = "fake"
+	}
+
+	_, err := computeBindVars(bindVars, fakeVarMap)
+	return err
+}
+
+// computeBindVars processes the HIL of the provided bind vars using the
+// projected variables. A bind var computed to an empty value is an error, as
+// the claim it's built from may simply be missing.
+func computeBindVars(bindVars map[string]string, projectedVars map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(bindVars))
+	for name, value := range bindVars {
+		computed, err := template.InterpolateHIL(value, projectedVars, false)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", name, err)
+		}
+		if computed == "" {
+			return nil, fmt.Errorf("variable %q: computed value is empty", name)
+		}
+		out[name] = computed
+	}
+	return out, nil
+}
+
 // computeBindName processes the HIL for the provided bind type+name using the
 // projected variables.
 //
@@ -162,6 +231,8 @@ func computeBindName(bindType, bindName string, projectedVars map[string]string)
 		valid = acl.IsValidNodeIdentityName(bindName)
 	case structs.BindingRuleBindTypeRole:
 		valid = acl.IsValidRoleName(bindName)
+	case structs.BindingRuleBindTypeTemplatedPolicy:
+		valid = acl.IsValidPolicyName(bindName)
 	default:
 		return "", false, fmt.Errorf("unknown binding rule bind type: %s", bindType)
 	}
@@ -29,6 +29,9 @@ func TestBindings_None(t *testing.T) {
 
 	b = &Bindings{NodeIdentities: []*structs.ACLNodeIdentity{{NodeName: "node-123"}}}
 	require.False(t, b.None())
+
+	b = &Bindings{TemplatedPolicies: []*structs.ACLTemplatedPolicy{{ID: generateID(t)}}}
+	require.False(t, b.None())
 }
 
 func TestBinder_Roles_Success(t *testing.T) {
@@ -255,6 +258,148 @@ func TestBinder_NodeIdentities_NameValidation(t *testing.T) {
 	require.Contains(t, err.Error(), "bind name for bind target is invalid")
 }
 
+func TestBinder_TemplatedPolicies_Success(t *testing.T) {
+	store := testStateStore(t)
+	binder := &Binder{store: store}
+
+	authMethod := &structs.ACLAuthMethod{
+		Name: "test-auth-method",
+		Type: "testing",
+	}
+	require.NoError(t, store.ACLAuthMethodSet(0, authMethod))
+
+	template := &structs.ACLPolicy{
+		ID:                generateID(t),
+		Name:              "kv-app",
+		Rules:             `key_prefix "${var.prefix}" { policy = "write" }`,
+		TemplateVariables: []string{"prefix"},
+	}
+	require.NoError(t, store.ACLPolicySet(0, template))
+
+	plain := &structs.ACLPolicy{
+		ID:    generateID(t),
+		Name:  "plain",
+		Rules: `node_prefix "" { policy = "read" }`,
+	}
+	require.NoError(t, store.ACLPolicySet(0, plain))
+
+	bindingRules := structs.ACLBindingRules{
+		{
+			ID:         generateID(t),
+			Selector:   "role==engineer",
+			BindType:   structs.BindingRuleBindTypeTemplatedPolicy,
+			BindName:   "kv-app",
+			BindVars:   map[string]string{"prefix": "apps/${editor}"},
+			AuthMethod: authMethod.Name,
+		},
+		{
+			ID:         generateID(t),
+			Selector:   "role==engineer",
+			BindType:   structs.BindingRuleBindTypeTemplatedPolicy,
+			BindName:   "this-template-does-not-exist",
+			AuthMethod: authMethod.Name,
+		},
+		{
+			ID:         generateID(t),
+			Selector:   "role==engineer",
+			BindType:   structs.BindingRuleBindTypeTemplatedPolicy,
+			BindName:   plain.Name,
+			AuthMethod: authMethod.Name,
+		},
+	}
+	require.NoError(t, store.ACLBindingRuleBatchSet(0, bindingRules))
+
+	result, err := binder.Bind(&structs.ACLAuthMethod{}, &authmethod.Identity{
+		SelectableFields: map[string]string{
+			"role": "engineer",
+		},
+		ProjectedVars: map[string]string{
+			"editor": "vim",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*structs.ACLTemplatedPolicy{
+		{ID: template.ID, Variables: map[string]string{"prefix": "apps/vim"}},
+	}, result.TemplatedPolicies)
+}
+
+func TestBinder_TemplatedPolicies_InvalidVars(t *testing.T) {
+	store := testStateStore(t)
+	binder := &Binder{store: store}
+
+	authMethod := &structs.ACLAuthMethod{
+		Name: "test-auth-method",
+		Type: "testing",
+	}
+	require.NoError(t, store.ACLAuthMethodSet(0, authMethod))
+
+	template := &structs.ACLPolicy{
+		ID:                generateID(t),
+		Name:              "kv-app",
+		Rules:             `key_prefix "${var.prefix}" { policy = "write" }`,
+		TemplateVariables: []string{"prefix"},
+	}
+	require.NoError(t, store.ACLPolicySet(0, template))
+
+	bindingRules := structs.ACLBindingRules{
+		{
+			ID:         generateID(t),
+			BindType:   structs.BindingRuleBindTypeTemplatedPolicy,
+			BindName:   "kv-app",
+			BindVars:   map[string]string{"prefix": "${editor}"},
+			AuthMethod: authMethod.Name,
+		},
+	}
+	require.NoError(t, store.ACLBindingRuleBatchSet(0, bindingRules))
+
+	_, err := binder.Bind(&structs.ACLAuthMethod{}, &authmethod.Identity{
+		ProjectedVars: map[string]string{
+			"editor": `vim" } key_prefix "" { policy = "write`,
+		},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "computed bind vars for bind target are invalid")
+
+	// A missing claim mustn't widen the prefix to every key.
+	_, err = binder.Bind(&structs.ACLAuthMethod{}, &authmethod.Identity{
+		ProjectedVars: map[string]string{
+			"editor": "",
+		},
+	})
+	require.ErrorContains(t, err, `variable "prefix": computed value is empty`)
+}
+
+func Test_IsValidBindVars(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		bindType string
+		bindVars map[string]string
+		fields   []string
+		err      string
+	}{
+		{"no vars", structs.BindingRuleBindTypeRole, nil, nil, ""},
+		{"valid", structs.BindingRuleBindTypeTemplatedPolicy,
+			map[string]string{"prefix": "apps/${name}"}, []string{"name"}, ""},
+		{"wrong bind type", structs.BindingRuleBindTypeService,
+			map[string]string{"prefix": "apps"}, nil, "BindVars can only be set"},
+		{"invalid name", structs.BindingRuleBindTypeTemplatedPolicy,
+			map[string]string{"Prefix": "apps"}, nil, "invalid variable name"},
+		{"empty value", structs.BindingRuleBindTypeTemplatedPolicy,
+			map[string]string{"prefix": ""}, nil, "computed value is empty"},
+		{"unknown projected var", structs.BindingRuleBindTypeTemplatedPolicy,
+			map[string]string{"prefix": "${unknown}"}, []string{"name"}, "variable \"prefix\""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := IsValidBindVars(test.bindType, test.bindVars, test.fields)
+			if test.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, test.err)
+			}
+		})
+	}
+}
+
 func Test_IsValidBindName(t *testing.T) {
 	type testcase struct {
 		name     string
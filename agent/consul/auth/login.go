@@ -41,8 +41,11 @@ func (l *Login) TokenForVerifiedIdentity(identity *authmethod.Identity, authMeth
 		Local:             authMethod.TokenLocality != "global", // TokenWriter prevents the creation of global tokens in secondary datacenters.
 		AuthMethod:        authMethod.Name,
 		ExpirationTTL:     authMethod.MaxTokenTTL,
+		Renewable:         authMethod.TokenRenewable,
+		MaxExpirationTTL:  authMethod.TokenMaxExpirationTTL,
 		ServiceIdentities: bindings.ServiceIdentities,
 		NodeIdentities:    bindings.NodeIdentities,
+		TemplatedPolicies: bindings.TemplatedPolicies,
 		Roles:             bindings.Roles,
 		EnterpriseMeta:    bindings.EnterpriseMeta,
 	}
@@ -137,6 +137,10 @@ func (w *TokenWriter) Create(token *structs.ACLToken, fromLogin bool) (*structs.
 		}
 	}
 
+	if err := w.validateRenewable(token); err != nil {
+		return nil, err
+	}
+
 	if fromLogin {
 		if token.AuthMethod == "" {
 			return nil, errors.New("AuthMethod field is required during login")
@@ -210,9 +214,195 @@ func (w *TokenWriter) Update(token *structs.ACLToken) (*structs.ACLToken, error)
 
 	token.CreateTime = match.CreateTime
 
+	if token.MaxExpirationTTL != 0 {
+		return nil, fmt.Errorf("Cannot change max expiration time of %s", token.AccessorID)
+	}
+
+	// Whether and how far the token can be renewed is set on creation.
+	token.Renewable = match.Renewable
+	token.RenewalTTL = match.RenewalTTL
+	token.MaxExpirationTime = match.MaxExpirationTime
+
+	// Updates don't end the grace period of a rotated token.
+	token.PreviousSecretID = match.PreviousSecretID
+	token.PreviousSecretExpirationTime = match.PreviousSecretExpirationTime
+
 	return w.write(token, match, false)
 }
 
+// validateRenewable checks the renewal settings of a token being created and
+// initializes its RenewalTTL and MaxExpirationTime.
+func (w *TokenWriter) validateRenewable(token *structs.ACLToken) error {
+	if token.MaxExpirationTTL < 0 {
+		return fmt.Errorf("Token Max Expiration TTL '%s' should be > 0", token.MaxExpirationTTL)
+	} else if token.MaxExpirationTTL > 0 {
+		if token.MaxExpirationTime != nil && !token.MaxExpirationTime.IsZero() {
+			return errors.New("Token Max Expiration TTL and Max Expiration Time cannot both be set")
+		}
+
+		maxExpirationTime := token.CreateTime.Add(token.MaxExpirationTTL)
+		token.MaxExpirationTime = &maxExpirationTime
+		token.MaxExpirationTTL = 0
+	}
+
+	if token.MaxExpirationTime != nil && token.MaxExpirationTime.IsZero() {
+		token.MaxExpirationTime = nil
+	}
+
+	if !token.Renewable {
+		if token.MaxExpirationTime != nil {
+			return errors.New("MaxExpirationTime can only be set on renewable tokens")
+		}
+		token.RenewalTTL = 0
+		return nil
+	}
+
+	if !token.HasExpirationTime() {
+		return errors.New("Renewable tokens must have an ExpirationTime")
+	}
+	if token.MaxExpirationTime != nil && token.MaxExpirationTime.Before(*token.ExpirationTime) {
+		return errors.New("MaxExpirationTime cannot be before ExpirationTime")
+	}
+
+	token.RenewalTTL = token.ExpirationTime.Sub(token.CreateTime)
+	return nil
+}
+
+// Renew extends the ExpirationTime of a renewable token to the given increment
+// from now, or its RenewalTTL if the increment is zero. The ExpirationTime is
+// never extended past the token's MaxExpirationTime, nor shortened.
+func (w *TokenWriter) Renew(accessorID string, entMeta *acl.EnterpriseMeta, increment time.Duration) (*structs.ACLToken, error) {
+	now := time.Now()
+
+	_, match, err := w.Store.ACLTokenGetByAccessor(nil, accessorID, entMeta)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("Failed acl token lookup by accessor: %w", err)
+	case match == nil || match.IsExpired(now):
+		return nil, fmt.Errorf("Cannot find token %q", accessorID)
+	case !match.Renewable:
+		return nil, fmt.Errorf("Token %q is not renewable", accessorID)
+	}
+
+	if err := w.checkCanWriteToken(match); err != nil {
+		return nil, err
+	}
+
+	if increment == 0 {
+		increment = match.RenewalTTL
+	}
+	if increment < 0 {
+		return nil, fmt.Errorf("Renewal increment '%s' should be > 0", increment)
+	} else if increment > w.MaxExpirationTTL {
+		return nil, fmt.Errorf("Renewal increment cannot be more than %s (was %s)", w.MaxExpirationTTL, increment)
+	} else if increment < w.MinExpirationTTL {
+		return nil, fmt.Errorf("Renewal increment cannot be less than %s (was %s)", w.MinExpirationTTL, increment)
+	}
+
+	expirationTime := now.Add(increment)
+	if match.MaxExpirationTime != nil && expirationTime.After(*match.MaxExpirationTime) {
+		expirationTime = *match.MaxExpirationTime
+	}
+	if !expirationTime.After(*match.ExpirationTime) {
+		// Nothing to extend.
+		return match, nil
+	}
+
+	token := match.Clone()
+	token.ExpirationTime = &expirationTime
+	token.SetHash(true)
+
+	_, err = w.RaftApply(structs.ACLTokenSetRequestType, &structs.ACLTokenBatchSetRequest{
+		Tokens: structs.ACLTokens{token},
+		CAS:    true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to apply token renew request: %w", err)
+	}
+
+	// Purge the token from the ACL cache, so it isn't rejected once its
+	// previous ExpirationTime has passed.
+	w.ACLCache.RemoveIdentityWithSecretToken(token.SecretID)
+	if token.PreviousSecretID != "" {
+		w.ACLCache.RemoveIdentityWithSecretToken(token.PreviousSecretID)
+	}
+
+	// Refresh the token from the state store.
+	_, updatedToken, err := w.Store.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	if err != nil || updatedToken == nil {
+		return nil, errors.New("Failed to retrieve token after renewal")
+	}
+	return updatedToken, nil
+}
+
+// Rotate issues a new SecretID for the token with the given AccessorID. The
+// current SecretID remains valid for the grace period, after which only the
+// new one can be used. A zero grace period invalidates the current SecretID
+// immediately. Rotating again before the grace period ends invalidates the
+// previous SecretID of the earlier rotation.
+func (w *TokenWriter) Rotate(accessorID string, entMeta *acl.EnterpriseMeta, grace time.Duration) (*structs.ACLToken, error) {
+	_, match, err := w.Store.ACLTokenGetByAccessor(nil, accessorID, entMeta)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("Failed acl token lookup by accessor: %w", err)
+	case match == nil || match.IsExpired(time.Now()):
+		return nil, fmt.Errorf("Cannot find token %q", accessorID)
+	case match.AccessorID == acl.AnonymousTokenID:
+		return nil, errors.New("Cannot rotate the anonymous token")
+	case match.AuthMethod != "":
+		return nil, errors.New("Cannot rotate a token created from an auth method")
+	}
+
+	if err := w.checkCanWriteToken(match); err != nil {
+		return nil, err
+	}
+
+	if grace < 0 {
+		return nil, fmt.Errorf("Grace period '%s' should be >= 0", grace)
+	} else if grace > w.MaxExpirationTTL {
+		return nil, fmt.Errorf("Grace period cannot be more than %s (was %s)", w.MaxExpirationTTL, grace)
+	}
+
+	secretID, err := lib.GenerateUUID(w.CheckUUID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate SecretID: %w", err)
+	}
+
+	token := match.Clone()
+	token.SecretID = secretID
+	token.PreviousSecretID = ""
+	if grace > 0 {
+		token.PreviousSecretID = match.SecretID
+	}
+	// The end of the grace period is recorded even without one, so that the
+	// hash of the token changes and the rotation is replicated.
+	graceExpirationTime := time.Now().Add(grace)
+	token.PreviousSecretExpirationTime = &graceExpirationTime
+	token.SetHash(true)
+
+	_, err = w.RaftApply(structs.ACLTokenSetRequestType, &structs.ACLTokenBatchSetRequest{
+		Tokens: structs.ACLTokens{token},
+		Rotate: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to apply token rotate request: %w", err)
+	}
+
+	// Purge the old secrets from the ACL cache, so they're resolved again
+	// with the grace period as their expiration time, or not at all.
+	w.ACLCache.RemoveIdentityWithSecretToken(match.SecretID)
+	if match.PreviousSecretID != "" {
+		w.ACLCache.RemoveIdentityWithSecretToken(match.PreviousSecretID)
+	}
+
+	// Refresh the token from the state store.
+	_, updatedToken, err := w.Store.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	if err != nil || updatedToken == nil {
+		return nil, errors.New("Failed to retrieve token after rotation")
+	}
+	return updatedToken, nil
+}
+
 // Delete the ACL token with the given SecretID from the state store.
 func (w *TokenWriter) Delete(secretID string, fromLogout bool) error {
 	_, token, err := w.Store.ACLTokenGetBySecret(nil, secretID, nil)
@@ -306,6 +496,12 @@ func (w *TokenWriter) write(token, existing *structs.ACLToken, fromLogin bool) (
 	}
 	token.NodeIdentities = nodeIdentities
 
+	templatedPolicies, err := w.normalizeTemplatedPolicies(token.TemplatedPolicies, &token.EnterpriseMeta)
+	if err != nil {
+		return nil, err
+	}
+	token.TemplatedPolicies = templatedPolicies
+
 	if err := w.enterpriseValidation(token, existing); err != nil {
 		return nil, err
 	}
@@ -327,6 +523,9 @@ func (w *TokenWriter) write(token, existing *structs.ACLToken, fromLogin bool) (
 
 	// Purge the token from the ACL cache.
 	w.ACLCache.RemoveIdentityWithSecretToken(token.SecretID)
+	if token.PreviousSecretID != "" {
+		w.ACLCache.RemoveIdentityWithSecretToken(token.PreviousSecretID)
+	}
 
 	// Refresh the token from the state store.
 	_, updatedToken, err := w.Store.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
@@ -385,6 +584,8 @@ func (w *TokenWriter) normalizePolicyLinks(links []structs.ACLTokenPolicyLink, e
 				return nil, fmt.Errorf("Error looking up policy for name: %q: %w", link.Name, err)
 			case role == nil:
 				return nil, fmt.Errorf("No such ACL policy with name %q", link.Name)
+			case role.IsTemplate():
+				return nil, fmt.Errorf("ACL policy %q is a template and must be linked as a templated policy", link.Name)
 			}
 			link.ID = role.ID
 		} else {
@@ -394,6 +595,8 @@ func (w *TokenWriter) normalizePolicyLinks(links []structs.ACLTokenPolicyLink, e
 				return nil, fmt.Errorf("Error looking up policy for ID: %q: %w", link.ID, err)
 			case role == nil:
 				return nil, fmt.Errorf("No such ACL policy with ID %q", link.ID)
+			case role.IsTemplate():
+				return nil, fmt.Errorf("ACL policy %q is a template and must be linked as a templated policy", role.Name)
 			}
 		}
 
@@ -410,6 +613,44 @@ func (w *TokenWriter) normalizePolicyLinks(links []structs.ACLTokenPolicyLink, e
 	return normalized, nil
 }
 
+func (w *TokenWriter) normalizeTemplatedPolicies(links structs.ACLTemplatedPolicies, entMeta *acl.EnterpriseMeta) (structs.ACLTemplatedPolicies, error) {
+	var normalized structs.ACLTemplatedPolicies
+	for _, link := range links {
+		var (
+			policy *structs.ACLPolicy
+			err    error
+		)
+		link = link.Clone()
+		if link.ID == "" {
+			_, policy, err = w.Store.ACLPolicyGetByName(nil, link.Name, entMeta)
+			switch {
+			case err != nil:
+				return nil, fmt.Errorf("Error looking up policy template for name: %q: %w", link.Name, err)
+			case policy == nil:
+				return nil, fmt.Errorf("No such ACL policy template with name %q", link.Name)
+			}
+			link.ID = policy.ID
+		} else {
+			_, policy, err = w.Store.ACLPolicyGetByID(nil, link.ID, entMeta)
+			switch {
+			case err != nil:
+				return nil, fmt.Errorf("Error looking up policy template for ID: %q: %w", link.ID, err)
+			case policy == nil:
+				return nil, fmt.Errorf("No such ACL policy template with ID %q", link.ID)
+			}
+		}
+
+		if err := link.Validate(policy); err != nil {
+			return nil, err
+		}
+
+		// Do not persist the template name as the template could be renamed in the future.
+		link.Name = ""
+		normalized = append(normalized, link)
+	}
+	return normalized.Deduplicate(), nil
+}
+
 func (w *TokenWriter) normalizeServiceIdentities(svcIDs structs.ACLServiceIdentities, tokenLocal bool) (structs.ACLServiceIdentities, error) {
 	for _, id := range svcIDs {
 		if id.ServiceName == "" {
@@ -400,6 +400,72 @@ func TestTokenWriter_Create_Expiration(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, expirationTime, *updated.ExpirationTime)
 	})
+
+	t.Run("Renewable", func(t *testing.T) {
+		token := &structs.ACLToken{
+			AccessorID: generateID(t),
+			SecretID:   generateID(t),
+			Roles: []structs.ACLTokenRoleLink{
+				{ID: role.ID},
+			},
+			ExpirationTTL:    10 * time.Minute,
+			Renewable:        true,
+			MaxExpirationTTL: 2 * time.Hour,
+		}
+
+		updated, err := writer.Create(token, false)
+		require.NoError(t, err)
+		require.True(t, updated.Renewable)
+		require.Equal(t, 10*time.Minute, updated.RenewalTTL)
+		require.Equal(t, updated.CreateTime.Add(2*time.Hour), *updated.MaxExpirationTime)
+		require.Zero(t, updated.MaxExpirationTTL)
+	})
+
+	t.Run("Renewable validation", func(t *testing.T) {
+		testCases := map[string]struct {
+			token         structs.ACLToken
+			errorContains string
+		}{
+			"without expiration": {
+				token:         structs.ACLToken{Renewable: true},
+				errorContains: "Renewable tokens must have an ExpirationTime",
+			},
+			"max ttl on non-renewable token": {
+				token: structs.ACLToken{
+					ExpirationTTL:    10 * time.Minute,
+					MaxExpirationTTL: time.Hour,
+				},
+				errorContains: "can only be set on renewable tokens",
+			},
+			"negative max ttl": {
+				token: structs.ACLToken{
+					ExpirationTTL:    10 * time.Minute,
+					Renewable:        true,
+					MaxExpirationTTL: -time.Hour,
+				},
+				errorContains: "should be > 0",
+			},
+			"max ttl before expiration": {
+				token: structs.ACLToken{
+					ExpirationTTL:    time.Hour,
+					Renewable:        true,
+					MaxExpirationTTL: 10 * time.Minute,
+				},
+				errorContains: "MaxExpirationTime cannot be before ExpirationTime",
+			},
+		}
+		for desc, tc := range testCases {
+			t.Run(desc, func(t *testing.T) {
+				token := tc.token
+				token.AccessorID = generateID(t)
+				token.SecretID = generateID(t)
+
+				_, err := writer.Create(&token, false)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errorContains)
+			})
+		}
+	})
 }
 
 func TestTokenWriter_Create_Success(t *testing.T) {
@@ -539,6 +605,271 @@ func TestTokenWriter_Update_Success(t *testing.T) {
 	require.NotEqual(t, token.Hash, updated.Hash)
 }
 
+func TestTokenWriter_Rotate(t *testing.T) {
+	t.Run("with grace period", func(t *testing.T) {
+		store := testStateStore(t)
+
+		token := &structs.ACLToken{
+			AccessorID:     generateID(t),
+			SecretID:       generateID(t),
+			Description:    "Rotated",
+			ExpirationTime: timePointer(time.Now().Add(48 * time.Hour)),
+		}
+		token.SetHash(true)
+		require.NoError(t, store.ACLTokenSet(0, token))
+
+		aclCache := &MockACLCache{}
+		aclCache.On("RemoveIdentityWithSecretToken", token.SecretID)
+		defer aclCache.AssertExpectations(t)
+
+		writer := buildTokenWriter(store, aclCache)
+		rotated, err := writer.Rotate(token.AccessorID, nil, 12*time.Hour)
+		require.NoError(t, err)
+
+		require.Equal(t, token.AccessorID, rotated.AccessorID)
+		require.NotEqual(t, token.SecretID, rotated.SecretID)
+		require.Equal(t, token.SecretID, rotated.PreviousSecretID)
+		require.WithinDuration(t, time.Now().Add(12*time.Hour), *rotated.PreviousSecretExpirationTime, time.Minute)
+		require.Equal(t, token.Description, rotated.Description)
+		require.Equal(t, token.ExpirationTime, rotated.ExpirationTime)
+		require.NotEqual(t, token.Hash, rotated.Hash)
+
+		// Updates leave the grace period alone.
+		aclCache.On("RemoveIdentityWithSecretToken", rotated.SecretID)
+		updated, err := writer.Update(&structs.ACLToken{
+			AccessorID:  token.AccessorID,
+			SecretID:    rotated.SecretID,
+			Description: "New Description",
+		})
+		require.NoError(t, err)
+		require.Equal(t, rotated.PreviousSecretID, updated.PreviousSecretID)
+		require.Equal(t, rotated.PreviousSecretExpirationTime, updated.PreviousSecretExpirationTime)
+	})
+
+	t.Run("without grace period", func(t *testing.T) {
+		store := testStateStore(t)
+
+		token := &structs.ACLToken{
+			AccessorID: generateID(t),
+			SecretID:   generateID(t),
+		}
+		token.SetHash(true)
+		require.NoError(t, store.ACLTokenSet(0, token))
+
+		aclCache := &MockACLCache{}
+		aclCache.On("RemoveIdentityWithSecretToken", token.SecretID)
+		defer aclCache.AssertExpectations(t)
+
+		writer := buildTokenWriter(store, aclCache)
+		rotated, err := writer.Rotate(token.AccessorID, nil, 0)
+		require.NoError(t, err)
+		require.NotEqual(t, token.SecretID, rotated.SecretID)
+		require.Empty(t, rotated.PreviousSecretID)
+		require.NotEqual(t, token.Hash, rotated.Hash)
+
+		_, match, err := store.ACLTokenGetBySecret(nil, token.SecretID, nil)
+		require.NoError(t, err)
+		require.Nil(t, match)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		store := testStateStore(t)
+
+		authMethod := &structs.ACLAuthMethod{
+			Name: generateID(t),
+			Type: "jwt",
+		}
+		require.NoError(t, store.ACLAuthMethodSet(0, authMethod))
+
+		token := &structs.ACLToken{
+			AccessorID: generateID(t),
+			SecretID:   generateID(t),
+		}
+		require.NoError(t, store.ACLTokenSet(0, token))
+
+		loginToken := &structs.ACLToken{
+			AccessorID: generateID(t),
+			SecretID:   generateID(t),
+			AuthMethod: authMethod.Name,
+		}
+		require.NoError(t, store.ACLTokenSet(0, loginToken))
+
+		anonymousToken := &structs.ACLToken{
+			AccessorID: acl.AnonymousTokenID,
+			SecretID:   acl.AnonymousTokenSecret,
+		}
+		require.NoError(t, store.ACLTokenSet(0, anonymousToken))
+
+		writer := buildTokenWriter(store, nil)
+
+		testCases := map[string]struct {
+			accessorID    string
+			grace         time.Duration
+			errorContains string
+		}{
+			"token not found": {
+				accessorID:    generateID(t),
+				errorContains: "Cannot find token",
+			},
+			"anonymous token": {
+				accessorID:    acl.AnonymousTokenID,
+				errorContains: "Cannot rotate the anonymous token",
+			},
+			"auth method token": {
+				accessorID:    loginToken.AccessorID,
+				errorContains: "Cannot rotate a token created from an auth method",
+			},
+			"negative grace period": {
+				accessorID:    token.AccessorID,
+				grace:         -time.Minute,
+				errorContains: "should be >= 0",
+			},
+			"grace period too long": {
+				accessorID:    token.AccessorID,
+				grace:         48 * time.Hour,
+				errorContains: "Grace period cannot be more than",
+			},
+		}
+		for desc, tc := range testCases {
+			t.Run(desc, func(t *testing.T) {
+				_, err := writer.Rotate(tc.accessorID, nil, tc.grace)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errorContains)
+			})
+		}
+	})
+}
+
+func TestTokenWriter_Renew(t *testing.T) {
+	createToken := func(t *testing.T, store *state.Store, token *structs.ACLToken) {
+		token.AccessorID = generateID(t)
+		token.SecretID = generateID(t)
+		token.CreateTime = time.Now()
+		token.SetHash(true)
+		require.NoError(t, store.ACLTokenSet(1, token))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		store := testStateStore(t)
+
+		token := &structs.ACLToken{
+			ExpirationTime: timePointer(time.Now().Add(5 * time.Minute)),
+			Renewable:      true,
+			RenewalTTL:     10 * time.Minute,
+		}
+		createToken(t, store, token)
+
+		aclCache := &MockACLCache{}
+		aclCache.On("RemoveIdentityWithSecretToken", token.SecretID)
+		defer aclCache.AssertExpectations(t)
+
+		writer := buildTokenWriter(store, aclCache)
+
+		renewed, err := writer.Renew(token.AccessorID, nil, 0)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(10*time.Minute), *renewed.ExpirationTime, time.Minute)
+		require.NotEqual(t, token.Hash, renewed.Hash)
+
+		renewed, err = writer.Renew(token.AccessorID, nil, 3*time.Hour)
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(3*time.Hour), *renewed.ExpirationTime, time.Minute)
+
+		// Renewals don't shorten the expiration time.
+		unchanged, err := writer.Renew(token.AccessorID, nil, time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, renewed.ExpirationTime, unchanged.ExpirationTime)
+		require.Equal(t, renewed.ModifyIndex, unchanged.ModifyIndex)
+	})
+
+	t.Run("capped at max expiration time", func(t *testing.T) {
+		store := testStateStore(t)
+
+		maxExpirationTime := time.Now().Add(30 * time.Minute)
+		token := &structs.ACLToken{
+			ExpirationTime:    timePointer(time.Now().Add(5 * time.Minute)),
+			Renewable:         true,
+			RenewalTTL:        10 * time.Minute,
+			MaxExpirationTime: &maxExpirationTime,
+		}
+		createToken(t, store, token)
+
+		aclCache := &MockACLCache{}
+		aclCache.On("RemoveIdentityWithSecretToken", token.SecretID)
+
+		writer := buildTokenWriter(store, aclCache)
+
+		renewed, err := writer.Renew(token.AccessorID, nil, time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, maxExpirationTime.UnixNano(), renewed.ExpirationTime.UnixNano())
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		store := testStateStore(t)
+
+		renewable := &structs.ACLToken{
+			ExpirationTime: timePointer(time.Now().Add(5 * time.Minute)),
+			Renewable:      true,
+			RenewalTTL:     10 * time.Minute,
+		}
+		createToken(t, store, renewable)
+
+		notRenewable := &structs.ACLToken{
+			ExpirationTime: timePointer(time.Now().Add(5 * time.Minute)),
+		}
+		createToken(t, store, notRenewable)
+
+		expired := &structs.ACLToken{
+			ExpirationTime: timePointer(time.Now().Add(-time.Minute)),
+			Renewable:      true,
+			RenewalTTL:     10 * time.Minute,
+		}
+		createToken(t, store, expired)
+
+		writer := buildTokenWriter(store, nil)
+
+		testCases := map[string]struct {
+			accessorID    string
+			increment     time.Duration
+			errorContains string
+		}{
+			"token not found": {
+				accessorID:    generateID(t),
+				errorContains: "Cannot find token",
+			},
+			"expired token": {
+				accessorID:    expired.AccessorID,
+				errorContains: "Cannot find token",
+			},
+			"not renewable": {
+				accessorID:    notRenewable.AccessorID,
+				errorContains: "is not renewable",
+			},
+			"negative increment": {
+				accessorID:    renewable.AccessorID,
+				increment:     -time.Minute,
+				errorContains: "should be > 0",
+			},
+			"increment too short": {
+				accessorID:    renewable.AccessorID,
+				increment:     time.Second,
+				errorContains: "Renewal increment cannot be less than",
+			},
+			"increment too long": {
+				accessorID:    renewable.AccessorID,
+				increment:     48 * time.Hour,
+				errorContains: "Renewal increment cannot be more than",
+			},
+		}
+		for desc, tc := range testCases {
+			t.Run(desc, func(t *testing.T) {
+				_, err := writer.Renew(tc.accessorID, nil, tc.increment)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errorContains)
+			})
+		}
+	})
+}
+
 func TestTokenWriter_Delete(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		store := testStateStore(t)
@@ -652,6 +983,8 @@ func TestTokenWriter_Delete(t *testing.T) {
 }
 
 func raftApplyACLTokenSet(store *state.Store) RaftApplyFn {
+	// Apply each request at a new index, so CAS requests can tell writes apart.
+	var idx uint64
 	return func(msgType structs.MessageType, msg interface{}) (interface{}, error) {
 		if msgType != structs.ACLTokenSetRequestType {
 			return nil, fmt.Errorf("unexpected message type: %v", msgType)
@@ -662,10 +995,12 @@ func raftApplyACLTokenSet(store *state.Store) RaftApplyFn {
 			return nil, fmt.Errorf("unexpected message: %T", msg)
 		}
 
-		err := store.ACLTokenBatchSet(0, req.Tokens, state.ACLTokenSetOptions{
+		idx++
+		err := store.ACLTokenBatchSet(idx, req.Tokens, state.ACLTokenSetOptions{
 			CAS:                          req.CAS,
 			AllowMissingPolicyAndRoleIDs: req.AllowMissingLinks,
 			ProhibitUnprivileged:         req.ProhibitUnprivileged,
+			Rotate:                       req.Rotate,
 		})
 		return nil, err
 	}
@@ -190,6 +190,7 @@ func ParseConfig(rawConfig map[string]interface{}, out interface{}) error {
 		Result:           out,
 		WeaklyTypedInput: true,
 		ErrorUnused:      true,
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
 	}
 
 	decoder, err := mapstructure.NewDecoder(decodeConf)
@@ -0,0 +1,384 @@
+package certauth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/hashicorp/consul/agent/consul/authmethod"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	authMethodType string = "cert"
+
+	// DefaultMaxTokenAge is the default for how long after it was issued a
+	// bearer token may be used to login.
+	DefaultMaxTokenAge = 5 * time.Minute
+
+	// DefaultClockSkewLeeway is the default allowance for clock skew between
+	// the client creating the bearer token and the servers.
+	DefaultClockSkewLeeway = jwt.DefaultLeeway
+)
+
+const (
+	subjectCommonNameField = "subject.common_name"
+	serialNumberField      = "serial_number"
+	dnsSANField            = "dns_san"
+	uriSANField            = "uri_san"
+	emailSANField          = "email_san"
+)
+
+func init() {
+	// register this as an available auth method type
+	authmethod.Register(authMethodType, func(logger hclog.Logger, method *structs.ACLAuthMethod) (authmethod.Validator, error) {
+		v, err := NewValidator(logger, method)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+type Config struct {
+	// CACerts are the PEM encoded CA certificates client certificate chains
+	// must be rooted in. Intermediate certificates are presented by the client
+	// along with its certificate.
+	CACerts []string `json:",omitempty"`
+
+	// CRLs are PEM encoded certificate revocation lists issued by the CAs or
+	// the intermediates they signed. Certificates revoked by any of them are
+	// rejected.
+	CRLs []string `json:",omitempty"`
+
+	// AllowedCommonNames, AllowedDNSSANs, AllowedURISANs and AllowedEmailSANs
+	// restrict which client certificates may login. Values may contain the *
+	// wildcard. When more than one is set, matching any one of them is enough.
+	// When none are set every certificate issued by the CAs may login.
+	AllowedCommonNames []string `json:",omitempty"`
+	AllowedDNSSANs     []string `json:",omitempty"`
+	AllowedURISANs     []string `json:",omitempty"`
+	AllowedEmailSANs   []string `json:",omitempty"`
+
+	// MaxTokenAge is how long after it was issued a bearer token may be used
+	// to login. Defaults to 5 minutes.
+	MaxTokenAge time.Duration `json:",omitempty"`
+
+	// ClockSkewLeeway is the allowance for clock skew when checking the
+	// timestamps of the bearer token. Defaults to 1 minute.
+	ClockSkewLeeway time.Duration `json:",omitempty"`
+}
+
+// Validator validates bearer tokens signed with the private key of a client
+// certificate, and conforms to the authmethod.Validator interface.
+type Validator struct {
+	name   string
+	config *Config
+	logger hclog.Logger
+
+	roots *x509.CertPool
+	crls  []*x509.RevocationList
+}
+
+func NewValidator(logger hclog.Logger, method *structs.ACLAuthMethod) (*Validator, error) {
+	if method.Type != authMethodType {
+		return nil, fmt.Errorf("%q is not a cert auth method", method.Name)
+	}
+
+	var config Config
+	if err := authmethod.ParseConfig(method.Config, &config); err != nil {
+		return nil, err
+	}
+
+	if len(config.CACerts) == 0 {
+		return nil, fmt.Errorf("Config.CACerts is required")
+	}
+	roots := x509.NewCertPool()
+	for _, caCert := range config.CACerts {
+		if !roots.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, fmt.Errorf("error parsing Config.CACerts: no PEM encoded certificates found")
+		}
+	}
+
+	var crls []*x509.RevocationList
+	for _, raw := range config.CRLs {
+		crl, err := parseCRL(raw)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing Config.CRLs: %v", err)
+		}
+		crls = append(crls, crl)
+	}
+
+	allowed := map[string][]string{
+		"AllowedCommonNames": config.AllowedCommonNames,
+		"AllowedDNSSANs":     config.AllowedDNSSANs,
+		"AllowedURISANs":     config.AllowedURISANs,
+		"AllowedEmailSANs":   config.AllowedEmailSANs,
+	}
+	for field, patterns := range allowed {
+		for _, pattern := range patterns {
+			if strings.Count(pattern, "*") > 1 {
+				return nil, fmt.Errorf("Config.%s %q may contain at most one wildcard", field, pattern)
+			}
+		}
+	}
+
+	if config.MaxTokenAge < 0 {
+		return nil, fmt.Errorf("Config.MaxTokenAge must not be negative")
+	}
+	if config.MaxTokenAge == 0 {
+		config.MaxTokenAge = DefaultMaxTokenAge
+	}
+	if config.ClockSkewLeeway < 0 {
+		return nil, fmt.Errorf("Config.ClockSkewLeeway must not be negative")
+	}
+	if config.ClockSkewLeeway == 0 {
+		config.ClockSkewLeeway = DefaultClockSkewLeeway
+	}
+
+	return &Validator{
+		name:   method.Name,
+		config: &config,
+		logger: logger,
+		roots:  roots,
+		crls:   crls,
+	}, nil
+}
+
+func parseCRL(raw string) (*x509.RevocationList, error) {
+	data := []byte(raw)
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "X509 CRL" {
+			return nil, fmt.Errorf("unexpected PEM block type %q", block.Type)
+		}
+		data = block.Bytes
+	}
+	return x509.ParseRevocationList(data)
+}
+
+// Name implements authmethod.Validator.
+func (v *Validator) Name() string { return v.name }
+
+// Stop implements authmethod.Validator.
+func (v *Validator) Stop() {}
+
+// ValidateLogin implements authmethod.Validator.
+func (v *Validator) ValidateLogin(ctx context.Context, loginToken string) (*authmethod.Identity, error) {
+	cert, err := v.verifyBearerToken(loginToken, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	fields := &certFieldDetails{
+		Subject: certFieldDetailsSubject{
+			CommonName:         cert.Subject.CommonName,
+			Organization:       cert.Subject.Organization,
+			OrganizationalUnit: cert.Subject.OrganizationalUnit,
+		},
+		SerialNumber: formatSerial(cert.SerialNumber),
+		DNSSANs:      cert.DNSNames,
+		EmailSANs:    cert.EmailAddresses,
+	}
+	for _, u := range cert.URIs {
+		fields.URISANs = append(fields.URISANs, u.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		fields.IPSANs = append(fields.IPSANs, ip.String())
+	}
+
+	id := v.NewIdentity()
+	id.SelectableFields = fields
+	id.ProjectedVars[subjectCommonNameField] = fields.Subject.CommonName
+	id.ProjectedVars[serialNumberField] = fields.SerialNumber
+	id.ProjectedVars[dnsSANField] = first(fields.DNSSANs)
+	id.ProjectedVars[uriSANField] = first(fields.URISANs)
+	id.ProjectedVars[emailSANField] = first(fields.EmailSANs)
+
+	return id, nil
+}
+
+// verifyBearerToken checks the signature and claims of the bearer token and
+// verifies the certificate chain it carries, returning the client
+// certificate.
+func (v *Validator) verifyBearerToken(loginToken string, now time.Time) (*x509.Certificate, error) {
+	tok, err := jwt.ParseSigned(loginToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bearer token: %v", err)
+	}
+	if len(tok.Headers) != 1 {
+		return nil, errors.New("bearer token must have exactly one signature")
+	}
+
+	chains, err := tok.Headers[0].Certificates(x509.VerifyOptions{
+		Roots:       v.roots,
+		CurrentTime: now,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify client certificate: %v", err)
+	}
+	cert := chains[0][0]
+
+	var claims jwt.Claims
+	if err := tok.Claims(cert.PublicKey, &claims); err != nil {
+		return nil, fmt.Errorf("failed to verify bearer token signature: %v", err)
+	}
+	if claims.IssuedAt == nil {
+		return nil, errors.New("bearer token is missing the iat claim")
+	}
+	expected := jwt.Expected{
+		Audience: jwt.Audience{v.name},
+		Time:     now,
+	}
+	if err := claims.ValidateWithLeeway(expected, v.config.ClockSkewLeeway); err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %v", err)
+	}
+	if now.Sub(claims.IssuedAt.Time()) > v.config.MaxTokenAge+v.config.ClockSkewLeeway {
+		return nil, errors.New("bearer token is too old")
+	}
+
+	if err := v.checkRevocation(chains); err != nil {
+		return nil, err
+	}
+
+	if !v.allowed(cert) {
+		return nil, errors.New("client certificate is not allowed to login to this auth method")
+	}
+
+	return cert, nil
+}
+
+// checkRevocation rejects the chains if a certificate in any of them has been
+// revoked by the CA that issued it.
+func (v *Validator) checkRevocation(chains [][]*x509.Certificate) error {
+	if len(v.crls) == 0 {
+		return nil
+	}
+	for _, chain := range chains {
+		for i := 0; i < len(chain)-1; i++ {
+			cert, issuer := chain[i], chain[i+1]
+			for _, crl := range v.crls {
+				if crl.CheckSignatureFrom(issuer) != nil {
+					continue
+				}
+				for _, revoked := range crl.RevokedCertificates {
+					if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+						return fmt.Errorf("certificate %s has been revoked", formatSerial(cert.SerialNumber))
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// allowed returns whether the certificate matches the allowed names.
+func (v *Validator) allowed(cert *x509.Certificate) bool {
+	c := v.config
+	if len(c.AllowedCommonNames) == 0 && len(c.AllowedDNSSANs) == 0 &&
+		len(c.AllowedURISANs) == 0 && len(c.AllowedEmailSANs) == 0 {
+		return true
+	}
+
+	if matchAny(c.AllowedCommonNames, cert.Subject.CommonName) {
+		return true
+	}
+	for _, name := range cert.DNSNames {
+		if matchAny(c.AllowedDNSSANs, name) {
+			return true
+		}
+	}
+	for _, u := range cert.URIs {
+		if matchAny(c.AllowedURISANs, u.String()) {
+			return true
+		}
+	}
+	for _, email := range cert.EmailAddresses {
+		if matchAny(c.AllowedEmailSANs, email) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Validator) NewIdentity() *authmethod.Identity {
+	id := &authmethod.Identity{
+		SelectableFields: &certFieldDetails{},
+		ProjectedVars:    map[string]string{},
+	}
+	for _, f := range availableFields {
+		id.ProjectedVars[f] = ""
+	}
+	return id
+}
+
+var availableFields = []string{
+	subjectCommonNameField,
+	serialNumberField,
+	dnsSANField,
+	uriSANField,
+	emailSANField,
+}
+
+type certFieldDetails struct {
+	Subject      certFieldDetailsSubject `bexpr:"subject"`
+	SerialNumber string                  `bexpr:"serial_number"`
+	DNSSANs      []string                `bexpr:"dns_sans"`
+	URISANs      []string                `bexpr:"uri_sans"`
+	EmailSANs    []string                `bexpr:"email_sans"`
+	IPSANs       []string                `bexpr:"ip_sans"`
+}
+
+type certFieldDetailsSubject struct {
+	CommonName         string   `bexpr:"common_name"`
+	Organization       []string `bexpr:"organization"`
+	OrganizationalUnit []string `bexpr:"organizational_unit"`
+}
+
+// matchAny returns whether the value matches any of the patterns. Patterns
+// may contain a single * wildcard matching any sequence of characters.
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		prefix, suffix, wildcard := strings.Cut(pattern, "*")
+		if !wildcard {
+			if pattern == value {
+				return true
+			}
+			continue
+		}
+		if len(value) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatSerial formats the serial number as colon separated hex bytes, the
+// same way it's shown by most tools.
+func formatSerial(serial *big.Int) string {
+	b := serial.Bytes()
+	if len(b) == 0 {
+		return "00"
+	}
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = fmt.Sprintf("%02x", c)
+	}
+	return strings.Join(parts, ":")
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
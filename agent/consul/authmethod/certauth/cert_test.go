@@ -0,0 +1,242 @@
+package certauth
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/consul/authmethod"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestNewValidator(t *testing.T) {
+	ca := NewTestCA(t, "Test Root CA")
+
+	type AM = *structs.ACLAuthMethod
+
+	makeAuthMethod := func(f func(method AM)) *structs.ACLAuthMethod {
+		method := &structs.ACLAuthMethod{
+			Name:        "test-cert",
+			Description: "cert test",
+			Type:        "cert",
+			Config: map[string]interface{}{
+				"CACerts": []string{ca.CertPEM},
+			},
+		}
+		if f != nil {
+			f(method)
+		}
+		return method
+	}
+
+	for _, test := range []struct {
+		name   string
+		method *structs.ACLAuthMethod
+		ok     bool
+	}{
+		// bad
+		{"wrong type", makeAuthMethod(func(method AM) {
+			method.Type = "invalid"
+		}), false},
+		{"extra config", makeAuthMethod(func(method AM) {
+			method.Config["extra"] = "config"
+		}), false},
+		{"missing ca certs", makeAuthMethod(func(method AM) {
+			delete(method.Config, "CACerts")
+		}), false},
+		{"invalid ca cert", makeAuthMethod(func(method AM) {
+			method.Config["CACerts"] = []string{"invalid"}
+		}), false},
+		{"invalid crl", makeAuthMethod(func(method AM) {
+			method.Config["CRLs"] = []string{ca.CertPEM}
+		}), false},
+		{"too many wildcards", makeAuthMethod(func(method AM) {
+			method.Config["AllowedDNSSANs"] = []string{"*.*.example.com"}
+		}), false},
+		{"negative max token age", makeAuthMethod(func(method AM) {
+			method.Config["MaxTokenAge"] = -time.Second
+		}), false},
+		// good
+		{"normal", makeAuthMethod(nil), true},
+		{"with crl and allowed names", makeAuthMethod(func(method AM) {
+			method.Config["CRLs"] = []string{ca.RevocationList(t)}
+			method.Config["AllowedURISANs"] = []string{"spiffe://example.org/*"}
+			method.Config["MaxTokenAge"] = "10m"
+		}), true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := NewValidator(hclog.NewNullLogger(), test.method)
+			if test.ok {
+				require.NoError(t, err)
+				require.NotNil(t, v)
+			} else {
+				require.NotNil(t, err)
+				require.Nil(t, v)
+			}
+		})
+	}
+}
+
+func TestNewIdentity(t *testing.T) {
+	ca := NewTestCA(t, "Test Root CA")
+
+	v, err := NewValidator(hclog.NewNullLogger(), &structs.ACLAuthMethod{
+		Name: "test-cert",
+		Type: "cert",
+		Config: map[string]interface{}{
+			"CACerts": []string{ca.CertPEM},
+		},
+	})
+	require.NoError(t, err)
+
+	id := v.NewIdentity()
+	authmethod.RequireIdentityMatch(t, id, map[string]string{
+		"subject.common_name": "",
+		"serial_number":       "",
+		"dns_san":             "",
+		"uri_san":             "",
+		"email_san":           "",
+	},
+		`subject.common_name == ""`,
+		`serial_number == ""`,
+		`dns_sans is empty`,
+		`uri_sans is empty`,
+	)
+}
+
+func TestValidateLogin(t *testing.T) {
+	root := NewTestCA(t, "Test Root CA")
+	intermediate := root.Intermediate(t, "Test Intermediate CA")
+	other := NewTestCA(t, "Other Root CA")
+
+	spiffeID, err := url.Parse("spiffe://example.org/ns/default/web")
+	require.NoError(t, err)
+
+	webCert, webKey, _ := intermediate.IssueClientCert(t, &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "web-1",
+			Organization:       []string{"Example"},
+			OrganizationalUnit: []string{"frontend", "web"},
+		},
+		DNSNames: []string{"web-1.example.org"},
+		URIs:     []*url.URL{spiffeID},
+	})
+	revokedCert, revokedKey, revoked := intermediate.IssueClientCert(t, &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "web-2"},
+		DNSNames: []string{"web-2.example.org"},
+	})
+	dbCert, dbKey, _ := root.IssueClientCert(t, &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "db-1"},
+		DNSNames: []string{"db-1.example.net"},
+	})
+	serverCert, serverKey, _ := root.IssueClientCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "server-1"},
+		DNSNames:    []string{"server-1.example.org"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	otherCert, otherKey, _ := other.IssueClientCert(t, &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "web-1"},
+		DNSNames: []string{"web-1.example.org"},
+	})
+
+	v, err := NewValidator(hclog.NewNullLogger(), &structs.ACLAuthMethod{
+		Name: "test-cert",
+		Type: "cert",
+		Config: map[string]interface{}{
+			"CACerts":        []string{root.CertPEM},
+			"CRLs":           []string{intermediate.RevocationList(t, revoked)},
+			"AllowedDNSSANs": []string{"*.example.org"},
+		},
+	})
+	require.NoError(t, err)
+
+	bearerToken := func(t *testing.T, method, certPEM, keyPEM string) string {
+		token, err := NewBearerToken(method, []byte(certPEM), []byte(keyPEM))
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("valid chain", func(t *testing.T) {
+		id, err := v.ValidateLogin(context.Background(), bearerToken(t, "test-cert", webCert, webKey))
+		require.NoError(t, err)
+
+		authmethod.RequireIdentityMatch(t, id, map[string]string{
+			"subject.common_name": "web-1",
+			"serial_number":       id.ProjectedVars["serial_number"],
+			"dns_san":             "web-1.example.org",
+			"uri_san":             "spiffe://example.org/ns/default/web",
+			"email_san":           "",
+		},
+			`subject.common_name == "web-1"`,
+			`"web" in subject.organizational_unit`,
+			`"Example" in subject.organization`,
+			`"web-1.example.org" in dns_sans`,
+			`"spiffe://example.org/ns/default/web" in uri_sans`,
+			`email_sans is empty`,
+		)
+		require.NotEmpty(t, id.ProjectedVars["serial_number"])
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		_, err := v.ValidateLogin(context.Background(), bearerToken(t, "other-method", webCert, webKey))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid bearer token")
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		_, err := v.verifyBearerToken(bearerToken(t, "test-cert", webCert, webKey), time.Now().Add(10*time.Minute))
+		require.Error(t, err)
+	})
+
+	t.Run("untrusted ca", func(t *testing.T) {
+		_, err := v.ValidateLogin(context.Background(), bearerToken(t, "test-cert", otherCert, otherKey))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify client certificate")
+	})
+
+	t.Run("not a client certificate", func(t *testing.T) {
+		_, err := v.ValidateLogin(context.Background(), bearerToken(t, "test-cert", serverCert, serverKey))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to verify client certificate")
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		_, err := v.ValidateLogin(context.Background(), bearerToken(t, "test-cert", revokedCert, revokedKey))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "has been revoked")
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		_, err := v.ValidateLogin(context.Background(), bearerToken(t, "test-cert", dbCert, dbKey))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not allowed")
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		_, err := NewBearerToken("test-cert", []byte(webCert), []byte(dbKey))
+		require.Error(t, err)
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		_, err := v.ValidateLogin(context.Background(), "not-a-jwt")
+		require.Error(t, err)
+	})
+}
+
+func TestMatchAny(t *testing.T) {
+	patterns := []string{"web.example.org", "*.internal", "spiffe://example.org/ns/*/web"}
+
+	require.True(t, matchAny(patterns, "web.example.org"))
+	require.True(t, matchAny(patterns, "db.internal"))
+	require.True(t, matchAny(patterns, "spiffe://example.org/ns/default/web"))
+	require.False(t, matchAny(patterns, "api.example.org"))
+	require.False(t, matchAny(patterns, "internal"))
+	require.False(t, matchAny(patterns, "spiffe://example.org/ns/default/db"))
+	require.False(t, matchAny(nil, "web.example.org"))
+}
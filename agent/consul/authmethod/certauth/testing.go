@@ -0,0 +1,153 @@
+package certauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/require"
+)
+
+var testSerial uint64
+
+// TestCA is a certificate authority issuing client certificates for testing
+// the cert auth method.
+type TestCA struct {
+	Cert    *x509.Certificate
+	CertPEM string
+
+	key crypto.Signer
+
+	// chain holds the PEM encoded intermediates between this CA and the root,
+	// it's appended to the client certificates it issues.
+	chain []string
+}
+
+// NewTestCA creates a self-signed root CA.
+func NewTestCA(t testing.T, name string) *TestCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          nextTestSerial(),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &TestCA{
+		Cert:    cert,
+		CertPEM: encodePEM("CERTIFICATE", der),
+		key:     key,
+	}
+}
+
+// Intermediate creates an intermediate CA signed by this CA.
+func (ca *TestCA) Intermediate(t testing.T, name string) *TestCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          nextTestSerial(),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	certPEM := encodePEM("CERTIFICATE", der)
+	return &TestCA{
+		Cert:    cert,
+		CertPEM: certPEM,
+		key:     key,
+		chain:   append([]string{certPEM}, ca.chain...),
+	}
+}
+
+// IssueClientCert issues a client certificate using the subject, SANs and
+// validity of the template, filling in the rest. It returns the PEM encoded
+// certificate followed by any intermediates, the PEM encoded private key and
+// the certificate itself.
+func (ca *TestCA) IssueClientCert(t testing.T, template *x509.Certificate) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = nextTestSerial()
+	if template.NotBefore.IsZero() {
+		template.NotBefore = time.Now().Add(-time.Hour)
+	}
+	if template.NotAfter.IsZero() {
+		template.NotAfter = time.Now().Add(time.Hour)
+	}
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	if template.ExtKeyUsage == nil {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := encodePEM("CERTIFICATE", der) + strings.Join(ca.chain, "")
+	return certPEM, encodePEM("EC PRIVATE KEY", keyDER), cert
+}
+
+// RevocationList returns a PEM encoded CRL revoking the given certificates.
+func (ca *TestCA) RevocationList(t testing.T, revoked ...*x509.Certificate) string {
+	t.Helper()
+
+	template := &x509.RevocationList{
+		Number:     nextTestSerial(),
+		ThisUpdate: time.Now().Add(-time.Minute),
+		NextUpdate: time.Now().Add(time.Hour),
+	}
+	for _, cert := range revoked {
+		template.RevokedCertificates = append(template.RevokedCertificates, pkix.RevokedCertificate{
+			SerialNumber:   cert.SerialNumber,
+			RevocationTime: time.Now().Add(-time.Minute),
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.Cert, ca.key)
+	require.NoError(t, err)
+	return encodePEM("X509 CRL", der)
+}
+
+func nextTestSerial() *big.Int {
+	return new(big.Int).SetUint64(atomic.AddUint64(&testSerial, 1))
+}
+
+func encodePEM(blockType string, der []byte) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
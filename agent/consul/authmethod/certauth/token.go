@@ -0,0 +1,79 @@
+package certauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// DefaultTokenTTL is how long bearer tokens created by NewBearerToken are
+// valid for.
+const DefaultTokenTTL = time.Minute
+
+// NewBearerToken creates a bearer token for logging in to the cert auth
+// method with the given name. The token is a JWT signed with the private key
+// of the client certificate, carrying the certificate chain in its x5c
+// header. The certificate PEM may include intermediates after the client
+// certificate.
+func NewBearerToken(authMethod string, certPEM, keyPEM []byte) (string, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return "", fmt.Errorf("failed to load client certificate: %v", err)
+	}
+
+	alg, err := signatureAlgorithm(pair.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	chain := make([]string, 0, len(pair.Certificate))
+	for _, der := range pair.Certificate {
+		chain = append(chain, base64.StdEncoding.EncodeToString(der))
+	}
+
+	opts := (&jose.SignerOptions{}).
+		WithType("JWT").
+		WithHeader("x5c", chain)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: pair.PrivateKey}, opts)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := jwt.Claims{
+		Audience:  jwt.Audience{authMethod},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Expiry:    jwt.NewNumericDate(now.Add(DefaultTokenTTL)),
+	}
+	return jwt.Signed(signer).Claims(claims).CompactSerialize()
+}
+
+func signatureAlgorithm(key crypto.PrivateKey) (jose.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jose.RS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+		return "", fmt.Errorf("unsupported elliptic curve %s", k.Curve.Params().Name)
+	case ed25519.PrivateKey:
+		return jose.EdDSA, nil
+	}
+	return "", fmt.Errorf("unsupported private key type %T", key)
+}
@@ -5,13 +5,16 @@ package consul
 
 import (
 	"github.com/hashicorp/consul/agent/metadata"
+	"github.com/hashicorp/consul/agent/structs"
 	autopilot "github.com/hashicorp/raft-autopilot"
 )
 
 func (s *Server) autopilotPromoter() autopilot.Promoter {
-	return autopilot.DefaultPromoter()
+	return &RedundancyZonePromoter{}
 }
 
-func (_ *Server) autopilotServerExt(_ *metadata.Server) interface{} {
-	return nil
+func (_ *Server) autopilotServerExt(srv *metadata.Server) interface{} {
+	return &structs.AutopilotServerExt{
+		ReadReplica: srv.ReadReplica,
+	}
 }
@@ -0,0 +1,235 @@
+package consul
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hashicorp/raft"
+	autopilot "github.com/hashicorp/raft-autopilot"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+const (
+	// NodeTypeReadReplica is the type of the read replicas, they are never
+	// promoted.
+	NodeTypeReadReplica autopilot.NodeType = "read-replica"
+
+	// NodeTypeZoneVoter is the type of the voter of a redundancy zone.
+	NodeTypeZoneVoter autopilot.NodeType = "zone-voter"
+
+	// NodeTypeZoneStandby is the type of the servers of a redundancy zone
+	// which are promoted when the voter of the zone fails.
+	NodeTypeZoneStandby autopilot.NodeType = "zone-standby"
+)
+
+// RedundancyZonePromoter is an autopilot promoter which keeps one voter in
+// each redundancy zone. When the voter of a zone fails, a healthy server of
+// the same zone is promoted and the failed voter is demoted. The servers
+// without a zone are promoted like with the default promoter, the read
+// replicas are never promoted.
+type RedundancyZonePromoter struct{}
+
+func (*RedundancyZonePromoter) GetServerExt(conf *autopilot.Config, srv *autopilot.ServerState) interface{} {
+	ext := serverExt(srv)
+	out := &structs.AutopilotServerExt{ReadReplica: ext.ReadReplica}
+	if tag := redundancyZoneTag(conf); tag != "" && !out.ReadReplica {
+		out.RedundancyZone = srv.Server.Meta[tag]
+	}
+	return out
+}
+
+func (*RedundancyZonePromoter) GetStateExt(conf *autopilot.Config, state *autopilot.State) interface{} {
+	ext := &structs.AutopilotStateExt{
+		OptimisticFailureTolerance: state.FailureTolerance,
+	}
+
+	for _, id := range sortedServerIDs(state) {
+		srv := state.Servers[id]
+		srvExt := serverExt(srv)
+		if srvExt.ReadReplica {
+			ext.ReadReplicas = append(ext.ReadReplicas, id)
+			continue
+		}
+		if srvExt.RedundancyZone == "" {
+			continue
+		}
+
+		if ext.RedundancyZones == nil {
+			ext.RedundancyZones = make(map[string]structs.AutopilotZone)
+		}
+		zone := ext.RedundancyZones[srvExt.RedundancyZone]
+		zone.Servers = append(zone.Servers, id)
+		if srv.HasVotingRights() {
+			zone.Voters = append(zone.Voters, id)
+		}
+		if srv.Health.Healthy {
+			zone.FailureTolerance++
+			if !srv.HasVotingRights() {
+				// The healthy standby servers replace the voters failing one
+				// after the other.
+				ext.OptimisticFailureTolerance++
+			}
+		}
+		ext.RedundancyZones[srvExt.RedundancyZone] = zone
+	}
+
+	// One healthy server of the zone must remain to be its voter.
+	for name, zone := range ext.RedundancyZones {
+		if zone.FailureTolerance > 0 {
+			zone.FailureTolerance--
+		}
+		ext.RedundancyZones[name] = zone
+	}
+
+	return ext
+}
+
+func (*RedundancyZonePromoter) GetNodeTypes(conf *autopilot.Config, state *autopilot.State) map[raft.ServerID]autopilot.NodeType {
+	voters := zoneVoters(conf, state)
+
+	types := make(map[raft.ServerID]autopilot.NodeType)
+	for id, srv := range state.Servers {
+		ext := serverExt(srv)
+		switch {
+		case ext.ReadReplica:
+			types[id] = NodeTypeReadReplica
+		case ext.RedundancyZone == "":
+			types[id] = autopilot.NodeVoter
+		case voters[ext.RedundancyZone] == id:
+			types[id] = NodeTypeZoneVoter
+		default:
+			types[id] = NodeTypeZoneStandby
+		}
+	}
+	return types
+}
+
+func (*RedundancyZonePromoter) FilterFailedServerRemovals(_ *autopilot.Config, _ *autopilot.State, failed *autopilot.FailedServers) *autopilot.FailedServers {
+	return failed
+}
+
+// CalculatePromotionsAndDemotions promotes the stable servers without a zone
+// and the voter elected in each zone. The other voters of a zone are demoted
+// once the zone has its voter, so that the number of voters never drops
+// while a zone changes its voter. The leader is never demoted.
+func (*RedundancyZonePromoter) CalculatePromotionsAndDemotions(conf *autopilot.Config, state *autopilot.State) autopilot.RaftChanges {
+	var changes autopilot.RaftChanges
+
+	now := time.Now()
+	minStableDuration := state.ServerStabilizationTime(conf)
+	voters := zoneVoters(conf, state)
+	for _, id := range sortedServerIDs(state) {
+		srv := state.Servers[id]
+		ext := serverExt(srv)
+		switch {
+		case ext.ReadReplica:
+			continue
+
+		case ext.RedundancyZone == "":
+			// ignore staging state as they are not ready yet
+			if srv.State == autopilot.RaftNonVoter && srv.Health.IsStable(now, minStableDuration) {
+				changes.Promotions = append(changes.Promotions, id)
+			}
+
+		case voters[ext.RedundancyZone] == id:
+			if srv.State == autopilot.RaftNonVoter {
+				changes.Promotions = append(changes.Promotions, id)
+			}
+
+		case srv.HasVotingRights() && srv.State != autopilot.RaftLeader:
+			voter := state.Servers[voters[ext.RedundancyZone]]
+			if voter != nil && voter.HasVotingRights() {
+				changes.Demotions = append(changes.Demotions, id)
+			}
+		}
+	}
+
+	return changes
+}
+
+// zoneVoters returns the server elected as the voter of each zone. The
+// current voter is kept while it is healthy, the leader being preferred,
+// otherwise the first stable server of the zone is elected. A zone without a
+// healthy server keeps its current voter.
+func zoneVoters(conf *autopilot.Config, state *autopilot.State) map[string]raft.ServerID {
+	now := time.Now()
+	minStableDuration := state.ServerStabilizationTime(conf)
+
+	type candidates struct {
+		healthyVoter raft.ServerID
+		stable       raft.ServerID
+		voter        raft.ServerID
+	}
+	zones := make(map[string]*candidates)
+	for _, id := range sortedServerIDs(state) {
+		srv := state.Servers[id]
+		ext := serverExt(srv)
+		if ext.ReadReplica || ext.RedundancyZone == "" {
+			continue
+		}
+
+		zone, ok := zones[ext.RedundancyZone]
+		if !ok {
+			zone = &candidates{}
+			zones[ext.RedundancyZone] = zone
+		}
+		switch {
+		case srv.HasVotingRights() && srv.Health.Healthy:
+			if zone.healthyVoter == "" || srv.State == autopilot.RaftLeader {
+				zone.healthyVoter = id
+			}
+		case srv.HasVotingRights():
+			if zone.voter == "" {
+				zone.voter = id
+			}
+		case srv.State == autopilot.RaftNonVoter && srv.Health.IsStable(now, minStableDuration):
+			if zone.stable == "" {
+				zone.stable = id
+			}
+		}
+	}
+
+	voters := make(map[string]raft.ServerID)
+	for name, zone := range zones {
+		switch {
+		case zone.healthyVoter != "":
+			voters[name] = zone.healthyVoter
+		case zone.stable != "":
+			voters[name] = zone.stable
+		case zone.voter != "":
+			voters[name] = zone.voter
+		}
+	}
+	return voters
+}
+
+// serverExt returns the extended state of the server, the read replica flag
+// is set by the autopilot delegate and the zone by the promoter.
+func serverExt(srv *autopilot.ServerState) *structs.AutopilotServerExt {
+	if ext, ok := srv.Server.Ext.(*structs.AutopilotServerExt); ok && ext != nil {
+		return ext
+	}
+	return &structs.AutopilotServerExt{}
+}
+
+func redundancyZoneTag(conf *autopilot.Config) string {
+	if conf == nil {
+		return ""
+	}
+	if ext, ok := conf.Ext.(*structs.AutopilotConfigExt); ok && ext != nil {
+		return ext.RedundancyZoneTag
+	}
+	return ""
+}
+
+// sortedServerIDs returns the IDs of the servers in a stable order, so that
+// the same servers are elected each time.
+func sortedServerIDs(state *autopilot.State) []raft.ServerID {
+	ids := make([]raft.ServerID, 0, len(state.Servers))
+	for id := range state.Servers {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
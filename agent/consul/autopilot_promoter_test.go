@@ -0,0 +1,228 @@
+package consul
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	autopilot "github.com/hashicorp/raft-autopilot"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestRedundancyZonePromoter(t *testing.T) {
+	conf := (&structs.AutopilotConfig{RedundancyZoneTag: "zone"}).ToAutopilotLibraryConfig()
+	stable := time.Now().Add(-time.Hour)
+
+	type server struct {
+		zone        string
+		readReplica bool
+		state       autopilot.RaftState
+		healthy     bool
+	}
+	newState := func(servers map[raft.ServerID]server) *autopilot.State {
+		state := &autopilot.State{Servers: make(map[raft.ServerID]*autopilot.ServerState)}
+		promoter := &RedundancyZonePromoter{}
+		for id, srv := range servers {
+			srvState := &autopilot.ServerState{
+				Server: autopilot.Server{
+					ID:   id,
+					Meta: map[string]string{},
+					Ext:  &structs.AutopilotServerExt{ReadReplica: srv.readReplica},
+				},
+				State:  srv.state,
+				Health: autopilot.ServerHealth{Healthy: srv.healthy, StableSince: stable},
+			}
+			if srv.zone != "" {
+				srvState.Server.Meta["zone"] = srv.zone
+			}
+			srvState.Server.Ext = promoter.GetServerExt(conf, srvState)
+			if srv.state == autopilot.RaftLeader {
+				state.Leader = id
+			}
+			state.Servers[id] = srvState
+		}
+		return state
+	}
+
+	cases := map[string]struct {
+		servers    map[raft.ServerID]server
+		types      map[raft.ServerID]autopilot.NodeType
+		promotions []raft.ServerID
+		demotions  []raft.ServerID
+	}{
+		"no zones": {
+			servers: map[raft.ServerID]server{
+				"a": {state: autopilot.RaftLeader, healthy: true},
+				"b": {state: autopilot.RaftNonVoter, healthy: true},
+				"c": {state: autopilot.RaftNonVoter, healthy: false},
+			},
+			types: map[raft.ServerID]autopilot.NodeType{
+				"a": autopilot.NodeVoter,
+				"b": autopilot.NodeVoter,
+				"c": autopilot.NodeVoter,
+			},
+			promotions: []raft.ServerID{"b"},
+		},
+		"read replicas are never promoted": {
+			servers: map[raft.ServerID]server{
+				"a": {state: autopilot.RaftLeader, healthy: true},
+				"b": {state: autopilot.RaftNonVoter, healthy: true, readReplica: true},
+				"c": {state: autopilot.RaftNonVoter, healthy: true, readReplica: true, zone: "z1"},
+			},
+			types: map[raft.ServerID]autopilot.NodeType{
+				"a": autopilot.NodeVoter,
+				"b": NodeTypeReadReplica,
+				"c": NodeTypeReadReplica,
+			},
+		},
+		"one voter per zone": {
+			servers: map[raft.ServerID]server{
+				"a": {state: autopilot.RaftLeader, healthy: true, zone: "z1"},
+				"b": {state: autopilot.RaftNonVoter, healthy: true, zone: "z1"},
+				"c": {state: autopilot.RaftNonVoter, healthy: true, zone: "z2"},
+				"d": {state: autopilot.RaftNonVoter, healthy: true, zone: "z2"},
+				"e": {state: autopilot.RaftNonVoter, healthy: true, zone: "z3"},
+			},
+			types: map[raft.ServerID]autopilot.NodeType{
+				"a": NodeTypeZoneVoter,
+				"b": NodeTypeZoneStandby,
+				"c": NodeTypeZoneVoter,
+				"d": NodeTypeZoneStandby,
+				"e": NodeTypeZoneVoter,
+			},
+			promotions: []raft.ServerID{"c", "e"},
+		},
+		"failed voter is replaced": {
+			servers: map[raft.ServerID]server{
+				"a": {state: autopilot.RaftLeader, healthy: true, zone: "z1"},
+				"b": {state: autopilot.RaftVoter, healthy: false, zone: "z2"},
+				"c": {state: autopilot.RaftNonVoter, healthy: false, zone: "z2"},
+				"d": {state: autopilot.RaftNonVoter, healthy: true, zone: "z2"},
+			},
+			types: map[raft.ServerID]autopilot.NodeType{
+				"a": NodeTypeZoneVoter,
+				"b": NodeTypeZoneStandby,
+				"c": NodeTypeZoneStandby,
+				"d": NodeTypeZoneVoter,
+			},
+			promotions: []raft.ServerID{"d"},
+		},
+		"failed voter is demoted once replaced": {
+			servers: map[raft.ServerID]server{
+				"a": {state: autopilot.RaftLeader, healthy: true, zone: "z1"},
+				"b": {state: autopilot.RaftVoter, healthy: false, zone: "z2"},
+				"d": {state: autopilot.RaftVoter, healthy: true, zone: "z2"},
+			},
+			types: map[raft.ServerID]autopilot.NodeType{
+				"a": NodeTypeZoneVoter,
+				"b": NodeTypeZoneStandby,
+				"d": NodeTypeZoneVoter,
+			},
+			demotions: []raft.ServerID{"b"},
+		},
+		"zone without healthy server keeps its voter": {
+			servers: map[raft.ServerID]server{
+				"a": {state: autopilot.RaftLeader, healthy: true, zone: "z1"},
+				"b": {state: autopilot.RaftVoter, healthy: false, zone: "z2"},
+				"c": {state: autopilot.RaftNonVoter, healthy: false, zone: "z2"},
+			},
+			types: map[raft.ServerID]autopilot.NodeType{
+				"a": NodeTypeZoneVoter,
+				"b": NodeTypeZoneVoter,
+				"c": NodeTypeZoneStandby,
+			},
+		},
+		"leader is kept as the voter of its zone": {
+			servers: map[raft.ServerID]server{
+				"a": {state: autopilot.RaftVoter, healthy: true, zone: "z1"},
+				"b": {state: autopilot.RaftLeader, healthy: true, zone: "z1"},
+			},
+			types: map[raft.ServerID]autopilot.NodeType{
+				"a": NodeTypeZoneStandby,
+				"b": NodeTypeZoneVoter,
+			},
+			demotions: []raft.ServerID{"a"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			state := newState(tc.servers)
+			promoter := &RedundancyZonePromoter{}
+
+			require.Equal(t, tc.types, promoter.GetNodeTypes(conf, state))
+
+			changes := promoter.CalculatePromotionsAndDemotions(conf, state)
+			require.Equal(t, tc.promotions, changes.Promotions)
+			require.Equal(t, tc.demotions, changes.Demotions)
+		})
+	}
+}
+
+func TestRedundancyZonePromoter_GetStateExt(t *testing.T) {
+	conf := (&structs.AutopilotConfig{RedundancyZoneTag: "zone"}).ToAutopilotLibraryConfig()
+	promoter := &RedundancyZonePromoter{}
+
+	newServer := func(id raft.ServerID, zone string, readReplica bool, state autopilot.RaftState, healthy bool) *autopilot.ServerState {
+		srv := &autopilot.ServerState{
+			Server: autopilot.Server{
+				ID:   id,
+				Meta: map[string]string{"zone": zone},
+				Ext:  &structs.AutopilotServerExt{ReadReplica: readReplica},
+			},
+			State:  state,
+			Health: autopilot.ServerHealth{Healthy: healthy},
+		}
+		srv.Server.Ext = promoter.GetServerExt(conf, srv)
+		return srv
+	}
+	state := &autopilot.State{
+		FailureTolerance: 0,
+		Servers: map[raft.ServerID]*autopilot.ServerState{
+			"a": newServer("a", "z1", false, autopilot.RaftLeader, true),
+			"b": newServer("b", "z1", false, autopilot.RaftNonVoter, true),
+			"c": newServer("c", "z1", false, autopilot.RaftNonVoter, true),
+			"d": newServer("d", "z2", false, autopilot.RaftVoter, true),
+			"e": newServer("e", "z2", false, autopilot.RaftNonVoter, false),
+			"f": newServer("f", "z3", false, autopilot.RaftVoter, true),
+			"g": newServer("g", "z3", true, autopilot.RaftNonVoter, true),
+		},
+	}
+
+	require.Equal(t, &structs.AutopilotServerExt{RedundancyZone: "z1"}, state.Servers["a"].Server.Ext)
+	require.Equal(t, &structs.AutopilotServerExt{ReadReplica: true}, state.Servers["g"].Server.Ext)
+
+	expected := &structs.AutopilotStateExt{
+		OptimisticFailureTolerance: 2,
+		ReadReplicas:               []raft.ServerID{"g"},
+		RedundancyZones: map[string]structs.AutopilotZone{
+			"z1": {
+				Servers:          []raft.ServerID{"a", "b", "c"},
+				Voters:           []raft.ServerID{"a"},
+				FailureTolerance: 2,
+			},
+			"z2": {
+				Servers:          []raft.ServerID{"d", "e"},
+				Voters:           []raft.ServerID{"d"},
+				FailureTolerance: 0,
+			},
+			"z3": {
+				Servers:          []raft.ServerID{"f"},
+				Voters:           []raft.ServerID{"f"},
+				FailureTolerance: 0,
+			},
+		},
+	}
+	require.Equal(t, expected, promoter.GetStateExt(conf, state))
+
+	// The extended state is decoded once it is received over RPC.
+	buf, err := structs.Encode(structs.AutopilotRequestType, expected)
+	require.NoError(t, err)
+	var raw interface{}
+	require.NoError(t, structs.Decode(buf[1:], &raw))
+	decoded, err := structs.DecodeAutopilotStateExt(raw)
+	require.NoError(t, err)
+	require.Equal(t, expected, decoded)
+}
@@ -436,6 +436,56 @@ func TestAutopilot_PromoteNonVoter(t *testing.T) {
 	})
 }
 
+func TestAutopilot_ReadReplicaNotPromoted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir1, s1 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc1"
+		c.Bootstrap = true
+		c.AutopilotConfig.ServerStabilizationTime = 200 * time.Millisecond
+		c.ServerHealthInterval = 100 * time.Millisecond
+		c.AutopilotInterval = 100 * time.Millisecond
+	})
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	dir2, s2 := testServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc1"
+		c.Bootstrap = false
+		c.ReadReplica = true
+		c.RaftConfig.ProtocolVersion = 3
+	})
+	defer os.RemoveAll(dir2)
+	defer s2.Shutdown()
+	joinLAN(t, s2, s1)
+
+	// Wait until the read replica is stable.
+	retry.Run(t, func(r *retry.R) {
+		state := s1.autopilot.GetState()
+		require.NotNil(r, state)
+		srv, ok := state.Servers[s2.config.RaftConfig.LocalID]
+		require.True(r, ok)
+		require.Equal(r, NodeTypeReadReplica, srv.Server.NodeType)
+		require.True(r, srv.Health.IsStable(time.Now(), s1.config.AutopilotConfig.ServerStabilizationTime))
+	})
+
+	// Give autopilot a few rounds to promote it, it must remain a non-voter.
+	time.Sleep(5 * s1.config.AutopilotInterval)
+	future := s1.raft.GetConfiguration()
+	require.NoError(t, future.Error())
+	servers := future.Configuration().Servers
+	require.Len(t, servers, 2)
+	for _, srv := range servers {
+		if srv.ID == s2.config.RaftConfig.LocalID {
+			require.Equal(t, raft.Nonvoter, srv.Suffrage)
+		}
+	}
+}
+
 func TestAutopilot_MinQuorum(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -39,6 +39,12 @@ const (
 	// log store backends.
 	LogStoreBackendBoltDB = "boltdb"
 	LogStoreBackendWAL    = "wal"
+
+	// ConsistentReadMode* are the ways the leader can verify its leadership
+	// before serving a consistent read. The barrier mode verifies it with a
+	// quorum of servers for each read, the lease mode once per leader lease.
+	ConsistentReadModeBarrier = "barrier"
+	ConsistentReadModeLease   = "lease"
 )
 
 var (
@@ -113,7 +119,7 @@ type Config struct {
 	// RaftConfig is the configuration used for Raft in the local DC
 	RaftConfig *raft.Config
 
-	// (Enterprise-only) ReadReplica is used to prevent this server from being added
+	// ReadReplica is used to prevent this server from being added
 	// as a voting member of the Raft cluster.
 	ReadReplica bool
 
@@ -321,6 +327,25 @@ type Config struct {
 	// warning and discard the remaining updates.
 	CoordinateUpdateMaxBatches int
 
+	// ACLTokenUsageUpdatePeriod controls how long a server batches token
+	// usage updates from agents before applying them in a Raft transaction.
+	ACLTokenUsageUpdatePeriod time.Duration
+
+	// ACLTokenUsageUpdateBatchSize controls the maximum number of token
+	// usage updates a server batches before applying them in a Raft
+	// transaction.
+	ACLTokenUsageUpdateBatchSize int
+
+	// ACLTokenUsageUpdateMaxBatches controls the maximum number of batches
+	// of token usage updates we are willing to apply in one period. After
+	// this limit we will issue a warning and discard the remaining updates.
+	ACLTokenUsageUpdateMaxBatches int
+
+	// ACLTokenUsageGranularity is how stale the recorded usage of a token
+	// can get before it's updated. A token that's used all the time only
+	// results in one write per granularity period.
+	ACLTokenUsageGranularity time.Duration
+
 	// CheckOutputMaxSize control the max size of output of checks
 	CheckOutputMaxSize int
 
@@ -343,6 +368,39 @@ type Config struct {
 	// limiter limits the rate to RequestLimitsWriteRate tokens per second.
 	RequestLimitsWriteRate rate.Limit
 
+	// RequestLimitsIPMode will disable or enable rate limiting by source IP
+	// address. It takes the same values as RequestLimitsMode, applied when the
+	// RequestLimitsIPReadRate or RequestLimitsIPWriteRate of a source IP
+	// address is exceeded.
+	RequestLimitsIPMode string
+
+	// RequestLimitsIPReadRate controls how frequently the RPC and gRPC queries
+	// of each source IP address are allowed to happen.
+	RequestLimitsIPReadRate rate.Limit
+
+	// RequestLimitsIPWriteRate controls how frequently the RPC and gRPC writes
+	// of each source IP address are allowed to happen.
+	RequestLimitsIPWriteRate rate.Limit
+
+	// RequestLimitsAccessorMode will disable or enable rate limiting by ACL
+	// token accessor ID. It takes the same values as RequestLimitsMode,
+	// applied when the RequestLimitsAccessorReadRate or
+	// RequestLimitsAccessorWriteRate of an accessor ID is exceeded.
+	RequestLimitsAccessorMode string
+
+	// RequestLimitsAccessorReadRate controls how frequently the RPC queries
+	// made with each ACL token are allowed to happen.
+	RequestLimitsAccessorReadRate rate.Limit
+
+	// RequestLimitsAccessorWriteRate controls how frequently the RPC writes
+	// made with each ACL token are allowed to happen.
+	RequestLimitsAccessorWriteRate rate.Limit
+
+	// RequestLimitsAdaptive configures the adaptive write limit, which reduces
+	// RequestLimitsWriteRate while the health signals of the leader exceed
+	// their targets and recovers it gradually once they are back under them.
+	RequestLimitsAdaptive consulrate.AdaptiveConfig
+
 	// RPCHandshakeTimeout limits how long we will wait for the initial magic byte
 	// on an RPC client connection. It also governs how long we will wait for a
 	// TLS handshake when TLS is configured however the timout applies separately
@@ -356,6 +414,18 @@ type Config struct {
 	// place, and a small jitter is applied to avoid a thundering herd.
 	RPCHoldTimeout time.Duration
 
+	// ConsistentReadMode is how the leader verifies its leadership before
+	// serving a consistent read, either ConsistentReadModeBarrier or
+	// ConsistentReadModeLease.
+	ConsistentReadMode string
+
+	// LeaseMaxClockDrift is the maximum amount the clocks of the servers can
+	// drift apart during a Raft heartbeat timeout. The leader lease lasts
+	// for the heartbeat timeout minus this drift: a quorum of followers
+	// acknowledged the leader when the lease was renewed, and they won't
+	// elect another leader before their heartbeat timeout expires.
+	LeaseMaxClockDrift time.Duration
+
 	// RPCClientTimeout limits how long a client is allowed to read from an RPC
 	// connection. This is used to set an upper bound for non-blocking queries to
 	// eventually terminate so that RPC connections are not held indefinitely.
@@ -524,12 +594,29 @@ func DefaultConfig() *Config {
 		CoordinateUpdateBatchSize:  128,
 		CoordinateUpdateMaxBatches: 5,
 
+		ACLTokenUsageUpdatePeriod:     30 * time.Second,
+		ACLTokenUsageUpdateBatchSize:  128,
+		ACLTokenUsageUpdateMaxBatches: 5,
+		ACLTokenUsageGranularity:      10 * time.Minute,
+
 		CheckOutputMaxSize: checks.DefaultBufSize,
 
 		RequestLimitsMode:      "disabled",
 		RequestLimitsReadRate:  rate.Inf, // ops / sec
 		RequestLimitsWriteRate: rate.Inf, // ops / sec
 
+		RequestLimitsIPMode:            "disabled",
+		RequestLimitsIPReadRate:        rate.Inf, // ops / sec
+		RequestLimitsIPWriteRate:       rate.Inf, // ops / sec
+		RequestLimitsAccessorMode:      "disabled",
+		RequestLimitsAccessorReadRate:  rate.Inf, // ops / sec
+		RequestLimitsAccessorWriteRate: rate.Inf, // ops / sec
+		RequestLimitsAdaptive: consulrate.AdaptiveConfig{
+			CommitTimeTarget:   100 * time.Millisecond,
+			ApplyQueueTarget:   128,
+			FSMApplyTimeTarget: 20 * time.Millisecond,
+		},
+
 		RPCRateLimit: rate.Inf,
 		RPCMaxBurst:  1000,
 
@@ -560,6 +647,9 @@ func DefaultConfig() *Config {
 		DefaultQueryTime:         300 * time.Second,
 		MaxQueryTime:             600 * time.Second,
 
+		ConsistentReadMode: ConsistentReadModeBarrier,
+		LeaseMaxClockDrift: 500 * time.Millisecond,
+
 		PeeringTestAllowPeerRegistrations: false,
 
 		EnterpriseConfig: DefaultEnterpriseConfig(),
@@ -655,6 +745,16 @@ type RequestLimits struct {
 	Mode      consulrate.Mode
 	ReadRate  rate.Limit
 	WriteRate rate.Limit
+
+	IPMode      consulrate.Mode
+	IPReadRate  rate.Limit
+	IPWriteRate rate.Limit
+
+	AccessorMode      consulrate.Mode
+	AccessorReadRate  rate.Limit
+	AccessorWriteRate rate.Limit
+
+	Adaptive consulrate.AdaptiveConfig
 }
 
 // ReloadableConfig is the configuration that is passed to ReloadConfig when
@@ -123,6 +123,7 @@ func init() {
 	registerCommand(structs.ConnectCARequestType, (*FSM).applyConnectCAOperation)
 	registerCommand(structs.ACLTokenSetRequestType, (*FSM).applyACLTokenSetOperation)
 	registerCommand(structs.ACLTokenDeleteRequestType, (*FSM).applyACLTokenDeleteOperation)
+	registerCommand(structs.ACLTokenUsageBatchUpdateType, (*FSM).applyACLTokenUsageBatchUpdate)
 	registerCommand(structs.ACLBootstrapRequestType, (*FSM).applyACLTokenBootstrap)
 	registerCommand(structs.ACLPolicySetRequestType, (*FSM).applyACLPolicySetOperation)
 	registerCommand(structs.ACLPolicyDeleteRequestType, (*FSM).applyACLPolicyDeleteOperation)
@@ -144,6 +145,45 @@ func init() {
 	registerCommand(structs.PeeringSecretsWriteType, (*FSM).applyPeeringSecretsWrite)
 }
 
+// commandPayloads is a map from message type to a constructor of the request
+// decoded by its command, it is used by DecodeCommand and must be kept in sync
+// with the registered commands.
+var commandPayloads = map[structs.MessageType]func() interface{}{
+	structs.RegisterRequestType:             func() interface{} { return &structs.RegisterRequest{} },
+	structs.DeregisterRequestType:           func() interface{} { return &structs.DeregisterRequest{} },
+	structs.KVSRequestType:                  func() interface{} { return &structs.KVSRequest{} },
+	structs.SessionRequestType:              func() interface{} { return &structs.SessionRequest{} },
+	structs.TombstoneRequestType:            func() interface{} { return &structs.TombstoneRequest{} },
+	structs.CoordinateBatchUpdateType:       func() interface{} { return &structs.Coordinates{} },
+	structs.PreparedQueryRequestType:        func() interface{} { return &structs.PreparedQueryRequest{} },
+	structs.TxnRequestType:                  func() interface{} { return &structs.TxnRequest{} },
+	structs.AutopilotRequestType:            func() interface{} { return &structs.AutopilotSetConfigRequest{} },
+	structs.IntentionRequestType:            func() interface{} { return &structs.IntentionRequest{} },
+	structs.ConnectCARequestType:            func() interface{} { return &structs.CARequest{} },
+	structs.ACLTokenSetRequestType:          func() interface{} { return &structs.ACLTokenBatchSetRequest{} },
+	structs.ACLTokenDeleteRequestType:       func() interface{} { return &structs.ACLTokenBatchDeleteRequest{} },
+	structs.ACLTokenUsageBatchUpdateType:    func() interface{} { return &structs.ACLTokenUsageBatchUpdateRequest{} },
+	structs.ACLBootstrapRequestType:         func() interface{} { return &structs.ACLTokenBootstrapRequest{} },
+	structs.ACLPolicySetRequestType:         func() interface{} { return &structs.ACLPolicyBatchSetRequest{} },
+	structs.ACLPolicyDeleteRequestType:      func() interface{} { return &structs.ACLPolicyBatchDeleteRequest{} },
+	structs.ConnectCALeafRequestType:        func() interface{} { return &structs.CALeafRequest{} },
+	structs.ConfigEntryRequestType:          func() interface{} { return &structs.ConfigEntryRequest{} },
+	structs.ACLRoleSetRequestType:           func() interface{} { return &structs.ACLRoleBatchSetRequest{} },
+	structs.ACLRoleDeleteRequestType:        func() interface{} { return &structs.ACLRoleBatchDeleteRequest{} },
+	structs.ACLBindingRuleSetRequestType:    func() interface{} { return &structs.ACLBindingRuleBatchSetRequest{} },
+	structs.ACLBindingRuleDeleteRequestType: func() interface{} { return &structs.ACLBindingRuleBatchDeleteRequest{} },
+	structs.ACLAuthMethodSetRequestType:     func() interface{} { return &structs.ACLAuthMethodBatchSetRequest{} },
+	structs.ACLAuthMethodDeleteRequestType:  func() interface{} { return &structs.ACLAuthMethodBatchDeleteRequest{} },
+	structs.FederationStateRequestType:      func() interface{} { return &structs.FederationStateRequest{} },
+	structs.SystemMetadataRequestType:       func() interface{} { return &structs.SystemMetadataRequest{} },
+	structs.PeeringWriteType:                func() interface{} { return &pbpeering.PeeringWriteRequest{} },
+	structs.PeeringDeleteType:               func() interface{} { return &pbpeering.PeeringDeleteRequest{} },
+	structs.PeeringTerminateByIDType:        func() interface{} { return &pbpeering.PeeringTerminateByIDRequest{} },
+	structs.PeeringTrustBundleWriteType:     func() interface{} { return &pbpeering.PeeringTrustBundleWriteRequest{} },
+	structs.PeeringTrustBundleDeleteType:    func() interface{} { return &pbpeering.PeeringTrustBundleDeleteRequest{} },
+	structs.PeeringSecretsWriteType:         func() interface{} { return &pbpeering.SecretsWriteRequest{} },
+}
+
 func (c *FSM) applyRegister(buf []byte, index uint64) interface{} {
 	defer metrics.MeasureSince([]string{"fsm", "register"}, time.Now())
 	var req structs.RegisterRequest
@@ -491,6 +531,7 @@ func (c *FSM) applyACLTokenSetOperation(buf []byte, index uint64) interface{} {
 		AllowMissingPolicyAndRoleIDs: req.AllowMissingLinks,
 		ProhibitUnprivileged:         req.ProhibitUnprivileged,
 		FromReplication:              req.FromReplication,
+		Rotate:                       req.Rotate,
 	}
 	return c.state.ACLTokenBatchSet(index, req.Tokens, opts)
 }
@@ -506,6 +547,17 @@ func (c *FSM) applyACLTokenDeleteOperation(buf []byte, index uint64) interface{}
 	return c.state.ACLTokenBatchDelete(index, req.TokenIDs)
 }
 
+func (c *FSM) applyACLTokenUsageBatchUpdate(buf []byte, index uint64) interface{} {
+	var req structs.ACLTokenUsageBatchUpdateRequest
+	if err := structs.Decode(buf, &req); err != nil {
+		panic(fmt.Errorf("failed to decode request: %v", err))
+	}
+	defer metrics.MeasureSinceWithLabels([]string{"fsm", "acl", "token"}, time.Now(),
+		[]metrics.Label{{Name: "op", Value: "usage"}})
+
+	return c.state.ACLTokenUsageBatchUpdate(index, req.Updates)
+}
+
 func (c *FSM) applyACLTokenBootstrap(buf []byte, index uint64) interface{} {
 	var req structs.ACLTokenBootstrapRequest
 	if err := structs.Decode(buf, &req); err != nil {
@@ -21,6 +21,7 @@ import (
 	"github.com/hashicorp/consul/agent/connect"
 	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/proto/private/pbpeering"
 	"github.com/hashicorp/consul/sdk/testutil"
 	"github.com/hashicorp/consul/types"
 )
@@ -1714,3 +1715,42 @@ func TestFSM_Chunking_TermChange(t *testing.T) {
 		}
 	}
 }
+
+func TestDecodeCommand(t *testing.T) {
+	// Every command has a payload, except the legacy ACL one.
+	for msg := range commands {
+		if msg == structs.DeprecatedACLRequestType {
+			continue
+		}
+		require.Contains(t, commandPayloads, msg, "no payload for %s", msg)
+	}
+
+	req := structs.KVSRequest{
+		Datacenter: "dc1",
+		Op:         api.KVSet,
+		DirEnt: structs.DirEntry{
+			Key:   "/test/path",
+			Flags: 0,
+			Value: []byte("test"),
+		},
+	}
+	buf, err := structs.Encode(structs.KVSRequestType, req)
+	require.NoError(t, err)
+
+	decoded, err := DecodeCommand(structs.KVSRequestType, buf[1:])
+	require.NoError(t, err)
+	require.Equal(t, &req, decoded)
+
+	// Protobuf payloads are decoded too.
+	peeringReq := &pbpeering.PeeringWriteRequest{
+		Peering: &pbpeering.Peering{ID: "9e650110-ac74-4c5a-a6a8-9348b2bed4e9", Name: "my-peer"},
+	}
+	buf, err = structs.EncodeProto(structs.PeeringWriteType, peeringReq)
+	require.NoError(t, err)
+	decoded, err = DecodeCommand(structs.PeeringWriteType, buf[1:])
+	require.NoError(t, err)
+	require.True(t, proto.Equal(peeringReq, decoded.(proto.Message)))
+
+	_, err = DecodeCommand(structs.MessageType(127), buf[1:])
+	require.EqualError(t, err, "unknown message type Unknown(127)")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-raftchunking"
 	"github.com/hashicorp/raft"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/hashicorp/consul-net-rpc/go-msgpack/codec"
 
@@ -40,6 +41,27 @@ func registerCommand(msg structs.MessageType, fn unboundCommand) {
 	commands[msg] = fn
 }
 
+// DecodeCommand decodes the payload of a Raft log entry into the request its
+// command applies, so tools can inspect the log without applying it. The
+// buffer must not include the leading message type byte.
+func DecodeCommand(msg structs.MessageType, buf []byte) (interface{}, error) {
+	newPayload, ok := commandPayloads[msg]
+	if !ok {
+		return nil, fmt.Errorf("unknown message type %s", msg)
+	}
+	payload := newPayload()
+	if pb, ok := payload.(proto.Message); ok {
+		if err := structs.DecodeProto(buf, pb); err != nil {
+			return nil, err
+		}
+		return pb, nil
+	}
+	if err := structs.Decode(buf, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
 // FSM implements a finite state machine that is used
 // along with Raft to provide strong consistency. We implement
 // this outside the Server to avoid exposing this outside the package.
@@ -83,6 +105,9 @@ type Deps struct {
 	NewStateStore func() *state.Store
 
 	Publisher *stream.EventPublisher
+
+	// ObserveApply is called with the time taken to apply each log, if set.
+	ObserveApply func(time.Duration)
 }
 
 // NewFromDeps creates a new FSM from its dependencies.
@@ -128,6 +153,12 @@ func (c *FSM) State() *state.Store {
 }
 
 func (c *FSM) Apply(log *raft.Log) interface{} {
+	if c.deps.ObserveApply != nil {
+		defer func(start time.Time) {
+			c.deps.ObserveApply(time.Since(start))
+		}(time.Now())
+	}
+
 	buf := log.Data
 	msgType := structs.MessageType(buf[0])
 
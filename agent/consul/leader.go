@@ -118,6 +118,9 @@ func (s *Server) monitorLeadership() {
 
 func (s *Server) leadershipTransfer() error {
 	retryCount := 3
+	resume := s.leaderLease.suspend()
+	defer resume()
+
 	for i := 0; i < retryCount; i++ {
 		future := s.raft.LeadershipTransfer()
 		if err := future.Error(); err != nil {
@@ -372,6 +375,7 @@ func (s *Server) revokeLeadership() {
 	s.stopACLTokenReaping()
 
 	s.resetConsistentReadReady()
+	s.leaderLease.revoke()
 
 	s.autopilot.DisableReconciliation()
 }
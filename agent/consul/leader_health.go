@@ -0,0 +1,78 @@
+package consul
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/consul/agent/consul/rate"
+)
+
+// leaderHealth measures the health signals of the leader the adaptive write
+// limit reacts to: the time taken to commit and apply the writes, the number
+// of writes waiting to be committed, and the time taken to apply them to the
+// FSM.
+type leaderHealth struct {
+	// applying is the number of raft applies in progress.
+	applying atomic.Int64
+
+	commitTime   latencyWindow
+	fsmApplyTime latencyWindow
+}
+
+// startApply records the start of a raft apply, the returned function must be
+// called with its result once it is done.
+func (h *leaderHealth) startApply() func(err error) {
+	start := time.Now()
+	h.applying.Add(1)
+	return func(err error) {
+		h.applying.Add(-1)
+		if err == nil {
+			h.commitTime.observe(time.Since(start))
+		}
+	}
+}
+
+// signals returns the health signals measured since it was last called.
+func (h *leaderHealth) signals() rate.HealthSignals {
+	return rate.HealthSignals{
+		CommitTime:      h.commitTime.reset(),
+		ApplyQueueDepth: int(h.applying.Load()),
+		FSMApplyTime:    h.fsmApplyTime.reset(),
+	}
+}
+
+// latencyWindow computes the mean of the durations observed since it was last
+// reset.
+type latencyWindow struct {
+	lock  sync.Mutex
+	total time.Duration
+	count int
+}
+
+func (w *latencyWindow) observe(d time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.total += d
+	w.count++
+}
+
+// reset returns the mean of the durations observed since the last reset, or
+// zero if none was observed.
+func (w *latencyWindow) reset() time.Duration {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var mean time.Duration
+	if w.count > 0 {
+		mean = w.total / time.Duration(w.count)
+	}
+	w.total, w.count = 0, 0
+	return mean
+}
+
+// LeaderHealthSignals returns the health signals of the leader measured since
+// it was last called. It is called periodically by the incoming RPC rate
+// limiter to adjust its adaptive write limit.
+func (s *Server) LeaderHealthSignals() rate.HealthSignals {
+	return s.leaderHealth.signals()
+}
@@ -0,0 +1,66 @@
+package consul
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/consul/rate"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestLeaderHealth(t *testing.T) {
+	var health leaderHealth
+	require.Equal(t, rate.HealthSignals{}, health.signals())
+
+	done1 := health.startApply()
+	done2 := health.startApply()
+	health.fsmApplyTime.observe(10 * time.Millisecond)
+	health.fsmApplyTime.observe(30 * time.Millisecond)
+
+	signals := health.signals()
+	require.Equal(t, 2, signals.ApplyQueueDepth)
+	require.Equal(t, time.Duration(0), signals.CommitTime)
+	require.Equal(t, 20*time.Millisecond, signals.FSMApplyTime)
+
+	// The failed applies are not included in the commit time.
+	done1(nil)
+	done2(errors.New("failed"))
+
+	signals = health.signals()
+	require.Equal(t, 0, signals.ApplyQueueDepth)
+	require.Greater(t, signals.CommitTime, time.Duration(0))
+	require.Equal(t, time.Duration(0), signals.FSMApplyTime)
+
+	// The latencies are reset each time the signals are read.
+	require.Equal(t, rate.HealthSignals{}, health.signals())
+}
+
+func TestServer_LeaderHealthSignals(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	_, s := testServer(t)
+	testrpc.WaitForLeader(t, s.RPC, "dc1")
+
+	// Discard the signals measured while the server was starting.
+	s.LeaderHealthSignals()
+
+	_, err := s.raftApply(structs.KVSRequestType, &structs.KVSRequest{
+		Datacenter: "dc1",
+		Op:         api.KVSet,
+		DirEnt:     structs.DirEntry{Key: "foo", Value: []byte("bar")},
+	})
+	require.NoError(t, err)
+
+	signals := s.LeaderHealthSignals()
+	require.Greater(t, signals.CommitTime, time.Duration(0))
+	require.Greater(t, signals.FSMApplyTime, time.Duration(0))
+	require.Equal(t, 0, signals.ApplyQueueDepth)
+}
@@ -0,0 +1,84 @@
+package consul
+
+import (
+	"sync"
+	"time"
+)
+
+// leaderLease tracks how long the leader can serve consistent reads without
+// verifying its leadership with a quorum of servers. The lease is renewed
+// with the start time of a successful leadership verification: the followers
+// which acknowledged the leader won't elect another leader before their
+// heartbeat timeout expires, as long as their clocks don't drift apart by
+// more than the configured bound.
+//
+// Leadership transfers bypass the heartbeat timeout of the followers, the
+// lease is suspended while a transfer is in progress.
+type leaderLease struct {
+	lock sync.Mutex
+
+	// expires is when the lease expires, it uses the monotonic clock.
+	expires time.Time
+
+	// generation is increased each time the lease is revoked, the
+	// verifications started before aren't allowed to renew it.
+	generation uint64
+
+	// suspended is the number of leadership transfers in progress.
+	suspended int
+}
+
+// valid returns whether the lease is valid at the given time.
+func (l *leaderLease) valid(now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.suspended == 0 && now.Before(l.expires)
+}
+
+// current returns the generation of the lease, it must be read before the
+// leadership is verified.
+func (l *leaderLease) current() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.generation
+}
+
+// renew extends the lease until the given time, unless it was revoked since
+// the generation was read.
+func (l *leaderLease) renew(generation uint64, expires time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.generation != generation || l.suspended > 0 {
+		return
+	}
+	if expires.After(l.expires) {
+		l.expires = expires
+	}
+}
+
+// revoke invalidates the lease.
+func (l *leaderLease) revoke() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.generation++
+	l.expires = time.Time{}
+}
+
+// suspend revokes the lease and prevents it from being renewed until the
+// returned function is called.
+func (l *leaderLease) suspend() func() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.generation++
+	l.expires = time.Time{}
+	l.suspended++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.lock.Lock()
+			defer l.lock.Unlock()
+			l.suspended--
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package consul
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderLease(t *testing.T) {
+	var lease leaderLease
+	now := time.Now()
+
+	// The lease isn't valid until it is renewed.
+	require.False(t, lease.valid(now))
+
+	generation := lease.current()
+	lease.renew(generation, now.Add(time.Second))
+	require.True(t, lease.valid(now))
+	require.False(t, lease.valid(now.Add(time.Second)))
+
+	// An older verification doesn't shorten the lease.
+	lease.renew(generation, now.Add(time.Millisecond))
+	require.True(t, lease.valid(now.Add(500*time.Millisecond)))
+
+	// The verifications started before the lease was revoked don't renew it.
+	lease.revoke()
+	require.False(t, lease.valid(now))
+	lease.renew(generation, now.Add(time.Second))
+	require.False(t, lease.valid(now))
+
+	generation = lease.current()
+	lease.renew(generation, now.Add(time.Second))
+	require.True(t, lease.valid(now))
+}
+
+func TestLeaderLease_Suspend(t *testing.T) {
+	var lease leaderLease
+	now := time.Now()
+
+	lease.renew(lease.current(), now.Add(time.Second))
+	require.True(t, lease.valid(now))
+
+	resume1 := lease.suspend()
+	resume2 := lease.suspend()
+	require.False(t, lease.valid(now))
+
+	// The lease isn't renewed while a leadership transfer is in progress.
+	lease.renew(lease.current(), now.Add(time.Second))
+	require.False(t, lease.valid(now))
+
+	// Resuming twice has no effect.
+	resume1()
+	resume1()
+	lease.renew(lease.current(), now.Add(time.Second))
+	require.False(t, lease.valid(now))
+
+	resume2()
+	lease.renew(lease.current(), now.Add(time.Second))
+	require.True(t, lease.valid(now))
+}
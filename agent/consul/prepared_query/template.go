@@ -13,6 +13,16 @@ import (
 	"github.com/hashicorp/consul/agent/structs"
 )
 
+// filterPath is the path of the filter expression in the Service
+// sub-structure. The values interpolated into it come from the name being
+// looked up, so they are escaped to keep them from changing the expression.
+const filterPath = ".Filter"
+
+// filterValueEscaper escapes a value interpolated into a double-quoted string
+// of a filter expression. The filter grammar ends a string at the first
+// double quote, even an escaped one, so it's written as a hex escape instead.
+var filterValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\x22`)
+
 // IsTemplate returns true if the given query is a template.
 func IsTemplate(query *structs.PreparedQuery) bool {
 	return query.Template.Type != ""
@@ -68,6 +78,11 @@ func Compile(query *structs.PreparedQuery) (*CompiledTemplate, error) {
 		if err != nil {
 			return fmt.Errorf("Bad format '%s' in Service%s: %s", v.String(), path, err)
 		}
+		if path == filterPath {
+			if err := validateFilterInterpolations(tree); err != nil {
+				return fmt.Errorf("Bad format '%s' in Service%s: %s", v.String(), path, err)
+			}
+		}
 
 		ct.trees[path] = tree
 		return nil
@@ -90,7 +105,14 @@ func Compile(query *structs.PreparedQuery) (*CompiledTemplate, error) {
 	// prefix it will be expected to run with. The results might not make
 	// sense and create a valid service to lookup, but it should render
 	// without any errors.
-	if _, err = ct.Render(ct.query.Name, structs.QuerySource{}); err != nil {
+	rendered, err := ct.Render(ct.query.Name, structs.QuerySource{})
+	if err != nil {
+		return nil, err
+	}
+
+	// The filter expression can only be checked once it's been rendered,
+	// since the interpolations aren't valid filter syntax.
+	if err := rendered.Service.ValidateFilter(); err != nil {
 		return nil, err
 	}
 
@@ -159,7 +181,11 @@ func (ct *CompiledTemplate) Render(name string, source structs.QuerySource) (*st
 				},
 			},
 			FuncMap: map[string]ast.Function{
-				"match": match,
+				"match":   match,
+				"lower":   stringFunc(strings.ToLower),
+				"upper":   stringFunc(strings.ToUpper),
+				"split":   splitFunc,
+				"default": defaultFunc,
 			},
 		},
 	}
@@ -172,7 +198,12 @@ func (ct *CompiledTemplate) Render(name string, source structs.QuerySource) (*st
 			return nil
 		}
 
-		res, err := hil.Eval(tree, config)
+		var res hil.EvaluationResult
+		if path == filterPath {
+			res, err = evalFilter(tree, config)
+		} else {
+			res, err = hil.Eval(tree, config)
+		}
 		if err != nil {
 			return fmt.Errorf("Bad evaluation for '%s' in Service%s: %s", v.String(), path, err)
 		}
@@ -199,3 +230,106 @@ func (ct *CompiledTemplate) Render(name string, source structs.QuerySource) (*st
 
 	return query, nil
 }
+
+// validateFilterInterpolations makes sure all the interpolations in a filter
+// expression are inside double-quoted strings, which is the only place their
+// values can be escaped.
+func validateFilterInterpolations(tree ast.Node) error {
+	out, ok := tree.(*ast.Output)
+	if !ok {
+		return nil
+	}
+
+	var quote rune
+	for _, expr := range out.Exprs {
+		lit, ok := expr.(*ast.LiteralNode)
+		if !ok {
+			if quote != '"' {
+				return fmt.Errorf("interpolations must be inside a double-quoted string")
+			}
+			continue
+		}
+		text, ok := lit.Value.(string)
+		if !ok {
+			continue
+		}
+		for _, r := range text {
+			switch {
+			case quote == 0 && (r == '"' || r == '`'):
+				quote = r
+			case quote == r:
+				quote = 0
+			}
+		}
+	}
+	return nil
+}
+
+// evalFilter evaluates a filter expression, escaping the result of each
+// interpolation so the values can't close the string they're part of.
+func evalFilter(tree ast.Node, config *hil.EvalConfig) (hil.EvaluationResult, error) {
+	out, ok := tree.(*ast.Output)
+	if !ok {
+		return hil.Eval(tree, config)
+	}
+
+	var b strings.Builder
+	for _, expr := range out.Exprs {
+		res, err := hil.Eval(&ast.Output{Exprs: []ast.Node{expr}, Posx: expr.Pos()}, config)
+		if err != nil {
+			return hil.EvaluationResult{}, err
+		}
+		value, ok := res.Value.(string)
+		if !ok {
+			return hil.EvaluationResult{}, fmt.Errorf("expected a string, got %s", res.Type)
+		}
+		if _, ok := expr.(*ast.LiteralNode); !ok {
+			value = filterValueEscaper.Replace(value)
+		}
+		b.WriteString(value)
+	}
+	return hil.EvaluationResult{Type: hil.TypeString, Value: b.String()}, nil
+}
+
+// stringFunc wraps a simple string transformation as a HIL function.
+func stringFunc(f func(string) string) ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString},
+		ReturnType: ast.TypeString,
+		Variadic:   false,
+		Callback: func(inputs []interface{}) (interface{}, error) {
+			return f(inputs[0].(string)), nil
+		},
+	}
+}
+
+// splitFunc splits a string by a separator and returns the element at the
+// given index. Like match, it can't fail at run time and will return an empty
+// string for an index that's out of range.
+var splitFunc = ast.Function{
+	ArgTypes:   []ast.Type{ast.TypeString, ast.TypeString, ast.TypeInt},
+	ReturnType: ast.TypeString,
+	Variadic:   false,
+	Callback: func(inputs []interface{}) (interface{}, error) {
+		sep, s, i := inputs[0].(string), inputs[1].(string), inputs[2].(int)
+		parts := strings.Split(s, sep)
+		if i >= 0 && i < len(parts) {
+			return parts[i], nil
+		}
+		return "", nil
+	},
+}
+
+// defaultFunc returns its first argument, or the second one if the first is
+// an empty string.
+var defaultFunc = ast.Function{
+	ArgTypes:   []ast.Type{ast.TypeString, ast.TypeString},
+	ReturnType: ast.TypeString,
+	Variadic:   false,
+	Callback: func(inputs []interface{}) (interface{}, error) {
+		if s := inputs[0].(string); s != "" {
+			return s, nil
+		}
+		return inputs[1].(string), nil
+	},
+}
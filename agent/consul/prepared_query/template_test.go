@@ -5,9 +5,12 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/go-bexpr"
+	"github.com/mitchellh/copystructure"
+	"github.com/stretchr/testify/require"
+
 	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/types"
-	"github.com/mitchellh/copystructure"
 )
 
 var (
@@ -202,6 +205,28 @@ func TestTemplate_Compile(t *testing.T) {
 	if err == nil || !strings.Contains(err.Error(), "Bad Regexp") {
 		t.Fatalf("bad: %v", err)
 	}
+
+	// Try a filter that doesn't render to a valid expression.
+	query.Template.Regexp = "^(hello)there$"
+	query.Service.Filter = "Service.Meta.env == \"${match(1)}\" or ("
+	_, err = Compile(query)
+	if err == nil || !strings.Contains(err.Error(), "Bad Filter") {
+		t.Fatalf("bad: %v", err)
+	}
+
+	// Try a filter with an interpolation outside of a quoted string.
+	query.Service.Filter = "Service.Meta.env == ${match(1)}"
+	_, err = Compile(query)
+	if err == nil || !strings.Contains(err.Error(), "must be inside a double-quoted string") {
+		t.Fatalf("bad: %v", err)
+	}
+
+	// Try a filter with an interpolation inside a raw string.
+	query.Service.Filter = "Service.Meta.env == `${match(1)}`"
+	_, err = Compile(query)
+	if err == nil || !strings.Contains(err.Error(), "must be inside a double-quoted string") {
+		t.Fatalf("bad: %v", err)
+	}
 }
 
 func TestTemplate_Render(t *testing.T) {
@@ -397,3 +422,144 @@ func TestTemplate_Render(t *testing.T) {
 		}
 	}
 }
+
+func TestTemplate_Render_Functions(t *testing.T) {
+	query := &structs.PreparedQuery{
+		Name: "",
+		Template: structs.QueryTemplateOptions{
+			Type:            structs.QueryTemplateTypeNamePrefixMatch,
+			Regexp:          "^(.*?)-(.*?)(?:-(.*))?$",
+			RemoveEmptyTags: true,
+		},
+		Service: structs.ServiceQuery{
+			Service: "${lower(match(1))}",
+			Failover: structs.QueryFailoverOptions{
+				Targets: []structs.QueryFailoverTarget{
+					{Datacenter: "${lower(default(match(3), \"us-east\"))}-2"},
+					{Peer: "${split(\".\", agent.segment, 1)}"},
+				},
+			},
+			Tags: []string{
+				"${upper(match(2))}",
+				"${split(\"-\", name.full, 5)}",
+			},
+			NodeMeta: map[string]string{
+				"region": "${default(match(3), \"us-east\")}",
+			},
+			ServiceMeta: map[string]string{
+				"env": "${lower(match(2))}",
+			},
+			Filter: `Service.Meta.env == "${lower(match(2))}" and Node.Meta.region != ""`,
+		},
+	}
+	ct, err := Compile(query)
+	require.NoError(t, err)
+
+	// Run a case with all the parts present.
+	actual, err := ct.Render("Web-Prod-EU-West", structs.QuerySource{Segment: "seg.alpha"})
+	require.NoError(t, err)
+	require.Equal(t, structs.ServiceQuery{
+		Service: "web",
+		Failover: structs.QueryFailoverOptions{
+			Targets: []structs.QueryFailoverTarget{
+				{Datacenter: "eu-west-2"},
+				{Peer: "alpha"},
+			},
+		},
+		Tags: []string{
+			"PROD",
+		},
+		NodeMeta: map[string]string{
+			"region": "EU-West",
+		},
+		ServiceMeta: map[string]string{
+			"env": "prod",
+		},
+		Filter: `Service.Meta.env == "prod" and Node.Meta.region != ""`,
+	}, actual.Service)
+
+	// Run a case that falls back to the defaults.
+	actual, err = ct.Render("db-staging", structs.QuerySource{})
+	require.NoError(t, err)
+	require.Equal(t, structs.ServiceQuery{
+		Service: "db",
+		Failover: structs.QueryFailoverOptions{
+			Targets: []structs.QueryFailoverTarget{
+				{Datacenter: "us-east-2"},
+				{Peer: ""},
+			},
+		},
+		Tags: []string{
+			"STAGING",
+		},
+		NodeMeta: map[string]string{
+			"region": "us-east",
+		},
+		ServiceMeta: map[string]string{
+			"env": "staging",
+		},
+		Filter: `Service.Meta.env == "staging" and Node.Meta.region != ""`,
+	}, actual.Service)
+}
+
+func TestTemplate_Render_FilterEscaping(t *testing.T) {
+	query := &structs.PreparedQuery{
+		Name: "svc-",
+		Template: structs.QueryTemplateOptions{
+			Type:   structs.QueryTemplateTypeNamePrefixMatch,
+			Regexp: "^svc-(.*)$",
+		},
+		Service: structs.ServiceQuery{
+			Service: "svc",
+			Filter:  `Service.Meta.env == "${match(1)}" and Node.Meta.region != "${name.prefix}"`,
+		},
+	}
+	ct, err := Compile(query)
+	require.NoError(t, err)
+
+	nodes := structs.CheckServiceNodes{
+		{
+			Node:    &structs.Node{Node: "foo", Meta: map[string]string{"region": "eu"}},
+			Service: &structs.NodeService{Service: "svc", Meta: map[string]string{"env": "prod"}},
+		},
+		{
+			Node:    &structs.Node{Node: "bar", Meta: map[string]string{"region": "eu"}},
+			Service: &structs.NodeService{Service: "svc", Meta: map[string]string{"env": `x" or Service.Meta.env != "`}},
+		},
+	}
+	execute := func(t *testing.T, name string) structs.CheckServiceNodes {
+		t.Helper()
+		rendered, err := ct.Render(name, structs.QuerySource{})
+		require.NoError(t, err)
+		require.NoError(t, rendered.Service.ValidateFilter())
+
+		filter, err := bexpr.CreateFilter(rendered.Service.Filter, nil, nodes)
+		require.NoError(t, err)
+		raw, err := filter.Execute(nodes)
+		require.NoError(t, err)
+		return raw.(structs.CheckServiceNodes)
+	}
+
+	t.Run("plain value", func(t *testing.T) {
+		actual := execute(t, "svc-prod")
+		require.Len(t, actual, 1)
+		require.Equal(t, "foo", actual[0].Node.Node)
+	})
+
+	t.Run("injected quote", func(t *testing.T) {
+		name := `svc-x" or Service.Meta.env != "`
+		rendered, err := ct.Render(name, structs.QuerySource{})
+		require.NoError(t, err)
+		require.Equal(t, `Service.Meta.env == "x\x22 or Service.Meta.env != \x22" and Node.Meta.region != "svc-"`, rendered.Service.Filter)
+
+		// The whole value is compared, so only the instance with that literal
+		// value matches instead of every instance.
+		actual := execute(t, name)
+		require.Len(t, actual, 1)
+		require.Equal(t, "bar", actual[0].Node.Node)
+	})
+
+	t.Run("injected backslash", func(t *testing.T) {
+		require.Empty(t, execute(t, `svc-x\`))
+	})
+}
@@ -43,6 +43,7 @@ func TestWalk_ServiceQuery(t *testing.T) {
 		".Tags[1]:tag2",
 		".Tags[2]:tag3",
 		".Peer:",
+		".Filter:",
 	}
 	expected = append(expected, entMetaWalkFields...)
 	sort.Strings(expected)
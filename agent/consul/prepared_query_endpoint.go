@@ -7,6 +7,7 @@ import (
 
 	"github.com/armon/go-metrics"
 	"github.com/armon/go-metrics/prometheus"
+	"github.com/hashicorp/go-bexpr"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-memdb"
 	"github.com/hashicorp/go-uuid"
@@ -174,6 +175,14 @@ func parseQuery(query *structs.PreparedQuery) error {
 		return err
 	}
 
+	// Templates may interpolate into the filter expression, so those are
+	// checked after rendering when the template is compiled.
+	if query.Template.Type == "" {
+		if err := query.Service.ValidateFilter(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -197,6 +206,15 @@ func parseService(svc *structs.ServiceQuery) error {
 		return fmt.Errorf("Targets cannot be populated with NearestN or Datacenters")
 	}
 
+	// MinHealthy and MinHealthyPercent can be 0 which means "only fail over
+	// when there are no healthy nodes".
+	if failover.MinHealthy < 0 {
+		return fmt.Errorf("Bad MinHealthy '%d', must be >= 0", failover.MinHealthy)
+	}
+	if failover.MinHealthyPercent < 0 || failover.MinHealthyPercent > 100 {
+		return fmt.Errorf("Bad MinHealthyPercent '%d', must be between 0 and 100", failover.MinHealthyPercent)
+	}
+
 	// Make sure the metadata filters are valid
 	if err := structs.ValidateNodeMetadata(svc.NodeMeta, true); err != nil {
 		return err
@@ -207,6 +225,7 @@ func parseService(svc *structs.ServiceQuery) error {
 	//   at execution time.
 	// - OnlyPassing is just a boolean so doesn't need further validation.
 	// - Tags is a free-form list of tags and doesn't need further validation.
+	// - Filter is checked by parseQuery, since templates need rendering first.
 
 	return nil
 }
@@ -369,7 +388,8 @@ func (p *PreparedQuery) Execute(args *structs.PreparedQueryExecuteRequest,
 	}
 
 	// Execute the query for the local DC.
-	if err := p.execute(query, reply, args.Connect); err != nil {
+	total, err := p.execute(query, reply, args.Connect)
+	if err != nil {
 		return err
 	}
 
@@ -458,6 +478,19 @@ func (p *PreparedQuery) Execute(args *structs.PreparedQueryExecuteRequest,
 		}
 	}
 
+	wrapper := &queryServerWrapper{srv: p.srv, executeRemote: p.ExecuteRemote}
+
+	// If a failover health threshold is set we merge the results of the
+	// failover targets with the local ones until the threshold is met. This
+	// is checked before the limit is applied, since the limit has nothing to
+	// do with the health of the service.
+	threshold := query.Service.Failover.HealthyThreshold(total)
+	if threshold > 0 {
+		if err := queryFailoverMerge(wrapper, query, args, reply, threshold); err != nil {
+			return err
+		}
+	}
+
 	// Apply the limit if given.
 	if args.Limit > 0 && len(reply.Nodes) > args.Limit {
 		reply.Nodes = reply.Nodes[:args.Limit]
@@ -466,8 +499,7 @@ func (p *PreparedQuery) Execute(args *structs.PreparedQueryExecuteRequest,
 	// In the happy path where we found some healthy nodes we go with that
 	// and bail out. Otherwise, we fail over and try remote DCs, as allowed
 	// by the query setup.
-	if len(reply.Nodes) == 0 {
-		wrapper := &queryServerWrapper{srv: p.srv, executeRemote: p.ExecuteRemote}
+	if len(reply.Nodes) == 0 && threshold == 0 {
 		if err := queryFailover(wrapper, query, args, reply); err != nil {
 			return err
 		}
@@ -496,7 +528,7 @@ func (p *PreparedQuery) ExecuteRemote(args *structs.PreparedQueryExecuteRemoteRe
 	}
 
 	// Run the query locally to see what we can find.
-	if err := p.execute(&args.Query, reply, args.Connect); err != nil {
+	if _, err := p.execute(&args.Query, reply, args.Connect); err != nil {
 		return err
 	}
 
@@ -528,9 +560,11 @@ func (p *PreparedQuery) ExecuteRemote(args *structs.PreparedQueryExecuteRemoteRe
 
 // execute runs a prepared query in the local DC without any failover. We don't
 // apply any sorting options or ACL checks at this level - it should be done up above.
+// This returns the total number of nodes matching the query, including the
+// unhealthy ones that were filtered out of the reply.
 func (p *PreparedQuery) execute(query *structs.PreparedQuery,
 	reply *structs.PreparedQueryExecuteResponse,
-	forceConnect bool) error {
+	forceConnect bool) (int, error) {
 	state := p.srv.fsm.State()
 
 	// If we're requesting Connect-capable services, then switch the
@@ -542,13 +576,9 @@ func (p *PreparedQuery) execute(query *structs.PreparedQuery,
 
 	_, nodes, err := f(nil, query.Service.Service, &query.Service.EnterpriseMeta, query.Service.Peer)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	// Filter out any unhealthy nodes.
-	nodes = nodes.FilterIgnore(query.Service.OnlyPassing,
-		query.Service.IgnoreCheckIDs)
-
 	// Apply the node metadata filters, if any.
 	if len(query.Service.NodeMeta) > 0 {
 		nodes = nodeMetaFilter(query.Service.NodeMeta, nodes)
@@ -564,6 +594,25 @@ func (p *PreparedQuery) execute(query *structs.PreparedQuery,
 		nodes = tagFilter(query.Service.Tags, nodes)
 	}
 
+	// Apply the filter expression, if any.
+	if query.Service.Filter != "" {
+		filter, err := bexpr.CreateFilter(query.Service.Filter, nil, nodes)
+		if err != nil {
+			return 0, err
+		}
+		raw, err := filter.Execute(nodes)
+		if err != nil {
+			return 0, err
+		}
+		nodes = raw.(structs.CheckServiceNodes)
+	}
+
+	// Filter out any unhealthy nodes, keeping track of how many matched the
+	// query before that.
+	total := len(nodes)
+	nodes = nodes.FilterIgnore(query.Service.OnlyPassing,
+		query.Service.IgnoreCheckIDs)
+
 	// Capture the nodes and pass the DNS information through to the reply.
 	reply.Service = query.Service.Service
 	reply.EnterpriseMeta = query.Service.EnterpriseMeta
@@ -578,7 +627,7 @@ func (p *PreparedQuery) execute(query *structs.PreparedQuery,
 		reply.Datacenter = p.srv.config.Datacenter
 	}
 
-	return nil
+	return total, nil
 }
 
 // tagFilter returns a list of nodes who satisfy the given tags. Nodes must have
@@ -705,17 +754,14 @@ func (q *queryServerWrapper) GetOtherDatacentersByDistance() ([]string, error) {
 	return result, nil
 }
 
-// queryFailover runs an algorithm to determine which DCs to try and then calls
-// them to try to locate alternative services.
-func queryFailover(q queryServer, query *structs.PreparedQuery,
-	args *structs.PreparedQueryExecuteRequest,
-	reply *structs.PreparedQueryExecuteResponse) error {
-
+// failoverTargets runs an algorithm to determine which DCs and peers to try,
+// in priority order.
+func failoverTargets(q queryServer, query *structs.PreparedQuery) ([]structs.QueryFailoverTarget, error) {
 	// Pull the list of other DCs. This is sorted by RTT in case the user
 	// has selected that.
 	nearest, err := q.GetOtherDatacentersByDistance()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// This will help us filter unknown DCs supplied by the user.
@@ -761,6 +807,20 @@ func queryFailover(q queryServer, query *structs.PreparedQuery,
 		}
 	}
 
+	return targets, nil
+}
+
+// queryFailover determines which DCs to try and then calls them to try to
+// locate alternative services.
+func queryFailover(q queryServer, query *structs.PreparedQuery,
+	args *structs.PreparedQueryExecuteRequest,
+	reply *structs.PreparedQueryExecuteResponse) error {
+
+	targets, err := failoverTargets(q, query)
+	if err != nil {
+		return err
+	}
+
 	// Now try the selected DCs in priority order.
 	failovers := 0
 	for _, target := range targets {
@@ -817,3 +877,94 @@ func queryFailover(q queryServer, query *structs.PreparedQuery,
 
 	return nil
 }
+
+// queryFailoverMerge is used instead of queryFailover when the query has a
+// failover health threshold. If the local results are below the threshold,
+// the results of the failover targets are appended to them in priority order
+// until the threshold is met or we run out of targets. The sources that
+// contributed nodes are recorded in the reply.
+func queryFailoverMerge(q queryServer, query *structs.PreparedQuery,
+	args *structs.PreparedQueryExecuteRequest,
+	reply *structs.PreparedQueryExecuteResponse,
+	threshold int) error {
+
+	var sources []structs.QueryResultSource
+	if len(reply.Nodes) > 0 {
+		sources = append(sources, structs.QueryResultSource{
+			Datacenter: reply.Datacenter,
+			PeerName:   reply.PeerName,
+			Nodes:      len(reply.Nodes),
+		})
+	}
+
+	// Setting this at the end makes sure it's set on every return path
+	// below.
+	defer func() { reply.Sources = sources }()
+
+	if len(reply.Nodes) >= threshold {
+		return nil
+	}
+
+	targets, err := failoverTargets(q, query)
+	if err != nil {
+		return err
+	}
+
+	failovers := 0
+	for _, target := range targets {
+		if len(reply.Nodes) >= threshold {
+			break
+		}
+
+		// This keeps track of how many iterations we actually run.
+		failovers++
+
+		// Work on a copy of the query so the peer of one target doesn't
+		// leak into the next one.
+		remoteQuery := *query
+		remoteQuery.Service.Peer = target.Peer
+		dc := target.Datacenter
+		if target.Peer != "" {
+			dc = q.GetLocalDC()
+		}
+
+		// Unlike queryFailover we use a fresh reply for every target, since
+		// the results are merged rather than replaced.
+		remote := &structs.PreparedQueryExecuteRemoteRequest{
+			Datacenter:   dc,
+			Query:        remoteQuery,
+			Limit:        args.Limit,
+			QueryOptions: args.QueryOptions,
+			Connect:      args.Connect,
+		}
+		var remoteReply structs.PreparedQueryExecuteResponse
+		if err := q.ExecuteRemote(remote, &remoteReply); err != nil {
+			q.GetLogger().Warn("Failed querying for service in datacenter",
+				"service", query.Service.Service,
+				"peerName", target.Peer,
+				"datacenter", dc,
+				"error", err,
+			)
+			continue
+		}
+		if len(remoteReply.Nodes) == 0 {
+			continue
+		}
+
+		// If there were no local results, the reply is stamped with the
+		// first source that contributed, like it is without a threshold.
+		if len(reply.Nodes) == 0 {
+			reply.Datacenter = remoteReply.Datacenter
+			reply.PeerName = remoteReply.PeerName
+		}
+		reply.Nodes = append(reply.Nodes, remoteReply.Nodes...)
+		sources = append(sources, structs.QueryResultSource{
+			Datacenter: remoteReply.Datacenter,
+			PeerName:   remoteReply.PeerName,
+			Nodes:      len(remoteReply.Nodes),
+		})
+	}
+
+	reply.Failovers = failovers
+	return nil
+}
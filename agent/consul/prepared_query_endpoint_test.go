@@ -616,6 +616,24 @@ func TestPreparedQuery_parseQuery(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
+	query.Service.Failover.MinHealthy = -1
+	err = parseQuery(query)
+	if err == nil || !strings.Contains(err.Error(), "Bad MinHealthy") {
+		t.Fatalf("bad: %v", err)
+	}
+
+	query.Service.Failover.MinHealthy = 2
+	query.Service.Failover.MinHealthyPercent = 101
+	err = parseQuery(query)
+	if err == nil || !strings.Contains(err.Error(), "Bad MinHealthyPercent") {
+		t.Fatalf("bad: %v", err)
+	}
+
+	query.Service.Failover.MinHealthyPercent = 50
+	if err := parseQuery(query); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
 	query.DNS.TTL = "two fortnights"
 	err = parseQuery(query)
 	if err == nil || !strings.Contains(err.Error(), "Bad DNS TTL") {
@@ -643,6 +661,24 @@ func TestPreparedQuery_parseQuery(t *testing.T) {
 	if err := parseQuery(query); err != nil {
 		t.Fatalf("err: %v", err)
 	}
+
+	query.Service.Filter = "Service.Meta.env =="
+	err = parseQuery(query)
+	if err == nil || !strings.Contains(err.Error(), "Bad Filter") {
+		t.Fatalf("bad: %v", err)
+	}
+
+	query.Service.Filter = "Service.Meta.env == prod"
+	if err := parseQuery(query); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Templates get their filters checked when they are compiled.
+	query.Template.Type = structs.QueryTemplateTypeNamePrefixMatch
+	query.Service.Filter = "Service.Meta.env == ${match(1)}"
+	if err := parseQuery(query); err != nil {
+		t.Fatalf("err: %v", err)
+	}
 }
 
 func TestPreparedQuery_ACLDeny_Catchall_Template(t *testing.T) {
@@ -1851,6 +1887,57 @@ func TestPreparedQuery_Execute(t *testing.T) {
 		}))
 	}
 
+	for name, tc := range map[string]struct {
+		filter   string
+		numNodes int
+	}{
+		"no filter 10 nodes": {
+			filter:   "",
+			numNodes: 10,
+		},
+		"group 0 - 5 nodes": {
+			filter:   `Service.Meta["svc-group"] == "0"`,
+			numNodes: 5,
+		},
+		"group 1 or unique - 6 nodes": {
+			filter:   `Service.Meta["svc-group"] == "1" or Service.Meta.unique == "true"`,
+			numNodes: 6,
+		},
+		"no match - 0 nodes": {
+			filter:   `Service.Meta.foo != "true"`,
+			numNodes: 0,
+		},
+	} {
+		tc := tc
+		require.True(t, t.Run("filter - "+name, func(t *testing.T) {
+			session := newSessionDC1(t)
+			filterQuery := structs.PreparedQueryRequest{
+				Datacenter: "dc1",
+				Op:         structs.PreparedQueryCreate,
+				Query: &structs.PreparedQuery{
+					Session: session,
+					Service: structs.ServiceQuery{
+						Service: "foo",
+						Filter:  tc.filter,
+					},
+				},
+				WriteRequest: structs.WriteRequest{Token: "root"},
+			}
+
+			require.NoError(t, msgpackrpc.CallWithCodec(codec1, "PreparedQuery.Apply", &filterQuery, &filterQuery.Query.ID))
+
+			req := structs.PreparedQueryExecuteRequest{
+				Datacenter:    "dc1",
+				QueryIDOrName: filterQuery.Query.ID,
+				QueryOptions:  structs.QueryOptions{Token: execToken},
+			}
+
+			var reply structs.PreparedQueryExecuteResponse
+			require.NoError(t, msgpackrpc.CallWithCodec(codec1, "PreparedQuery.Execute", &req, &reply))
+			assert.Len(t, reply.Nodes, tc.numNodes)
+		}))
+	}
+
 	// Push a coordinate for one of the nodes so we can try an RTT sort. We
 	// have to sleep a little while for the coordinate batch to get flushed.
 	{
@@ -2784,6 +2871,76 @@ func TestPreparedQuery_Execute_ConnectExact(t *testing.T) {
 		codec, "PreparedQuery.Apply", &query, &query.Query.ID))
 }
 
+func TestPreparedQuery_Execute_HealthThreshold(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	dir1, s1 := testServer(t)
+	defer os.RemoveAll(dir1)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	defer codec.Close()
+
+	testrpc.WaitForLeader(t, s1.RPC, "dc1")
+
+	// Set up 4 instances, only one of which is healthy.
+	for i := 0; i < 4; i++ {
+		req := structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       fmt.Sprintf("node%d", i+1),
+			Address:    fmt.Sprintf("127.0.0.%d", i+1),
+			Service: &structs.NodeService{
+				Service: "foo",
+				Port:    8000,
+			},
+			Check: &structs.HealthCheck{
+				Name:      "failing",
+				Status:    api.HealthCritical,
+				ServiceID: "foo",
+			},
+		}
+		if i == 0 {
+			req.Check.Status = api.HealthPassing
+		}
+
+		var reply struct{}
+		require.NoError(t, msgpackrpc.CallWithCodec(codec, "Catalog.Register", &req, &reply))
+	}
+
+	query := structs.PreparedQueryRequest{
+		Datacenter: "dc1",
+		Op:         structs.PreparedQueryCreate,
+		Query: &structs.PreparedQuery{
+			Name: "test",
+			Service: structs.ServiceQuery{
+				Service: "foo",
+				Failover: structs.QueryFailoverOptions{
+					MinHealthyPercent: 50,
+				},
+			},
+		},
+	}
+	require.NoError(t, msgpackrpc.CallWithCodec(
+		codec, "PreparedQuery.Apply", &query, &query.Query.ID))
+
+	// The local results are below the threshold of 2 nodes but there are no
+	// failover targets, so the healthy node is returned on its own.
+	req := structs.PreparedQueryExecuteRequest{
+		Datacenter:    "dc1",
+		QueryIDOrName: query.Query.ID,
+	}
+	var reply structs.PreparedQueryExecuteResponse
+	require.NoError(t, msgpackrpc.CallWithCodec(codec, "PreparedQuery.Execute", &req, &reply))
+	require.Len(t, reply.Nodes, 1)
+	require.Equal(t, "node1", reply.Nodes[0].Node.Node)
+	require.Equal(t, "dc1", reply.Datacenter)
+	require.Equal(t, 0, reply.Failovers)
+	require.Equal(t, []structs.QueryResultSource{{Datacenter: "dc1", Nodes: 1}}, reply.Sources)
+}
+
 func TestPreparedQuery_tagFilter(t *testing.T) {
 	t.Parallel()
 	testNodes := func() structs.CheckServiceNodes {
@@ -3341,3 +3498,110 @@ func TestPreparedQuery_queryFailover(t *testing.T) {
 		require.Equal(t, "peer:cluster-01|dc44:PreparedQuery.ExecuteRemote|peer:cluster-02", mock.JoinQueryLog())
 	}
 }
+
+func TestPreparedQuery_queryFailoverMerge(t *testing.T) {
+	t.Parallel()
+	query := &structs.PreparedQuery{
+		Name: "test",
+		Service: structs.ServiceQuery{
+			Failover: structs.QueryFailoverOptions{
+				Targets: []structs.QueryFailoverTarget{
+					{Datacenter: "dc2"},
+					{Peer: "cluster-01"},
+					{Datacenter: "dc3"},
+				},
+			},
+		},
+	}
+
+	nodes := func(names ...string) structs.CheckServiceNodes {
+		var out structs.CheckServiceNodes
+		for _, name := range names {
+			out = append(out, structs.CheckServiceNode{Node: &structs.Node{Node: name}})
+		}
+		return out
+	}
+
+	remoteNodes := func(args *structs.PreparedQueryExecuteRemoteRequest, reply *structs.PreparedQueryExecuteResponse) error {
+		switch {
+		case args.Query.Service.Peer == "cluster-01":
+			reply.Nodes = nodes("peer1", "peer2")
+		case args.Datacenter == "dc2":
+			reply.Nodes = nodes("dc2-node1")
+		case args.Datacenter == "dc3":
+			reply.Nodes = nodes("dc3-node1")
+		}
+		return nil
+	}
+
+	// The local results meet the threshold so no targets are tried.
+	{
+		mock := &mockQueryServer{
+			Datacenters: []string{"dc2", "dc3"},
+			QueryFn:     remoteNodes,
+		}
+
+		reply := structs.PreparedQueryExecuteResponse{
+			Datacenter: "dc1",
+			Nodes:      nodes("node1", "node2"),
+		}
+		require.NoError(t, queryFailoverMerge(mock, query, &structs.PreparedQueryExecuteRequest{}, &reply, 2))
+		require.Equal(t, nodes("node1", "node2"), reply.Nodes)
+		require.Equal(t, 0, reply.Failovers)
+		require.Equal(t, []structs.QueryResultSource{{Datacenter: "dc1", Nodes: 2}}, reply.Sources)
+		require.Empty(t, mock.JoinQueryLog())
+	}
+
+	// The local results are merged with the targets, in order, until the
+	// threshold is met.
+	{
+		mock := &mockQueryServer{
+			Datacenters: []string{"dc2", "dc3"},
+			QueryFn:     remoteNodes,
+		}
+
+		reply := structs.PreparedQueryExecuteResponse{
+			Datacenter: "dc1",
+			Nodes:      nodes("node1"),
+		}
+		require.NoError(t, queryFailoverMerge(mock, query, &structs.PreparedQueryExecuteRequest{}, &reply, 3))
+		require.Equal(t, nodes("node1", "dc2-node1", "peer1", "peer2"), reply.Nodes)
+		require.Equal(t, "dc1", reply.Datacenter)
+		require.Equal(t, 2, reply.Failovers)
+		require.Equal(t, []structs.QueryResultSource{
+			{Datacenter: "dc1", Nodes: 1},
+			{Datacenter: "dc2", Nodes: 1},
+			{PeerName: "cluster-01", Datacenter: "dc1", Nodes: 2},
+		}, reply.Sources)
+		require.Equal(t, "dc2:PreparedQuery.ExecuteRemote|peer:cluster-01", mock.JoinQueryLog())
+
+		// The query itself is left alone.
+		require.Empty(t, query.Service.Peer)
+	}
+
+	// Without local results the reply is stamped with the first target that
+	// contributed, and targets that fail or return nothing are skipped.
+	{
+		mock := &mockQueryServer{
+			Datacenters: []string{"dc2", "dc3"},
+			QueryFn: func(args *structs.PreparedQueryExecuteRemoteRequest, reply *structs.PreparedQueryExecuteResponse) error {
+				switch args.Datacenter {
+				case "dc2":
+					return fmt.Errorf("XXX")
+				case "dc3":
+					reply.Nodes = nodes("dc3-node1")
+				}
+				return nil
+			},
+		}
+
+		var reply structs.PreparedQueryExecuteResponse
+		require.NoError(t, queryFailoverMerge(mock, query, &structs.PreparedQueryExecuteRequest{}, &reply, 5))
+		require.Equal(t, nodes("dc3-node1"), reply.Nodes)
+		require.Equal(t, "dc3", reply.Datacenter)
+		require.Equal(t, 3, reply.Failovers)
+		require.Equal(t, []structs.QueryResultSource{{Datacenter: "dc3", Nodes: 1}}, reply.Sources)
+		require.Equal(t, "dc2:PreparedQuery.ExecuteRemote|peer:cluster-01|dc3:PreparedQuery.ExecuteRemote", mock.JoinQueryLog())
+		require.Contains(t, mock.LogBuffer.String(), "Failed querying for service in datacenter")
+	}
+}
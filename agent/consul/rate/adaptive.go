@@ -0,0 +1,255 @@
+package rate
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	xrate "golang.org/x/time/rate"
+
+	"github.com/hashicorp/consul/agent/consul/multilimiter"
+)
+
+const (
+	// adaptiveInterval is how often the health signals of the leader are
+	// checked to adjust the write limit.
+	adaptiveInterval = time.Second
+
+	// adaptiveDecrease is the factor the write limit is multiplied by each
+	// interval while a health signal exceeds its target.
+	adaptiveDecrease = 0.75
+
+	// adaptiveRecovery is the fraction of the configured write limit restored
+	// each interval once the health signals are back under their targets.
+	adaptiveRecovery = 0.05
+
+	// adaptiveMinLevel is the lowest fraction of the configured write limit
+	// allowed for the low priority operations.
+	adaptiveMinLevel = 0.1
+)
+
+// OperationPriority determines which operations are shed first when the
+// adaptive write limit is reduced.
+type OperationPriority int
+
+const (
+	// OperationPriorityNormal is the priority of most operations.
+	OperationPriorityNormal OperationPriority = iota
+
+	// OperationPriorityLow represents an operation shed first (e.g. catalog
+	// registrations from anti-entropy and KV writes).
+	OperationPriorityLow
+
+	// OperationPriorityHigh represents an operation shed last (e.g. session
+	// renewals and ACL logins).
+	OperationPriorityHigh
+)
+
+var priorityToName = map[OperationPriority]string{
+	OperationPriorityNormal: "normal",
+	OperationPriorityLow:    "low",
+	OperationPriorityHigh:   "high",
+}
+
+func (p OperationPriority) String() string {
+	return priorityToName[p]
+}
+
+// weight is the multiple of the adaptive level allowed for the priority, so
+// that the higher priorities are only limited once the level is low enough.
+func (p OperationPriority) weight() float64 {
+	switch p {
+	case OperationPriorityLow:
+		return 1
+	case OperationPriorityHigh:
+		return 4
+	default:
+		return 2
+	}
+}
+
+// AdaptiveConfig configures the adaptive write limit, which is reduced while
+// the health signals of the leader exceed their targets and recovered
+// gradually once they are back under them.
+type AdaptiveConfig struct {
+	// Enabled enables the adaptive write limit. The configured global write
+	// limit is used as the maximum, and GlobalMode as the mode.
+	Enabled bool
+
+	// CommitTimeTarget is the target time to commit and apply a write on the
+	// leader.
+	CommitTimeTarget time.Duration
+
+	// ApplyQueueTarget is the target number of writes waiting to be committed
+	// by the leader.
+	ApplyQueueTarget int
+
+	// FSMApplyTimeTarget is the target time to apply a log to the FSM.
+	FSMApplyTimeTarget time.Duration
+}
+
+// HealthSignals are the signals of the leader's health the adaptive write
+// limit reacts to, measured since the previous call to
+// LeaderStatusProvider.LeaderHealthSignals.
+type HealthSignals struct {
+	// CommitTime is the mean time taken to commit and apply a write.
+	CommitTime time.Duration
+
+	// ApplyQueueDepth is the number of writes waiting to be committed.
+	ApplyQueueDepth int
+
+	// FSMApplyTime is the mean time taken to apply a log to the FSM.
+	FSMApplyTime time.Duration
+}
+
+// exceeds returns whether any signal exceeds its target, the targets which
+// are not set are ignored.
+func (s HealthSignals) exceeds(cfg AdaptiveConfig) bool {
+	return (cfg.CommitTimeTarget > 0 && s.CommitTime > cfg.CommitTimeTarget) ||
+		(cfg.ApplyQueueTarget > 0 && s.ApplyQueueDepth > cfg.ApplyQueueTarget) ||
+		(cfg.FSMApplyTimeTarget > 0 && s.FSMApplyTime > cfg.FSMApplyTimeTarget)
+}
+
+// adaptiveLimiter tracks the fraction of the global write limit currently
+// allowed by the adaptive mode.
+type adaptiveLimiter struct {
+	lock sync.Mutex
+
+	// level is the fraction of the global write limit allowed for the low
+	// priority operations, the higher priorities are allowed a multiple of it.
+	level float64
+}
+
+func newAdaptiveLimiter() *adaptiveLimiter {
+	return &adaptiveLimiter{level: 1}
+}
+
+// current returns the current level.
+func (a *adaptiveLimiter) current() float64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.level
+}
+
+// adjust reduces the level if the signals exceed their targets, otherwise it
+// recovers it. It returns the new level.
+func (a *adaptiveLimiter) adjust(exceeded bool) float64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if exceeded {
+		a.level = math.Max(adaptiveMinLevel, a.level*adaptiveDecrease)
+	} else {
+		a.level = math.Min(1, a.level+adaptiveRecovery)
+	}
+	return a.level
+}
+
+// reset restores the full write limit.
+func (a *adaptiveLimiter) reset() float64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.level = 1
+	return a.level
+}
+
+// fraction returns the fraction of the global write limit allowed for the
+// given priority at the given level.
+func fraction(level float64, priority OperationPriority) float64 {
+	return math.Min(1, level*priority.weight())
+}
+
+// runAdaptive adjusts the adaptive write limit until the given context is
+// canceled.
+func (h *Handler) runAdaptive(ctx context.Context) {
+	ticker := time.NewTicker(h.adaptiveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.adjustAdaptive()
+		}
+	}
+}
+
+// adjustAdaptive checks the health signals of the leader and updates the
+// write limit of each priority accordingly. The followers forward the writes
+// to the leader and always allow the full write limit.
+func (h *Handler) adjustAdaptive() {
+	cfg := h.cfg.Load()
+	previous := h.adaptive.current()
+
+	var level float64
+	switch {
+	case !cfg.Adaptive.Enabled || h.leaderStatusProvider == nil || !h.leaderStatusProvider.IsLeader():
+		level = h.adaptive.reset()
+	default:
+		level = h.adaptive.adjust(h.leaderStatusProvider.LeaderHealthSignals().exceeds(cfg.Adaptive))
+	}
+	metrics.SetGauge([]string{"rpc", "rate_limit", "adaptive_write_level"}, float32(level))
+
+	if level == previous {
+		return
+	}
+	switch {
+	case previous == 1:
+		h.logger.Warn("leader health signals exceeded their targets, reducing the write rate limit")
+	case level == 1:
+		h.logger.Info("leader health signals are back under their targets, write rate limit recovered")
+	}
+	h.updateAdaptiveConfigs(cfg, level)
+}
+
+// updateAdaptiveConfigs sets the rate limit of each priority for the given
+// level.
+func (h *Handler) updateAdaptiveConfigs(cfg *HandlerConfig, level float64) {
+	for priority, prefix := range adaptiveWrite {
+		h.limiter.UpdateConfig(adaptiveLimiterConfig(cfg.GlobalWriteConfig, fraction(level, priority)), prefix)
+	}
+}
+
+// adaptiveLimiterConfig scales the given limiter configuration, the burst
+// allows at least one operation.
+func adaptiveLimiterConfig(cfg multilimiter.LimiterConfig, fraction float64) multilimiter.LimiterConfig {
+	burst := int(float64(cfg.Burst) * fraction)
+	if burst < 1 {
+		burst = 1
+	}
+	return multilimiter.LimiterConfig{
+		Rate:  cfg.Rate * xrate.Limit(fraction),
+		Burst: burst,
+	}
+}
+
+func (h *Handler) adaptiveLimit(op Operation) *limit {
+	if op.Type != OperationTypeWrite {
+		return nil
+	}
+	cfg := h.cfg.Load()
+	if !cfg.Adaptive.Enabled {
+		return nil
+	}
+
+	// The priorities allowed the full write limit are only checked against
+	// the global limit.
+	if fraction(h.adaptive.current(), op.Priority) >= 1 {
+		return nil
+	}
+
+	return &limit{
+		mode: cfg.GlobalMode,
+		ent:  adaptiveWrite[op.Priority],
+		desc: "adaptive/write/" + op.Priority.String(),
+	}
+}
+
+// adaptiveWrite identifies the adaptive rate limit applied to the write
+// operations of each priority.
+var adaptiveWrite = map[OperationPriority]globalLimit{
+	OperationPriorityLow:    globalLimit("adaptive.write.low"),
+	OperationPriorityNormal: globalLimit("adaptive.write.normal"),
+	OperationPriorityHigh:   globalLimit("adaptive.write.high"),
+}
@@ -0,0 +1,153 @@
+package rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/consul/multilimiter"
+)
+
+func TestAdaptiveLimiter(t *testing.T) {
+	a := newAdaptiveLimiter()
+	require.Equal(t, 1.0, a.current())
+
+	// The level is reduced while the signals exceed their targets, down to
+	// the minimum.
+	require.Equal(t, 0.75, a.adjust(true))
+	for i := 0; i < 20; i++ {
+		a.adjust(true)
+	}
+	require.Equal(t, adaptiveMinLevel, a.current())
+
+	// It is then recovered gradually.
+	require.InDelta(t, 0.15, a.adjust(false), 0.0001)
+	for i := 0; i < 30; i++ {
+		a.adjust(false)
+	}
+	require.Equal(t, 1.0, a.current())
+
+	a.adjust(true)
+	require.Equal(t, 1.0, a.reset())
+}
+
+func TestAdaptiveFraction(t *testing.T) {
+	cases := []struct {
+		level             float64
+		low, normal, high float64
+	}{
+		{level: 1, low: 1, normal: 1, high: 1},
+		{level: 0.5, low: 0.5, normal: 1, high: 1},
+		{level: 0.2, low: 0.2, normal: 0.4, high: 0.8},
+		{level: 0.1, low: 0.1, normal: 0.2, high: 0.4},
+	}
+	for _, tc := range cases {
+		require.InDelta(t, tc.low, fraction(tc.level, OperationPriorityLow), 0.0001)
+		require.InDelta(t, tc.normal, fraction(tc.level, OperationPriorityNormal), 0.0001)
+		require.InDelta(t, tc.high, fraction(tc.level, OperationPriorityHigh), 0.0001)
+	}
+}
+
+func TestHealthSignals_Exceeds(t *testing.T) {
+	cfg := AdaptiveConfig{
+		Enabled:            true,
+		CommitTimeTarget:   100 * time.Millisecond,
+		ApplyQueueTarget:   64,
+		FSMApplyTimeTarget: 10 * time.Millisecond,
+	}
+
+	require.False(t, HealthSignals{}.exceeds(cfg))
+	require.False(t, HealthSignals{CommitTime: 100 * time.Millisecond, ApplyQueueDepth: 64, FSMApplyTime: 10 * time.Millisecond}.exceeds(cfg))
+	require.True(t, HealthSignals{CommitTime: 101 * time.Millisecond}.exceeds(cfg))
+	require.True(t, HealthSignals{ApplyQueueDepth: 65}.exceeds(cfg))
+	require.True(t, HealthSignals{FSMApplyTime: 11 * time.Millisecond}.exceeds(cfg))
+
+	// The targets which are not set are ignored.
+	require.False(t, HealthSignals{ApplyQueueDepth: 1000}.exceeds(AdaptiveConfig{Enabled: true}))
+}
+
+func TestHandler_AdaptiveWriteLimit(t *testing.T) {
+	writeCfg := multilimiter.LimiterConfig{Rate: 100, Burst: 1000}
+	cfg := HandlerConfig{
+		GlobalMode:        ModeEnforcing,
+		GlobalWriteConfig: writeCfg,
+		GlobalReadConfig:  multilimiter.LimiterConfig{Rate: 100, Burst: 1000},
+		Adaptive: AdaptiveConfig{
+			Enabled:          true,
+			CommitTimeTarget: 100 * time.Millisecond,
+		},
+	}
+
+	limiter := newMockLimiter(t)
+	limiter.On("UpdateConfig", mock.Anything, mock.Anything).Return()
+	limiter.On("Allow", globalWrite).Return(true)
+
+	provider := NewMockLeaderStatusProvider(t)
+	provider.On("IsLeader").Return(true)
+	provider.On("LeaderHealthSignals").Return(HealthSignals{CommitTime: time.Second})
+
+	handler := NewHandlerWithLimiter(cfg, limiter, hclog.NewNullLogger())
+	handler.Register(provider)
+
+	// The full write limit is allowed until the signals are checked.
+	require.NoError(t, handler.Allow(Operation{Type: OperationTypeWrite, Priority: OperationPriorityLow}))
+	limiter.AssertNotCalled(t, "Allow", adaptiveWrite[OperationPriorityLow])
+
+	// The low priority writes are limited first.
+	limiter.Calls = nil
+	handler.adjustAdaptive()
+	require.Equal(t, 0.75, handler.adaptive.current())
+	limiter.AssertCalled(t, "UpdateConfig", multilimiter.LimiterConfig{Rate: 75, Burst: 750}, []byte("adaptive.write.low"))
+	limiter.AssertCalled(t, "UpdateConfig", writeCfg, []byte("adaptive.write.normal"))
+	limiter.AssertCalled(t, "UpdateConfig", writeCfg, []byte("adaptive.write.high"))
+
+	limiter.On("Allow", adaptiveWrite[OperationPriorityLow]).Return(false)
+	require.Equal(t, ErrRetryLater, handler.Allow(Operation{Type: OperationTypeWrite, Priority: OperationPriorityLow}))
+	require.NoError(t, handler.Allow(Operation{Type: OperationTypeWrite, Priority: OperationPriorityNormal}))
+	limiter.AssertNotCalled(t, "Allow", adaptiveWrite[OperationPriorityNormal])
+
+	// Reads are not limited by the adaptive mode.
+	limiter.On("Allow", globalRead).Return(true)
+	require.NoError(t, handler.Allow(Operation{Type: OperationTypeRead, Priority: OperationPriorityLow}))
+
+	// The high priority writes are limited last.
+	for i := 0; i < 20; i++ {
+		handler.adjustAdaptive()
+	}
+	require.Equal(t, adaptiveMinLevel, handler.adaptive.current())
+	limiter.On("Allow", adaptiveWrite[OperationPriorityHigh]).Return(true)
+	require.NoError(t, handler.Allow(Operation{Type: OperationTypeWrite, Priority: OperationPriorityHigh}))
+	limiter.AssertCalled(t, "Allow", adaptiveWrite[OperationPriorityHigh])
+}
+
+func TestHandler_AdaptiveWriteLimit_Follower(t *testing.T) {
+	cfg := HandlerConfig{
+		GlobalMode:        ModeEnforcing,
+		GlobalWriteConfig: multilimiter.LimiterConfig{Rate: 100, Burst: 1000},
+		Adaptive:          AdaptiveConfig{Enabled: true, ApplyQueueTarget: 10},
+	}
+
+	limiter := newMockLimiter(t)
+	limiter.On("UpdateConfig", mock.Anything, mock.Anything).Return()
+
+	isLeader := true
+	provider := NewMockLeaderStatusProvider(t)
+	provider.On("IsLeader").Return(func() bool { return isLeader })
+	provider.On("LeaderHealthSignals").Return(HealthSignals{ApplyQueueDepth: 100})
+
+	handler := NewHandlerWithLimiter(cfg, limiter, hclog.NewNullLogger())
+	handler.Register(provider)
+
+	handler.adjustAdaptive()
+	require.Equal(t, 0.75, handler.adaptive.current())
+
+	// The full write limit is restored once the server is not the leader
+	// anymore.
+	isLeader = false
+	handler.adjustAdaptive()
+	require.Equal(t, 1.0, handler.adaptive.current())
+	provider.AssertNumberOfCalls(t, "LeaderHealthSignals", 1)
+}
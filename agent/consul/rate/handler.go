@@ -8,6 +8,7 @@ import (
 	"net"
 	"reflect"
 	"sync/atomic"
+	"time"
 
 	"github.com/armon/go-metrics"
 	"github.com/hashicorp/go-hclog"
@@ -102,14 +103,23 @@ type Operation struct {
 	// SourceAddr is the client's (or forwarding server's) IP address.
 	SourceAddr net.Addr
 
+	// Token is the secret of the ACL token used by the client, the limits of
+	// its accessor ID are checked by RequestLimitsHandler.AllowAccessor. An
+	// empty token is the anonymous token.
+	Token string
+
 	// Type of operation to be performed (e.g. read or write).
 	Type OperationType
+
+	// Priority of the operation when the adaptive write limit is reduced.
+	Priority OperationPriority
 }
 
 //go:generate mockery --name RequestLimitsHandler --inpackage
 type RequestLimitsHandler interface {
 	Run(ctx context.Context)
 	Allow(op Operation) error
+	AllowAccessor(op Operation) error
 	UpdateConfig(cfg HandlerConfig)
 	Register(leaderStatusProvider LeaderStatusProvider)
 }
@@ -121,6 +131,9 @@ type Handler struct {
 
 	limiter multilimiter.RateLimiter
 
+	adaptive         *adaptiveLimiter
+	adaptiveInterval time.Duration
+
 	logger hclog.Logger
 }
 
@@ -129,8 +142,6 @@ type HandlerConfig struct {
 
 	// GlobalMode configures the action that will be taken when a global rate-limit
 	// has been exhausted.
-	//
-	// Note: in the future there'll be a separate Mode for IP-based limits.
 	GlobalMode Mode
 
 	// GlobalWriteConfig configures the global rate limiter for write operations.
@@ -138,6 +149,33 @@ type HandlerConfig struct {
 
 	// GlobalReadConfig configures the global rate limiter for read operations.
 	GlobalReadConfig multilimiter.LimiterConfig
+
+	// IPMode configures the action that will be taken when the rate-limit of a
+	// source IP address has been exhausted.
+	IPMode Mode
+
+	// IPWriteConfig configures the rate limiter of each source IP address for
+	// write operations.
+	IPWriteConfig multilimiter.LimiterConfig
+
+	// IPReadConfig configures the rate limiter of each source IP address for
+	// read operations.
+	IPReadConfig multilimiter.LimiterConfig
+
+	// AccessorMode configures the action that will be taken when the rate-limit
+	// of an ACL token accessor ID has been exhausted.
+	AccessorMode Mode
+
+	// AccessorWriteConfig configures the rate limiter of each ACL token accessor
+	// ID for write operations.
+	AccessorWriteConfig multilimiter.LimiterConfig
+
+	// AccessorReadConfig configures the rate limiter of each ACL token accessor
+	// ID for read operations.
+	AccessorReadConfig multilimiter.LimiterConfig
+
+	// Adaptive configures the adaptive write limit.
+	Adaptive AdaptiveConfig
 }
 
 //go:generate mockery --name LeaderStatusProvider --inpackage --filename mock_LeaderStatusProvider_test.go
@@ -147,6 +185,23 @@ type LeaderStatusProvider interface {
 	// the leader (e.g. write operations) we don't tell clients to retry against
 	// a different server.
 	IsLeader() bool
+
+	// IsServerAddr is used to exempt the operations forwarded by the other
+	// servers from the IP-based limits, the source address of those operations
+	// being the address of the forwarding server rather than the client's. The
+	// gRPC operations they forward are exempt from the accessor-based limits
+	// too, since the forwarding server already applied them.
+	IsServerAddr(addr net.Addr) bool
+
+	// LeaderHealthSignals returns the health signals of the leader measured
+	// since it was last called, the adaptive write limit is reduced while they
+	// exceed their targets.
+	LeaderHealthSignals() HealthSignals
+
+	// ResolveAccessorID returns the accessor ID of the given ACL token secret
+	// the accessor-based limits are keyed by, or an empty string when ACLs are
+	// disabled. It is only called while these limits are enabled.
+	ResolveAccessorID(token string) string
 }
 
 func NewHandlerWithLimiter(
@@ -156,13 +211,20 @@ func NewHandlerWithLimiter(
 
 	limiter.UpdateConfig(cfg.GlobalWriteConfig, globalWrite)
 	limiter.UpdateConfig(cfg.GlobalReadConfig, globalRead)
+	limiter.UpdateConfig(cfg.IPWriteConfig, ipWrite)
+	limiter.UpdateConfig(cfg.IPReadConfig, ipRead)
+	limiter.UpdateConfig(cfg.AccessorWriteConfig, accessorWrite)
+	limiter.UpdateConfig(cfg.AccessorReadConfig, accessorRead)
 
 	h := &Handler{
-		cfg:     new(atomic.Pointer[HandlerConfig]),
-		limiter: limiter,
-		logger:  logger,
+		cfg:              new(atomic.Pointer[HandlerConfig]),
+		limiter:          limiter,
+		adaptive:         newAdaptiveLimiter(),
+		adaptiveInterval: adaptiveInterval,
+		logger:           logger,
 	}
 	h.cfg.Store(&cfg)
+	h.updateAdaptiveConfigs(&cfg, h.adaptive.current())
 
 	return h
 }
@@ -178,10 +240,11 @@ func NewHandler(cfg HandlerConfig, logger hclog.Logger) *Handler {
 // Note: this starts a goroutine.
 func (h *Handler) Run(ctx context.Context) {
 	h.limiter.Run(ctx)
+	go h.runAdaptive(ctx)
 }
 
 // Allow returns an error if the given operation is not allowed to proceed
-// because of an exhausted rate-limit.
+// because of an exhausted global or IP-based rate-limit.
 func (h *Handler) Allow(op Operation) error {
 
 	if h.leaderStatusProvider == nil {
@@ -191,12 +254,31 @@ func (h *Handler) Allow(op Operation) error {
 		// panic("leaderStatusProvider required to be set via Register(..)")
 	}
 
-	cfg := h.cfg.Load()
-	if cfg.GlobalMode == ModeDisabled {
+	return h.allow(op, h.limits(op))
+}
+
+// AllowAccessor returns an error if the given operation is not allowed to
+// proceed because of the exhausted rate-limit of its ACL token accessor ID.
+//
+// Unlike Allow, which is called before the request is decoded, it is called
+// once the token of the request is known: by ForwardRPC for the net/rpc
+// endpoints the server handles itself, and before the handler runs for the
+// gRPC endpoints taking the token from the request metadata.
+func (h *Handler) AllowAccessor(op Operation) error {
+	if h.leaderStatusProvider == nil {
+		h.logger.Error("leaderStatusProvider required to be set via Register(). bailing on rate limiter")
 		return nil
 	}
 
-	for _, l := range h.limits(op) {
+	var limits []limit
+	if accessor := h.accessorLimit(op); accessor != nil {
+		limits = append(limits, *accessor)
+	}
+	return h.allow(op, limits)
+}
+
+func (h *Handler) allow(op Operation, limits []limit) error {
+	for _, l := range limits {
 		if l.mode == ModeDisabled {
 			continue
 		}
@@ -212,6 +294,7 @@ func (h *Handler) Allow(op Operation) error {
 			"rpc", op.Name,
 			"source_addr", op.SourceAddr,
 			"limit_type", l.desc,
+			"limit_key", l.key,
 			"limit_enforced", enforced,
 		)
 
@@ -231,6 +314,21 @@ func (h *Handler) Allow(op Operation) error {
 		})
 
 		if enforced {
+			// Only emitted when enforcing, to avoid unbounded cardinality when
+			// rate limits are being tuned in permissive mode.
+			if l.key != "" {
+				metrics.IncrCounterWithLabels([]string{"rpc", "rate_limit", "exceeded_key"}, 1, []metrics.Label{
+					{
+						Name:  "limit_type",
+						Value: l.desc,
+					},
+					{
+						Name:  "key",
+						Value: l.key,
+					},
+				})
+			}
+
 			if h.leaderStatusProvider.IsLeader() && op.Type == OperationTypeWrite {
 				return ErrRetryLater
 			}
@@ -253,6 +351,21 @@ func (h *Handler) UpdateConfig(cfg HandlerConfig) {
 	if !reflect.DeepEqual(existingCfg.GlobalReadConfig, cfg.GlobalReadConfig) {
 		h.limiter.UpdateConfig(cfg.GlobalReadConfig, globalRead)
 	}
+	if !reflect.DeepEqual(existingCfg.IPWriteConfig, cfg.IPWriteConfig) {
+		h.limiter.UpdateConfig(cfg.IPWriteConfig, ipWrite)
+	}
+	if !reflect.DeepEqual(existingCfg.IPReadConfig, cfg.IPReadConfig) {
+		h.limiter.UpdateConfig(cfg.IPReadConfig, ipRead)
+	}
+	if !reflect.DeepEqual(existingCfg.AccessorWriteConfig, cfg.AccessorWriteConfig) {
+		h.limiter.UpdateConfig(cfg.AccessorWriteConfig, accessorWrite)
+	}
+	if !reflect.DeepEqual(existingCfg.AccessorReadConfig, cfg.AccessorReadConfig) {
+		h.limiter.UpdateConfig(cfg.AccessorReadConfig, accessorRead)
+	}
+	if !reflect.DeepEqual(existingCfg.GlobalWriteConfig, cfg.GlobalWriteConfig) {
+		h.updateAdaptiveConfigs(&cfg, h.adaptive.current())
+	}
 }
 
 func (h *Handler) Register(leaderStatusProvider LeaderStatusProvider) {
@@ -263,10 +376,14 @@ type limit struct {
 	mode Mode
 	ent  multilimiter.LimitedEntity
 	desc string
+
+	// key is the source IP address or accessor ID the limit is keyed by, it
+	// is empty for the global limits.
+	key string
 }
 
 // limits returns the limits to check for the given operation (e.g. global +
-// ip-based + tenant-based).
+// ip-based). The accessor-based limits are checked by AllowAccessor.
 func (h *Handler) limits(op Operation) []limit {
 	limits := make([]limit, 0)
 
@@ -274,6 +391,14 @@ func (h *Handler) limits(op Operation) []limit {
 		limits = append(limits, *global)
 	}
 
+	if ip := h.ipLimit(op); ip != nil {
+		limits = append(limits, *ip)
+	}
+
+	if adaptive := h.adaptiveLimit(op); adaptive != nil {
+		limits = append(limits, *adaptive)
+	}
+
 	return limits
 }
 
@@ -297,12 +422,98 @@ func (h *Handler) globalLimit(op Operation) *limit {
 	return lim
 }
 
+func (h *Handler) ipLimit(op Operation) *limit {
+	if op.Type == OperationTypeExempt || op.SourceAddr == nil {
+		return nil
+	}
+	cfg := h.cfg.Load()
+	if cfg.IPMode == ModeDisabled {
+		return nil
+	}
+	ip := sourceIP(op.SourceAddr)
+	if ip == "" || h.leaderStatusProvider.IsServerAddr(op.SourceAddr) {
+		return nil
+	}
+
+	lim := &limit{mode: cfg.IPMode, key: ip}
+	switch op.Type {
+	case OperationTypeRead:
+		lim.desc = "ip/read"
+		lim.ent = keyedLimit{prefix: ipRead, key: ip}
+	case OperationTypeWrite:
+		lim.desc = "ip/write"
+		lim.ent = keyedLimit{prefix: ipWrite, key: ip}
+	default:
+		panic(fmt.Sprintf("unknown operation type %d", op.Type))
+	}
+	return lim
+}
+
+func (h *Handler) accessorLimit(op Operation) *limit {
+	if op.Type == OperationTypeExempt {
+		return nil
+	}
+	cfg := h.cfg.Load()
+	if cfg.AccessorMode == ModeDisabled {
+		return nil
+	}
+	if op.SourceAddr != nil && h.leaderStatusProvider.IsServerAddr(op.SourceAddr) {
+		return nil
+	}
+	accessorID := h.leaderStatusProvider.ResolveAccessorID(op.Token)
+	if accessorID == "" {
+		return nil
+	}
+
+	lim := &limit{mode: cfg.AccessorMode, key: accessorID}
+	switch op.Type {
+	case OperationTypeRead:
+		lim.desc = "accessor/read"
+		lim.ent = keyedLimit{prefix: accessorRead, key: accessorID}
+	case OperationTypeWrite:
+		lim.desc = "accessor/write"
+		lim.ent = keyedLimit{prefix: accessorWrite, key: accessorID}
+	default:
+		panic(fmt.Sprintf("unknown operation type %d", op.Type))
+	}
+	return lim
+}
+
+// sourceIP returns the IP address of the given source address, without its
+// port.
+func sourceIP(addr net.Addr) string {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
 var (
 	// globalWrite identifies the global rate limit applied to write operations.
 	globalWrite = globalLimit("global.write")
 
 	// globalRead identifies the global rate limit applied to read operations.
 	globalRead = globalLimit("global.read")
+
+	// ipWrite is the prefix of the rate limits applied to the write operations
+	// of each source IP address.
+	ipWrite = []byte("ip.write")
+
+	// ipRead is the prefix of the rate limits applied to the read operations
+	// of each source IP address.
+	ipRead = []byte("ip.read")
+
+	// accessorWrite is the prefix of the rate limits applied to the write
+	// operations of each ACL token accessor ID.
+	accessorWrite = []byte("accessor.write")
+
+	// accessorRead is the prefix of the rate limits applied to the read
+	// operations of each ACL token accessor ID.
+	accessorRead = []byte("accessor.read")
 )
 
 // globalLimit represents a limit that applies to all writes or reads.
@@ -313,6 +524,19 @@ func (prefix globalLimit) Key() multilimiter.KeyType {
 	return multilimiter.Key(prefix, nil)
 }
 
+// keyedLimit represents a limit that applies to the writes or reads of a
+// single source IP address or accessor ID, its configuration is the one of its
+// prefix.
+type keyedLimit struct {
+	prefix []byte
+	key    string
+}
+
+// Key satisfies the multilimiter.LimitedEntity interface.
+func (l keyedLimit) Key() multilimiter.KeyType {
+	return multilimiter.Key(l.prefix, []byte(l.key))
+}
+
 // NullRequestLimitsHandler returns a RequestLimitsHandler that allows every operation.
 func NullRequestLimitsHandler() RequestLimitsHandler {
 	return nullRequestLimitsHandler{}
@@ -322,6 +546,8 @@ type nullRequestLimitsHandler struct{}
 
 func (nullRequestLimitsHandler) Allow(Operation) error { return nil }
 
+func (nullRequestLimitsHandler) AllowAccessor(Operation) error { return nil }
+
 func (nullRequestLimitsHandler) Run(ctx context.Context) {}
 
 func (nullRequestLimitsHandler) UpdateConfig(cfg HandlerConfig) {}
@@ -260,6 +260,182 @@ func TestHandler(t *testing.T) {
 	}
 }
 
+func TestHandler_KeyedLimits(t *testing.T) {
+	var (
+		rpcName    = "Foo.Bar"
+		sourceAddr = net.TCPAddrFromAddrPort(netip.MustParseAddrPort("1.2.3.4:5678"))
+		accessorID = "8c1f5a58-42a6-4d4c-9e2c-3ab5d1c2a0f1"
+		token      = "5e2b7d4c-1f0a-4c8e-b3d6-9a7f2e1c4b80"
+	)
+
+	type limitCheck struct {
+		limit multilimiter.LimitedEntity
+		allow bool
+	}
+	testCases := map[string]struct {
+		op               Operation
+		accessor         bool
+		ipMode           Mode
+		accessorMode     Mode
+		isServerAddr     bool
+		aclsDisabled     bool
+		checks           []limitCheck
+		isLeader         bool
+		expectErr        error
+		expectLog        bool
+		expectMetricName string
+		expectKeyMetric  string
+	}{
+		"ip write limit disabled": {
+			op:     Operation{Type: OperationTypeWrite, Name: rpcName, SourceAddr: sourceAddr},
+			ipMode: ModeDisabled,
+		},
+		"ip write limit within allowance": {
+			op:     Operation{Type: OperationTypeWrite, Name: rpcName, SourceAddr: sourceAddr},
+			ipMode: ModeEnforcing,
+			checks: []limitCheck{
+				{limit: keyedLimit{prefix: ipWrite, key: "1.2.3.4"}, allow: true},
+			},
+		},
+		"ip read limit exceeded (permissive)": {
+			op:     Operation{Type: OperationTypeRead, Name: rpcName, SourceAddr: sourceAddr},
+			ipMode: ModePermissive,
+			checks: []limitCheck{
+				{limit: keyedLimit{prefix: ipRead, key: "1.2.3.4"}, allow: false},
+			},
+			expectLog:        true,
+			expectMetricName: "rpc.rate_limit.exceeded;limit_type=ip/read;op=Foo.Bar;mode=permissive",
+		},
+		"ip write limit exceeded (enforcing, leader)": {
+			op:     Operation{Type: OperationTypeWrite, Name: rpcName, SourceAddr: sourceAddr},
+			ipMode: ModeEnforcing,
+			checks: []limitCheck{
+				{limit: keyedLimit{prefix: ipWrite, key: "1.2.3.4"}, allow: false},
+			},
+			isLeader:         true,
+			expectErr:        ErrRetryLater,
+			expectLog:        true,
+			expectMetricName: "rpc.rate_limit.exceeded;limit_type=ip/write;op=Foo.Bar;mode=enforcing",
+			expectKeyMetric:  "rpc.rate_limit.exceeded_key;limit_type=ip/write;key=1.2.3.4",
+		},
+		"ip limit skipped for servers": {
+			op:           Operation{Type: OperationTypeWrite, Name: rpcName, SourceAddr: sourceAddr},
+			ipMode:       ModeEnforcing,
+			isServerAddr: true,
+		},
+		"ip limit skipped for exempt operations": {
+			op:     Operation{Type: OperationTypeExempt, Name: rpcName, SourceAddr: sourceAddr},
+			ipMode: ModeEnforcing,
+		},
+		"accessor write limit disabled": {
+			op:           Operation{Type: OperationTypeWrite, Name: rpcName, Token: token},
+			accessor:     true,
+			accessorMode: ModeDisabled,
+		},
+		"accessor read limit within allowance": {
+			op:           Operation{Type: OperationTypeRead, Name: rpcName, Token: token},
+			accessor:     true,
+			accessorMode: ModeEnforcing,
+			checks: []limitCheck{
+				{limit: keyedLimit{prefix: accessorRead, key: accessorID}, allow: true},
+			},
+		},
+		"accessor write limit exceeded (permissive)": {
+			op:           Operation{Type: OperationTypeWrite, Name: rpcName, Token: token},
+			accessor:     true,
+			accessorMode: ModePermissive,
+			checks: []limitCheck{
+				{limit: keyedLimit{prefix: accessorWrite, key: accessorID}, allow: false},
+			},
+			expectLog:        true,
+			expectMetricName: "rpc.rate_limit.exceeded;limit_type=accessor/write;op=Foo.Bar;mode=permissive",
+		},
+		"accessor read limit exceeded (enforcing)": {
+			op:           Operation{Type: OperationTypeRead, Name: rpcName, Token: token},
+			accessor:     true,
+			accessorMode: ModeEnforcing,
+			checks: []limitCheck{
+				{limit: keyedLimit{prefix: accessorRead, key: accessorID}, allow: false},
+			},
+			isLeader:         true,
+			expectErr:        ErrRetryElsewhere,
+			expectLog:        true,
+			expectMetricName: "rpc.rate_limit.exceeded;limit_type=accessor/read;op=Foo.Bar;mode=enforcing",
+			expectKeyMetric:  "rpc.rate_limit.exceeded_key;limit_type=accessor/read;key=" + accessorID,
+		},
+		"accessor limit skipped for servers": {
+			op:           Operation{Type: OperationTypeRead, Name: rpcName, SourceAddr: sourceAddr, Token: token},
+			accessor:     true,
+			accessorMode: ModeEnforcing,
+			isServerAddr: true,
+		},
+		"accessor limit skipped without ACLs": {
+			op:           Operation{Type: OperationTypeRead, Name: rpcName, Token: token},
+			accessor:     true,
+			accessorMode: ModeEnforcing,
+			aclsDisabled: true,
+		},
+		"accessor limit not checked by Allow": {
+			op:           Operation{Type: OperationTypeRead, Name: rpcName, Token: token},
+			accessorMode: ModeEnforcing,
+		},
+	}
+	for desc, tc := range testCases {
+		t.Run(desc, func(t *testing.T) {
+			sink := metrics.TestSetupMetrics(t, "")
+			limiter := newMockLimiter(t)
+			limiter.On("UpdateConfig", mock.Anything, mock.Anything).Return()
+			for _, c := range tc.checks {
+				limiter.On("Allow", c.limit).Return(c.allow)
+			}
+
+			leaderStatusProvider := NewMockLeaderStatusProvider(t)
+			leaderStatusProvider.On("IsLeader").Return(tc.isLeader).Maybe()
+			leaderStatusProvider.On("IsServerAddr", sourceAddr).Return(tc.isServerAddr).Maybe()
+			if tc.aclsDisabled {
+				leaderStatusProvider.On("ResolveAccessorID", token).Return("").Maybe()
+			} else {
+				leaderStatusProvider.On("ResolveAccessorID", token).Return(accessorID).Maybe()
+			}
+
+			var output bytes.Buffer
+			logger := hclog.NewInterceptLogger(&hclog.LoggerOptions{
+				Level:  hclog.Trace,
+				Output: &output,
+			})
+
+			handler := NewHandlerWithLimiter(
+				HandlerConfig{
+					IPMode:       tc.ipMode,
+					AccessorMode: tc.accessorMode,
+				},
+				limiter,
+				logger,
+			)
+			handler.Register(leaderStatusProvider)
+
+			if tc.accessor {
+				require.Equal(t, tc.expectErr, handler.AllowAccessor(tc.op))
+			} else {
+				require.Equal(t, tc.expectErr, handler.Allow(tc.op))
+			}
+
+			if tc.expectLog {
+				require.Contains(t, output.String(), "RPC exceeded allowed rate limit")
+			} else {
+				require.Zero(t, output.Len(), "expected no logs to be emitted")
+			}
+
+			if tc.expectMetricName != "" {
+				metrics.AssertCounter(t, sink, tc.expectMetricName, 1)
+			}
+			if tc.expectKeyMetric != "" {
+				metrics.AssertCounter(t, sink, tc.expectKeyMetric, 1)
+			}
+		})
+	}
+}
+
 func TestNewHandlerWithLimiter_CallsUpdateConfig(t *testing.T) {
 	mockRateLimiter := multilimiter.NewMockRateLimiter(t)
 	mockRateLimiter.On("UpdateConfig", mock.Anything, mock.Anything).Return()
@@ -272,7 +448,7 @@ func TestNewHandlerWithLimiter_CallsUpdateConfig(t *testing.T) {
 	}
 	logger := hclog.NewNullLogger()
 	NewHandlerWithLimiter(*cfg, mockRateLimiter, logger)
-	mockRateLimiter.AssertNumberOfCalls(t, "UpdateConfig", 2)
+	mockRateLimiter.AssertNumberOfCalls(t, "UpdateConfig", 9)
 }
 
 func TestUpdateConfig(t *testing.T) {
@@ -305,8 +481,11 @@ func TestUpdateConfig(t *testing.T) {
 				cfg.GlobalWriteConfig.Burst++
 			},
 			assertFunc: func(mockRateLimiter *multilimiter.MockRateLimiter, cfg *HandlerConfig) {
-				mockRateLimiter.AssertNumberOfCalls(t, "UpdateConfig", 1)
+				// The adaptive limits of each priority are scaled from the
+				// global write limit.
+				mockRateLimiter.AssertNumberOfCalls(t, "UpdateConfig", 1+len(adaptiveWrite))
 				mockRateLimiter.AssertCalled(t, "UpdateConfig", cfg.GlobalWriteConfig, []byte("global.write"))
+				mockRateLimiter.AssertCalled(t, "UpdateConfig", cfg.GlobalWriteConfig, []byte("adaptive.write.low"))
 			},
 		},
 		{
@@ -7,8 +7,19 @@ var Counters = []prometheus.CounterDefinition{
 		Name: []string{"rpc", "rate_limit", "exceeded"},
 		Help: "Increments whenever an RPC is over a configured rate limit. Note: in permissive mode, the RPC will have still been allowed to proceed.",
 	},
+	{
+		Name: []string{"rpc", "rate_limit", "exceeded_key"},
+		Help: "Increments whenever an RPC is rejected because the rate limit of its source IP address or ACL token accessor ID is exhausted. Only emitted in enforcing mode.",
+	},
 	{
 		Name: []string{"rpc", "rate_limit", "log_dropped"},
 		Help: "Increments whenever a log that is emitted because an RPC exceeded a rate limit gets dropped because the output buffer is full.",
 	},
 }
+
+var Gauges = []prometheus.GaugeDefinition{
+	{
+		Name: []string{"rpc", "rate_limit", "adaptive_write_level"},
+		Help: "Tracks the fraction of the global write rate limit allowed for the low priority RPCs by the adaptive mode. 1 when the leader health signals are under their targets.",
+	},
+}
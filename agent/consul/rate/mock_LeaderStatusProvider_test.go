@@ -3,6 +3,7 @@
 package rate
 
 import (
+	net "net"
 	testing "testing"
 
 	mock "github.com/stretchr/testify/mock"
@@ -27,6 +28,48 @@ func (_m *MockLeaderStatusProvider) IsLeader() bool {
 	return r0
 }
 
+// IsServerAddr provides a mock function with given fields: addr
+func (_m *MockLeaderStatusProvider) IsServerAddr(addr net.Addr) bool {
+	ret := _m.Called(addr)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(net.Addr) bool); ok {
+		r0 = rf(addr)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// LeaderHealthSignals provides a mock function with given fields:
+func (_m *MockLeaderStatusProvider) LeaderHealthSignals() HealthSignals {
+	ret := _m.Called()
+
+	var r0 HealthSignals
+	if rf, ok := ret.Get(0).(func() HealthSignals); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(HealthSignals)
+	}
+
+	return r0
+}
+
+// ResolveAccessorID provides a mock function with given fields: token
+func (_m *MockLeaderStatusProvider) ResolveAccessorID(token string) string {
+	ret := _m.Called(token)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // NewMockLeaderStatusProvider creates a new instance of MockLeaderStatusProvider. It also registers the testing.TB interface on the mock and a cleanup function to assert the mocks expectations.
 func NewMockLeaderStatusProvider(t testing.TB) *MockLeaderStatusProvider {
 	mock := &MockLeaderStatusProvider{}
@@ -27,6 +27,20 @@ func (_m *MockRequestLimitsHandler) Allow(op Operation) error {
 	return r0
 }
 
+// AllowAccessor provides a mock function with given fields: op
+func (_m *MockRequestLimitsHandler) AllowAccessor(op Operation) error {
+	ret := _m.Called(op)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(Operation) error); ok {
+		r0 = rf(op)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Run provides a mock function with given fields: ctx
 func (_m *MockRequestLimitsHandler) Run(ctx context.Context) {
 	_m.Called(ctx)
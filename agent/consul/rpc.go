@@ -77,6 +77,14 @@ var RPCSummaries = []prometheus.SummaryDefinition{
 		Name: []string{"rpc", "consistentRead"},
 		Help: "Measures the time spent confirming that a consistent read can be performed.",
 	},
+	{
+		Name: []string{"rpc", "consistentRead", "barrier"},
+		Help: "Measures the time spent verifying the leadership with a quorum of servers for a consistent read.",
+	},
+	{
+		Name: []string{"rpc", "consistentRead", "lease"},
+		Help: "Measures the time spent verifying the leadership with the leader lease for a consistent read.",
+	},
 }
 
 const (
@@ -620,7 +628,22 @@ func (s *Server) ForwardRPC(method string, info structs.RPCInfo, reply interface
 		return s.connPool.RPC(s.config.Datacenter, leader.ShortName, leader.Addr,
 			method, info, reply)
 	}
-	return s.forwardRPC(info, forwardToDC, forwardToLeader)
+	if handled, err := s.forwardRPC(info, forwardToDC, forwardToLeader); handled || err != nil {
+		return handled, err
+	}
+	return false, s.allowAccessor(method, info)
+}
+
+// allowAccessor applies the rate limits of the accessor ID of the token used
+// by a request this server is about to handle. The global and IP-based limits
+// are applied before the request is decoded, see
+// middleware.GetNetRPCRateLimitingInterceptor.
+func (s *Server) allowAccessor(method string, info structs.RPCInfo) error {
+	return s.incomingRPCLimiter.AllowAccessor(rate.Operation{
+		Name:  method,
+		Type:  middleware.NetRPCOperationType(method),
+		Token: info.TokenSecret(),
+	})
 }
 
 // ForwardGRPC is used to potentially forward an RPC request to a remote DC or
@@ -935,6 +958,7 @@ func (s *Server) raftApplyWithEncoder(
 
 	var chunked bool
 	var future raft.ApplyFuture
+	done := s.leaderHealth.startApply()
 	switch {
 	case len(buf) <= raft.SuggestedMaxDataSize || t != structs.KVSRequestType:
 		future = s.raft.Apply(buf, enqueueLimit)
@@ -943,7 +967,9 @@ func (s *Server) raftApplyWithEncoder(
 		future = raftchunking.ChunkingApply(buf, nil, enqueueLimit, s.raft.ApplyLog)
 	}
 
-	if err := future.Error(); err != nil {
+	err = future.Error()
+	done(err)
+	if err != nil {
 		return nil, err
 	}
 
@@ -1178,8 +1204,7 @@ func (s *Server) setQueryMeta(m blockingQueryResponseMeta, token string) {
 // read. This is done by verifying leadership before the read.
 func (s *Server) consistentRead() error {
 	defer metrics.MeasureSince([]string{"rpc", "consistentRead"}, time.Now())
-	future := s.raft.VerifyLeader()
-	if err := future.Error(); err != nil {
+	if err := s.verifyLeader(); err != nil {
 		return err // fail fast if leader verification fails
 	}
 	// poll consistent read readiness, wait for up to RPCHoldTimeout milliseconds
@@ -1207,6 +1232,32 @@ func (s *Server) consistentRead() error {
 	return structs.ErrNotReadyForConsistentReads
 }
 
+// verifyLeader verifies that this server is still the leader. In the lease
+// consistent read mode, the leadership is only verified with a quorum of
+// servers when the leader lease expired, and the successful verifications
+// renew the lease.
+func (s *Server) verifyLeader() error {
+	start := time.Now()
+	lease := s.config.ConsistentReadMode == ConsistentReadModeLease
+	if lease && s.IsLeader() && s.leaderLease.valid(start) {
+		metrics.MeasureSince([]string{"rpc", "consistentRead", "lease"}, start)
+		return nil
+	}
+
+	generation := s.leaderLease.current()
+	future := s.raft.VerifyLeader()
+	if err := future.Error(); err != nil {
+		return err
+	}
+	metrics.MeasureSince([]string{"rpc", "consistentRead", "barrier"}, start)
+
+	if lease {
+		duration := s.raft.ReloadableConfig().HeartbeatTimeout - s.config.LeaseMaxClockDrift
+		s.leaderLease.renew(generation, start.Add(duration))
+	}
+	return nil
+}
+
 // rpcQueryTimeout calculates the timeout for the query, ensures it is
 // constrained to the configured limit, and adds jitter to prevent multiple
 // blocking queries from all timing out at the same time.
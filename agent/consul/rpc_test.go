@@ -516,6 +516,142 @@ func TestRPC_ReadyForConsistentReads(t *testing.T) {
 	})
 }
 
+func TestRPC_ConsistentReadLease(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	dir, s := testServerWithConfig(t, func(c *Config) {
+		c.ConsistentReadMode = ConsistentReadModeLease
+		c.LeaseMaxClockDrift = 50 * time.Millisecond
+	})
+	defer os.RemoveAll(dir)
+	defer s.Shutdown()
+
+	testrpc.WaitForLeader(t, s.RPC, "dc1")
+
+	// The lease is renewed by a successful leadership verification.
+	retry.Run(t, func(r *retry.R) {
+		start := time.Now()
+		if err := s.consistentRead(); err != nil {
+			r.Fatalf("err: %v", err)
+		}
+		if !s.leaderLease.valid(start) {
+			r.Fatal("expected the lease to be valid")
+		}
+	})
+
+	// The lease doesn't outlive the heartbeat timeout minus the clock drift.
+	require.False(t, s.leaderLease.valid(time.Now().Add(150*time.Millisecond)))
+
+	// A revoked lease isn't valid anymore.
+	s.leaderLease.revoke()
+	require.False(t, s.leaderLease.valid(time.Now()))
+	require.NoError(t, s.consistentRead())
+
+	// The lease is never renewed in the barrier mode.
+	s.config.ConsistentReadMode = ConsistentReadModeBarrier
+	s.leaderLease.revoke()
+	require.NoError(t, s.consistentRead())
+	require.False(t, s.leaderLease.valid(time.Now()))
+}
+
+func TestRPC_AllowAccessor(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	_, srv, _ := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, srv)
+
+	limiter := rate.NewMockRequestLimitsHandler(t)
+	limiter.On("AllowAccessor", rate.Operation{
+		Name:  "KVS.Get",
+		Type:  rate.OperationTypeRead,
+		Token: "my-token",
+	}).Return(rate.ErrRetryElsewhere).Once()
+	limiter.On("AllowAccessor", rate.Operation{
+		Name: "KVS.Apply",
+		Type: rate.OperationTypeWrite,
+	}).Return(nil).Once()
+	srv.incomingRPCLimiter = limiter
+
+	// The limit of the token's accessor ID is exhausted.
+	var reply structs.IndexedDirEntries
+	handled, err := srv.ForwardRPC("KVS.Get", &structs.KeyRequest{
+		Datacenter:   "dc1",
+		Key:          "foo",
+		QueryOptions: structs.QueryOptions{Token: "my-token"},
+	}, &reply)
+	require.False(t, handled)
+	require.ErrorIs(t, err, rate.ErrRetryElsewhere)
+
+	var out bool
+	handled, err = srv.ForwardRPC("KVS.Apply", &structs.KVSRequest{
+		Datacenter: "dc1",
+		Op:         api.KVSet,
+		DirEnt:     structs.DirEntry{Key: "foo"},
+	}, &out)
+	require.False(t, handled)
+	require.NoError(t, err)
+}
+
+func TestServer_ResolveAccessorID(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	_, s1, codec := testACLServerWithConfig(t, nil, false)
+	waitForLeaderEstablishment(t, s1)
+
+	// The secondary datacenter doesn't replicate the tokens, so they are
+	// resolved remotely.
+	_, s2, _ := testACLServerWithConfig(t, func(c *Config) {
+		c.Datacenter = "dc2"
+		c.PrimaryDatacenter = "dc1"
+	}, false)
+	joinWAN(t, s2, s1)
+	testrpc.WaitForLeader(t, s1.RPC, "dc2")
+
+	token, err := upsertTestToken(codec, TestDefaultInitialManagementToken, "dc1", nil)
+	require.NoError(t, err)
+
+	for _, srv := range []*Server{s1, s2} {
+		require.Equal(t, token.AccessorID, srv.ResolveAccessorID(token.SecretID))
+		require.Equal(t, acl.AnonymousTokenID, srv.ResolveAccessorID(""))
+		require.Equal(t, acl.AnonymousTokenID, srv.ResolveAccessorID("4c3b9fa4-2dcc-4ab1-9e1e-9f2a5e5b1c2d"))
+	}
+
+	// The token resolved remotely is cached for the endpoint.
+	entry := s2.ACLResolver.cache.GetIdentityWithSecretToken(token.SecretID)
+	require.NotNil(t, entry)
+	require.Equal(t, token.AccessorID, entry.Identity.ID())
+
+	_, noACLs := testServer(t)
+	require.Empty(t, noACLs.ResolveAccessorID(token.SecretID))
+}
+
+func TestServer_IsServerAddr(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	_, srv := testServer(t)
+	testrpc.WaitForLeader(t, srv.RPC, "dc1")
+
+	retry.Run(t, func(r *retry.R) {
+		addr := &net.TCPAddr{IP: srv.config.RPCAdvertise.IP, Port: 54321}
+		if !srv.IsServerAddr(addr) {
+			r.Fatalf("expected %s to be a server address", addr)
+		}
+	})
+	require.False(t, srv.IsServerAddr(&net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 54321}))
+}
+
 func TestRPC_MagicByteTimeout(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -175,6 +175,9 @@ type Server struct {
 
 	aclAuthMethodValidators authmethod.Cache
 
+	// aclTokenUsage batches the token usage reported by agents.
+	aclTokenUsage *aclTokenUsage
+
 	// autopilot is the Autopilot instance for this server.
 	autopilot *autopilot.Autopilot
 
@@ -257,6 +260,11 @@ type Server struct {
 	// barrier. This is updated atomically.
 	readyForConsistentReads int32
 
+	// leaderLease allows the leader to serve consistent reads without
+	// verifying its leadership for each read, in the lease consistent read
+	// mode.
+	leaderLease leaderLease
+
 	// leaveCh is used to signal that the server is leaving the cluster
 	// and trying to shed its RPC traffic onto other Consul servers. This
 	// is only ever closed.
@@ -295,6 +303,10 @@ type Server struct {
 	// incomingRPCLimiter rate-limits incoming net/rpc and gRPC calls.
 	incomingRPCLimiter rpcRate.RequestLimitsHandler
 
+	// leaderHealth measures the health signals the adaptive write limit of
+	// incomingRPCLimiter reacts to.
+	leaderHealth *leaderHealth
+
 	// insecureRPCServer is a RPC server that is configure with
 	// IncomingInsecureRPCConfig to allow clients to call AutoEncrypt.Sign
 	// to request client certificates. At this point a client doesn't have
@@ -442,12 +454,14 @@ func NewServer(config *Config, flat Deps, externalGRPCServer *grpc.Server, incom
 
 	loggers := newLoggerStore(serverLogger)
 
+	health := &leaderHealth{}
 	fsmDeps := fsm.Deps{
 		Logger: flat.Logger,
 		NewStateStore: func() *state.Store {
 			return state.NewStateStoreWithEventPublisher(gc, flat.EventPublisher)
 		},
-		Publisher: flat.EventPublisher,
+		Publisher:    flat.EventPublisher,
+		ObserveApply: health.fsmApplyTime.observe,
 	}
 
 	if incomingRPCLimiter == nil {
@@ -479,6 +493,7 @@ func NewServer(config *Config, flat Deps, externalGRPCServer *grpc.Server, incom
 		fsm:                     fsm.NewFromDeps(fsmDeps),
 		publisher:               flat.EventPublisher,
 		incomingRPCLimiter:      incomingRPCLimiter,
+		leaderHealth:            health,
 	}
 
 	incomingRPCLimiter.Register(s)
@@ -515,6 +530,11 @@ func NewServer(config *Config, flat Deps, externalGRPCServer *grpc.Server, incom
 
 	go s.publisher.Run(&lib.StopChannelContext{StopCh: s.shutdownCh})
 
+	s.aclTokenUsage = newACLTokenUsage(s)
+	if s.config.ACLsEnabled {
+		go s.aclTokenUsage.batchUpdate()
+	}
+
 	if s.config.ConnectMeshGatewayWANFederationEnabled {
 		s.gatewayLocator = NewGatewayLocator(
 			s.logger,
@@ -1288,6 +1308,11 @@ func (s *Server) Shutdown() error {
 }
 
 func (s *Server) attemptLeadershipTransfer(id raft.ServerID) (err error) {
+	// The new leader is elected without waiting for the heartbeat timeout of
+	// the followers, which the leader lease relies on.
+	resume := s.leaderLease.suspend()
+	defer resume()
+
 	var addr raft.ServerAddress
 	if id != "" {
 		addr, err = s.serverLookup.ServerAddr(id)
@@ -1524,6 +1549,28 @@ func (s *Server) IsLeader() bool {
 	return s.raft.State() == raft.Leader
 }
 
+// IsServerAddr checks if the given address is the address of a server of the
+// local datacenter, ignoring its port.
+func (s *Server) IsServerAddr(addr net.Addr) bool {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	found := false
+	s.serverLookup.CheckServers(func(srv *metadata.Server) bool {
+		if tcp, ok := srv.Addr.(*net.TCPAddr); ok && tcp.IP.Equal(ip) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
 // LeaderLastContact returns the time of last contact by a leader.
 // This only makes sense if we are currently a follower.
 func (s *Server) LeaderLastContact() time.Time {
@@ -1922,6 +1969,16 @@ func ConfiguredIncomingRPCLimiter(ctx context.Context, serverLogger hclog.Interc
 		Mode:      rpcRate.RequestLimitsModeFromNameWithDefault(consulCfg.RequestLimitsMode),
 		ReadRate:  consulCfg.RequestLimitsReadRate,
 		WriteRate: consulCfg.RequestLimitsWriteRate,
+
+		IPMode:      rpcRate.RequestLimitsModeFromNameWithDefault(consulCfg.RequestLimitsIPMode),
+		IPReadRate:  consulCfg.RequestLimitsIPReadRate,
+		IPWriteRate: consulCfg.RequestLimitsIPWriteRate,
+
+		AccessorMode:      rpcRate.RequestLimitsModeFromNameWithDefault(consulCfg.RequestLimitsAccessorMode),
+		AccessorReadRate:  consulCfg.RequestLimitsAccessorReadRate,
+		AccessorWriteRate: consulCfg.RequestLimitsAccessorWriteRate,
+
+		Adaptive: consulCfg.RequestLimitsAdaptive,
 	}
 
 	sink := logdrop.NewLogDropSink(ctx, 100, serverLogger.Named("rpc-rate-limit"), func(l logdrop.Log) {
@@ -1946,6 +2003,25 @@ func convertConsulConfigToRateLimitHandlerConfig(limitsConfig RequestLimits, mul
 			Rate:  limitsConfig.WriteRate,
 			Burst: int(limitsConfig.WriteRate) * requestLimitsBurstMultiplier,
 		},
+		IPMode: limitsConfig.IPMode,
+		IPReadConfig: multilimiter.LimiterConfig{
+			Rate:  limitsConfig.IPReadRate,
+			Burst: int(limitsConfig.IPReadRate) * requestLimitsBurstMultiplier,
+		},
+		IPWriteConfig: multilimiter.LimiterConfig{
+			Rate:  limitsConfig.IPWriteRate,
+			Burst: int(limitsConfig.IPWriteRate) * requestLimitsBurstMultiplier,
+		},
+		AccessorMode: limitsConfig.AccessorMode,
+		AccessorReadConfig: multilimiter.LimiterConfig{
+			Rate:  limitsConfig.AccessorReadRate,
+			Burst: int(limitsConfig.AccessorReadRate) * requestLimitsBurstMultiplier,
+		},
+		AccessorWriteConfig: multilimiter.LimiterConfig{
+			Rate:  limitsConfig.AccessorWriteRate,
+			Burst: int(limitsConfig.AccessorWriteRate) * requestLimitsBurstMultiplier,
+		},
+		Adaptive: limitsConfig.Adaptive,
 	}
 	if multilimiterConfig != nil {
 		hc.Config = *multilimiterConfig
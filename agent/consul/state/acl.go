@@ -267,6 +267,109 @@ func fixupTokenPolicyLinks(tx ReadTxn, original *structs.ACLToken) (*structs.ACL
 	return token, nil
 }
 
+// resolveTemplatedPolicyLinks checks that the policy templates linked by a
+// token or role exist and fills in their names.
+func resolveTemplatedPolicyLinks(tx ReadTxn, links structs.ACLTemplatedPolicies, entMeta *acl.EnterpriseMeta, allowMissing bool) (int, error) {
+	var numValid int
+	for _, link := range links {
+		if link.ID == "" {
+			return 0, fmt.Errorf("Encountered a templated policy linked by Name in the state store")
+		}
+
+		policy, err := getPolicyWithTxn(tx, nil, link.ID, aclPolicyGetByID, entMeta)
+		if err != nil {
+			return 0, err
+		}
+
+		if policy != nil {
+			if !policy.IsTemplate() {
+				return 0, fmt.Errorf("Policy with ID %s is not a template", link.ID)
+			}
+			// the name doesn't matter here
+			link.Name = policy.Name
+			numValid++
+		} else if !allowMissing {
+			return 0, fmt.Errorf("No such policy template with ID: %s", link.ID)
+		}
+	}
+	return numValid, nil
+}
+
+// fixupTemplatedPolicyLinks is used when retrieving tokens or roles from memdb.
+// The templated policy links could have gotten stale when a linked template was
+// deleted or renamed. Like fixupACLLinks it treats the links with copy-on-write
+// semantics and its output indicates whether any modifications were made.
+func fixupTemplatedPolicyLinks(tx ReadTxn, original structs.ACLTemplatedPolicies, entMeta *acl.EnterpriseMeta) (structs.ACLTemplatedPolicies, bool, error) {
+	owned := false
+	links := original
+
+	cloneLinks := func(l structs.ACLTemplatedPolicies, copyNumLinks int) structs.ACLTemplatedPolicies {
+		clone := make(structs.ACLTemplatedPolicies, copyNumLinks)
+		copy(clone, l[:copyNumLinks])
+		return clone
+	}
+
+	for linkIndex, link := range original {
+		if link.ID == "" {
+			return nil, false, fmt.Errorf("Detected corrupted templated policy within the state store - missing policy template link ID")
+		}
+
+		policy, err := getPolicyWithTxn(tx, nil, link.ID, aclPolicyGetByID, entMeta)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if policy == nil {
+			if !owned {
+				// clone the original as we cannot modify anything stored in memdb
+				links = cloneLinks(original, linkIndex)
+				owned = true
+			}
+			// if already owned then we just don't append it.
+		} else if policy.Name != link.Name {
+			if !owned {
+				links = cloneLinks(original, linkIndex)
+				owned = true
+			}
+
+			// append the corrected link
+			fixed := link.Clone()
+			fixed.Name = policy.Name
+			links = append(links, fixed)
+		} else if owned {
+			links = append(links, link)
+		}
+	}
+
+	return links, owned, nil
+}
+
+// fixupTokenTemplatedPolicyLinks is to be used when retrieving tokens from
+// memdb, see fixupTemplatedPolicyLinks.
+func fixupTokenTemplatedPolicyLinks(tx ReadTxn, original *structs.ACLToken) (*structs.ACLToken, error) {
+	links, owned, err := fixupTemplatedPolicyLinks(tx, original.TemplatedPolicies, &original.EnterpriseMeta)
+	if err != nil || !owned {
+		return original, err
+	}
+
+	token := *original
+	token.TemplatedPolicies = links
+	return &token, nil
+}
+
+// fixupRoleTemplatedPolicyLinks is to be used when retrieving roles from memdb,
+// see fixupTemplatedPolicyLinks.
+func fixupRoleTemplatedPolicyLinks(tx ReadTxn, original *structs.ACLRole) (*structs.ACLRole, error) {
+	links, owned, err := fixupTemplatedPolicyLinks(tx, original.TemplatedPolicies, &original.EnterpriseMeta)
+	if err != nil || !owned {
+		return original, err
+	}
+
+	role := *original
+	role.TemplatedPolicies = links
+	return &role, nil
+}
+
 func resolveTokenRoleLinks(tx ReadTxn, token *structs.ACLToken, allowMissing bool) (int, error) {
 	var numValid int
 	for linkIndex, link := range token.Roles {
@@ -423,6 +526,7 @@ type ACLTokenSetOptions struct {
 	AllowMissingPolicyAndRoleIDs bool
 	ProhibitUnprivileged         bool
 	FromReplication              bool
+	Rotate                       bool
 }
 
 func (s *Store) ACLTokenBatchSet(idx uint64, tokens structs.ACLTokens, opts ACLTokenSetOptions) error {
@@ -455,6 +559,10 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 		return fmt.Errorf("Cannot replicate local tokens")
 	}
 
+	if token.PreviousSecretID != "" && token.PreviousSecretID == token.SecretID {
+		return fmt.Errorf("The ACL Token PreviousSecretID must differ from the SecretID")
+	}
+
 	// Check for an existing ACL
 	_, existing, err := aclTokenGetFromIndex(tx, token.AccessorID, indexAccessor, nil)
 	if err != nil {
@@ -496,6 +604,11 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 		return err
 	}
 
+	var numValidTemplatedPolicies int
+	if numValidTemplatedPolicies, err = resolveTemplatedPolicyLinks(tx, token.TemplatedPolicies, &token.EnterpriseMeta, opts.AllowMissingPolicyAndRoleIDs); err != nil {
+		return err
+	}
+
 	if token.AuthMethod != "" && !opts.FromReplication {
 		methodMeta := token.ACLAuthMethodEnterpriseMeta.ToEnterpriseMeta()
 		methodMeta.Merge(&token.EnterpriseMeta)
@@ -523,7 +636,7 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 	}
 
 	if opts.ProhibitUnprivileged {
-		if numValidRoles == 0 && numValidPolicies == 0 && len(token.ServiceIdentities) == 0 && len(token.NodeIdentities) == 0 {
+		if numValidRoles == 0 && numValidPolicies == 0 && numValidTemplatedPolicies == 0 && len(token.ServiceIdentities) == 0 && len(token.NodeIdentities) == 0 {
 			return ErrTokenHasNoPrivileges
 		}
 	}
@@ -534,15 +647,30 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 			return fmt.Errorf("The ACL Token AccessorID field is immutable")
 		}
 
+		// Rotating a token changes its SecretID, which is otherwise immutable.
+		// Replicated tokens may have been rotated in the primary datacenter.
 		if token.SecretID != original.SecretID {
-			return fmt.Errorf("The ACL Token SecretID field is immutable")
+			if !opts.Rotate && !opts.FromReplication {
+				return fmt.Errorf("The ACL Token SecretID field is immutable")
+			}
+
+			// The SecretID is the primary key, so the token has to be
+			// removed and inserted again rather than updated in place.
+			if err := aclTokenDeleteWithToken(tx, original, idx); err != nil {
+				return err
+			}
 		}
 
 		token.CreateIndex = original.CreateIndex
 		token.ModifyIndex = idx
+
+		// Usage is only ever updated by ACLTokenUsageBatchUpdate, so it
+		// survives any changes to the token, including replicated ones.
+		token.Usage = original.Usage
 	} else {
 		token.CreateIndex = idx
 		token.ModifyIndex = idx
+		token.Usage = structs.ACLTokenUsage{}
 	}
 
 	// ensure that a hash is set
@@ -551,9 +679,69 @@ func aclTokenSetTxn(tx WriteTxn, idx uint64, token *structs.ACLToken, opts ACLTo
 	return aclTokenInsert(tx, token)
 }
 
+// ACLTokenUsageBatchUpdate records the latest usage of the given tokens. This
+// deliberately leaves the tokens' modify index alone, since usage isn't part
+// of a token's contents and shouldn't trigger replication or CAS failures.
+// Updates for tokens that no longer exist, or that are older than the usage
+// already recorded, are ignored.
+func (s *Store) ACLTokenUsageBatchUpdate(idx uint64, updates []*structs.ACLTokenUsageUpdate) error {
+	tx := s.db.WriteTxn(idx)
+	defer tx.Abort()
+
+	for _, update := range updates {
+		if update.Usage.LastUsedTime == nil {
+			continue
+		}
+
+		_, existing, err := aclTokenGetFromIndex(tx, update.AccessorID, indexAccessor, &update.EnterpriseMeta)
+		if err != nil {
+			return fmt.Errorf("failed token lookup: %s", err)
+		}
+		if existing == nil {
+			continue
+		}
+
+		original := existing.(*structs.ACLToken)
+		if last := original.Usage.LastUsedTime; last != nil && !update.Usage.LastUsedTime.After(*last) {
+			continue
+		}
+
+		token := original.Clone()
+		token.Usage = update.Usage
+		if err := tx.Insert(tableACLTokens, token); err != nil {
+			return fmt.Errorf("failed inserting acl token: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // ACLTokenGetBySecret is used to look up an existing ACL token by its SecretID.
+//
+// The previous SecretID of a rotated token is accepted as well. In that case
+// the token is returned as seen by holders of the previous secret, with the
+// end of the grace period as its expiration time. Callers already reject
+// expired tokens, which takes care of secrets whose grace period ended.
 func (s *Store) ACLTokenGetBySecret(ws memdb.WatchSet, secret string, entMeta *acl.EnterpriseMeta) (uint64, *structs.ACLToken, error) {
-	return s.aclTokenGet(ws, secret, indexID, entMeta)
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	token, err := aclTokenGetTxn(tx, ws, secret, indexID, entMeta)
+	if err != nil {
+		return 0, nil, err
+	}
+	if token == nil {
+		token, err = aclTokenGetTxn(tx, ws, secret, indexPreviousSecret, entMeta)
+		if err != nil {
+			return 0, nil, err
+		}
+		if token != nil {
+			token = token.PreviousSecretView()
+		}
+	}
+
+	idx := aclTokenMaxIndex(tx, token, entMeta)
+	return idx, token, nil
 }
 
 // ACLTokenGetByAccessor is used to look up an existing ACL token by its AccessorID.
@@ -614,6 +802,10 @@ func aclTokenGetTxn(tx ReadTxn, ws memdb.WatchSet, value, index string, entMeta
 		if err != nil {
 			return nil, err
 		}
+		token, err = fixupTokenTemplatedPolicyLinks(tx, token)
+		if err != nil {
+			return nil, err
+		}
 		return token, nil
 	}
 
@@ -691,6 +883,10 @@ func (s *Store) ACLTokenList(ws memdb.WatchSet, local, global bool, policy, role
 		if err != nil {
 			return 0, nil, err
 		}
+		token, err = fixupTokenTemplatedPolicyLinks(tx, token)
+		if err != nil {
+			return 0, nil, err
+		}
 		result = append(result, token)
 	}
 
@@ -748,6 +944,30 @@ func (s *Store) ACLTokenListExpired(local bool, asOf time.Time, max int) (struct
 	return tokens, iter.WatchCh(), nil
 }
 
+// ACLTokenListExpiredPreviousSecrets lists rotated tokens whose previous
+// SecretID stopped being valid as of the provided time. The returned set will
+// be no larger than the max value provided.
+func (s *Store) ACLTokenListExpiredPreviousSecrets(local bool, asOf time.Time, max int) (structs.ACLTokens, error) {
+	tx := s.db.Txn(false)
+	defer tx.Abort()
+
+	iter, err := tx.Get(tableACLTokens, indexPreviousSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed acl token listing: %v", err)
+	}
+
+	var tokens structs.ACLTokens
+	for raw := iter.Next(); raw != nil && len(tokens) < max; raw = iter.Next() {
+		token := raw.(*structs.ACLToken)
+		if token.Local != local || token.HasPreviousSecret(asOf) {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
 func (s *Store) expiresIndexName(local bool) string {
 	if local {
 		return indexExpiresLocal
@@ -1124,6 +1344,10 @@ func aclRoleSetTxn(tx WriteTxn, idx uint64, role *structs.ACLRole, allowMissing
 		return err
 	}
 
+	if _, err := resolveTemplatedPolicyLinks(tx, role.TemplatedPolicies, &role.EnterpriseMeta, allowMissing); err != nil {
+		return err
+	}
+
 	for _, svcid := range role.ServiceIdentities {
 		if svcid.ServiceName == "" {
 			return fmt.Errorf("Encountered a Role with an empty service identity name in the state store")
@@ -1208,6 +1432,10 @@ func getRoleWithTxn(tx ReadTxn, ws memdb.WatchSet, value string, fn aclRoleGetFn
 		if err != nil {
 			return nil, err
 		}
+		role, err = fixupRoleTemplatedPolicyLinks(tx, role)
+		if err != nil {
+			return nil, err
+		}
 		return role, nil
 	}
 
@@ -1259,6 +1487,10 @@ func (s *Store) ACLRoleList(ws memdb.WatchSet, policy string, entMeta *acl.Enter
 		if err != nil {
 			return 0, nil, err
 		}
+		role, err = fixupRoleTemplatedPolicyLinks(tx, role)
+		if err != nil {
+			return 0, nil, err
+		}
 		result = append(result, role)
 	}
 
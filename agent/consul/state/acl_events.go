@@ -18,6 +18,17 @@ func aclChangeUnsubscribeEvent(tx ReadTxn, changes Changes) ([]stream.Event, err
 		switch change.Table {
 		case tableACLTokens:
 			token := changeObject(change).(*structs.ACLToken)
+
+			// Usage updates leave the modify index alone and don't change
+			// what the token is allowed to do, so there's no need to
+			// unsubscribe anything. Any other write copies the usage of the
+			// token as-is.
+			if change.Updated() {
+				before := change.Before.(*structs.ACLToken)
+				if before.ModifyIndex == token.ModifyIndex && before.Usage != token.Usage {
+					continue
+				}
+			}
 			secretIDs = append(secretIDs, token.SecretID)
 
 		case tableACLRoles:
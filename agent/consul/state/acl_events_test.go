@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/consul/agent/consul/stream"
 	"github.com/hashicorp/consul/agent/structs"
@@ -38,6 +39,33 @@ func TestACLChangeUnsubscribeEvent(t *testing.T) {
 			},
 			expected: stream.NewCloseSubscriptionEvent(newSecretIDs(1)),
 		},
+		{
+			Name: "token update at the same index",
+			Setup: func(tx *txn) error {
+				return aclTokenSetTxn(tx, 100, newACLToken(1), ACLTokenSetOptions{})
+			},
+			Mutate: func(tx *txn) error {
+				return aclTokenSetTxn(tx, 100, newACLToken(1), ACLTokenSetOptions{})
+			},
+			expected: stream.NewCloseSubscriptionEvent(newSecretIDs(1)),
+		},
+		{
+			Name: "token usage update",
+			Setup: func(tx *txn) error {
+				return aclTokenSetTxn(tx, tx.Index, newACLToken(1), ACLTokenSetOptions{})
+			},
+			Mutate: func(tx *txn) error {
+				_, existing, err := aclTokenGetFromIndex(tx, newACLToken(1).AccessorID, indexAccessor, nil)
+				if err != nil {
+					return err
+				}
+				token := existing.(*structs.ACLToken).Clone()
+				now := time.Now()
+				token.Usage = structs.ACLTokenUsage{LastUsedTime: &now, Agent: "node-1"}
+				return tx.Insert(tableACLTokens, token)
+			},
+			expected: stream.NewCloseSubscriptionEvent(nil),
+		},
 		{
 			Name: "token delete",
 			Setup: func(tx *txn) error {
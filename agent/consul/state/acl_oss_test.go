@@ -96,6 +96,28 @@ func testIndexerTableACLTokens() map[string]indexerTestCase {
 				expected: []byte("test-auth-method\x00"),
 			},
 		},
+		indexPreviousSecret: {
+			read: indexValue{
+				source:   "123e4567-e89a-12d7-a456-426614174abe",
+				expected: []byte("123e4567-e89a-12d7-a456-426614174abe\x00"),
+			},
+			write: indexValue{
+				source: &structs.ACLToken{
+					AccessorID:       "123e4567-e89a-12d7-a456-426614174abc",
+					SecretID:         "123e4567-e89a-12d7-a456-426614174abd",
+					PreviousSecretID: "123e4567-e89a-12d7-a456-426614174abe",
+				},
+				expected: []byte("123e4567-e89a-12d7-a456-426614174abe\x00"),
+			},
+			extra: []indexerTestCase{
+				{
+					write: indexValue{
+						source:               obj,
+						expectedIndexMissing: true,
+					},
+				},
+			},
+		},
 	}
 }
 
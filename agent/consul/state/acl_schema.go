@@ -24,6 +24,8 @@ const (
 	indexName          = "name"
 	indexExpiresGlobal = "expires-global"
 	indexExpiresLocal  = "expires-local"
+
+	indexPreviousSecret = "previous-secret"
 )
 
 func tokensTableSchema() *memdb.TableSchema {
@@ -48,6 +50,15 @@ func tokensTableSchema() *memdb.TableSchema {
 					writeIndex: indexSecretIDFromACLToken,
 				},
 			},
+			indexPreviousSecret: {
+				Name:         indexPreviousSecret,
+				AllowMissing: true,
+				Unique:       true,
+				Indexer: indexerSingle[string, *structs.ACLToken]{
+					readIndex:  indexFromStringCaseSensitive,
+					writeIndex: indexPreviousSecretIDFromACLToken,
+				},
+			},
 			indexPolicies: {
 				Name: indexPolicies,
 				// Need to allow missing for the anonymous token
@@ -305,6 +316,16 @@ func indexSecretIDFromACLToken(t *structs.ACLToken) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
+func indexPreviousSecretIDFromACLToken(t *structs.ACLToken) ([]byte, error) {
+	if t.PreviousSecretID == "" {
+		return nil, errMissingValueForIndex
+	}
+
+	var b indexBuilder
+	b.String(t.PreviousSecretID)
+	return b.Bytes(), nil
+}
+
 func indexFromStringCaseSensitive(s string) ([]byte, error) {
 	var b indexBuilder
 	b.String(s)
@@ -1279,6 +1279,80 @@ func TestStateStore_ACLToken_FixupRoleLinks(t *testing.T) {
 	require.True(t, found)
 }
 
+func TestStateStore_ACLToken_FixupTemplatedPolicyLinks(t *testing.T) {
+	t.Parallel()
+	s := testACLTokensStateStore(t)
+
+	template := &structs.ACLPolicy{
+		ID:                "0f2b4c9e-7d3a-4c36-a8b0-4a6b9d8e1f21",
+		Name:              "kv-app",
+		Rules:             `key_prefix "${var.prefix}" { policy = "write" }`,
+		TemplateVariables: []string{"prefix"},
+	}
+	template.SetHash(true)
+	require.NoError(t, s.ACLPolicySet(2, template))
+
+	t.Run("Not a template", func(t *testing.T) {
+		token := &structs.ACLToken{
+			AccessorID: "e1d6a2d4-8e8b-4ee1-a6e7-8c2a5e4b7e11",
+			SecretID:   "7c1b3f49-6c3d-4c4e-9a49-8c7a2b1f0d22",
+			TemplatedPolicies: []*structs.ACLTemplatedPolicy{
+				{ID: testPolicyID_A},
+			},
+		}
+		require.ErrorContains(t, s.ACLTokenSet(3, token), "is not a template")
+	})
+
+	token := &structs.ACLToken{
+		AccessorID: "47eea4da-bda1-48a6-901c-3e36d2d9262f",
+		SecretID:   "548bdb8e-c0d6-477b-bcc4-67fb836e9e61",
+		TemplatedPolicies: []*structs.ACLTemplatedPolicy{
+			{ID: template.ID, Variables: map[string]string{"prefix": "web"}},
+		},
+	}
+	require.NoError(t, s.ACLTokenSet(3, token))
+
+	_, retrieved, err := s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	// pointer equality check these should be identical
+	require.True(t, token == retrieved)
+	require.Len(t, retrieved.TemplatedPolicies, 1)
+	require.Equal(t, "kv-app", retrieved.TemplatedPolicies[0].Name)
+
+	// rename the template
+	renamed := template.Clone()
+	renamed.Name = "kv-app-renamed"
+	renamed.SetHash(true)
+	require.NoError(t, s.ACLPolicySet(4, renamed))
+
+	_, retrieved, err = s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	// pointer equality check these should be different if we cloned things appropriately
+	require.True(t, token != retrieved)
+	require.Len(t, retrieved.TemplatedPolicies, 1)
+	require.Equal(t, "kv-app-renamed", retrieved.TemplatedPolicies[0].Name)
+	require.Equal(t, map[string]string{"prefix": "web"}, retrieved.TemplatedPolicies[0].Variables)
+	// the link tracked by memdb must not have been modified
+	require.Equal(t, "kv-app", token.TemplatedPolicies[0].Name)
+
+	// delete the template
+	require.NoError(t, s.ACLPolicyDeleteByID(5, template.ID, nil))
+
+	_, tokens, err := s.ACLTokenList(nil, true, true, "", "", "", nil, nil)
+	require.NoError(t, err)
+
+	found := false
+	for _, tok := range tokens {
+		if tok.AccessorID == token.AccessorID {
+			require.True(t, tok != token)
+			require.Len(t, tok.TemplatedPolicies, 0)
+			found = true
+			break
+		}
+	}
+	require.True(t, found)
+}
+
 func TestStateStore_ACLToken_Delete(t *testing.T) {
 	t.Parallel()
 
@@ -1374,6 +1448,131 @@ func TestStateStore_ACLToken_Delete(t *testing.T) {
 	})
 }
 
+func TestStateStore_ACLToken_Rotate(t *testing.T) {
+	t.Parallel()
+	s := testACLTokensStateStore(t)
+
+	token := &structs.ACLToken{
+		AccessorID: "f1093997-b6c7-496d-bfb8-6b1b1895641b",
+		SecretID:   "34ec8eb3-095d-417a-a937-b439af7a8e8b",
+		Policies: []structs.ACLTokenPolicyLink{
+			{
+				ID: structs.ACLPolicyGlobalManagementID,
+			},
+		},
+	}
+	require.NoError(t, s.ACLTokenSet(2, token.Clone()))
+
+	graceExpirationTime := time.Now().Add(time.Hour)
+	rotated := token.Clone()
+	rotated.SecretID = "be444e46-fb95-4ccc-80d5-c873f34e6fa6"
+	rotated.PreviousSecretID = token.SecretID
+	rotated.PreviousSecretExpirationTime = &graceExpirationTime
+
+	// The SecretID can only be changed when rotating.
+	err := s.ACLTokenBatchSet(3, structs.ACLTokens{rotated.Clone()}, ACLTokenSetOptions{})
+	require.ErrorContains(t, err, "SecretID field is immutable")
+
+	require.NoError(t, s.ACLTokenBatchSet(3, structs.ACLTokens{rotated.Clone()}, ACLTokenSetOptions{Rotate: true}))
+
+	idx, rtoken, err := s.ACLTokenGetBySecret(nil, rotated.SecretID, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), idx)
+	require.Equal(t, token.AccessorID, rtoken.AccessorID)
+	require.Equal(t, token.SecretID, rtoken.PreviousSecretID)
+	require.Nil(t, rtoken.ExpirationTime)
+
+	// The previous secret returns the token without the new secret.
+	_, rtoken, err = s.ACLTokenGetBySecret(nil, token.SecretID, nil)
+	require.NoError(t, err)
+	require.Equal(t, token.AccessorID, rtoken.AccessorID)
+	require.Equal(t, token.SecretID, rtoken.SecretID)
+	require.Empty(t, rtoken.PreviousSecretID)
+	require.Equal(t, graceExpirationTime, *rtoken.ExpirationTime)
+
+	// The token was replaced rather than inserted again, only it and the
+	// anonymous token are listed.
+	_, tokens, err := s.ACLTokenList(nil, true, true, "", "", "", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+
+	expired, err := s.ACLTokenListExpiredPreviousSecrets(false, time.Now(), 10)
+	require.NoError(t, err)
+	require.Empty(t, expired)
+
+	expired, err = s.ACLTokenListExpiredPreviousSecrets(false, graceExpirationTime.Add(time.Second), 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	require.Equal(t, token.AccessorID, expired[0].AccessorID)
+
+	expired, err = s.ACLTokenListExpiredPreviousSecrets(true, graceExpirationTime.Add(time.Second), 10)
+	require.NoError(t, err)
+	require.Empty(t, expired)
+}
+
+func TestStateStore_ACLToken_UsageBatchUpdate(t *testing.T) {
+	t.Parallel()
+	s := testACLTokensStateStore(t)
+
+	token := &structs.ACLToken{
+		AccessorID: "f1093997-b6c7-496d-bfb8-6b1b1895641b",
+		SecretID:   "34ec8eb3-095d-417a-a937-b439af7a8e8b",
+		Policies: []structs.ACLTokenPolicyLink{
+			{
+				ID: structs.ACLPolicyGlobalManagementID,
+			},
+		},
+	}
+	require.NoError(t, s.ACLTokenSet(2, token.Clone()))
+
+	used := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	update := func(idx uint64, accessorID string, lastUsed time.Time, addr string) {
+		t.Helper()
+		require.NoError(t, s.ACLTokenUsageBatchUpdate(idx, []*structs.ACLTokenUsageUpdate{
+			{
+				AccessorID: accessorID,
+				Usage: structs.ACLTokenUsage{
+					LastUsedTime: &lastUsed,
+					SourceAddr:   addr,
+					Agent:        "node-1",
+				},
+			},
+		}))
+	}
+
+	update(3, token.AccessorID, used, "10.0.0.1")
+
+	idx, rtoken, err := s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), idx)
+	require.Equal(t, uint64(2), rtoken.ModifyIndex)
+	require.Equal(t, used, *rtoken.Usage.LastUsedTime)
+	require.Equal(t, "10.0.0.1", rtoken.Usage.SourceAddr)
+	require.Equal(t, "node-1", rtoken.Usage.Agent)
+
+	// Older usage is ignored.
+	update(4, token.AccessorID, used.Add(-time.Hour), "10.0.0.2")
+	_, rtoken, err = s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1", rtoken.Usage.SourceAddr)
+
+	// Usage of unknown tokens is ignored.
+	update(5, "a0bfe8d4-b2f3-4b48-b387-f28afb820eab", used, "10.0.0.3")
+	_, rtoken, err = s.ACLTokenGetByAccessor(nil, "a0bfe8d4-b2f3-4b48-b387-f28afb820eab", nil)
+	require.NoError(t, err)
+	require.Nil(t, rtoken)
+
+	// Updating the token keeps the recorded usage.
+	token.Description = "updated"
+	require.NoError(t, s.ACLTokenSet(6, token.Clone()))
+	_, rtoken, err = s.ACLTokenGetByAccessor(nil, token.AccessorID, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), rtoken.ModifyIndex)
+	require.Equal(t, "updated", rtoken.Description)
+	require.Equal(t, used, *rtoken.Usage.LastUsedTime)
+	require.Equal(t, "10.0.0.1", rtoken.Usage.SourceAddr)
+}
+
 func TestStateStore_ACLPolicy_SetGet(t *testing.T) {
 	t.Parallel()
 
@@ -8,6 +8,7 @@ import (
 	"math"
 	"net"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -35,6 +36,10 @@ var DNSCounters = []prometheus.CounterDefinition{
 		Name: []string{"dns", "stale_queries"},
 		Help: "Increments when an agent serves a query within the allowed stale threshold.",
 	},
+	{
+		Name: []string{"dns", "rate_limit", "exceeded"},
+		Help: "Increments whenever a DNS query is over a configured rate limit. The action label is the action taken: allow (in permissive mode), drop or truncate.",
+	},
 }
 
 var DNSSummaries = []prometheus.SummaryDefinition{
@@ -100,10 +105,128 @@ type dnsConfig struct {
 	// TTLStict sets TTLs to service by full name match. It Has higher priority than TTLRadix
 	TTLStrict          map[string]time.Duration
 	DisableCompression bool
+	// Views are matched in order against the client source address.
+	Views     []*dnsView
+	RateLimit dnsRateLimitConfig
+	// Filters are the named service filters, keyed by lower case name.
+	Filters map[string]string
 
 	enterpriseDNSConfig
 }
 
+// dnsView controls how answers are presented to clients whose source address
+// falls within one of its networks. A nil *dnsView is valid and applies no
+// changes.
+type dnsView struct {
+	Name          string
+	SourceCIDRs   []*net.IPNet
+	TaggedAddress string
+	Datacenters   map[string]struct{}
+	Peers         map[string]struct{}
+	Rewrites      map[string]string
+}
+
+func newDNSView(conf config.RuntimeDNSView) *dnsView {
+	v := &dnsView{
+		Name:          conf.Name,
+		SourceCIDRs:   conf.SourceCIDRs,
+		TaggedAddress: conf.TaggedAddress,
+		Rewrites:      conf.Rewrites,
+	}
+	if len(conf.Datacenters) > 0 {
+		v.Datacenters = make(map[string]struct{}, len(conf.Datacenters))
+		for _, dc := range conf.Datacenters {
+			v.Datacenters[strings.ToLower(dc)] = struct{}{}
+		}
+	}
+	if len(conf.Peers) > 0 {
+		v.Peers = make(map[string]struct{}, len(conf.Peers))
+		for _, peer := range conf.Peers {
+			v.Peers[strings.ToLower(peer)] = struct{}{}
+		}
+	}
+	return v
+}
+
+// viewForSource returns the first view matching the given client address, or
+// nil if no view matches.
+func (cfg *dnsConfig) viewForSource(ip net.IP) *dnsView {
+	if ip == nil {
+		return nil
+	}
+	for _, v := range cfg.Views {
+		for _, n := range v.SourceCIDRs {
+			if n.Contains(ip) {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// datacenterVisible returns true if records from the datacenter may be
+// returned through the view.
+func (v *dnsView) datacenterVisible(dc string) bool {
+	if v == nil || v.Datacenters == nil || dc == "" {
+		return true
+	}
+	_, ok := v.Datacenters[strings.ToLower(dc)]
+	return ok
+}
+
+// peerVisible returns true if records from the peer may be returned through
+// the view.
+func (v *dnsView) peerVisible(peer string) bool {
+	if v == nil || v.Peers == nil || peer == "" {
+		return true
+	}
+	_, ok := v.Peers[strings.ToLower(peer)]
+	return ok
+}
+
+// nodeAddress returns the view's tagged address of a node if it has one, or
+// addr otherwise. Rewrites are applied to the result.
+func (v *dnsView) nodeAddress(addr string, taggedAddresses map[string]string) string {
+	if v == nil {
+		return addr
+	}
+	if tagged, ok := taggedAddresses[v.TaggedAddress]; ok && v.TaggedAddress != "" && tagged != "" {
+		addr = tagged
+	}
+	return v.rewrite(addr)
+}
+
+// serviceAddress returns the view's tagged address of a service if it has
+// one, or addr otherwise. Rewrites are applied to the result.
+func (v *dnsView) serviceAddress(addr string, taggedAddresses map[string]structs.ServiceAddress) string {
+	if v == nil {
+		return addr
+	}
+	if tagged, ok := taggedAddresses[v.TaggedAddress]; ok && v.TaggedAddress != "" && tagged.Address != "" {
+		addr = tagged.Address
+	}
+	return v.rewrite(addr)
+}
+
+// servicePort returns the port of the view's tagged address of a service if
+// it has one, or port otherwise.
+func (v *dnsView) servicePort(port int, taggedAddresses map[string]structs.ServiceAddress) int {
+	if v == nil || v.TaggedAddress == "" {
+		return port
+	}
+	if tagged, ok := taggedAddresses[v.TaggedAddress]; ok && tagged.Port != 0 {
+		return tagged.Port
+	}
+	return port
+}
+
+func (v *dnsView) rewrite(addr string) string {
+	if r, ok := v.Rewrites[addr]; ok && addr != "" {
+		return r
+	}
+	return addr
+}
+
 type serviceLookup struct {
 	PeerName          string
 	Datacenter        string
@@ -112,6 +235,10 @@ type serviceLookup struct {
 	MaxRecursionLevel int
 	Connect           bool
 	Ingress           bool
+	// Filter is a bexpr expression applied to the service instances.
+	Filter string
+	// View is the DNS view selected for the client, if any.
+	View *dnsView
 	acl.EnterpriseMeta
 }
 
@@ -121,6 +248,8 @@ type nodeLookup struct {
 	Node              string
 	Tag               string
 	MaxRecursionLevel int
+	// View is the DNS view selected for the client, if any.
+	View *dnsView
 	acl.EnterpriseMeta
 }
 
@@ -141,6 +270,9 @@ type DNSServer struct {
 	// the recursor handler is only enabled if recursors are configured. This flag is used during config hot-reloading
 	recursorEnabled uint32
 
+	// rateLimiter enforces the per-client and response rate limits.
+	rateLimiter *dnsRateLimiter
+
 	defaultEnterpriseMeta acl.EnterpriseMeta
 }
 
@@ -162,6 +294,9 @@ func NewDNSServer(a *Agent) (*DNSServer, error) {
 	}
 	srv.config.Store(cfg)
 
+	srv.rateLimiter = newDNSRateLimiter(defaultDNSRateLimiterConfig, cfg.RateLimit, srv.logger)
+	srv.rateLimiter.Run(&lib.StopChannelContext{StopCh: a.shutdownCh})
+
 	srv.mux.HandleFunc("arpa.", srv.handlePtr)
 	srv.mux.HandleFunc(srv.domain, srv.handleQuery)
 	// this is not an empty string check because NewDNSServer will have
@@ -203,6 +338,7 @@ func GetDNSConfig(conf *config.RuntimeConfig) (*dnsConfig, error) {
 			Refresh: conf.DNSSOA.Refresh,
 			Retry:   conf.DNSSOA.Retry,
 		},
+		RateLimit:           getDNSRateLimitConfig(conf),
 		enterpriseDNSConfig: getEnterpriseDNSConfig(conf),
 	}
 	if conf.DNSServiceTTL != nil {
@@ -226,6 +362,15 @@ func GetDNSConfig(conf *config.RuntimeConfig) (*dnsConfig, error) {
 		}
 		cfg.Recursors = append(cfg.Recursors, ra)
 	}
+	for _, v := range conf.DNSViews {
+		cfg.Views = append(cfg.Views, newDNSView(v))
+	}
+	if len(conf.DNSFilters) > 0 {
+		cfg.Filters = make(map[string]string, len(conf.DNSFilters))
+		for name, filter := range conf.DNSFilters {
+			cfg.Filters[strings.ToLower(name)] = filter
+		}
+	}
 
 	return cfg, nil
 }
@@ -286,6 +431,7 @@ func (d *DNSServer) ReloadConfig(newCfg *config.RuntimeConfig) error {
 	}
 	d.config.Store(cfg)
 	d.toggleRecursorHandlerFromConfig(cfg)
+	d.rateLimiter.updateConfig(cfg.RateLimit)
 	return nil
 }
 
@@ -393,6 +539,14 @@ func (d *DNSServer) handlePtr(resp dns.ResponseWriter, req *dns.Msg) {
 
 	cfg := d.config.Load().(*dnsConfig)
 
+	network := "udp"
+	if _, ok := resp.RemoteAddr().(*net.TCPAddr); ok {
+		network = "tcp"
+	}
+	if d.rateLimitedReply(d.rateLimiter.allowClient(cfg.RateLimit, network, remoteIP(resp.RemoteAddr())), resp, req) {
+		return
+	}
+
 	// Setup the message response
 	m := new(dns.Msg)
 	m.SetReply(req)
@@ -510,6 +664,12 @@ func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 
 	cfg := d.config.Load().(*dnsConfig)
 
+	// Check the client's query rate before doing any work on its behalf.
+	clientIP := remoteIP(resp.RemoteAddr())
+	if d.rateLimitedReply(d.rateLimiter.allowClient(cfg.RateLimit, network, clientIP), resp, req) {
+		return
+	}
+
 	// Setup the message response
 	m := new(dns.Msg)
 	m.SetReply(req)
@@ -549,6 +709,10 @@ func (d *DNSServer) handleQuery(resp dns.ResponseWriter, req *dns.Msg) {
 
 	d.trimDNSResponse(cfg, network, req, m)
 
+	if d.rateLimitedReply(d.rateLimiter.allowResponse(cfg.RateLimit, network, clientIP, m), resp, req) {
+		return
+	}
+
 	if err := resp.WriteMsg(m); err != nil {
 		d.logger.Warn("failed to respond", "error", err)
 	}
@@ -630,7 +794,7 @@ func (d *DNSServer) nameservers(questionName string, cfg *dnsConfig, maxRecursio
 		}
 		ns = append(ns, nsrr)
 
-		extra = append(extra, d.makeRecordFromNode(o.Node, dns.TypeANY, fqdn, cfg.NodeTTL, maxRecursionLevel)...)
+		extra = append(extra, d.makeRecordFromNode(o.Node, nil, dns.TypeANY, fqdn, cfg.NodeTTL, maxRecursionLevel)...)
 
 		// don't provide more than 3 servers
 		if len(ns) >= 3 {
@@ -734,8 +898,15 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, maxRecursi
 
 	// Split into the label parts
 	labels := dns.SplitDomainName(qName)
+	// Keep the original case labels for the parts that are case sensitive,
+	// such as service metadata. These share indexes with labels.
+	origLabels := dns.SplitDomainName(req.Question[0].Name)
 
 	cfg := d.config.Load().(*dnsConfig)
+	// The view is selected from the address of the connection rather than
+	// the EDNS client subnet, which is set by the client and would let it
+	// select any view.
+	view := cfg.viewForSource(remoteIP(remoteAddr))
 
 	var queryKind string
 	var queryParts []string
@@ -784,12 +955,16 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, maxRecursi
 			Connect:           false,
 			Ingress:           false,
 			MaxRecursionLevel: maxRecursionLevel,
+			View:              view,
 			EnterpriseMeta:    locality.EnterpriseMeta,
 		}
 		// Only one of dc or peer can be used.
 		if lookup.PeerName != "" {
 			lookup.Datacenter = ""
 		}
+		if !view.datacenterVisible(lookup.Datacenter) || !view.peerVisible(lookup.PeerName) {
+			return errNameNotFound
+		}
 
 		// Support RFC 2782 style syntax
 		if n == 2 && strings.HasPrefix(queryParts[1], "_") && strings.HasPrefix(queryParts[0], "_") {
@@ -808,6 +983,17 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, maxRecursi
 			return d.serviceLookup(cfg, lookup, req, resp)
 		}
 
+		// Filter on service metadata or a named filter from the config:
+		// <key>=<value>[.<key>=<value>].meta.name.service.consul
+		// <filter>.filter.name.service.consul
+		if n >= 3 {
+			if filter, ok := serviceFilter(cfg, queryParts[n-2], origLabels[:n-2]); ok {
+				lookup.Filter = filter
+				lookup.Service = queryParts[n-1]
+				return d.serviceLookup(cfg, lookup, req, resp)
+			}
+		}
+
 		// Consul 0.3 and prior format for SRV queries
 		// Support "." in the label, re-join all the parts
 		tag := ""
@@ -840,8 +1026,12 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, maxRecursi
 			Connect:           true,
 			Ingress:           false,
 			MaxRecursionLevel: maxRecursionLevel,
+			View:              view,
 			EnterpriseMeta:    locality.EnterpriseMeta,
 		}
+		if !view.datacenterVisible(lookup.Datacenter) {
+			return errNameNotFound
+		}
 		// name.connect.consul
 		return d.serviceLookup(cfg, lookup, req, resp)
 
@@ -907,8 +1097,12 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, maxRecursi
 			Connect:           false,
 			Ingress:           true,
 			MaxRecursionLevel: maxRecursionLevel,
+			View:              view,
 			EnterpriseMeta:    locality.EnterpriseMeta,
 		}
+		if !view.datacenterVisible(lookup.Datacenter) {
+			return errNameNotFound
+		}
 		// name.ingress.consul
 		return d.serviceLookup(cfg, lookup, req, resp)
 
@@ -936,12 +1130,16 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, maxRecursi
 			PeerName:          locality.peer,
 			Node:              node,
 			MaxRecursionLevel: maxRecursionLevel,
+			View:              view,
 			EnterpriseMeta:    locality.EnterpriseMeta,
 		}
 		// Only one of dc or peer can be used.
 		if lookup.PeerName != "" {
 			lookup.Datacenter = ""
 		}
+		if !view.datacenterVisible(lookup.Datacenter) || !view.peerVisible(lookup.PeerName) {
+			return errNameNotFound
+		}
 
 		return d.nodeLookup(cfg, lookup, req, resp)
 
@@ -975,7 +1173,7 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, maxRecursi
 			query = strings.Join(queryParts, ".")
 		}
 
-		err := d.preparedQueryLookup(cfg, datacenter, query, remoteAddr, req, resp, maxRecursionLevel)
+		err := d.preparedQueryLookup(cfg, view, datacenter, query, remoteAddr, req, resp, maxRecursionLevel)
 		return ecsNotGlobalError{error: err}
 
 	case "addr":
@@ -1035,6 +1233,34 @@ func (d *DNSServer) dispatch(remoteAddr net.Addr, req, resp *dns.Msg, maxRecursi
 	}
 }
 
+// serviceFilter returns the bexpr filter expressed by the labels preceding a
+// "meta" or "filter" label of a service lookup. It returns false if the labels
+// are not a valid filter, in which case they are treated as a tag for
+// compatibility with tags containing periods.
+func serviceFilter(cfg *dnsConfig, kind string, labels []string) (string, bool) {
+	switch kind {
+	case "filter":
+		if len(labels) != 1 {
+			return "", false
+		}
+		filter, ok := cfg.Filters[strings.ToLower(labels[0])]
+		return filter, ok
+
+	case "meta":
+		exprs := make([]string, 0, len(labels))
+		for _, label := range labels {
+			key, value, ok := strings.Cut(label, "=")
+			// bexpr string literals cannot contain double quotes.
+			if !ok || key == "" || strings.Contains(label, `"`) {
+				return "", false
+			}
+			exprs = append(exprs, fmt.Sprintf("Service.Meta[%q] == %q", key, value))
+		}
+		return strings.Join(exprs, " and "), true
+	}
+	return "", false
+}
+
 func (d *DNSServer) trimDomain(query string) string {
 	longer := d.domain
 	shorter := d.altDomain
@@ -1107,7 +1333,7 @@ func (d *DNSServer) nodeLookup(cfg *dnsConfig, lookup nodeLookup, req, resp *dns
 	q := req.Question[0]
 	// Only compute A and CNAME record if query is not TXT type
 	if qType != dns.TypeTXT {
-		records := d.makeRecordFromNode(n, q.Qtype, q.Name, cfg.NodeTTL, lookup.MaxRecursionLevel)
+		records := d.makeRecordFromNode(n, lookup.View, q.Qtype, q.Name, cfg.NodeTTL, lookup.MaxRecursionLevel)
 		resp.Answer = append(resp.Answer, records...)
 	}
 
@@ -1191,21 +1417,28 @@ func indexRRs(rrs []dns.RR, index map[string]dns.RR) {
 // syncExtra takes a DNS response message and sets the extra data to the most
 // minimal set needed to cover the answer data. A pre-made index of RRs is given
 // so that can be re-used between calls. This assumes that the extra data is
-// only used to provide info for SRV records. If that's not the case, then this
-// will wipe out any additional data.
+// only used to provide info for SRV, SVCB and HTTPS records. If that's not the
+// case, then this will wipe out any additional data.
 func syncExtra(index map[string]dns.RR, resp *dns.Msg) {
 	extra := make([]dns.RR, 0, len(resp.Answer))
 	resolved := make(map[string]struct{}, len(resp.Answer))
 	for _, ansRR := range resp.Answer {
-		srv, ok := ansRR.(*dns.SRV)
-		if !ok {
+		var target string
+		switch rr := ansRR.(type) {
+		case *dns.SRV:
+			target = rr.Target
+		case *dns.SVCB:
+			target = rr.Target
+		case *dns.HTTPS:
+			target = rr.Target
+		default:
 			continue
 		}
 
 		// Note that we always use lower case when using the index so
 		// that compares are not case-sensitive. We don't alter the actual
 		// RRs we add into the extra section, however.
-		target := strings.ToLower(srv.Target)
+		target = strings.ToLower(target)
 
 	RESOLVE:
 		if _, ok := resolved[target]; ok {
@@ -1413,6 +1646,7 @@ func (d *DNSServer) lookupServiceNodes(cfg *dnsConfig, lookup serviceLookup) (st
 		ServiceTags: serviceTags,
 		TagFilter:   lookup.Tag != "",
 		QueryOptions: structs.QueryOptions{
+			Filter:           lookup.Filter,
 			Token:            d.agent.tokens.UserToken(),
 			AllowStale:       cfg.AllowStale,
 			MaxAge:           cfg.CacheMaxAge,
@@ -1454,10 +1688,14 @@ func (d *DNSServer) serviceLookup(cfg *dnsConfig, lookup serviceLookup, req, res
 	ttl, _ := cfg.GetTTLForService(lookup.Service)
 
 	// Add various responses depending on the request
-	qType := req.Question[0].Qtype
-	if qType == dns.TypeSRV {
+	switch req.Question[0].Qtype {
+	case dns.TypeSRV:
 		d.serviceSRVRecords(cfg, lookup, out.Nodes, req, resp, ttl, lookup.MaxRecursionLevel)
-	} else {
+	case dns.TypeSVCB, dns.TypeHTTPS:
+		d.serviceSVCBRecords(cfg, lookup, out.Nodes, req, resp, ttl, lookup.MaxRecursionLevel)
+	case dns.TypeURI:
+		d.serviceURIRecords(cfg, lookup, out.Nodes, req, resp, ttl, lookup.MaxRecursionLevel)
+	default:
 		d.serviceNodeRecords(cfg, lookup, out.Nodes, req, resp, ttl, lookup.MaxRecursionLevel)
 	}
 
@@ -1484,8 +1722,30 @@ func ednsSubnetForRequest(req *dns.Msg) *dns.EDNS0_SUBNET {
 	return nil
 }
 
+// sourceIPForRequest returns the client address of a request, preferring the
+// EDNS client subnet if one was sent.
+func sourceIPForRequest(remoteAddr net.Addr, req *dns.Msg) net.IP {
+	if subnet := ednsSubnetForRequest(req); subnet != nil {
+		return subnet.Address
+	}
+	return remoteIP(remoteAddr)
+}
+
+// remoteIP returns the IP address of the remote end of a connection.
+func remoteIP(remoteAddr net.Addr) net.IP {
+	switch v := remoteAddr.(type) {
+	case *net.UDPAddr:
+		return v.IP
+	case *net.TCPAddr:
+		return v.IP
+	case *net.IPAddr:
+		return v.IP
+	}
+	return nil
+}
+
 // preparedQueryLookup is used to handle a prepared query.
-func (d *DNSServer) preparedQueryLookup(cfg *dnsConfig, datacenter, query string, remoteAddr net.Addr, req, resp *dns.Msg, maxRecursionLevel int) error {
+func (d *DNSServer) preparedQueryLookup(cfg *dnsConfig, view *dnsView, datacenter, query string, remoteAddr net.Addr, req, resp *dns.Msg, maxRecursionLevel int) error {
 	// Execute the prepared query.
 	args := structs.PreparedQueryExecuteRequest{
 		Datacenter:    datacenter,
@@ -1508,19 +1768,8 @@ func (d *DNSServer) preparedQueryLookup(cfg *dnsConfig, datacenter, query string
 		},
 	}
 
-	subnet := ednsSubnetForRequest(req)
-
-	if subnet != nil {
-		args.Source.Ip = subnet.Address.String()
-	} else {
-		switch v := remoteAddr.(type) {
-		case *net.UDPAddr:
-			args.Source.Ip = v.IP.String()
-		case *net.TCPAddr:
-			args.Source.Ip = v.IP.String()
-		case *net.IPAddr:
-			args.Source.Ip = v.IP.String()
-		}
+	if ip := sourceIPForRequest(remoteAddr, req); ip != nil {
+		args.Source.Ip = ip.String()
 	}
 
 	out, err := d.lookupPreparedQuery(cfg, args)
@@ -1554,20 +1803,43 @@ func (d *DNSServer) preparedQueryLookup(cfg *dnsConfig, datacenter, query string
 		ttl, _ = cfg.GetTTLForService(out.Service)
 	}
 
-	// If we have no nodes, return not found!
-	if len(out.Nodes) == 0 {
-		return errNameNotFound
+	// Results merged from several failover targets can include nodes from
+	// datacenters hidden by the view. Their addresses are translated up
+	// front since the records are built for the datacenter of the reply.
+	if len(out.Sources) > 1 {
+		nodes := make(structs.CheckServiceNodes, 0, len(out.Nodes))
+		for _, node := range out.Nodes {
+			visible := view.datacenterVisible(node.Node.Datacenter)
+			if node.Node.PeerName != "" {
+				visible = view.peerVisible(node.Node.PeerName)
+			}
+			if visible {
+				nodes = append(nodes, node)
+			}
+		}
+		out.Nodes = nodes
+		d.agent.TranslatePreparedQueryAddresses(out, TranslateAddressAcceptAny)
 	}
 
-	// Add various responses depending on the request.
-	qType := req.Question[0].Qtype
+	// If we have no nodes, or they come from a datacenter hidden by the
+	// view, return not found!
+	if len(out.Nodes) == 0 || !view.datacenterVisible(out.Datacenter) {
+		return errNameNotFound
+	}
 
 	// This serviceLookup only needs the datacenter field populated,
 	// because peering is not supported with prepared queries.
-	lookup := serviceLookup{Datacenter: out.Datacenter}
-	if qType == dns.TypeSRV {
+	lookup := serviceLookup{Datacenter: out.Datacenter, View: view}
+
+	// Add various responses depending on the request.
+	switch req.Question[0].Qtype {
+	case dns.TypeSRV:
 		d.serviceSRVRecords(cfg, lookup, out.Nodes, req, resp, ttl, maxRecursionLevel)
-	} else {
+	case dns.TypeSVCB, dns.TypeHTTPS:
+		d.serviceSVCBRecords(cfg, lookup, out.Nodes, req, resp, ttl, maxRecursionLevel)
+	case dns.TypeURI:
+		d.serviceURIRecords(cfg, lookup, out.Nodes, req, resp, ttl, maxRecursionLevel)
+	default:
 		d.serviceNodeRecords(cfg, lookup, out.Nodes, req, resp, ttl, maxRecursionLevel)
 	}
 
@@ -1726,6 +1998,13 @@ func (d *DNSServer) encodeIPAsFqdn(questionName string, lookup serviceLookup, ip
 	return fmt.Sprintf("%s.addr.%s.%s", ipStr, lookup.Datacenter, respDomain)
 }
 
+// translateServicePort returns the port to advertise for the service, taking
+// WAN translation and the lookup's view into account.
+func (d *DNSServer) translateServicePort(lookup serviceLookup, node structs.CheckServiceNode) int {
+	port := d.agent.TranslateServicePort(lookup.Datacenter, node.Service.Port, node.Service.TaggedAddresses)
+	return lookup.View.servicePort(port, node.Service.TaggedAddresses)
+}
+
 func makeARecord(qType uint16, ip net.IP, ttl time.Duration) dns.RR {
 
 	var ipRecord dns.RR
@@ -1757,7 +2036,8 @@ func makeARecord(qType uint16, ip net.IP, ttl time.Duration) dns.RR {
 // Craft dns records for a node
 // In case of an SRV query the answer will be a IN SRV and additional data will store an IN A to the node IP
 // Otherwise it will return a IN A record
-func (d *DNSServer) makeRecordFromNode(node *structs.Node, qType uint16, qName string, ttl time.Duration, maxRecursionLevel int) []dns.RR {
+// The addresses are presented through the given view, which may be nil.
+func (d *DNSServer) makeRecordFromNode(node *structs.Node, view *dnsView, qType uint16, qName string, ttl time.Duration, maxRecursionLevel int) []dns.RR {
 	addrTranslate := TranslateAddressAcceptDomain
 	if qType == dns.TypeA {
 		addrTranslate |= TranslateAddressAcceptIPv4
@@ -1768,6 +2048,10 @@ func (d *DNSServer) makeRecordFromNode(node *structs.Node, qType uint16, qName s
 	}
 
 	addr := d.agent.TranslateAddress(node.Datacenter, node.Address, node.TaggedAddresses, addrTranslate)
+	target := node.Address
+	if viewAddr := view.nodeAddress(addr, node.TaggedAddresses); viewAddr != addr {
+		addr, target = viewAddr, viewAddr
+	}
 	ip := net.ParseIP(addr)
 
 	var res []dns.RR
@@ -1780,11 +2064,11 @@ func (d *DNSServer) makeRecordFromNode(node *structs.Node, qType uint16, qName s
 				Class:  dns.ClassINET,
 				Ttl:    uint32(ttl / time.Second),
 			},
-			Target: dns.Fqdn(node.Address),
+			Target: dns.Fqdn(target),
 		})
 
 		res = append(res,
-			d.resolveCNAME(d.config.Load().(*dnsConfig), dns.Fqdn(node.Address), maxRecursionLevel)...,
+			d.resolveCNAME(d.config.Load().(*dnsConfig), dns.Fqdn(target), maxRecursionLevel)...,
 		)
 
 		return res
@@ -1822,7 +2106,7 @@ func (d *DNSServer) makeRecordFromServiceNode(lookup serviceLookup, serviceNode
 				},
 				Priority: 1,
 				Weight:   uint16(findWeight(serviceNode)),
-				Port:     uint16(d.agent.TranslateServicePort(lookup.Datacenter, serviceNode.Service.Port, serviceNode.Service.TaggedAddresses)),
+				Port:     uint16(d.translateServicePort(lookup, serviceNode)),
 				Target:   nodeFQDN,
 			},
 		}
@@ -1857,7 +2141,7 @@ func (d *DNSServer) makeRecordFromIP(lookup serviceLookup, addr net.IP, serviceN
 				},
 				Priority: 1,
 				Weight:   uint16(findWeight(serviceNode)),
-				Port:     uint16(d.agent.TranslateServicePort(lookup.Datacenter, serviceNode.Service.Port, serviceNode.Service.TaggedAddresses)),
+				Port:     uint16(d.translateServicePort(lookup, serviceNode)),
 				Target:   ipFQDN,
 			},
 		}
@@ -1906,7 +2190,7 @@ MORE_REC:
 				},
 				Priority: 1,
 				Weight:   uint16(findWeight(serviceNode)),
-				Port:     uint16(d.agent.TranslateServicePort(lookup.Datacenter, serviceNode.Service.Port, serviceNode.Service.TaggedAddresses)),
+				Port:     uint16(d.translateServicePort(lookup, serviceNode)),
 				Target:   dns.Fqdn(fqdn),
 			},
 		}
@@ -1941,7 +2225,9 @@ func (d *DNSServer) nodeServiceRecords(lookup serviceLookup, node structs.CheckS
 	// The datacenter should be empty during translation if it is a peering lookup.
 	// This should be fine because we should always prefer the WAN address.
 	serviceAddr := d.agent.TranslateServiceAddress(lookup.Datacenter, node.Service.Address, node.Service.TaggedAddresses, addrTranslate)
+	serviceAddr = lookup.View.serviceAddress(serviceAddr, node.Service.TaggedAddresses)
 	nodeAddr := d.agent.TranslateAddress(node.Node.Datacenter, node.Node.Address, node.Node.TaggedAddresses, addrTranslate)
+	nodeAddr = lookup.View.nodeAddress(nodeAddr, node.Node.TaggedAddresses)
 	if serviceAddr == "" && nodeAddr == "" {
 		return nil, nil
 	}
@@ -2011,7 +2297,8 @@ func (d *DNSServer) serviceSRVRecords(cfg *dnsConfig, lookup serviceLookup, node
 		// The datacenter should be empty during translation if it is a peering lookup.
 		// This should be fine because we should always prefer the WAN address.
 		serviceAddress := d.agent.TranslateServiceAddress(lookup.Datacenter, node.Service.Address, node.Service.TaggedAddresses, TranslateAddressAcceptAny)
-		servicePort := d.agent.TranslateServicePort(lookup.Datacenter, node.Service.Port, node.Service.TaggedAddresses)
+		serviceAddress = lookup.View.serviceAddress(serviceAddress, node.Service.TaggedAddresses)
+		servicePort := d.translateServicePort(lookup, node)
 		tuple := fmt.Sprintf("%s:%s:%d", node.Node.Node, serviceAddress, servicePort)
 		if _, ok := handled[tuple]; ok {
 			continue
@@ -2030,6 +2317,155 @@ func (d *DNSServer) serviceSRVRecords(cfg *dnsConfig, lookup serviceLookup, node
 	}
 }
 
+// serviceEndpoint is the target, port and addresses of a service instance as
+// they would be advertised in an SRV record.
+type serviceEndpoint struct {
+	target string
+	port   uint16
+	ips    []net.IP
+	extra  []dns.RR
+}
+
+// serviceEndpoints resolves the endpoints of a service instance the same way
+// SRV lookups do, so that SVCB, HTTPS and URI records honor the same address
+// translation and views.
+func (d *DNSServer) serviceEndpoints(cfg *dnsConfig, lookup serviceLookup, node structs.CheckServiceNode, req *dns.Msg, ttl time.Duration, maxRecursionLevel int) []serviceEndpoint {
+	srvReq := req.Copy()
+	srvReq.Question[0].Qtype = dns.TypeSRV
+
+	answers, extra := d.nodeServiceRecords(lookup, node, srvReq, ttl, cfg, maxRecursionLevel)
+
+	var endpoints []serviceEndpoint
+	for _, rr := range answers {
+		srv, ok := rr.(*dns.SRV)
+		if !ok {
+			continue
+		}
+		ep := serviceEndpoint{target: srv.Target, port: srv.Port, extra: extra}
+		for _, e := range extra {
+			if !strings.EqualFold(e.Header().Name, srv.Target) {
+				continue
+			}
+			switch rr := e.(type) {
+			case *dns.A:
+				ep.ips = append(ep.ips, rr.A)
+			case *dns.AAAA:
+				ep.ips = append(ep.ips, rr.AAAA)
+			}
+		}
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
+
+// serviceSVCBRecords is used to add the SVCB or HTTPS records for a service
+// lookup. The comma separated "alpn" service metadata key is advertised as
+// the ALPN parameter.
+func (d *DNSServer) serviceSVCBRecords(cfg *dnsConfig, lookup serviceLookup, nodes structs.CheckServiceNodes, req, resp *dns.Msg, ttl time.Duration, maxRecursionLevel int) {
+	q := req.Question[0]
+	handled := make(map[string]struct{})
+
+	for _, node := range nodes {
+		for _, ep := range d.serviceEndpoints(cfg, lookup, node, req, ttl, maxRecursionLevel) {
+			// Avoid duplicate entries, possible if a node has
+			// the same service on the same port, etc.
+			tuple := fmt.Sprintf("%s:%d", strings.ToLower(ep.target), ep.port)
+			if _, ok := handled[tuple]; ok {
+				continue
+			}
+			handled[tuple] = struct{}{}
+
+			svcb := dns.SVCB{
+				Hdr: dns.RR_Header{
+					Name:   q.Name,
+					Rrtype: q.Qtype,
+					Class:  dns.ClassINET,
+					Ttl:    uint32(ttl / time.Second),
+				},
+				Priority: 1,
+				Target:   ep.target,
+			}
+
+			// Parameters must be sorted by key.
+			if alpn := svcbALPN(node.Service.Meta["alpn"]); len(alpn) > 0 {
+				svcb.Value = append(svcb.Value, &dns.SVCBAlpn{Alpn: alpn})
+			}
+			svcb.Value = append(svcb.Value, &dns.SVCBPort{Port: ep.port})
+			var v4, v6 []net.IP
+			for _, ip := range ep.ips {
+				if ipv4 := ip.To4(); ipv4 != nil {
+					v4 = append(v4, ipv4)
+				} else {
+					v6 = append(v6, ip)
+				}
+			}
+			if len(v4) > 0 {
+				svcb.Value = append(svcb.Value, &dns.SVCBIPv4Hint{Hint: v4})
+			}
+			if len(v6) > 0 {
+				svcb.Value = append(svcb.Value, &dns.SVCBIPv6Hint{Hint: v6})
+			}
+
+			if q.Qtype == dns.TypeHTTPS {
+				resp.Answer = append(resp.Answer, &dns.HTTPS{SVCB: svcb})
+			} else {
+				resp.Answer = append(resp.Answer, &svcb)
+			}
+			resp.Extra = append(resp.Extra, ep.extra...)
+		}
+	}
+}
+
+// svcbALPN splits a comma separated list of ALPN protocol identifiers.
+func svcbALPN(value string) []string {
+	var alpn []string
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			alpn = append(alpn, p)
+		}
+	}
+	return alpn
+}
+
+// serviceURIRecords is used to add the URI records for a service lookup.
+// Only instances declaring a "scheme" in their service metadata are
+// returned, since a URI cannot be built without one.
+func (d *DNSServer) serviceURIRecords(cfg *dnsConfig, lookup serviceLookup, nodes structs.CheckServiceNodes, req, resp *dns.Msg, ttl time.Duration, maxRecursionLevel int) {
+	q := req.Question[0]
+	handled := make(map[string]struct{})
+
+	for _, node := range nodes {
+		scheme := node.Service.Meta["scheme"]
+		if scheme == "" {
+			continue
+		}
+
+		for _, ep := range d.serviceEndpoints(cfg, lookup, node, req, ttl, maxRecursionLevel) {
+			host := strings.TrimSuffix(ep.target, ".")
+			if len(ep.ips) > 0 {
+				host = ep.ips[0].String()
+			}
+			target := fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(ep.port))))
+			if _, ok := handled[target]; ok {
+				continue
+			}
+			handled[target] = struct{}{}
+
+			resp.Answer = append(resp.Answer, &dns.URI{
+				Hdr: dns.RR_Header{
+					Name:   q.Name,
+					Rrtype: dns.TypeURI,
+					Class:  dns.ClassINET,
+					Ttl:    uint32(ttl / time.Second),
+				},
+				Priority: 1,
+				Weight:   uint16(findWeight(node)),
+				Target:   target,
+			})
+		}
+	}
+}
+
 // handleRecurse is used to handle recursive DNS queries
 func (d *DNSServer) handleRecurse(resp dns.ResponseWriter, req *dns.Msg) {
 	cfg := d.config.Load().(*dnsConfig)
@@ -2051,6 +2487,13 @@ func (d *DNSServer) handleRecurse(resp dns.ResponseWriter, req *dns.Msg) {
 		network = "tcp"
 	}
 
+	// Check the client's query rate before forwarding the query, so that
+	// the agent can't be used as an open resolver.
+	clientIP := remoteIP(resp.RemoteAddr())
+	if d.rateLimitedReply(d.rateLimiter.allowClient(cfg.RateLimit, network, clientIP), resp, req) {
+		return
+	}
+
 	// Recursively resolve
 	c := &dns.Client{Net: network, Timeout: cfg.RecursorTimeout}
 	var r *dns.Msg
@@ -2082,6 +2525,9 @@ func (d *DNSServer) handleRecurse(resp dns.ResponseWriter, req *dns.Msg) {
 				"rtt", rtt,
 				"recursor", recursor,
 			)
+			if d.rateLimitedReply(d.rateLimiter.allowResponse(cfg.RateLimit, network, clientIP, r), resp, req) {
+				return
+			}
 			if err := resp.WriteMsg(r); err != nil {
 				d.logger.Warn("failed to respond", "error", err)
 			}
@@ -2104,6 +2550,9 @@ func (d *DNSServer) handleRecurse(resp dns.ResponseWriter, req *dns.Msg) {
 	if edns := req.IsEdns0(); edns != nil {
 		setEDNS(req, m, true)
 	}
+	if d.rateLimitedReply(d.rateLimiter.allowResponse(cfg.RateLimit, network, clientIP, m), resp, req) {
+		return
+	}
 	resp.WriteMsg(m)
 }
 
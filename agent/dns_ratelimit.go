@@ -0,0 +1,232 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-hclog"
+	"github.com/miekg/dns"
+	"golang.org/x/time/rate"
+
+	"github.com/hashicorp/consul/agent/config"
+	"github.com/hashicorp/consul/agent/consul/multilimiter"
+	consulrate "github.com/hashicorp/consul/agent/consul/rate"
+)
+
+var (
+	// dnsClientLimit prefixes the keys of the per-client query limiters.
+	dnsClientLimit = []byte("dns.client")
+
+	// dnsResponseLimit prefixes the keys of the response rate limiters.
+	dnsResponseLimit = []byte("dns.response")
+)
+
+const (
+	dnsRateLimitActionAllow    = "allow"
+	dnsRateLimitActionDrop     = "drop"
+	dnsRateLimitActionTruncate = "truncate"
+)
+
+// dnsRateLimitConfig is the rate limiting part of the DNS server config.
+type dnsRateLimitConfig struct {
+	Mode          consulrate.Mode
+	Client        multilimiter.LimiterConfig
+	Response      multilimiter.LimiterConfig
+	IPv4PrefixLen int
+	IPv6PrefixLen int
+	Slip          int
+}
+
+func getDNSRateLimitConfig(conf *config.RuntimeConfig) dnsRateLimitConfig {
+	return dnsRateLimitConfig{
+		Mode:          conf.DNSRateLimitMode,
+		Client:        multilimiter.LimiterConfig{Rate: conf.DNSClientRate, Burst: conf.DNSClientBurst},
+		Response:      multilimiter.LimiterConfig{Rate: conf.DNSResponseRate, Burst: conf.DNSResponseBurst},
+		IPv4PrefixLen: conf.DNSRateLimitIPv4PrefixLen,
+		IPv6PrefixLen: conf.DNSRateLimitIPv6PrefixLen,
+		Slip:          conf.DNSRateLimitSlip,
+	}
+}
+
+// dnsRateLimiter limits the rate of queries from a single client address, and
+// the rate of identical responses sent to a client network (BIND-style
+// response rate limiting). The limiters are created per key on first use and
+// expire once the key is idle.
+type dnsRateLimiter struct {
+	limiter multilimiter.RateLimiter
+	logger  hclog.Logger
+	mlCfg   multilimiter.Config
+
+	// limitedLock protects limited.
+	limitedLock sync.Mutex
+
+	// limited counts the queries over the limit of each key, and is used to
+	// truncate every Slip-th of them instead of dropping it.
+	limited map[string]*limitedCount
+}
+
+// limitedCount is the number of queries over the limit of a key.
+type limitedCount struct {
+	count    uint64
+	lastSeen time.Time
+}
+
+func newDNSRateLimiter(mlCfg multilimiter.Config, cfg dnsRateLimitConfig, logger hclog.Logger) *dnsRateLimiter {
+	l := &dnsRateLimiter{
+		limiter: multilimiter.NewMultiLimiter(mlCfg),
+		logger:  logger,
+		mlCfg:   mlCfg,
+		limited: make(map[string]*limitedCount),
+	}
+	l.updateConfig(cfg)
+	return l
+}
+
+// Run the limiter cleanup routines until the given context is canceled.
+func (l *dnsRateLimiter) Run(ctx context.Context) {
+	l.limiter.Run(ctx)
+	go l.reconcileLimited(ctx)
+}
+
+// reconcileLimited removes the counts of the keys which have not been over
+// their limit for ReconcileCheckLimit, like the multilimiter does for their
+// limiters.
+func (l *dnsRateLimiter) reconcileLimited(ctx context.Context) {
+	ticker := time.NewTicker(l.mlCfg.ReconcileCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.limitedLock.Lock()
+			for key, c := range l.limited {
+				if now.Sub(c.lastSeen) > l.mlCfg.ReconcileCheckLimit {
+					delete(l.limited, key)
+				}
+			}
+			l.limitedLock.Unlock()
+		}
+	}
+}
+
+// countLimited increments and returns the number of queries over the limit
+// of the given key.
+func (l *dnsRateLimiter) countLimited(key multilimiter.KeyType) uint64 {
+	l.limitedLock.Lock()
+	defer l.limitedLock.Unlock()
+	c, ok := l.limited[string(key)]
+	if !ok {
+		c = &limitedCount{}
+		l.limited[string(key)] = c
+	}
+	c.count++
+	c.lastSeen = time.Now()
+	return c.count
+}
+
+func (l *dnsRateLimiter) updateConfig(cfg dnsRateLimitConfig) {
+	l.limiter.UpdateConfig(cfg.Client, dnsClientLimit)
+	l.limiter.UpdateConfig(cfg.Response, dnsResponseLimit)
+}
+
+// allowClient returns the action to take for a query from the given client.
+func (l *dnsRateLimiter) allowClient(cfg dnsRateLimitConfig, network string, ip net.IP) string {
+	if cfg.Mode == consulrate.ModeDisabled || cfg.Client.Rate == rate.Inf || ip == nil {
+		return dnsRateLimitActionAllow
+	}
+	key := multilimiter.Key(dnsClientLimit, ip)
+	return l.allow(cfg, "client", network, dnsLimitKey(key))
+}
+
+// allowResponse returns the action to take for a response to the given
+// client. Response rate limiting only applies to UDP, where source addresses
+// can be spoofed to use the server as an amplifier.
+func (l *dnsRateLimiter) allowResponse(cfg dnsRateLimitConfig, network string, ip net.IP, resp *dns.Msg) string {
+	if cfg.Mode == consulrate.ModeDisabled || cfg.Response.Rate == rate.Inf || ip == nil || network != "udp" || len(resp.Question) == 0 {
+		return dnsRateLimitActionAllow
+	}
+
+	var clientNet net.IP
+	if ipv4 := ip.To4(); ipv4 != nil {
+		clientNet = ipv4.Mask(net.CIDRMask(cfg.IPv4PrefixLen, 8*net.IPv4len))
+	} else {
+		clientNet = ip.Mask(net.CIDRMask(cfg.IPv6PrefixLen, 8*net.IPv6len))
+	}
+
+	q := resp.Question[0]
+	key := multilimiter.Key(
+		dnsResponseLimit,
+		clientNet,
+		[]byte(strings.ToLower(q.Name)),
+		[]byte(strconv.Itoa(int(q.Qtype))),
+		[]byte(strconv.Itoa(resp.Rcode)),
+	)
+	return l.allow(cfg, "response", network, dnsLimitKey(key))
+}
+
+func (l *dnsRateLimiter) allow(cfg dnsRateLimitConfig, limitType, network string, ent multilimiter.LimitedEntity) string {
+	if l.limiter.Allow(ent) {
+		return dnsRateLimitActionAllow
+	}
+
+	action := dnsRateLimitActionAllow
+	if cfg.Mode == consulrate.ModeEnforcing {
+		action = dnsRateLimitActionDrop
+		// Truncation only makes sense for UDP, where it tells the client to
+		// retry over TCP.
+		if n := l.countLimited(ent.Key()); network == "udp" && cfg.Slip > 0 && n%uint64(cfg.Slip) == 0 {
+			action = dnsRateLimitActionTruncate
+		}
+	}
+
+	l.logger.Trace("DNS query exceeded allowed rate limit",
+		"limit_type", limitType,
+		"action", action,
+	)
+	metrics.IncrCounterWithLabels([]string{"dns", "rate_limit", "exceeded"}, 1, []metrics.Label{
+		{Name: "limit_type", Value: limitType},
+		{Name: "action", Value: action},
+		{Name: "mode", Value: cfg.Mode.String()},
+	})
+	return action
+}
+
+// dnsLimitKey satisfies the multilimiter.LimitedEntity interface.
+type dnsLimitKey multilimiter.KeyType
+
+func (k dnsLimitKey) Key() multilimiter.KeyType {
+	return multilimiter.KeyType(k)
+}
+
+// rateLimitedReply handles a query that a rate limiter did not allow. It
+// returns true if the query was answered or dropped and must not be processed
+// further.
+func (d *DNSServer) rateLimitedReply(action string, resp dns.ResponseWriter, req *dns.Msg) bool {
+	switch action {
+	case dnsRateLimitActionDrop:
+		return true
+	case dnsRateLimitActionTruncate:
+		m := new(dns.Msg)
+		m.SetReply(req)
+		m.Truncated = true
+		if err := resp.WriteMsg(m); err != nil {
+			d.logger.Warn("failed to respond", "error", err)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// defaultDNSRateLimiterConfig is the limiter cleanup configuration used by
+// the DNS server. Idle limiters are removed after ReconcileCheckLimit.
+var defaultDNSRateLimiterConfig = multilimiter.Config{
+	ReconcileCheckLimit:    30 * time.Second,
+	ReconcileCheckInterval: time.Second,
+}
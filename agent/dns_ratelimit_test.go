@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/consul/multilimiter"
+	consulrate "github.com/hashicorp/consul/agent/consul/rate"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+)
+
+func newTestDNSRateLimiter(t *testing.T, cfg dnsRateLimitConfig) *dnsRateLimiter {
+	t.Helper()
+
+	l := newDNSRateLimiter(multilimiter.Config{
+		ReconcileCheckLimit:    time.Hour,
+		ReconcileCheckInterval: 10 * time.Millisecond,
+	}, cfg, hclog.NewNullLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	l.Run(ctx)
+	return l
+}
+
+func TestDNSRateLimiter_Client(t *testing.T) {
+	cfg := dnsRateLimitConfig{
+		Mode:   consulrate.ModeEnforcing,
+		Client: multilimiter.LimiterConfig{Rate: 0.001, Burst: 1},
+		Slip:   2,
+	}
+	l := newTestDNSRateLimiter(t, cfg)
+	ip := net.ParseIP("10.0.0.1")
+
+	// Limiters are shared once the multilimiter has reconciled them, so
+	// retry until the client is limited.
+	retry.Run(t, func(r *retry.R) {
+		require.NotEqual(r, dnsRateLimitActionAllow, l.allowClient(cfg, "tcp", ip))
+	})
+
+	// TCP queries are never truncated.
+	require.Equal(t, dnsRateLimitActionDrop, l.allowClient(cfg, "tcp", ip))
+	require.Equal(t, dnsRateLimitActionDrop, l.allowClient(cfg, "tcp", ip))
+
+	// Every other limited UDP query is truncated.
+	actions := map[string]int{}
+	for i := 0; i < 4; i++ {
+		actions[l.allowClient(cfg, "udp", ip)]++
+	}
+	require.Equal(t, map[string]int{dnsRateLimitActionDrop: 2, dnsRateLimitActionTruncate: 2}, actions)
+
+	// Other clients have their own limit, and their own count of limited
+	// queries.
+	other := net.ParseIP("10.0.0.2")
+	require.Equal(t, dnsRateLimitActionAllow, l.allowClient(cfg, "udp", other))
+	retry.Run(t, func(r *retry.R) {
+		require.NotEqual(r, dnsRateLimitActionAllow, l.allowClient(cfg, "tcp", other))
+	})
+	require.Equal(t, dnsRateLimitActionTruncate, l.allowClient(cfg, "udp", ip))
+
+	// Permissive mode only counts the queries.
+	cfg.Mode = consulrate.ModePermissive
+	require.Equal(t, dnsRateLimitActionAllow, l.allowClient(cfg, "udp", ip))
+
+	// Disabled mode skips the limiter entirely.
+	cfg.Mode = consulrate.ModeDisabled
+	require.Equal(t, dnsRateLimitActionAllow, l.allowClient(cfg, "udp", ip))
+}
+
+func TestDNSRateLimiter_Response(t *testing.T) {
+	cfg := dnsRateLimitConfig{
+		Mode:          consulrate.ModeEnforcing,
+		Response:      multilimiter.LimiterConfig{Rate: 0.001, Burst: 1},
+		IPv4PrefixLen: 24,
+		IPv6PrefixLen: 56,
+	}
+	l := newTestDNSRateLimiter(t, cfg)
+
+	response := func(name string) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(name, dns.TypeA)
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		return resp
+	}
+
+	retry.Run(t, func(r *retry.R) {
+		require.Equal(r, dnsRateLimitActionDrop, l.allowResponse(cfg, "udp", net.ParseIP("10.0.0.1"), response("web.service.consul.")))
+	})
+
+	// Clients in the same network share the limit, even for differently
+	// cased names.
+	require.Equal(t, dnsRateLimitActionDrop, l.allowResponse(cfg, "udp", net.ParseIP("10.0.0.200"), response("WEB.service.consul.")))
+
+	// Other responses and networks are not limited.
+	require.Equal(t, dnsRateLimitActionAllow, l.allowResponse(cfg, "udp", net.ParseIP("10.0.0.1"), response("db.service.consul.")))
+	require.Equal(t, dnsRateLimitActionAllow, l.allowResponse(cfg, "udp", net.ParseIP("10.0.1.1"), response("web.service.consul.")))
+
+	// Responses over TCP are never limited.
+	require.Equal(t, dnsRateLimitActionAllow, l.allowResponse(cfg, "tcp", net.ParseIP("10.0.0.1"), response("web.service.consul.")))
+}
+
+func TestDNSRateLimiter_UpdateConfig(t *testing.T) {
+	cfg := dnsRateLimitConfig{
+		Mode:   consulrate.ModeEnforcing,
+		Client: multilimiter.LimiterConfig{Rate: 0.001, Burst: 1},
+	}
+	l := newTestDNSRateLimiter(t, cfg)
+	ip := net.ParseIP("10.0.0.1")
+
+	retry.Run(t, func(r *retry.R) {
+		require.Equal(r, dnsRateLimitActionDrop, l.allowClient(cfg, "udp", ip))
+	})
+
+	// Raising the limit applies to existing clients once reconciled.
+	cfg.Client = multilimiter.LimiterConfig{Rate: 1000, Burst: 1000}
+	l.updateConfig(cfg)
+	retry.Run(t, func(r *retry.R) {
+		require.Equal(r, dnsRateLimitActionAllow, l.allowClient(cfg, "udp", ip))
+	})
+}
@@ -2600,6 +2600,239 @@ func TestDNS_ServiceLookup_ServiceAddress_A(t *testing.T) {
 	}
 }
 
+func TestDNS_ServiceLookup_SVCB(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	// Register a node with a service.
+	{
+		args := &structs.RegisterRequest{
+			Datacenter: "dc1",
+			Node:       "foo",
+			Address:    "127.0.0.1",
+			Service: &structs.NodeService{
+				Service: "web",
+				Address: "127.0.0.2",
+				Port:    8443,
+				Meta:    map[string]string{"alpn": "h2, http/1.1"},
+			},
+		}
+
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+	}
+
+	// Register an equivalent prepared query.
+	var id string
+	{
+		args := &structs.PreparedQueryRequest{
+			Datacenter: "dc1",
+			Op:         structs.PreparedQueryCreate,
+			Query: &structs.PreparedQuery{
+				Name: "test",
+				Service: structs.ServiceQuery{
+					Service: "web",
+				},
+			},
+		}
+		require.NoError(t, a.RPC(context.Background(), "PreparedQuery.Apply", args, &id))
+	}
+
+	// Look up the service directly and via prepared query.
+	questions := []string{
+		"web.service.consul.",
+		id + ".query.consul.",
+	}
+	for _, question := range questions {
+		for _, qType := range []uint16{dns.TypeSVCB, dns.TypeHTTPS} {
+			m := new(dns.Msg)
+			m.SetQuestion(question, qType)
+
+			c := new(dns.Client)
+			in, _, err := c.Exchange(m, a.DNSAddr())
+			require.NoError(t, err)
+			require.Len(t, in.Answer, 1, question)
+
+			var svcb *dns.SVCB
+			switch rr := in.Answer[0].(type) {
+			case *dns.SVCB:
+				require.Equal(t, dns.TypeSVCB, qType)
+				svcb = rr
+			case *dns.HTTPS:
+				require.Equal(t, dns.TypeHTTPS, qType)
+				svcb = &rr.SVCB
+			default:
+				t.Fatalf("Bad: %#v", in.Answer[0])
+			}
+			require.Equal(t, uint16(1), svcb.Priority)
+			require.Equal(t, "7f000002.addr.dc1.consul.", svcb.Target)
+			require.Len(t, svcb.Value, 3)
+			require.Equal(t, []string{"h2", "http/1.1"}, svcb.Value[0].(*dns.SVCBAlpn).Alpn)
+			require.Equal(t, uint16(8443), svcb.Value[1].(*dns.SVCBPort).Port)
+			require.Equal(t, "127.0.0.2", svcb.Value[2].(*dns.SVCBIPv4Hint).Hint[0].String())
+
+			require.Len(t, in.Extra, 1)
+			aRec, ok := in.Extra[0].(*dns.A)
+			require.True(t, ok)
+			require.Equal(t, "7f000002.addr.dc1.consul.", aRec.Hdr.Name)
+			require.Equal(t, "127.0.0.2", aRec.A.String())
+		}
+	}
+}
+
+func TestDNS_ServiceLookup_URI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, "")
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	// Register two instances, only one of which declares a scheme.
+	for _, args := range []*structs.RegisterRequest{
+		{
+			Datacenter: "dc1",
+			Node:       "foo",
+			Address:    "127.0.0.1",
+			Service: &structs.NodeService{
+				Service: "web",
+				Port:    8443,
+				Meta:    map[string]string{"scheme": "https"},
+			},
+		},
+		{
+			Datacenter: "dc1",
+			Node:       "bar",
+			Address:    "127.0.0.3",
+			Service: &structs.NodeService{
+				Service: "web",
+				Port:    8080,
+			},
+		},
+	} {
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+	}
+
+	var id string
+	{
+		args := &structs.PreparedQueryRequest{
+			Datacenter: "dc1",
+			Op:         structs.PreparedQueryCreate,
+			Query: &structs.PreparedQuery{
+				Name: "test",
+				Service: structs.ServiceQuery{
+					Service: "web",
+				},
+			},
+		}
+		require.NoError(t, a.RPC(context.Background(), "PreparedQuery.Apply", args, &id))
+	}
+
+	questions := []string{
+		"web.service.consul.",
+		id + ".query.consul.",
+	}
+	for _, question := range questions {
+		m := new(dns.Msg)
+		m.SetQuestion(question, dns.TypeURI)
+
+		c := new(dns.Client)
+		in, _, err := c.Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		require.Len(t, in.Answer, 1, question)
+
+		uri, ok := in.Answer[0].(*dns.URI)
+		require.True(t, ok)
+		require.Equal(t, "https://127.0.0.1:8443", uri.Target)
+		require.Equal(t, uint16(1), uri.Priority)
+		require.Equal(t, uint16(1), uri.Weight)
+	}
+}
+
+func TestDNS_ServiceLookup_Filter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			filters {
+				canary = "Service.Meta.track == \"Canary\""
+			}
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	for _, args := range []*structs.RegisterRequest{
+		{
+			Datacenter: "dc1",
+			Node:       "foo",
+			Address:    "127.0.0.1",
+			Service: &structs.NodeService{
+				Service: "web",
+				Port:    8080,
+				Meta:    map[string]string{"version": "2", "track": "Canary"},
+			},
+		},
+		{
+			Datacenter: "dc1",
+			Node:       "bar",
+			Address:    "127.0.0.2",
+			Service: &structs.NodeService{
+				Service: "web",
+				Port:    8080,
+				Meta:    map[string]string{"version": "1"},
+				Tags:    []string{"v1.meta"},
+			},
+		},
+	} {
+		var out struct{}
+		require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+	}
+
+	cases := map[string][]string{
+		"version=2.meta.web.service.consul.":              {"127.0.0.1"},
+		"version=1.meta.web.service.consul.":              {"127.0.0.2"},
+		"version=2.track=Canary.meta.web.service.consul.": {"127.0.0.1"},
+		"version=2.track=canary.meta.web.service.consul.": nil,
+		"version=3.meta.web.service.consul.":              nil,
+		"canary.filter.web.service.consul.":               {"127.0.0.1"},
+		"CANARY.filter.web.service.consul.":               {"127.0.0.1"},
+		"unknown.filter.web.service.consul.":              nil,
+		// Labels that are not a filter still match tags containing periods.
+		"v1.meta.web.service.consul.": {"127.0.0.2"},
+	}
+	for question, expected := range cases {
+		t.Run(question, func(t *testing.T) {
+			m := new(dns.Msg)
+			m.SetQuestion(question, dns.TypeA)
+
+			c := new(dns.Client)
+			in, _, err := c.Exchange(m, a.DNSAddr())
+			require.NoError(t, err)
+
+			var addrs []string
+			for _, rr := range in.Answer {
+				aRec, ok := rr.(*dns.A)
+				require.True(t, ok)
+				addrs = append(addrs, aRec.A.String())
+			}
+			require.Equal(t, expected, addrs)
+		})
+	}
+}
+
 func TestDNS_AltDomain_ServiceLookup_ServiceAddress_A(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -3428,6 +3661,231 @@ func TestDNS_Lookup_TaggedIPAddresses(t *testing.T) {
 	}
 }
 
+func TestDNS_ServiceLookup_Views(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			views = [
+				{
+					name = "remote"
+					source_cidrs = ["10.0.0.0/8"]
+					tagged_address = "lan"
+					datacenters = ["dc2"]
+				},
+				{
+					name = "local"
+					source_cidrs = ["127.0.0.0/8"]
+					tagged_address = "wan"
+					datacenters = ["dc1"]
+					rewrites = {
+						"198.18.0.2" = "198.18.0.20"
+					}
+				},
+			]
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	// Register a node with a service that has a WAN address, and one that
+	// only inherits the node's WAN address.
+	args := &structs.RegisterRequest{
+		Datacenter:      "dc1",
+		Node:            "foo",
+		Address:         "127.0.0.1",
+		TaggedAddresses: map[string]string{structs.TaggedAddressWAN: "198.18.0.1"},
+		Service: &structs.NodeService{
+			Service: "db",
+			Address: "127.0.0.2",
+			Port:    12345,
+			TaggedAddresses: map[string]structs.ServiceAddress{
+				structs.TaggedAddressWAN: {Address: "198.18.0.2", Port: 8080},
+			},
+		},
+	}
+	var out struct{}
+	require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+	args.Service = &structs.NodeService{Service: "web", Port: 80}
+	require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+	exchange := func(question string, qType uint16, subnet string) *dns.Msg {
+		m := new(dns.Msg)
+		m.SetQuestion(question, qType)
+		if subnet != "" {
+			edns := new(dns.OPT)
+			edns.Hdr.Name = "."
+			edns.Hdr.Rrtype = dns.TypeOPT
+			edns.Option = append(edns.Option, &dns.EDNS0_SUBNET{
+				Code:          dns.EDNS0SUBNET,
+				Family:        1,
+				SourceNetmask: 32,
+				Address:       net.ParseIP(subnet),
+			})
+			m.Extra = append(m.Extra, edns)
+		}
+		c := new(dns.Client)
+		in, _, err := c.Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		return in
+	}
+
+	in := exchange("db.service.consul.", dns.TypeA, "")
+	require.Len(t, in.Answer, 1)
+	aRec, ok := in.Answer[0].(*dns.A)
+	require.True(t, ok)
+	require.Equal(t, "198.18.0.20", aRec.A.String())
+
+	// The EDNS client subnet is set by the client, it must not select the
+	// view of another network.
+	in = exchange("db.service.consul.", dns.TypeA, "10.1.2.3")
+	require.Equal(t, dns.RcodeSuccess, in.Rcode)
+	require.Len(t, in.Answer, 1)
+	aRec, ok = in.Answer[0].(*dns.A)
+	require.True(t, ok)
+	require.Equal(t, "198.18.0.20", aRec.A.String())
+
+	in = exchange("db.service.consul.", dns.TypeSRV, "")
+	require.Len(t, in.Answer, 1)
+	srvRec, ok := in.Answer[0].(*dns.SRV)
+	require.True(t, ok)
+	require.Equal(t, uint16(8080), srvRec.Port)
+	require.Equal(t, "c6120014.addr.dc1.consul.", srvRec.Target)
+
+	in = exchange("web.service.consul.", dns.TypeA, "")
+	require.Len(t, in.Answer, 1)
+	aRec, ok = in.Answer[0].(*dns.A)
+	require.True(t, ok)
+	require.Equal(t, "198.18.0.1", aRec.A.String())
+
+	// Node lookups return the view's tagged address too.
+	in = exchange("foo.node.consul.", dns.TypeA, "")
+	require.Len(t, in.Answer, 1)
+	aRec, ok = in.Answer[0].(*dns.A)
+	require.True(t, ok)
+	require.Equal(t, "198.18.0.1", aRec.A.String())
+}
+
+func TestDNS_ServiceLookup_ViewNotMatching(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			views = [
+				{
+					name = "remote"
+					source_cidrs = ["10.0.0.0/8"]
+					tagged_address = "wan"
+					datacenters = ["dc2"]
+				}
+			]
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	args := &structs.RegisterRequest{
+		Datacenter:      "dc1",
+		Node:            "foo",
+		Address:         "127.0.0.1",
+		TaggedAddresses: map[string]string{structs.TaggedAddressWAN: "198.18.0.1"},
+		Service: &structs.NodeService{
+			Service: "db",
+			Port:    12345,
+		},
+	}
+	var out struct{}
+	require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+	// Clients outside of the view's networks get the default answers.
+	for _, question := range []string{"db.service.consul.", "foo.node.consul."} {
+		m := new(dns.Msg)
+		m.SetQuestion(question, dns.TypeA)
+		c := new(dns.Client)
+		in, _, err := c.Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		require.Len(t, in.Answer, 1, question)
+		aRec, ok := in.Answer[0].(*dns.A)
+		require.True(t, ok, question)
+		require.Equal(t, "127.0.0.1", aRec.A.String(), question)
+	}
+}
+
+func TestDNS_ServiceLookup_ViewHidesDatacenter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := NewTestAgent(t, `
+		dns_config {
+			views = [
+				{
+					source_cidrs = ["127.0.0.0/8"]
+					datacenters = ["dc2"]
+				}
+			]
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	args := &structs.RegisterRequest{
+		Datacenter: "dc1",
+		Node:       "foo",
+		Address:    "127.0.0.1",
+		Service: &structs.NodeService{
+			Service: "db",
+			Port:    12345,
+		},
+	}
+	var out struct{}
+	require.NoError(t, a.RPC(context.Background(), "Catalog.Register", args, &out))
+
+	for _, question := range []string{"db.service.consul.", "foo.node.consul."} {
+		m := new(dns.Msg)
+		m.SetQuestion(question, dns.TypeA)
+		c := new(dns.Client)
+		in, _, err := c.Exchange(m, a.DNSAddr())
+		require.NoError(t, err)
+		require.Empty(t, in.Answer, question)
+		require.Equal(t, dns.RcodeNameError, in.Rcode, question)
+	}
+}
+
+func TestDNSView_ForSource(t *testing.T) {
+	_, internal, _ := net.ParseCIDR("10.0.0.0/8")
+	_, office, _ := net.ParseCIDR("192.168.0.0/16")
+	cfg := &dnsConfig{
+		Views: []*dnsView{
+			newDNSView(config.RuntimeDNSView{Name: "internal", SourceCIDRs: []*net.IPNet{internal}}),
+			newDNSView(config.RuntimeDNSView{Name: "office", SourceCIDRs: []*net.IPNet{office, internal}, Peers: []string{"Cluster-01"}}),
+		},
+	}
+
+	require.Equal(t, "internal", cfg.viewForSource(net.ParseIP("10.1.2.3")).Name)
+	require.Equal(t, "office", cfg.viewForSource(net.ParseIP("192.168.1.1")).Name)
+	require.Nil(t, cfg.viewForSource(net.ParseIP("172.16.0.1")))
+	require.Nil(t, cfg.viewForSource(nil))
+
+	view := cfg.viewForSource(net.ParseIP("192.168.1.1"))
+	require.True(t, view.peerVisible("cluster-01"))
+	require.True(t, view.peerVisible(""))
+	require.False(t, view.peerVisible("cluster-02"))
+	require.True(t, view.datacenterVisible("dc1"))
+
+	var none *dnsView
+	require.True(t, none.datacenterVisible("dc1"))
+	require.Equal(t, "10.0.0.1", none.nodeAddress("10.0.0.1", map[string]string{"wan": "1.2.3.4"}))
+}
+
 func TestDNS_CaseInsensitiveServiceLookup(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
@@ -4212,6 +4670,42 @@ func TestDNS_Recurse(t *testing.T) {
 	}
 }
 
+func TestDNS_Recurse_RateLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	recursor := makeRecursor(t, dns.Msg{
+		Answer: []dns.RR{dnsA("apple.com", "1.2.3.4")},
+	})
+	defer recursor.Shutdown()
+
+	a := NewTestAgent(t, `
+		recursors = ["`+recursor.Addr+`"]
+		dns_config {
+			rate_limit {
+				mode = "enforcing"
+				client_rate = 0.001
+				client_burst = 1
+				slip = 0
+			}
+		}
+	`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	m := new(dns.Msg)
+	m.SetQuestion("apple.com.", dns.TypeANY)
+	c := &dns.Client{Timeout: 200 * time.Millisecond}
+
+	// The recursive queries are dropped once the client exceeds its limit.
+	retry.Run(t, func(r *retry.R) {
+		_, _, err := c.Exchange(m, a.DNSAddr())
+		require.Error(r, err)
+	})
+}
+
 func TestDNS_Recurse_Truncation(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
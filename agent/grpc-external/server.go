@@ -29,15 +29,18 @@ func NewServer(logger agentmiddleware.Logger, metricsObj *metrics.Metrics, tls *
 		metricsObj = metrics.Default()
 	}
 	recoveryOpts := agentmiddleware.PanicHandlerMiddlewareOpts(logger)
+	accessorRateLimit := agentmiddleware.AccessorRateLimitInterceptor{Limiter: limiter}
 
 	unaryInterceptors := []grpc.UnaryServerInterceptor{
 		// Add middlware interceptors to recover in case of panics.
 		recovery.UnaryServerInterceptor(recoveryOpts...),
+		accessorRateLimit.InterceptUnary,
 	}
 	streamInterceptors := []grpc.StreamServerInterceptor{
 		// Add middlware interceptors to recover in case of panics.
 		recovery.StreamServerInterceptor(recoveryOpts...),
 		agentmiddleware.NewActiveStreamCounter(metricsObj, metricsLabels).Intercept,
+		accessorRateLimit.InterceptStream,
 	}
 
 	if tls != nil {
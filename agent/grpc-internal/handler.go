@@ -34,6 +34,7 @@ func NewHandler(logger Logger, addr net.Addr, register func(server *grpc.Server)
 	// We don't need to pass tls.Config to the server since it's multiplexed
 	// behind the RPC listener, which already has TLS configured.
 	recoveryOpts := agentmiddleware.PanicHandlerMiddlewareOpts(logger)
+	accessorRateLimit := agentmiddleware.AccessorRateLimitInterceptor{Limiter: rateLimiter}
 
 	opts := []grpc.ServerOption{
 		grpc.InTapHandle(agentmiddleware.ServerRateLimiterMiddleware(rateLimiter, agentmiddleware.NewPanicHandler(logger), logger)),
@@ -41,11 +42,13 @@ func NewHandler(logger Logger, addr net.Addr, register func(server *grpc.Server)
 		middleware.WithUnaryServerChain(
 			// Add middlware interceptors to recover in case of panics.
 			recovery.UnaryServerInterceptor(recoveryOpts...),
+			accessorRateLimit.InterceptUnary,
 		),
 		middleware.WithStreamServerChain(
 			// Add middlware interceptors to recover in case of panics.
 			recovery.StreamServerInterceptor(recoveryOpts...),
 			agentmiddleware.NewActiveStreamCounter(metricsObj, metricsLabels).Intercept,
+			accessorRateLimit.InterceptStream,
 		),
 		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
 			MinTime: 15 * time.Second,
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/tap"
@@ -44,21 +46,125 @@ func ServerRateLimiterMiddleware(limiter rate.RequestLimitsHandler, panicHandler
 			return ctx, nil
 		}
 
-		err := limiter.Allow(rate.Operation{
+		op := rate.Operation{
 			Name:       info.FullMethodName,
 			SourceAddr: peer.Addr,
 			Type:       operationType,
-		})
-
-		switch {
-		case err == nil:
-			return ctx, nil
-		case errors.Is(err, rate.ErrRetryElsewhere):
-			return ctx, status.Error(codes.ResourceExhausted, err.Error())
-		case errors.Is(err, rate.ErrRetryLater):
-			return ctx, status.Error(codes.Unavailable, err.Error())
-		default:
-			return ctx, status.Error(codes.Internal, err.Error())
+			Priority:   grpcRateLimitPriorities[info.FullMethodName],
 		}
+		return ctx, rateLimitStatusError(limiter.Allow(op))
+	}
+}
+
+// AccessorRateLimitInterceptor provides gRPC interceptors enforcing the
+// accessor-based rate limits. Unlike the other limits they're not enforced by
+// the ServerInHandle: it runs in the connection's goroutine and must not
+// block, but resolving the accessor ID of a token may require a blocking RPC
+// to the primary datacenter.
+//
+// The interceptors must be chained after the recovery ones, which handle
+// their panics.
+type AccessorRateLimitInterceptor struct {
+	Limiter rate.RequestLimitsHandler
+}
+
+// InterceptUnary enforces the accessor-based rate limits on non-streaming
+// gRPC calls.
+func (a *AccessorRateLimitInterceptor) InterceptUnary(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if err := a.allow(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// InterceptStream enforces the accessor-based rate limits on streaming gRPC
+// calls.
+func (a *AccessorRateLimitInterceptor) InterceptStream(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if err := a.allow(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (a *AccessorRateLimitInterceptor) allow(ctx context.Context, method string) error {
+	if _, exempt := accessorLimitExemptMethods[method]; exempt {
+		return nil
+	}
+
+	// Methods without a rate limit spec were already logged by the
+	// ServerInHandle.
+	operationType, ok := rpcRateLimitSpecs[method]
+	if !ok {
+		return nil
+	}
+
+	peer, ok := peer.FromContext(ctx)
+	if !ok {
+		// This should never happen!
+		return status.Error(codes.Internal, "gRPC rate limit interceptor unable to read peer")
+	}
+
+	return rateLimitStatusError(a.Limiter.AllowAccessor(rate.Operation{
+		Name:       method,
+		SourceAddr: peer.Addr,
+		Type:       operationType,
+		Priority:   grpcRateLimitPriorities[method],
+		Token:      tokenFromContext(ctx),
+	}))
+}
+
+// rateLimitStatusError converts an error returned by the rate limiter into a
+// gRPC status error.
+func rateLimitStatusError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, rate.ErrRetryElsewhere):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, rate.ErrRetryLater):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// Maps the gRPC methods which are shed first or last when the adaptive write
+// limit is reduced to their priority, in line with their net/rpc equivalents.
+// The other methods have the normal priority.
+var grpcRateLimitPriorities = map[string]rate.OperationPriority{
+	// Failing to log in invalidates the tokens clients already hold.
+	"/hashicorp.consul.acl.ACLService/Login": rate.OperationPriorityHigh,
+}
+
+// accessorLimitExemptMethods are the gRPC methods the accessor-based limits
+// don't apply to, because they don't take the ACL token from the request
+// metadata: it is part of the request message instead, or the peers
+// authenticate with the peering secrets.
+var accessorLimitExemptMethods = map[string]struct{}{
+	"/hashicorp.consul.acl.ACLService/Logout":                                 {},
+	"/hashicorp.consul.internal.peerstream.PeerStreamService/ExchangeSecret":  {},
+	"/hashicorp.consul.internal.peerstream.PeerStreamService/StreamResources": {},
+	"/subscribe.StateChangeSubscription/Subscribe":                            {},
+}
+
+// tokenFromContext returns the ACL token in the metadata of the request.
+func tokenFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if tokens := md.Get("x-consul-token"); len(tokens) > 0 {
+		return tokens[0]
 	}
+	return ""
 }
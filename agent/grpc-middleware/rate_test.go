@@ -4,19 +4,25 @@ import (
 	"context"
 	"errors"
 	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
 
 	pbacl "github.com/hashicorp/consul/proto-public/pbacl"
 
 	"github.com/hashicorp/go-hclog"
 
+	"github.com/hashicorp/consul/agent/consul/multilimiter"
 	"github.com/hashicorp/consul/agent/consul/rate"
 )
 
@@ -24,8 +30,10 @@ func TestServerRateLimiterMiddleware_Integration(t *testing.T) {
 	limiter := rate.NewMockRequestLimitsHandler(t)
 
 	logger := hclog.NewNullLogger()
+	accessorRateLimit := AccessorRateLimitInterceptor{Limiter: limiter}
 	server := grpc.NewServer(
 		grpc.InTapHandle(ServerRateLimiterMiddleware(limiter, NewPanicHandler(logger), logger)),
+		grpc.ChainUnaryInterceptor(accessorRateLimit.InterceptUnary),
 	)
 	pbacl.RegisterACLServiceServer(server, mockACLServer{})
 
@@ -59,6 +67,7 @@ func TestServerRateLimiterMiddleware_Integration(t *testing.T) {
 			Run(func(args mock.Arguments) {
 				op := args.Get(0).(rate.Operation)
 				require.Equal(t, "/hashicorp.consul.acl.ACLService/Login", op.Name)
+				require.Equal(t, rate.OperationPriorityHigh, op.Priority)
 
 				addr := op.SourceAddr.(*net.TCPAddr)
 				require.True(t, addr.IP.IsLoopback())
@@ -95,11 +104,47 @@ func TestServerRateLimiterMiddleware_Integration(t *testing.T) {
 		limiter.On("Allow", mock.Anything).
 			Return(nil).
 			Once()
+		limiter.On("AllowAccessor", mock.Anything).
+			Return(nil).
+			Once()
 
 		_, err = client.Login(ctx, &pbacl.LoginRequest{})
 		require.NoError(t, err)
 	})
 
+	t.Run("accessor limit uses the token metadata", func(t *testing.T) {
+		limiter.On("Allow", mock.Anything).
+			Return(nil).
+			Once()
+		limiter.On("AllowAccessor", mock.Anything).
+			Run(func(args mock.Arguments) {
+				op := args.Get(0).(rate.Operation)
+				require.Equal(t, "/hashicorp.consul.acl.ACLService/Login", op.Name)
+				require.Equal(t, rate.OperationTypeWrite, op.Type)
+				require.Equal(t, "my-token", op.Token)
+			}).
+			Return(rate.ErrRetryElsewhere).
+			Once()
+
+		tokenCtx := metadata.AppendToOutgoingContext(ctx, "x-consul-token", "my-token")
+		_, err = client.Login(tokenCtx, &pbacl.LoginRequest{})
+		require.Error(t, err)
+		require.Equal(t, codes.ResourceExhausted.String(), status.Code(err).String())
+	})
+
+	t.Run("accessor limit skipped for exempt methods", func(t *testing.T) {
+		limiter.On("Allow", mock.Anything).
+			Run(func(args mock.Arguments) {
+				op := args.Get(0).(rate.Operation)
+				require.Equal(t, rate.OperationPriorityNormal, op.Priority)
+			}).
+			Return(nil).
+			Once()
+
+		_, err = client.Logout(ctx, &pbacl.LogoutRequest{})
+		require.NoError(t, err)
+	})
+
 	t.Run("Allow panics", func(t *testing.T) {
 		limiter.On("Allow", mock.Anything).
 			Panic("uh oh").
@@ -111,6 +156,120 @@ func TestServerRateLimiterMiddleware_Integration(t *testing.T) {
 	})
 }
 
+func TestServerRateLimiterMiddleware_AccessorResolutionDoesNotBlock(t *testing.T) {
+	provider := &blockingLeaderStatusProvider{
+		slowToken: "slow-token",
+		resolving: make(chan struct{}),
+		unblock:   make(chan struct{}),
+	}
+	t.Cleanup(provider.release)
+
+	limiter := rate.NewHandler(rate.HandlerConfig{
+		AccessorMode:        rate.ModeEnforcing,
+		AccessorWriteConfig: multilimiter.LimiterConfig{Rate: 100, Burst: 100},
+		AccessorReadConfig:  multilimiter.LimiterConfig{Rate: 100, Burst: 100},
+	}, hclog.NewNullLogger())
+	limiter.Register(provider)
+
+	logger := hclog.NewNullLogger()
+	tapHandle := ServerRateLimiterMiddleware(limiter, NewPanicHandler(logger), logger)
+	accessorRateLimit := AccessorRateLimitInterceptor{Limiter: limiter}
+
+	t.Run("tap doesn't resolve the token", func(t *testing.T) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+		})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-consul-token", "slow-token"))
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := tapHandle(ctx, &tap.Info{FullMethodName: "/hashicorp.consul.acl.ACLService/Login"})
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("tap handle blocked on the accessor resolution")
+		}
+	})
+
+	t.Run("slow resolution only blocks its own call", func(t *testing.T) {
+		server := grpc.NewServer(
+			grpc.InTapHandle(tapHandle),
+			grpc.ChainUnaryInterceptor(accessorRateLimit.InterceptUnary),
+		)
+		pbacl.RegisterACLServiceServer(server, mockACLServer{})
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		go server.Serve(lis)
+		t.Cleanup(server.Stop)
+		// Let the blocked call return before the server is stopped.
+		t.Cleanup(provider.release)
+
+		conn, err := grpc.Dial(
+			lis.Addr().String(),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		client := pbacl.NewACLServiceClient(conn)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		go client.Login(metadata.AppendToOutgoingContext(ctx, "x-consul-token", "slow-token"), &pbacl.LoginRequest{})
+		select {
+		case <-provider.resolving:
+		case <-time.After(5 * time.Second):
+			t.Fatal("slow token was never resolved")
+		}
+
+		// The second call on the same connection completes while the first
+		// one is still waiting for its token to be resolved.
+		callCtx, callCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer callCancel()
+		_, err = client.Login(metadata.AppendToOutgoingContext(callCtx, "x-consul-token", "fast-token"), &pbacl.LoginRequest{})
+		require.NoError(t, err)
+	})
+}
+
+// blockingLeaderStatusProvider is a rate.LeaderStatusProvider whose accessor
+// ID resolution blocks for the slow token, like a cache miss in a secondary
+// datacenter waiting on the primary.
+type blockingLeaderStatusProvider struct {
+	slowToken string
+
+	// resolving is closed once the slow token is being resolved, which
+	// then blocks until unblock is closed.
+	resolving chan struct{}
+	unblock   chan struct{}
+
+	resolvingOnce sync.Once
+	releaseOnce   sync.Once
+}
+
+func (p *blockingLeaderStatusProvider) release() {
+	p.releaseOnce.Do(func() { close(p.unblock) })
+}
+
+func (*blockingLeaderStatusProvider) IsLeader() bool { return true }
+
+func (*blockingLeaderStatusProvider) IsServerAddr(net.Addr) bool { return false }
+
+func (*blockingLeaderStatusProvider) LeaderHealthSignals() rate.HealthSignals {
+	return rate.HealthSignals{}
+}
+
+func (p *blockingLeaderStatusProvider) ResolveAccessorID(token string) string {
+	if token == p.slowToken {
+		p.resolvingOnce.Do(func() { close(p.resolving) })
+		<-p.unblock
+	}
+	return "accessor-" + token
+}
+
 type mockACLServer struct {
 	pbacl.ACLServiceServer
 }
@@ -118,3 +277,7 @@ type mockACLServer struct {
 func (mockACLServer) Login(context.Context, *pbacl.LoginRequest) (*pbacl.LoginResponse, error) {
 	return &pbacl.LoginResponse{}, nil
 }
+
+func (mockACLServer) Logout(context.Context, *pbacl.LogoutRequest) (*pbacl.LogoutResponse, error) {
+	return &pbacl.LogoutResponse{}, nil
+}
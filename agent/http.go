@@ -374,6 +374,12 @@ func (s *HTTPHandlers) wrap(handler endpoint, methods []string) http.HandlerFunc
 		}
 		logURL = aclEndpointRE.ReplaceAllString(logURL, "$1<hidden>$4")
 
+		if s.agent.auditLogger != nil {
+			var logAudit func()
+			resp, logAudit = s.auditRequest(resp, req)
+			defer logAudit()
+		}
+
 		if s.denylist.Block(req.URL.Path) {
 			errMsg := "Endpoint is blocked by agent configuration"
 			httpLogger.Error("Request error",
@@ -548,6 +554,12 @@ func (s *HTTPHandlers) wrap(handler endpoint, methods []string) http.HandlerFunc
 				// Invoke the handler
 				obj, err = handler(resp, req)
 			}
+
+			if s.agent.tokenUsage != nil {
+				var token string
+				s.parseToken(req, &token)
+				s.agent.tokenUsage.record(token, sourceAddrFromRequest(req))
+			}
 		}
 		contentType := "application/json"
 		httpCode := http.StatusOK
@@ -0,0 +1,212 @@
+package agent
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/audit"
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+// auditMaxBodySize is the largest request body recorded in the audit log,
+// larger bodies only have their size recorded.
+const auditMaxBodySize = 64 * 1024
+
+// auditRawBodyPrefixes are the endpoints whose request body is stored as-is
+// rather than decoded, so only its size is recorded: the values of the KV
+// entries and the snapshots. The values could otherwise be recorded whenever
+// they happen to be valid JSON.
+var auditRawBodyPrefixes = []string{
+	"/v1/kv/",
+	"/v1/snapshot",
+}
+
+// auditRequest prepares the audit event of the request. It returns the
+// response writer the handler must use so the status can be recorded, and a
+// function to call once the request is done to log the event. When no sink
+// records requests for the endpoint the response writer is returned as-is and
+// the function is a no-op.
+func (s *HTTPHandlers) auditRequest(resp http.ResponseWriter, req *http.Request) (http.ResponseWriter, func()) {
+	record, recordBody := s.agent.auditLogger.Match(req.URL.Path)
+	if !record {
+		return resp, func() {}
+	}
+
+	start := time.Now()
+	var body *auditBodyReader
+	if recordBody && isWriteMethod(req.Method) && req.Body != nil {
+		body = &auditBodyReader{
+			ReadCloser: req.Body,
+			truncated:  isAuditRawBody(req.URL.Path),
+		}
+		req.Body = body
+	}
+	w := &auditResponseWriter{ResponseWriter: resp}
+
+	return w, func() {
+		id, err := uuid.GenerateUUID()
+		if err != nil {
+			s.agent.logger.Warn("failed to generate audit event ID", "error", err)
+		}
+
+		event := &audit.Event{
+			ID:        id,
+			Type:      audit.EventTypeHTTP,
+			Timestamp: start.UTC(),
+			Auth:      s.auditAuth(req),
+			Request: audit.Request{
+				Method:       req.Method,
+				Endpoint:     aclEndpointRE.ReplaceAllString(req.URL.Path, "$1<hidden>$4"),
+				Query:        auditQuery(req.URL.RawQuery),
+				SourceIP:     auditSourceIP(req.RemoteAddr),
+				ForwardedFor: req.Header.Get("X-Forwarded-For"),
+				UserAgent:    req.UserAgent(),
+			},
+			Response: audit.Response{
+				Status:    w.status(),
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			},
+		}
+		if body != nil {
+			event.Request.BodyBytes = body.n
+			if !body.truncated {
+				event.Request.Body = audit.RedactBody(body.buf.Bytes())
+			}
+		}
+
+		s.agent.auditLogger.Log(event)
+	}
+}
+
+// auditAuth resolves the token of the request. Only the accessor ID and the
+// auth method are recorded, never the secret.
+func (s *HTTPHandlers) auditAuth(req *http.Request) audit.Auth {
+	var token string
+	s.parseToken(req, &token)
+
+	ident, err := s.agent.delegate.ResolveTokenAndDefaultMeta(token, nil, nil)
+	if err != nil {
+		if !acl.IsErrNotFound(err) {
+			s.agent.logger.Debug("non-critical error resolving acl token for audit log", "error", err)
+		}
+		return audit.Auth{}
+	}
+
+	auth := audit.Auth{AccessorID: ident.AccessorID()}
+	if t, ok := ident.ACLIdentity.(*structs.ACLToken); ok {
+		auth.AuthMethod = t.AuthMethod
+	}
+	return auth
+}
+
+// auditQuery returns the query string with the values of the token parameter
+// hidden.
+func auditQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ""
+	}
+	if tokens, ok := query["token"]; ok {
+		for i := range tokens {
+			tokens[i] = "<hidden>"
+		}
+	}
+	return query.Encode()
+}
+
+// auditSourceIP returns the IP of the peer the request was received from.
+// Unlike sourceAddrFromRequest it ignores the X-Forwarded-For header since it
+// can be set by the client.
+func auditSourceIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+func isAuditRawBody(path string) bool {
+	for _, prefix := range auditRawBodyPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPut, http.MethodPost, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// auditBodyReader keeps a copy of the first auditMaxBodySize bytes of the
+// request body read by the handler. Only the size is counted once truncated
+// is set.
+type auditBodyReader struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	n         int64
+	truncated bool
+}
+
+func (r *auditBodyReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	if !r.truncated {
+		if r.buf.Len()+n > auditMaxBodySize {
+			r.truncated = true
+			r.buf.Reset()
+		} else {
+			r.buf.Write(p[:n])
+		}
+	}
+	return n, err
+}
+
+// auditResponseWriter records the status of the response.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *auditResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush is implemented for the endpoints streaming their response.
+func (w *auditResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *auditResponseWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/audit"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestHTTPHandlers_AuditLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	dir := t.TempDir()
+	a := NewTestAgent(t, TestACLConfig()+fmt.Sprintf(`
+		audit {
+			enabled = true
+			sink "test" {
+				type = "file"
+				format = "json"
+				path = %q
+				delivery_guarantee = "blocking"
+				include_paths = ["/v1/acl/", "/v1/kv/"]
+				exclude_paths = ["/v1/acl/token/self"]
+				log_request_body = true
+			}
+		}
+	`, filepath.Join(dir, "audit.json")))
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	do := func(method, url, token, body string) int {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", "198.51.100.1")
+		if token != "" {
+			req.Header.Set("X-Consul-Token", token)
+		}
+		resp := httptest.NewRecorder()
+		a.srv.handler(true).ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	require.Equal(t, http.StatusOK, do("PUT", "/v1/acl/token", "root",
+		`{"SecretID":"8f3f7f86-3d2f-4fb7-a4b1-60b5c7b5c6d5","Description":"audited"}`))
+	require.Equal(t, http.StatusForbidden, do("GET", "/v1/kv/foo?token=bogus", "", ""))
+	require.Equal(t, http.StatusOK, do("PUT", "/v1/kv/foo", "root", `{"password":"hunter2"}`))
+	require.Equal(t, http.StatusOK, do("GET", "/v1/acl/token/self", "root", ""))
+	require.Equal(t, http.StatusOK, do("GET", "/v1/agent/self", "root", ""))
+
+	files, err := filepath.Glob(filepath.Join(dir, "audit-*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events []audit.Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e audit.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, events, 3)
+
+	create := events[0]
+	require.NotEmpty(t, create.ID)
+	require.Equal(t, audit.EventTypeHTTP, create.Type)
+	require.Equal(t, "PUT", create.Request.Method)
+	require.Equal(t, "/v1/acl/token", create.Request.Endpoint)
+	require.Equal(t, "192.0.2.1", create.Request.SourceIP)
+	require.Equal(t, "198.51.100.1", create.Request.ForwardedFor)
+	require.Equal(t, http.StatusOK, create.Response.Status)
+	require.NotEmpty(t, create.Auth.AccessorID)
+	require.NotEqual(t, "root", create.Auth.AccessorID)
+	require.JSONEq(t, `{"SecretID":"<redacted>","Description":"audited"}`, string(create.Request.Body))
+
+	read := events[1]
+	require.Equal(t, "GET", read.Request.Method)
+	require.Equal(t, "/v1/kv/foo", read.Request.Endpoint)
+	require.Equal(t, "token=%3Chidden%3E", read.Request.Query)
+	require.Equal(t, http.StatusForbidden, read.Response.Status)
+	require.Empty(t, read.Auth.AccessorID)
+	require.Nil(t, read.Request.Body)
+
+	// The KV values are never recorded, even when they are JSON.
+	write := events[2]
+	require.Equal(t, "PUT", write.Request.Method)
+	require.Equal(t, "/v1/kv/foo", write.Request.Endpoint)
+	require.Equal(t, int64(len(`{"password":"hunter2"}`)), write.Request.BodyBytes)
+	require.Nil(t, write.Request.Body)
+}
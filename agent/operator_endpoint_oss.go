@@ -4,16 +4,43 @@
 package agent
 
 import (
+	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/api"
 	autopilot "github.com/hashicorp/raft-autopilot"
 )
 
-func autopilotToAPIServerEnterprise(_ *autopilot.ServerState, _ *api.AutopilotServer) {
-	// noop in oss
+func autopilotToAPIServerEnterprise(srv *autopilot.ServerState, apiSrv *api.AutopilotServer) {
+	ext, err := structs.DecodeAutopilotServerExt(srv.Server.Ext)
+	if err != nil || ext == nil {
+		return
+	}
+
+	apiSrv.RedundancyZone = ext.RedundancyZone
+	apiSrv.ReadReplica = ext.ReadReplica
 }
 
 func autopilotToAPIStateEnterprise(state *autopilot.State, apiState *api.AutopilotState) {
-	// without the enterprise features there is no different between these two and we don't want to
+	// without any standby servers there is no different between these two and we don't want to
 	// alarm anyone by leaving this as the zero value.
 	apiState.OptimisticFailureTolerance = state.FailureTolerance
+
+	ext, err := structs.DecodeAutopilotStateExt(state.Ext)
+	if err != nil || ext == nil {
+		return
+	}
+
+	apiState.OptimisticFailureTolerance = ext.OptimisticFailureTolerance
+	if len(ext.ReadReplicas) > 0 {
+		apiState.ReadReplicas = stringIDs(ext.ReadReplicas)
+	}
+	for name, zone := range ext.RedundancyZones {
+		if apiState.RedundancyZones == nil {
+			apiState.RedundancyZones = make(map[string]api.AutopilotZone)
+		}
+		apiState.RedundancyZones[name] = api.AutopilotZone{
+			Servers:          stringIDs(zone.Servers),
+			Voters:           stringIDs(zone.Voters),
+			FailureTolerance: zone.FailureTolerance,
+		}
+	}
 }
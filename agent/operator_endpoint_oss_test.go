@@ -9,8 +9,12 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/raft"
+	autopilot "github.com/hashicorp/raft-autopilot"
 	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
 )
 
 func TestOperator_Usage(t *testing.T) {
@@ -86,3 +90,66 @@ func upsertTestService(rpc rpcFn, secret, datacenter, name, node, partition stri
 	var out struct{}
 	return rpc(context.Background(), "Catalog.Register", &req, &out)
 }
+
+func TestAutopilotStateToAPIConversion_RedundancyZones(t *testing.T) {
+	// The extended state is a map once it was received over RPC.
+	input := autopilot.State{
+		Healthy:          true,
+		FailureTolerance: 0,
+		Leader:           "a",
+		Voters:           []raft.ServerID{"a"},
+		Servers: map[raft.ServerID]*autopilot.ServerState{
+			"a": {
+				Server: autopilot.Server{
+					ID:       "a",
+					NodeType: "zone-voter",
+					Ext:      map[string]interface{}{"RedundancyZone": "z1", "ReadReplica": false},
+				},
+				State: autopilot.RaftLeader,
+			},
+			"b": {
+				Server: autopilot.Server{
+					ID:       "b",
+					NodeType: "zone-standby",
+					Ext:      map[string]interface{}{"RedundancyZone": "z1", "ReadReplica": false},
+				},
+				State: autopilot.RaftNonVoter,
+			},
+			"c": {
+				Server: autopilot.Server{
+					ID:       "c",
+					NodeType: "read-replica",
+					Ext:      map[string]interface{}{"RedundancyZone": "", "ReadReplica": true},
+				},
+				State: autopilot.RaftNonVoter,
+			},
+		},
+		Ext: map[string]interface{}{
+			"OptimisticFailureTolerance": int64(1),
+			"ReadReplicas":               []interface{}{"c"},
+			"RedundancyZones": map[string]interface{}{
+				"z1": map[string]interface{}{
+					"Servers":          []interface{}{"a", "b"},
+					"Voters":           []interface{}{"a"},
+					"FailureTolerance": int64(1),
+				},
+			},
+		},
+	}
+
+	out := autopilotToAPIState(&input)
+	require.Equal(t, 1, out.OptimisticFailureTolerance)
+	require.Equal(t, []string{"c"}, out.ReadReplicas)
+	require.Equal(t, map[string]api.AutopilotZone{
+		"z1": {
+			Servers:          []string{"a", "b"},
+			Voters:           []string{"a"},
+			FailureTolerance: 1,
+		},
+	}, out.RedundancyZones)
+
+	require.Equal(t, "z1", out.Servers["b"].RedundancyZone)
+	require.Equal(t, api.AutopilotTypeZoneStandby, out.Servers["b"].NodeType)
+	require.True(t, out.Servers["c"].ReadReplica)
+	require.Equal(t, api.AutopilotTypeReadReplica, out.Servers["c"].NodeType)
+}
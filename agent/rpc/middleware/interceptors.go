@@ -160,6 +160,12 @@ func GetNetRPCInterceptor(recorder *RequestRecorder) rpc.ServerServiceCallInterc
 	}
 }
 
+// NetRPCOperationType returns the type of operation performed by the given
+// net/rpc endpoint for rate limiting purposes.
+func NetRPCOperationType(reqServiceMethod string) rpcRate.OperationType {
+	return rpcRateLimitSpecs[reqServiceMethod]
+}
+
 func GetNetRPCRateLimitingInterceptor(requestLimitsHandler rpcRate.RequestLimitsHandler, panicHandler RecoveryHandlerFunc) rpc.PreBodyInterceptor {
 
 	return func(reqServiceMethod string, sourceAddr net.Addr) (retErr error) {
@@ -173,7 +179,8 @@ func GetNetRPCRateLimitingInterceptor(requestLimitsHandler rpcRate.RequestLimits
 		op := rpcRate.Operation{
 			Name:       reqServiceMethod,
 			SourceAddr: sourceAddr,
-			Type:       rpcRateLimitSpecs[reqServiceMethod],
+			Type:       NetRPCOperationType(reqServiceMethod),
+			Priority:   rpcRateLimitPriorities[reqServiceMethod],
 		}
 
 		// net/rpc does not provide a way to encode the nuances of the
@@ -289,6 +289,28 @@ func TestGetNetRPCRateLimitingInterceptor(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("operation priority", func(t *testing.T) {
+		limiter.On("Allow", rate.Operation{
+			Name:       "Session.Renew",
+			SourceAddr: addr,
+			Type:       rate.OperationTypeWrite,
+			Priority:   rate.OperationPriorityHigh,
+		}).
+			Return(nil).
+			Once()
+		limiter.On("Allow", rate.Operation{
+			Name:       "KVS.Apply",
+			SourceAddr: addr,
+			Type:       rate.OperationTypeWrite,
+			Priority:   rate.OperationPriorityLow,
+		}).
+			Return(nil).
+			Once()
+
+		require.NoError(t, rateLimitInterceptor("Session.Renew", addr))
+		require.NoError(t, rateLimitInterceptor("KVS.Apply", addr))
+	})
+
 	t.Run("allow returns error", func(t *testing.T) {
 		limiter.On("Allow", mock.Anything).
 			Return(errors.New("uh oh")).
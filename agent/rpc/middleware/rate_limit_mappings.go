@@ -11,6 +11,7 @@ var rpcRateLimitSpecs = map[string]rate.OperationType{
 	"ACL.AuthMethodRead":    rate.OperationTypeRead,
 	"ACL.AuthMethodSet":     rate.OperationTypeWrite,
 	"ACL.Authorize":         rate.OperationTypeRead,
+	"ACL.AuthorizeExplain":  rate.OperationTypeRead,
 	"ACL.BindingRuleDelete": rate.OperationTypeWrite,
 	"ACL.BindingRuleList":   rate.OperationTypeRead,
 	"ACL.BindingRuleRead":   rate.OperationTypeRead,
@@ -36,7 +37,10 @@ var rpcRateLimitSpecs = map[string]rate.OperationType{
 	"ACL.TokenDelete":       rate.OperationTypeWrite,
 	"ACL.TokenList":         rate.OperationTypeRead,
 	"ACL.TokenRead":         rate.OperationTypeRead,
+	"ACL.TokenRenew":        rate.OperationTypeWrite,
+	"ACL.TokenRotate":       rate.OperationTypeWrite,
 	"ACL.TokenSet":          rate.OperationTypeWrite,
+	"ACL.TokenUsageUpdate":  rate.OperationTypeWrite,
 
 	"AutoConfig.InitialConfiguration": rate.OperationTypeRead,
 
@@ -142,3 +146,21 @@ var rpcRateLimitSpecs = map[string]rate.OperationType{
 	"Txn.Apply": rate.OperationTypeWrite,
 	"Txn.Read":  rate.OperationTypeRead,
 }
+
+// Maps the net/rpc endpoints which are shed first or last when the
+// adaptive write limit is reduced to their priority. The other endpoints
+// have the normal priority.
+var rpcRateLimitPriorities = map[string]rate.OperationPriority{
+	// Anti-entropy re-registers the services and checks periodically, and
+	// the coordinates are updated continuously.
+	"Catalog.Deregister": rate.OperationPriorityLow,
+	"Catalog.Register":   rate.OperationPriorityLow,
+	"Coordinate.Update":  rate.OperationPriorityLow,
+	"KVS.Apply":          rate.OperationPriorityLow,
+	"Txn.Apply":          rate.OperationPriorityLow,
+
+	// Failing to renew a session or to log in invalidates the locks and
+	// tokens clients already hold.
+	"ACL.Login":     rate.OperationPriorityHigh,
+	"Session.Renew": rate.OperationPriorityHigh,
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/raft-wal/verifier"
 	"google.golang.org/grpc/grpclog"
 
+	"github.com/hashicorp/consul/agent/audit"
 	autoconf "github.com/hashicorp/consul/agent/auto-config"
 	"github.com/hashicorp/consul/agent/cache"
 	"github.com/hashicorp/consul/agent/config"
@@ -288,6 +289,7 @@ func getPrometheusDefs(cfg *config.RuntimeConfig, isServer bool) ([]prometheus.G
 			consul.LeaderCertExpirationGauges,
 			consul.LeaderPeeringMetrics,
 			xdscapacity.StatsGauges,
+			rate.Gauges,
 		)
 	}
 
@@ -347,8 +349,10 @@ func getPrometheusDefs(cfg *config.RuntimeConfig, isServer bool) ([]prometheus.G
 
 	var counters = [][]prometheus.CounterDefinition{
 		CatalogCounters,
+		audit.Counters,
 		cache.Counters,
 		consul.ACLCounters,
+		consul.ACLTokenUsageCounters,
 		consul.CatalogCounters,
 		consul.ClientCounters,
 		consul.RPCCounters,
@@ -7,16 +7,21 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/go-bexpr"
+
 	"github.com/hashicorp/consul/lib/stringslice"
 
 	"golang.org/x/crypto/blake2b"
 
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/lib"
+	"github.com/hashicorp/consul/lib/template"
 )
 
 type ACLMode string
@@ -96,6 +101,7 @@ type ACLIdentity interface {
 	RoleIDs() []string
 	ServiceIdentityList() []*ACLServiceIdentity
 	NodeIdentityList() []*ACLNodeIdentity
+	TemplatedPolicyList() []*ACLTemplatedPolicy
 	IsExpired(asOf time.Time) bool
 	IsLocal() bool
 	EnterpriseMetadata() *acl.EnterpriseMeta
@@ -258,6 +264,143 @@ func (ids ACLNodeIdentities) Deduplicate() ACLNodeIdentities {
 	return results
 }
 
+// ACLTemplatedPolicy links a policy template to a token or role, with the
+// values of the variables of the template. The resolver renders the template
+// into a synthetic policy.
+type ACLTemplatedPolicy struct {
+	// ID of the policy template. Like policy links, templates linked by Name
+	// are resolved to an ID before the link is persisted.
+	ID   string
+	Name string `hash:"ignore"`
+
+	// Variables holds the value of each variable of the template.
+	Variables map[string]string `json:",omitempty"`
+}
+
+func (t *ACLTemplatedPolicy) Clone() *ACLTemplatedPolicy {
+	t2 := *t
+	if t.Variables != nil {
+		t2.Variables = make(map[string]string, len(t.Variables))
+		for k, v := range t.Variables {
+			t2.Variables[k] = v
+		}
+	}
+	return &t2
+}
+
+func (t *ACLTemplatedPolicy) AddToHash(h hash.Hash) {
+	h.Write([]byte(t.ID))
+	for _, name := range t.variableNames() {
+		h.Write([]byte(name))
+		h.Write([]byte(t.Variables[name]))
+	}
+}
+
+func (t *ACLTemplatedPolicy) EstimateSize() int {
+	size := len(t.ID) + len(t.Name)
+	for k, v := range t.Variables {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+func (t *ACLTemplatedPolicy) variableNames() []string {
+	names := make([]string, 0, len(t.Variables))
+	for name := range t.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// key identifies the template and the values of its variables.
+func (t *ACLTemplatedPolicy) key() string {
+	var b strings.Builder
+	b.WriteString(t.ID)
+	for _, name := range t.variableNames() {
+		fmt.Fprintf(&b, "\x00%s=%s", name, t.Variables[name])
+	}
+	return b.String()
+}
+
+// Validate checks that the policy is a template and that the link gives a
+// valid value to each of its variables.
+func (t *ACLTemplatedPolicy) Validate(policy *ACLPolicy) error {
+	if !policy.IsTemplate() {
+		return fmt.Errorf("ACL policy %q is not a template", policy.Name)
+	}
+	if _, err := policy.RenderTemplate(t.Variables); err != nil {
+		return fmt.Errorf("ACL policy template %q: %w", policy.Name, err)
+	}
+	return nil
+}
+
+// SyntheticPolicy renders the linked policy template with the values of the
+// variables. The synthetic policy has the same datacenters as the template.
+func (t *ACLTemplatedPolicy) SyntheticPolicy(policy *ACLPolicy) (*ACLPolicy, error) {
+	rules, err := policy.RenderTemplate(t.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := fnv.New128a()
+	hashID := fmt.Sprintf("%x", hasher.Sum([]byte(rules)))
+
+	synthetic := &ACLPolicy{}
+	synthetic.ID = hashID
+	synthetic.Name = fmt.Sprintf("synthetic-policy-%s", hashID)
+	synthetic.Description = fmt.Sprintf("synthetic policy for policy template %q", policy.Name)
+	synthetic.Rules = rules
+	synthetic.Datacenters = stringslice.CloneStringSlice(policy.Datacenters)
+	synthetic.EnterpriseMeta.Merge(&policy.EnterpriseMeta)
+	synthetic.SetHash(true)
+	return synthetic, nil
+}
+
+type ACLTemplatedPolicies []*ACLTemplatedPolicy
+
+// Deduplicate returns a new list of templated policies without links to the
+// same template with the same variables.
+func (tps ACLTemplatedPolicies) Deduplicate() ACLTemplatedPolicies {
+	seen := make(map[string]struct{})
+
+	var results ACLTemplatedPolicies
+	for _, tp := range tps {
+		key := tp.key()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		results = append(results, tp.Clone())
+		seen[key] = struct{}{}
+	}
+	return results
+}
+
+// PolicyIDs returns the IDs of the linked policy templates.
+func (tps ACLTemplatedPolicies) PolicyIDs() []string {
+	if len(tps) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(tps))
+	for _, tp := range tps {
+		ids = append(ids, tp.ID)
+	}
+	return ids
+}
+
+func (tps ACLTemplatedPolicies) clone() ACLTemplatedPolicies {
+	if len(tps) == 0 {
+		return nil
+	}
+
+	out := make(ACLTemplatedPolicies, 0, len(tps))
+	for _, tp := range tps {
+		out = append(out, tp.Clone())
+	}
+	return out
+}
+
 type ACLToken struct {
 	// This is the UUID used for tracking and management purposes
 	AccessorID string
@@ -284,6 +427,10 @@ type ACLToken struct {
 	// The node identities that this token should be allowed to manage.
 	NodeIdentities ACLNodeIdentities `json:",omitempty"`
 
+	// List of policy templates to render synthetic policies for, with the
+	// values of their variables.
+	TemplatedPolicies ACLTemplatedPolicies `json:",omitempty"`
+
 	// Whether this token is DC local. This means that it will not be synced
 	// to the ACL datacenter and replicated to others.
 	Local bool
@@ -311,9 +458,52 @@ type ACLToken struct {
 	// This is a string version of a time.Duration like "2m".
 	ExpirationTTL time.Duration `json:",omitempty"`
 
+	// Renewable tokens can have their ExpirationTime extended by renewing
+	// them, up to MaxExpirationTime if it's set. Renewable tokens always
+	// have an ExpirationTime.
+	Renewable bool `json:",omitempty"`
+
+	// RenewalTTL is how far into the future renewing the token extends its
+	// ExpirationTime, unless the renewal asks for a different increment. It's
+	// initialized from the ExpirationTime of renewable tokens when they are
+	// created.
+	RenewalTTL time.Duration `json:",omitempty"`
+
+	// MaxExpirationTime is the point after which renewing a token can't
+	// extend its ExpirationTime any further. The zero value allows renewing
+	// the token indefinitely.
+	//
+	// This is a pointer value so that the zero value is omitted properly
+	// during json serialization.
+	MaxExpirationTime *time.Time `json:",omitempty"`
+
+	// MaxExpirationTTL is a convenience field for helping set
+	// MaxExpirationTime to a value of CreateTime+MaxExpirationTTL. Like
+	// ExpirationTTL it can only be set during TokenCreate and is never
+	// persisted.
+	MaxExpirationTTL time.Duration `json:",omitempty"`
+
 	// The time when this token was created
 	CreateTime time.Time `json:",omitempty"`
 
+	// PreviousSecretID is the SecretID the token had before it was last
+	// rotated. It remains valid until PreviousSecretExpirationTime, so that
+	// holders of the old secret have time to switch to the new one.
+	PreviousSecretID string `json:",omitempty"`
+
+	// PreviousSecretExpirationTime is the end of the grace period of the
+	// last rotation, after which PreviousSecretID can no longer be used. It's
+	// kept once the previous secret has been cleared, to tell rotations apart.
+	//
+	// This is a pointer value so that the zero value is omitted properly
+	// during json serialization.
+	PreviousSecretExpirationTime *time.Time `json:",omitempty"`
+
+	// Usage records approximately when, from where and through which agent
+	// the token was last used. It is maintained separately by the servers in
+	// each datacenter, so it isn't part of the Hash and is never replicated.
+	Usage ACLTokenUsage
+
 	// Hash of the contents of the token
 	//
 	// This is needed mainly for replication purposes. When replicating from
@@ -328,11 +518,124 @@ type ACLToken struct {
 	RaftIndex
 }
 
+// ACLTokenUsage is the approximate usage of a token, as reported by the
+// agents that the token was presented to.
+type ACLTokenUsage struct {
+	// LastUsedTime is when the token was last presented to an agent. The
+	// servers only record a new time when the previous one is older than
+	// their update interval, so this can lag behind by that much.
+	//
+	// This is a pointer value so that the zero value is omitted properly
+	// during json serialization.
+	LastUsedTime *time.Time `json:",omitempty"`
+
+	// SourceAddr is the address of the client that last used the token.
+	SourceAddr string `json:",omitempty"`
+
+	// Agent is the name of the agent that the token was last presented to.
+	Agent string `json:",omitempty"`
+}
+
+// UnusedFor returns whether the token has gone unused for at least the given
+// duration. Tokens without any recorded usage are always considered unused.
+func (u ACLTokenUsage) UnusedFor(d time.Duration) bool {
+	if u.LastUsedTime == nil {
+		return true
+	}
+	return time.Since(*u.LastUsedTime) >= d
+}
+
+// FieldConfigurations implements bexpr.MatchExpressionEvaluator so that
+// tokens can be filtered by how long they've been unused, which can't be
+// expressed with comparisons against the LastUsedTime alone.
+func (u ACLTokenUsage) FieldConfigurations() bexpr.FieldConfigurations {
+	stringOps := []bexpr.MatchOperator{
+		bexpr.MatchEqual,
+		bexpr.MatchNotEqual,
+		bexpr.MatchIsEmpty,
+		bexpr.MatchIsNotEmpty,
+		bexpr.MatchMatches,
+		bexpr.MatchNotMatches,
+	}
+	return bexpr.FieldConfigurations{
+		"SourceAddr": &bexpr.FieldConfiguration{
+			CoerceFn:            bexpr.CoerceString,
+			SupportedOperations: stringOps,
+		},
+		"Agent": &bexpr.FieldConfiguration{
+			CoerceFn:            bexpr.CoerceString,
+			SupportedOperations: stringOps,
+		},
+		"UnusedFor": &bexpr.FieldConfiguration{
+			CoerceFn:            coerceUsageDuration,
+			SupportedOperations: []bexpr.MatchOperator{bexpr.MatchEqual, bexpr.MatchNotEqual},
+		},
+	}
+}
+
+// EvaluateMatch implements bexpr.MatchExpressionEvaluator.
+func (u ACLTokenUsage) EvaluateMatch(sel bexpr.Selector, op bexpr.MatchOperator, value interface{}) (bool, error) {
+	if len(sel) != 1 {
+		return false, fmt.Errorf("invalid token usage selector %q", strings.Join(sel, "."))
+	}
+
+	var field string
+	switch sel[0] {
+	case "SourceAddr":
+		field = u.SourceAddr
+	case "Agent":
+		field = u.Agent
+	case "UnusedFor":
+		d, ok := value.(time.Duration)
+		if !ok {
+			return false, fmt.Errorf("invalid duration for token usage selector %q", sel[0])
+		}
+		return u.UnusedFor(d) == (op == bexpr.MatchEqual), nil
+	default:
+		return false, fmt.Errorf("invalid token usage selector %q", sel[0])
+	}
+
+	switch op {
+	case bexpr.MatchEqual:
+		return field == value, nil
+	case bexpr.MatchNotEqual:
+		return field != value, nil
+	case bexpr.MatchIsEmpty:
+		return field == "", nil
+	case bexpr.MatchIsNotEmpty:
+		return field != "", nil
+	case bexpr.MatchMatches, bexpr.MatchNotMatches:
+		re, ok := value.(*regexp.Regexp)
+		if !ok {
+			return false, fmt.Errorf("invalid regular expression for token usage selector %q", sel[0])
+		}
+		return re.MatchString(field) == (op == bexpr.MatchMatches), nil
+	default:
+		return false, fmt.Errorf("invalid match operation for token usage selector %q", sel[0])
+	}
+}
+
+// coerceUsageDuration parses a duration for the UnusedFor selector. On top of
+// the usual Go durations it supports a number of days like "90d", since that's
+// what token cleanup policies are usually expressed in.
+func coerceUsageDuration(value string) (interface{}, error) {
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid number of days %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
 func (t *ACLToken) UnmarshalJSON(data []byte) (err error) {
 	type Alias ACLToken
 	aux := &struct {
-		ExpirationTTL interface{}
-		Hash          string
+		ExpirationTTL    interface{}
+		RenewalTTL       interface{}
+		MaxExpirationTTL interface{}
+		Hash             string
 		*Alias
 	}{
 		Alias: (*Alias)(t),
@@ -341,16 +644,22 @@ func (t *ACLToken) UnmarshalJSON(data []byte) (err error) {
 	if err = lib.UnmarshalJSON(data, &aux); err != nil {
 		return err
 	}
-	if aux.ExpirationTTL != nil {
-		switch v := aux.ExpirationTTL.(type) {
+	for _, ttl := range []struct {
+		raw interface{}
+		out *time.Duration
+	}{
+		{aux.ExpirationTTL, &t.ExpirationTTL},
+		{aux.RenewalTTL, &t.RenewalTTL},
+		{aux.MaxExpirationTTL, &t.MaxExpirationTTL},
+	} {
+		switch v := ttl.raw.(type) {
 		case string:
-			if t.ExpirationTTL, err = time.ParseDuration(v); err != nil {
+			if *ttl.out, err = time.ParseDuration(v); err != nil {
 				return err
 			}
 		case float64:
-			t.ExpirationTTL = time.Duration(v)
+			*ttl.out = time.Duration(v)
 		}
-
 	}
 	if aux.Hash != "" {
 		t.Hash = []byte(aux.Hash)
@@ -364,6 +673,7 @@ func (t *ACLToken) Clone() *ACLToken {
 	t2.Roles = nil
 	t2.ServiceIdentities = nil
 	t2.NodeIdentities = nil
+	t2.TemplatedPolicies = t.TemplatedPolicies.clone()
 
 	if len(t.Policies) > 0 {
 		t2.Policies = make([]ACLTokenPolicyLink, len(t.Policies))
@@ -433,6 +743,10 @@ func (t *ACLToken) ServiceIdentityList() []*ACLServiceIdentity {
 	return out
 }
 
+func (t *ACLToken) TemplatedPolicyList() []*ACLTemplatedPolicy {
+	return t.TemplatedPolicies.clone()
+}
+
 func (t *ACLToken) IsExpired(asOf time.Time) bool {
 	if asOf.IsZero() || !t.HasExpirationTime() {
 		return false
@@ -448,6 +762,31 @@ func (t *ACLToken) HasExpirationTime() bool {
 	return t.ExpirationTime != nil && !t.ExpirationTime.IsZero()
 }
 
+// HasPreviousSecret returns whether the token was rotated with a grace period
+// that hasn't ended as of the given time.
+func (t *ACLToken) HasPreviousSecret(asOf time.Time) bool {
+	if t.PreviousSecretID == "" || t.PreviousSecretExpirationTime == nil {
+		return false
+	}
+	return !t.PreviousSecretExpirationTime.Before(asOf)
+}
+
+// PreviousSecretView returns a copy of the token as seen by holders of its
+// previous SecretID. The copy carries the previous SecretID, so the new one
+// isn't revealed to them, and expires at the end of the grace period unless
+// the token itself expires earlier.
+func (t *ACLToken) PreviousSecretView() *ACLToken {
+	view := t.Clone()
+	view.SecretID = t.PreviousSecretID
+	if !t.HasExpirationTime() || t.PreviousSecretExpirationTime.Before(*t.ExpirationTime) {
+		expirationTime := *t.PreviousSecretExpirationTime
+		view.ExpirationTime = &expirationTime
+	}
+	view.PreviousSecretID = ""
+	view.PreviousSecretExpirationTime = nil
+	return view
+}
+
 func (t *ACLToken) EnterpriseMetadata() *acl.EnterpriseMeta {
 	return &t.EnterpriseMeta
 }
@@ -493,8 +832,28 @@ func (t *ACLToken) SetHash(force bool) []byte {
 			nodeID.AddToHash(hash)
 		}
 
+		for _, tp := range t.TemplatedPolicies {
+			tp.AddToHash(hash)
+		}
+
 		t.EnterpriseMeta.AddToHash(hash, false)
 
+		// Rotating a token changes its SecretID, which replication has to
+		// pick up. Every rotation sets a new end of the grace period, even
+		// without one, which is included instead of the secrets themselves.
+		if t.PreviousSecretExpirationTime != nil {
+			hash.Write([]byte(t.PreviousSecretExpirationTime.UTC().Format(time.RFC3339Nano)))
+		}
+
+		// The ExpirationTime of renewable tokens changes when they are
+		// renewed, which replication has to pick up.
+		if t.Renewable {
+			hash.Write([]byte("renewable"))
+			if t.HasExpirationTime() {
+				hash.Write([]byte(t.ExpirationTime.UTC().Format(time.RFC3339Nano)))
+			}
+		}
+
 		// Finalize the hash
 		hashVal := hash.Sum(nil)
 
@@ -506,7 +865,7 @@ func (t *ACLToken) SetHash(force bool) []byte {
 
 func (t *ACLToken) EstimateSize() int {
 	// 41 = 16 (RaftIndex) + 8 (Hash) + 8 (ExpirationTime) + 8 (CreateTime) + 1 (Local)
-	size := 41 + len(t.AccessorID) + len(t.SecretID) + len(t.Description) + len(t.AuthMethod)
+	size := 41 + len(t.AccessorID) + len(t.SecretID) + len(t.Description) + len(t.AuthMethod) + len(t.PreviousSecretID)
 	for _, link := range t.Policies {
 		size += len(link.ID) + len(link.Name)
 	}
@@ -519,6 +878,9 @@ func (t *ACLToken) EstimateSize() int {
 	for _, nodeID := range t.NodeIdentities {
 		size += nodeID.EstimateSize()
 	}
+	for _, tp := range t.TemplatedPolicies {
+		size += tp.EstimateSize()
+	}
 	return size + t.EnterpriseMeta.EstimateSize()
 }
 
@@ -533,10 +895,12 @@ type ACLTokenListStub struct {
 	Roles             []ACLTokenRoleLink   `json:",omitempty"`
 	ServiceIdentities ACLServiceIdentities `json:",omitempty"`
 	NodeIdentities    ACLNodeIdentities    `json:",omitempty"`
+	TemplatedPolicies ACLTemplatedPolicies `json:",omitempty"`
 	Local             bool
 	AuthMethod        string     `json:",omitempty"`
 	ExpirationTime    *time.Time `json:",omitempty"`
 	CreateTime        time.Time  `json:",omitempty"`
+	Usage             ACLTokenUsage
 	Hash              []byte
 	CreateIndex       uint64
 	ModifyIndex       uint64
@@ -555,10 +919,12 @@ func (token *ACLToken) Stub() *ACLTokenListStub {
 		Roles:                       token.Roles,
 		ServiceIdentities:           token.ServiceIdentities,
 		NodeIdentities:              token.NodeIdentities,
+		TemplatedPolicies:           token.TemplatedPolicies,
 		Local:                       token.Local,
 		AuthMethod:                  token.AuthMethod,
 		ExpirationTime:              token.ExpirationTime,
 		CreateTime:                  token.CreateTime,
+		Usage:                       token.Usage,
 		Hash:                        token.Hash,
 		CreateIndex:                 token.CreateIndex,
 		ModifyIndex:                 token.ModifyIndex,
@@ -567,6 +933,26 @@ func (token *ACLToken) Stub() *ACLTokenListStub {
 	}
 }
 
+// MarshalJSON omits the usage of the tokens that were never used. Usage isn't
+// a pointer because the filters can't select its fields through a nil one.
+// Unmarshaling is not implemented because the API is read only
+func (token *ACLTokenListStub) MarshalJSON() ([]byte, error) {
+	type Alias ACLTokenListStub
+	exported := &struct {
+		Usage *ACLTokenUsage `json:",omitempty"`
+		*Alias
+	}{
+		Alias: (*Alias)(token),
+	}
+	if token.Usage.LastUsedTime != nil {
+		exported.Usage = &token.Usage
+	}
+
+	data, err := json.Marshal(exported)
+
+	return data, err
+}
+
 func (tokens ACLTokens) Sort() {
 	sort.Slice(tokens, func(i, j int) bool {
 		return tokens[i].AccessorID < tokens[j].AccessorID
@@ -599,6 +985,12 @@ type ACLPolicy struct {
 	//   - If empty then the policy is valid within all datacenters
 	Datacenters []string `json:",omitempty"`
 
+	// TemplateVariables makes the policy a template. Its rules can refer to
+	// the variables as ${var.<name>}, and tokens and roles link it as a
+	// templated policy with a value for each of them instead of linking it
+	// directly.
+	TemplateVariables []string `json:",omitempty"`
+
 	// Hash of the contents of the policy
 	// This does not take into account the ID (which is immutable)
 	// nor the raft metadata.
@@ -636,30 +1028,68 @@ func (t *ACLPolicy) UnmarshalJSON(data []byte) error {
 func (p *ACLPolicy) Clone() *ACLPolicy {
 	p2 := *p
 	p2.Datacenters = stringslice.CloneStringSlice(p.Datacenters)
+	p2.TemplateVariables = stringslice.CloneStringSlice(p.TemplateVariables)
 	return &p2
 }
 
+// IsTemplate returns whether the policy is a template.
+func (p *ACLPolicy) IsTemplate() bool {
+	return len(p.TemplateVariables) > 0
+}
+
+// RenderTemplate returns the rules of the policy template with its variables
+// replaced by the given values. Every variable of the template must be given
+// a value.
+func (p *ACLPolicy) RenderTemplate(vars map[string]string) (string, error) {
+	declared := make(map[string]struct{}, len(p.TemplateVariables))
+	for _, name := range p.TemplateVariables {
+		if _, ok := vars[name]; !ok {
+			return "", fmt.Errorf("missing value for variable %q", name)
+		}
+		declared[name] = struct{}{}
+	}
+
+	hilVars := make(map[string]string, len(vars))
+	for name, value := range vars {
+		if _, ok := declared[name]; !ok {
+			return "", fmt.Errorf("unknown variable %q", name)
+		}
+		if !acl.IsValidTemplateVariableValue(value) {
+			return "", fmt.Errorf("invalid value for variable %q: it must not be empty, and quotes, backslashes, braces, dollar signs and control characters are not allowed", name)
+		}
+		hilVars["var."+name] = value
+	}
+
+	rules, err := template.InterpolateHIL(p.Rules, hilVars, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to render rules: %w", err)
+	}
+	return rules, nil
+}
+
 type ACLPolicyListStub struct {
-	ID          string
-	Name        string
-	Description string
-	Datacenters []string
-	Hash        []byte
-	CreateIndex uint64
-	ModifyIndex uint64
+	ID                string
+	Name              string
+	Description       string
+	Datacenters       []string
+	TemplateVariables []string `json:",omitempty"`
+	Hash              []byte
+	CreateIndex       uint64
+	ModifyIndex       uint64
 	acl.EnterpriseMeta
 }
 
 func (p *ACLPolicy) Stub() *ACLPolicyListStub {
 	return &ACLPolicyListStub{
-		ID:             p.ID,
-		Name:           p.Name,
-		Description:    p.Description,
-		Datacenters:    p.Datacenters,
-		Hash:           p.Hash,
-		CreateIndex:    p.CreateIndex,
-		ModifyIndex:    p.ModifyIndex,
-		EnterpriseMeta: p.EnterpriseMeta,
+		ID:                p.ID,
+		Name:              p.Name,
+		Description:       p.Description,
+		Datacenters:       p.Datacenters,
+		TemplateVariables: p.TemplateVariables,
+		Hash:              p.Hash,
+		CreateIndex:       p.CreateIndex,
+		ModifyIndex:       p.ModifyIndex,
+		EnterpriseMeta:    p.EnterpriseMeta,
 	}
 }
 
@@ -689,6 +1119,9 @@ func (p *ACLPolicy) SetHash(force bool) []byte {
 		for _, dc := range p.Datacenters {
 			hash.Write([]byte(dc))
 		}
+		for _, name := range p.TemplateVariables {
+			hash.Write([]byte(name))
+		}
 
 		p.EnterpriseMeta.AddToHash(hash, false)
 
@@ -710,6 +1143,9 @@ func (p *ACLPolicy) EstimateSize() int {
 	for _, dc := range p.Datacenters {
 		size += len(dc)
 	}
+	for _, name := range p.TemplateVariables {
+		size += len(name)
+	}
 
 	return size + p.EnterpriseMeta.EstimateSize()
 }
@@ -840,6 +1276,10 @@ type ACLRole struct {
 	// List of nodes to generate synthetic policies for.
 	NodeIdentities ACLNodeIdentities `json:",omitempty"`
 
+	// List of policy templates to render synthetic policies for, with the
+	// values of their variables.
+	TemplatedPolicies ACLTemplatedPolicies `json:",omitempty"`
+
 	// Hash of the contents of the role
 	// This does not take into account the ID (which is immutable)
 	// nor the raft metadata.
@@ -879,6 +1319,7 @@ func (r *ACLRole) Clone() *ACLRole {
 	r2.Policies = nil
 	r2.ServiceIdentities = nil
 	r2.NodeIdentities = nil
+	r2.TemplatedPolicies = r.TemplatedPolicies.clone()
 
 	if len(r.Policies) > 0 {
 		r2.Policies = make([]ACLRolePolicyLink, len(r.Policies))
@@ -899,6 +1340,10 @@ func (r *ACLRole) Clone() *ACLRole {
 	return &r2
 }
 
+func (r *ACLRole) TemplatedPolicyList() []*ACLTemplatedPolicy {
+	return r.TemplatedPolicies.clone()
+}
+
 func (r *ACLRole) SetHash(force bool) []byte {
 	if force || r.Hash == nil {
 		// Initialize a 256bit Blake2 hash (32 bytes)
@@ -927,6 +1372,9 @@ func (r *ACLRole) SetHash(force bool) []byte {
 		for _, nodeID := range r.NodeIdentities {
 			nodeID.AddToHash(hash)
 		}
+		for _, tp := range r.TemplatedPolicies {
+			tp.AddToHash(hash)
+		}
 
 		r.EnterpriseMeta.AddToHash(hash, false)
 
@@ -954,6 +1402,9 @@ func (r *ACLRole) EstimateSize() int {
 	for _, nodeID := range r.NodeIdentities {
 		size += nodeID.EstimateSize()
 	}
+	for _, tp := range r.TemplatedPolicies {
+		size += tp.EstimateSize()
+	}
 
 	return size + r.EnterpriseMeta.EstimateSize()
 }
@@ -1002,6 +1453,27 @@ const (
 	//   }
 	// }
 	BindingRuleBindTypeNode = "node"
+
+	// BindingRuleBindTypeTemplatedPolicy is the binding rule bind type that
+	// links the policy template named by the computed BindName to the token
+	// that is created, with the values of its variables computed from
+	// BindVars like:
+	//
+	// &ACLToken{
+	//   ...other fields...
+	//   TemplatedPolicies: []*ACLTemplatedPolicy{
+	//     &ACLTemplatedPolicy{
+	//       Name: "<computed BindName>",
+	//       Variables: map[string]string{
+	//         "<variable>": "<computed BindVars value>",
+	//       },
+	//     },
+	//   },
+	// }
+	//
+	// If no policy template with that name exists at login-time the rule is
+	// ignored.
+	BindingRuleBindTypeTemplatedPolicy = "templated-policy"
 )
 
 type ACLBindingRule struct {
@@ -1021,8 +1493,10 @@ type ACLBindingRule struct {
 	// BindType adjusts how this binding rule is applied at login time.  The
 	// valid values are:
 	//
-	//  - BindingRuleBindTypeService = "service"
-	//  - BindingRuleBindTypeRole    = "role"
+	//  - BindingRuleBindTypeService        = "service"
+	//  - BindingRuleBindTypeRole           = "role"
+	//  - BindingRuleBindTypeNode           = "node"
+	//  - BindingRuleBindTypeTemplatedPolicy = "templated-policy"
 	BindType string
 
 	// BindName is the target of the binding. Can be lightly templated using
@@ -1030,6 +1504,12 @@ type ACLBindingRule struct {
 	// upon the BindType.
 	BindName string
 
+	// BindVars are the values of the variables of the policy template linked
+	// by a "templated-policy" binding rule. Like BindName the values can be
+	// templated using HIL ${foo} syntax from available field names, but
+	// unlike it they are not lowercased.
+	BindVars map[string]string `json:",omitempty"`
+
 	// Embedded Enterprise ACL metadata
 	acl.EnterpriseMeta `mapstructure:",squash"`
 
@@ -1039,6 +1519,12 @@ type ACLBindingRule struct {
 
 func (r *ACLBindingRule) Clone() *ACLBindingRule {
 	r2 := *r
+	if r.BindVars != nil {
+		r2.BindVars = make(map[string]string, len(r.BindVars))
+		for k, v := range r.BindVars {
+			r2.BindVars[k] = v
+		}
+	}
 	return &r2
 }
 
@@ -1138,6 +1624,15 @@ type ACLAuthMethod struct {
 	// This can be either 'local' or 'global'. If empty 'local' is assumed.
 	TokenLocality string `json:",omitempty"`
 
+	// TokenRenewable makes the tokens created by this method renewable, by
+	// MaxTokenTTL at a time.
+	TokenRenewable bool `json:",omitempty"`
+
+	// TokenMaxExpirationTTL is the maximum life of a renewable token created
+	// by this method, including renewals. The zero value allows renewing the
+	// tokens indefinitely.
+	TokenMaxExpirationTTL time.Duration `json:",omitempty"`
+
 	// Configuration is arbitrary configuration for the auth method. This
 	// should only contain primitive values and containers (such as lists and
 	// maps).
@@ -1155,15 +1650,20 @@ type ACLAuthMethod struct {
 func (m *ACLAuthMethod) MarshalJSON() ([]byte, error) {
 	type Alias ACLAuthMethod
 	exported := &struct {
-		MaxTokenTTL string `json:",omitempty"`
+		MaxTokenTTL           string `json:",omitempty"`
+		TokenMaxExpirationTTL string `json:",omitempty"`
 		*Alias
 	}{
-		MaxTokenTTL: m.MaxTokenTTL.String(),
-		Alias:       (*Alias)(m),
+		MaxTokenTTL:           m.MaxTokenTTL.String(),
+		TokenMaxExpirationTTL: m.TokenMaxExpirationTTL.String(),
+		Alias:                 (*Alias)(m),
 	}
 	if m.MaxTokenTTL == 0 {
 		exported.MaxTokenTTL = ""
 	}
+	if m.TokenMaxExpirationTTL == 0 {
+		exported.TokenMaxExpirationTTL = ""
+	}
 
 	return json.Marshal(exported)
 }
@@ -1171,7 +1671,8 @@ func (m *ACLAuthMethod) MarshalJSON() ([]byte, error) {
 func (m *ACLAuthMethod) UnmarshalJSON(data []byte) (err error) {
 	type Alias ACLAuthMethod
 	aux := &struct {
-		MaxTokenTTL interface{}
+		MaxTokenTTL           interface{}
+		TokenMaxExpirationTTL interface{}
 		*Alias
 	}{
 		Alias: (*Alias)(m),
@@ -1189,6 +1690,16 @@ func (m *ACLAuthMethod) UnmarshalJSON(data []byte) (err error) {
 			m.MaxTokenTTL = time.Duration(v)
 		}
 	}
+	if aux.TokenMaxExpirationTTL != nil {
+		switch v := aux.TokenMaxExpirationTTL.(type) {
+		case string:
+			if m.TokenMaxExpirationTTL, err = time.ParseDuration(v); err != nil {
+				return err
+			}
+		case float64:
+			m.TokenMaxExpirationTTL = time.Duration(v)
+		}
+	}
 
 	return nil
 }
@@ -1242,6 +1753,34 @@ func (r *ACLTokenSetRequest) RequestDatacenter() string {
 	return r.Datacenter
 }
 
+// ACLTokenRotateRequest is used to issue a new SecretID for a token at the
+// RPC layer
+type ACLTokenRotateRequest struct {
+	AccessorID  string        // Accessor ID of the token to rotate
+	GracePeriod time.Duration // How long the current SecretID remains valid
+	Datacenter  string        // The datacenter to perform the request within
+	acl.EnterpriseMeta
+	WriteRequest
+}
+
+func (r *ACLTokenRotateRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLTokenRenewRequest is used to extend the expiration time of a renewable
+// token at the RPC layer
+type ACLTokenRenewRequest struct {
+	AccessorID string        // Accessor ID of the token to renew
+	Increment  time.Duration // How far from now to extend the expiration time, zero for the token's RenewalTTL
+	Datacenter string        // The datacenter to perform the request within
+	acl.EnterpriseMeta
+	WriteRequest
+}
+
+func (r *ACLTokenRenewRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
 // ACLTokenGetRequest is used for token read operations at the RPC layer
 type ACLTokenGetRequest struct {
 	TokenID     string         // Accessor ID used for the token lookup
@@ -1316,6 +1855,9 @@ type ACLTokenBatchSetRequest struct {
 	AllowMissingLinks    bool
 	ProhibitUnprivileged bool
 	FromReplication      bool
+
+	// Rotate allows the SecretID of existing tokens to be changed.
+	Rotate bool
 }
 
 // ACLTokenBatchDeleteRequest is used only at the Raft layer
@@ -1327,6 +1869,33 @@ type ACLTokenBatchDeleteRequest struct {
 	TokenIDs []string // Tokens to delete
 }
 
+// ACLTokenUsageUpdateRequest is used by agents to report the tokens that were
+// presented to them.
+type ACLTokenUsageUpdateRequest struct {
+	Datacenter string
+	Node       string                   // The agent reporting the usage
+	Usage      map[string]ACLTokenUsage // Latest usage keyed by SecretID
+	WriteRequest
+}
+
+func (r *ACLTokenUsageUpdateRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+// ACLTokenUsageUpdate is the latest usage of a single token, used at the Raft
+// layer to batch the updates from many agents together.
+type ACLTokenUsageUpdate struct {
+	AccessorID string
+	Usage      ACLTokenUsage
+	acl.EnterpriseMeta
+}
+
+// ACLTokenUsageBatchUpdateRequest is used only at the Raft layer for batching
+// token usage updates.
+type ACLTokenUsageBatchUpdateRequest struct {
+	Updates []*ACLTokenUsageUpdate
+}
+
 type ACLInitialTokenBootstrapRequest struct {
 	BootstrapSecret string
 	Datacenter      string
@@ -1778,6 +2347,94 @@ func CreateACLAuthorizationResponses(authz acl.Authorizer, requests []ACLAuthori
 	return responses, nil
 }
 
+// ACLAuthorizeExplainRequest is used to explain the authorization decisions
+// made for a token, optionally with additional policies that don't exist yet.
+type ACLAuthorizeExplainRequest struct {
+	Datacenter string
+
+	// AccessorID is the token to explain. When empty the token used to make
+	// the request is explained. Explaining another token requires acl:read.
+	AccessorID string
+
+	// Requests are the authorizations to explain.
+	Requests []ACLAuthorizationRequest
+
+	// Policies are simulated policies that are evaluated along with the ones
+	// linked to the token. Only their Name and Rules are used.
+	Policies []*ACLPolicy
+
+	// PoliciesOnly only evaluates the simulated policies, ignoring everything
+	// that's linked to the token.
+	PoliciesOnly bool
+
+	QueryOptions
+}
+
+func (r *ACLAuthorizeExplainRequest) RequestDatacenter() string {
+	return r.Datacenter
+}
+
+const (
+	ACLAuthorizationSourcePolicy          = "policy"
+	ACLAuthorizationSourceServiceIdentity = "service-identity"
+	ACLAuthorizationSourceNodeIdentity    = "node-identity"
+	ACLAuthorizationSourceTemplatedPolicy = "templated-policy"
+	ACLAuthorizationSourceSimulated       = "simulated"
+)
+
+// ACLAuthorizationSource is a rule that applies to an authorization request
+// and where it came from.
+type ACLAuthorizationSource struct {
+	// Type is one of the ACLAuthorizationSource* constants.
+	Type string
+
+	// ID and Name identify the policy. For service and node identities Name
+	// is the name of the service or node instead, for templated policies ID
+	// and Name identify the template.
+	ID   string `json:",omitempty"`
+	Name string
+
+	// RoleID and RoleName are set when the source is linked to the token
+	// through a role.
+	RoleID   string `json:",omitempty"`
+	RoleName string `json:",omitempty"`
+
+	// Rule is the rule that applies, in policy syntax.
+	Rule string
+
+	// Decision is the decision the rule makes on its own, either "allow" or
+	// "deny".
+	Decision string
+}
+
+// ACLAuthorizationExplanation is the explanation of a single authorization.
+type ACLAuthorizationExplanation struct {
+	ACLAuthorizationRequest
+	Allow bool
+
+	// DefaultPolicy is set when no rule applies and the decision falls back
+	// to the default policy.
+	DefaultPolicy bool
+
+	// Source is the rule that made the decision. It's nil when the decision
+	// came from the default policy.
+	Source *ACLAuthorizationSource `json:",omitempty"`
+
+	// Matches are all the rules that apply, the most specific first.
+	Matches []ACLAuthorizationSource
+}
+
+type ACLAuthorizeExplainResponse struct {
+	// AccessorID is the token that was explained, empty when only simulated
+	// policies were evaluated.
+	AccessorID string
+
+	// DefaultPolicy is the default policy of the datacenter.
+	DefaultPolicy string
+
+	Explanations []ACLAuthorizationExplanation
+}
+
 type AgentRecoveryTokenIdentity struct {
 	agent    string
 	secretID string
@@ -1814,6 +2471,10 @@ func (id *AgentRecoveryTokenIdentity) NodeIdentityList() []*ACLNodeIdentity {
 	return nil
 }
 
+func (id *AgentRecoveryTokenIdentity) TemplatedPolicyList() []*ACLTemplatedPolicy {
+	return nil
+}
+
 func (id *AgentRecoveryTokenIdentity) IsExpired(asOf time.Time) bool {
 	return false
 }
@@ -1862,6 +2523,10 @@ func (i *ACLServerIdentity) NodeIdentityList() []*ACLNodeIdentity {
 	return nil
 }
 
+func (i *ACLServerIdentity) TemplatedPolicyList() []*ACLTemplatedPolicy {
+	return nil
+}
+
 func (i *ACLServerIdentity) IsExpired(asOf time.Time) bool {
 	return false
 }
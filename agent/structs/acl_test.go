@@ -1,9 +1,13 @@
 package structs
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/hashicorp/go-bexpr"
 
 	"github.com/hashicorp/consul/acl"
 
@@ -114,6 +118,107 @@ func TestStructs_ACLNodeIdentities_Deduplicate(t *testing.T) {
 	require.Len(t, identities, 3, "original slice shouldn't have been mutated")
 }
 
+func TestStructs_ACLPolicy_RenderTemplate(t *testing.T) {
+	policy := &ACLPolicy{
+		Name:              "kv-app",
+		Rules:             `key_prefix "${var.prefix}" { policy = "write" }`,
+		TemplateVariables: []string{"prefix"},
+	}
+	require.True(t, policy.IsTemplate())
+
+	cases := map[string]struct {
+		vars      map[string]string
+		expect    string
+		expectErr string
+	}{
+		"valid": {
+			vars:   map[string]string{"prefix": "apps/Web"},
+			expect: `key_prefix "apps/Web" { policy = "write" }`,
+		},
+		"missing value": {
+			vars:      nil,
+			expectErr: `missing value for variable "prefix"`,
+		},
+		"unknown variable": {
+			vars:      map[string]string{"prefix": "apps", "other": "x"},
+			expectErr: `unknown variable "other"`,
+		},
+		"quote injection": {
+			vars:      map[string]string{"prefix": `" { policy = "write" } key_prefix "`},
+			expectErr: `invalid value for variable "prefix"`,
+		},
+		"empty value": {
+			vars:      map[string]string{"prefix": ""},
+			expectErr: `invalid value for variable "prefix"`,
+		},
+		"interpolation injection": {
+			vars:      map[string]string{"prefix": "${var.prefix}"},
+			expectErr: `invalid value for variable "prefix"`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rules, err := policy.RenderTemplate(tc.vars)
+			if tc.expectErr != "" {
+				require.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, rules)
+		})
+	}
+}
+
+func TestStructs_ACLTemplatedPolicy_SyntheticPolicy(t *testing.T) {
+	policy := &ACLPolicy{
+		ID:                "4f1a0b66-0f2e-4b0a-8ec9-0b0bb1c1a5b5",
+		Name:              "kv-app",
+		Datacenters:       []string{"dc1"},
+		Rules:             `key_prefix "${var.prefix}" { policy = "write" }`,
+		TemplateVariables: []string{"prefix"},
+	}
+
+	web := &ACLTemplatedPolicy{ID: policy.ID, Variables: map[string]string{"prefix": "web"}}
+	require.NoError(t, web.Validate(policy))
+
+	got, err := web.SyntheticPolicy(policy)
+	require.NoError(t, err)
+	require.NotEmpty(t, got.ID)
+	require.True(t, strings.HasPrefix(got.Name, "synthetic-policy-"))
+	require.Equal(t, `key_prefix "web" { policy = "write" }`, got.Rules)
+	require.Equal(t, []string{"dc1"}, got.Datacenters)
+	require.Empty(t, got.TemplateVariables)
+
+	db := &ACLTemplatedPolicy{ID: policy.ID, Variables: map[string]string{"prefix": "db"}}
+	got2, err := db.SyntheticPolicy(policy)
+	require.NoError(t, err)
+	require.NotEqual(t, got.ID, got2.ID)
+
+	_, err = (&ACLTemplatedPolicy{ID: policy.ID}).SyntheticPolicy(policy)
+	require.Error(t, err)
+
+	notTemplate := &ACLPolicy{Name: "plain", Rules: `node_prefix "" { policy = "read" }`}
+	require.ErrorContains(t, web.Validate(notTemplate), "is not a template")
+}
+
+func TestStructs_ACLTemplatedPolicies_Deduplicate(t *testing.T) {
+	templated := ACLTemplatedPolicies{
+		{ID: "a", Variables: map[string]string{"prefix": "web"}},
+		{ID: "a", Variables: map[string]string{"prefix": "db"}},
+		{ID: "a", Variables: map[string]string{"prefix": "web"}},
+		{ID: "b", Variables: map[string]string{"prefix": "web"}},
+	}
+
+	require.Equal(t, ACLTemplatedPolicies{
+		{ID: "a", Variables: map[string]string{"prefix": "web"}},
+		{ID: "a", Variables: map[string]string{"prefix": "db"}},
+		{ID: "b", Variables: map[string]string{"prefix": "web"}},
+	}, templated.Deduplicate())
+
+	require.Len(t, templated, 4, "original slice shouldn't have been mutated")
+}
+
 func TestStructs_ACLToken_SetHash(t *testing.T) {
 
 	token := ACLToken{
@@ -215,6 +320,56 @@ func TestStructs_ACLToken_Stub(t *testing.T) {
 		require.Equal(t, token.CreateIndex, stub.CreateIndex)
 		require.Equal(t, token.ModifyIndex, stub.ModifyIndex)
 	})
+
+	t.Run("Stub JSON Usage", func(t *testing.T) {
+		stub := &ACLTokenListStub{AccessorID: "09d1c059-961a-46bd-a2e4-76adebe35fa5"}
+
+		data, err := json.Marshal(stub)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), `"Usage"`)
+
+		used := time.Now()
+		stub.Usage = ACLTokenUsage{LastUsedTime: &used, Agent: "node-1"}
+		data, err = json.Marshal(stub)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"Agent":"node-1"`)
+		require.Contains(t, string(data), `"AccessorID":"09d1c059-961a-46bd-a2e4-76adebe35fa5"`)
+	})
+}
+
+func TestStructs_ACLTokenUsage_Filter(t *testing.T) {
+	recent := time.Now().Add(-time.Hour)
+	stale := time.Now().Add(-100 * 24 * time.Hour)
+
+	tokens := ACLTokenListStubs{
+		{AccessorID: "recent", Usage: ACLTokenUsage{LastUsedTime: &recent, SourceAddr: "10.0.0.1", Agent: "node-1"}},
+		{AccessorID: "stale", Usage: ACLTokenUsage{LastUsedTime: &stale, SourceAddr: "192.168.0.1", Agent: "node-2"}},
+		{AccessorID: "never"},
+	}
+
+	run := func(t *testing.T, expression string) []string {
+		t.Helper()
+		filter, err := bexpr.CreateFilter(expression, nil, tokens)
+		require.NoError(t, err)
+		raw, err := filter.Execute(tokens)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, token := range raw.(ACLTokenListStubs) {
+			ids = append(ids, token.AccessorID)
+		}
+		return ids
+	}
+
+	require.Equal(t, []string{"stale", "never"}, run(t, `Usage.UnusedFor == "90d"`))
+	require.Equal(t, []string{"recent"}, run(t, `Usage.UnusedFor != "90d"`))
+	require.Equal(t, []string{"stale", "never"}, run(t, `Usage.UnusedFor == "2h"`))
+	require.Equal(t, []string{"recent"}, run(t, `Usage.Agent == "node-1"`))
+	require.Equal(t, []string{"stale"}, run(t, `Usage.SourceAddr matches "^192\\.168\\."`))
+	require.Equal(t, []string{"never"}, run(t, `Usage.Agent is empty`))
+
+	_, err := bexpr.CreateFilter(`Usage.UnusedFor == "ninety days"`, nil, tokens)
+	require.Error(t, err)
 }
 
 func TestStructs_ACLTokens_Sort(t *testing.T) {
@@ -3,8 +3,10 @@ package structs
 import (
 	"time"
 
+	"github.com/hashicorp/raft"
 	autopilot "github.com/hashicorp/raft-autopilot"
 	"github.com/hashicorp/serf/serf"
+	"github.com/mitchellh/mapstructure"
 )
 
 // Autopilotconfig holds the Autopilot configuration for a cluster.
@@ -30,8 +32,10 @@ type AutopilotConfig struct {
 	// applicable with Raft protocol version 3 or higher.
 	ServerStabilizationTime time.Duration
 
-	// (Enterprise-only) RedundancyZoneTag is the node tag to use for separating
-	// servers into zones for redundancy. If left blank, this feature will be disabled.
+	// RedundancyZoneTag is the node meta key to use for separating servers
+	// into zones for redundancy. Autopilot keeps one voter in each zone and
+	// promotes another server of the zone when it fails. If left blank, this
+	// feature will be disabled.
 	RedundancyZoneTag string
 
 	// (Enterprise-only) DisableUpgradeMigration will disable Autopilot's upgrade migration
@@ -62,6 +66,77 @@ func (c *AutopilotConfig) ToAutopilotLibraryConfig() *autopilot.Config {
 	}
 }
 
+// AutopilotConfigExt is the part of the Autopilot configuration used by the
+// redundancy zone promoter.
+type AutopilotConfigExt struct {
+	RedundancyZoneTag string
+}
+
+// AutopilotServerExt is the extended state of a server tracked by Autopilot.
+type AutopilotServerExt struct {
+	// RedundancyZone is the zone of the server, read from its node meta with
+	// the RedundancyZoneTag.
+	RedundancyZone string
+
+	// ReadReplica is whether the server is a read replica, read replicas are
+	// never promoted to voters.
+	ReadReplica bool
+}
+
+// AutopilotStateExt is the extended state of the cluster tracked by
+// Autopilot.
+type AutopilotStateExt struct {
+	// OptimisticFailureTolerance is the number of voters that could fail one
+	// after the other, when the failed voters are replaced by the healthy
+	// servers of their zone.
+	OptimisticFailureTolerance int
+
+	ReadReplicas    []raft.ServerID
+	RedundancyZones map[string]AutopilotZone
+}
+
+// AutopilotZone is the state of a redundancy zone.
+type AutopilotZone struct {
+	Servers []raft.ServerID
+	Voters  []raft.ServerID
+
+	// FailureTolerance is the number of healthy servers of the zone that
+	// could be lost while the zone can still have a healthy voter.
+	FailureTolerance int
+}
+
+// DecodeAutopilotServerExt returns the extended state of a server. The state
+// is decoded from a map when it was received over RPC.
+func DecodeAutopilotServerExt(ext interface{}) (*AutopilotServerExt, error) {
+	if ext == nil {
+		return nil, nil
+	}
+	if out, ok := ext.(*AutopilotServerExt); ok {
+		return out, nil
+	}
+	var out AutopilotServerExt
+	if err := mapstructure.Decode(ext, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DecodeAutopilotStateExt returns the extended state of the cluster. The
+// state is decoded from a map when it was received over RPC.
+func DecodeAutopilotStateExt(ext interface{}) (*AutopilotStateExt, error) {
+	if ext == nil {
+		return nil, nil
+	}
+	if out, ok := ext.(*AutopilotStateExt); ok {
+		return out, nil
+	}
+	var out AutopilotStateExt
+	if err := mapstructure.Decode(ext, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 // AutopilotHealthReply is a representation of the overall health of the cluster
 type AutopilotHealthReply struct {
 	// Healthy is true if all the servers in the cluster are healthy.
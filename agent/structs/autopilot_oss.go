@@ -4,5 +4,7 @@
 package structs
 
 func (c *AutopilotConfig) autopilotConfigExt() interface{} {
-	return nil
+	return &AutopilotConfigExt{
+		RedundancyZoneTag: c.RedundancyZoneTag,
+	}
 }
@@ -1,8 +1,10 @@
 package structs
 
 import (
+	"fmt"
 	"strconv"
 
+	"github.com/hashicorp/go-bexpr"
 	"github.com/mitchellh/hashstructure"
 
 	"github.com/hashicorp/consul/acl"
@@ -25,6 +27,32 @@ type QueryFailoverOptions struct {
 	// Targets is a fixed list of datacenters and peers to try. This field cannot
 	// be populated with NearestN or Datacenters.
 	Targets []QueryFailoverTarget
+
+	// MinHealthy is the minimum number of healthy nodes the local datacenter
+	// must return. If fewer are found, the local results are merged with the
+	// results of the failover targets, in order, until the threshold is met.
+	// Zero disables the threshold, so we only fail over when there are no
+	// healthy nodes.
+	MinHealthy int
+
+	// MinHealthyPercent is like MinHealthy, but expressed as a percentage
+	// (0-100) of all the local nodes matching the query, including unhealthy
+	// ones. When both are set the higher threshold applies.
+	MinHealthyPercent int
+}
+
+// HealthyThreshold returns the number of healthy nodes below which results
+// are merged with the results of the failover targets, given the total number
+// of nodes matching the query. It returns 0 if no threshold is set.
+func (f *QueryFailoverOptions) HealthyThreshold(total int) int {
+	threshold := f.MinHealthy
+	if f.MinHealthyPercent > 0 {
+		// Round up so that any percentage requires at least one node.
+		if n := (total*f.MinHealthyPercent + 99) / 100; n > threshold {
+			threshold = n
+		}
+	}
+	return threshold
 }
 
 // AsTargets either returns Targets as is or Datacenters converted into
@@ -97,6 +125,11 @@ type ServiceQuery struct {
 	// service entry to be returned.
 	ServiceMeta map[string]string
 
+	// Filter is an optional bexpr filter expression that is evaluated against
+	// each of the service instances, after the other filters above have been
+	// applied. Only instances that match the expression are returned.
+	Filter string
+
 	// Connect if true will filter the prepared query results to only
 	// include Connect-capable services. These include both native services
 	// and proxies for matching services. Note that if a proxy matches,
@@ -113,6 +146,18 @@ type ServiceQuery struct {
 	acl.EnterpriseMeta `hcl:",squash" mapstructure:",squash"`
 }
 
+// ValidateFilter makes sure the filter expression, if any, is valid for the
+// service instances it will be evaluated against.
+func (q *ServiceQuery) ValidateFilter() error {
+	if q.Filter == "" {
+		return nil
+	}
+	if _, err := bexpr.CreateFilter(q.Filter, nil, CheckServiceNodes{}); err != nil {
+		return fmt.Errorf("Bad Filter '%s': %v", q.Filter, err)
+	}
+	return nil
+}
+
 const (
 	// QueryTemplateTypeNamePrefixMatch uses the Name field of the query as
 	// a prefix to select the template.
@@ -356,10 +401,30 @@ type PreparedQueryExecuteResponse struct {
 	// datacenter.
 	Failovers int
 
+	// Sources lists the datacenters and peers that contributed nodes to the
+	// results, in order, when a failover health threshold is set. Nodes from
+	// several sources are merged when the local datacenter is below the
+	// threshold.
+	Sources []QueryResultSource `json:",omitempty"`
+
 	// QueryMeta has freshness information about the query.
 	QueryMeta
 }
 
+// QueryResultSource is a datacenter or peer that contributed nodes to the
+// results of a prepared query.
+type QueryResultSource struct {
+	// Datacenter is the datacenter the nodes came from. It is empty for
+	// nodes imported from a peer.
+	Datacenter string
+
+	// PeerName is the cluster peer the nodes came from.
+	PeerName string
+
+	// Nodes is the number of nodes from this source.
+	Nodes int
+}
+
 // PreparedQueryExplainResponse has the results when explaining a query/
 type PreparedQueryExplainResponse struct {
 	// Query has the fully-rendered query.
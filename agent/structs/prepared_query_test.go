@@ -2,6 +2,8 @@ package structs
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestStructs_PreparedQuery_GetACLPrefix(t *testing.T) {
@@ -33,3 +35,25 @@ func TestPreparedQueryExecuteRequest_CacheInfoKey(t *testing.T) {
 	ignored := []string{"Agent", "QueryOptions"}
 	assertCacheInfoKeyIsComplete(t, &PreparedQueryExecuteRequest{}, ignored...)
 }
+
+func TestQueryFailoverOptions_HealthyThreshold(t *testing.T) {
+	cases := []struct {
+		name      string
+		failover  QueryFailoverOptions
+		total     int
+		threshold int
+	}{
+		{"no threshold", QueryFailoverOptions{}, 10, 0},
+		{"count", QueryFailoverOptions{MinHealthy: 3}, 10, 3},
+		{"percent", QueryFailoverOptions{MinHealthyPercent: 50}, 10, 5},
+		{"percent rounds up", QueryFailoverOptions{MinHealthyPercent: 10}, 3, 1},
+		{"percent of nothing", QueryFailoverOptions{MinHealthyPercent: 50}, 0, 0},
+		{"higher count", QueryFailoverOptions{MinHealthy: 7, MinHealthyPercent: 50}, 10, 7},
+		{"higher percent", QueryFailoverOptions{MinHealthy: 2, MinHealthyPercent: 50}, 10, 5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.threshold, tc.failover.HealthyThreshold(tc.total))
+		})
+	}
+}
@@ -84,6 +84,7 @@ const (
 	PeeringTrustBundleDeleteType                = 39
 	PeeringSecretsWriteType                     = 40
 	RaftLogVerifierCheckpoint                   = 41 // Only used for log verifier, no-op on FSM.
+	ACLTokenUsageBatchUpdateType                = 42
 )
 
 const (
@@ -151,6 +152,7 @@ var requestTypeStrings = map[MessageType]string{
 	PeeringTrustBundleDeleteType:    "PeeringTrustBundleDelete",
 	PeeringSecretsWriteType:         "PeeringSecret",
 	RaftLogVerifierCheckpoint:       "RaftLogVerifierCheckpoint",
+	ACLTokenUsageBatchUpdateType:    "ACLTokenUsageBatchUpdate",
 }
 
 const (
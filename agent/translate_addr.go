@@ -161,3 +161,19 @@ func (a *Agent) TranslateAddresses(dc string, subj interface{}, accept Translate
 		panic(fmt.Errorf("Unhandled type passed to address translator: %#v", subj))
 	}
 }
+
+// TranslatePreparedQueryAddresses translates the addresses of the results of a
+// prepared query. These are translated using the datacenter the results came
+// from, since a query can fail over to a different DC than where the execute
+// request was sent to. Results merged from several failover targets are
+// translated node by node, using the datacenter each node is registered in.
+func (a *Agent) TranslatePreparedQueryAddresses(reply *structs.PreparedQueryExecuteResponse, accept TranslateAddressAccept) {
+	if len(reply.Sources) <= 1 {
+		a.TranslateAddresses(reply.Datacenter, reply.Nodes, accept)
+		return
+	}
+
+	for i := range reply.Nodes {
+		a.TranslateAddresses(reply.Nodes[i].Node.Datacenter, reply.Nodes[i:i+1], accept)
+	}
+}
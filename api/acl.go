@@ -37,6 +37,7 @@ type ACLToken struct {
 	Roles             []*ACLTokenRoleLink   `json:",omitempty"`
 	ServiceIdentities []*ACLServiceIdentity `json:",omitempty"`
 	NodeIdentities    []*ACLNodeIdentity    `json:",omitempty"`
+	TemplatedPolicies []*ACLTemplatedPolicy `json:",omitempty"`
 	Local             bool
 	AuthMethod        string        `json:",omitempty"`
 	ExpirationTTL     time.Duration `json:",omitempty"`
@@ -44,6 +45,25 @@ type ACLToken struct {
 	CreateTime        time.Time     `json:",omitempty"`
 	Hash              []byte        `json:",omitempty"`
 
+	// Renewable tokens can have their ExpirationTime extended with
+	// TokenRenew, by RenewalTTL at a time and up to MaxExpirationTime.
+	// RenewalTTL is set from the ExpirationTTL of the token when it's
+	// created, MaxExpirationTTL can be used to set MaxExpirationTime
+	// relative to the creation time.
+	Renewable         bool          `json:",omitempty"`
+	RenewalTTL        time.Duration `json:",omitempty"`
+	MaxExpirationTime *time.Time    `json:",omitempty"`
+	MaxExpirationTTL  time.Duration `json:",omitempty"`
+
+	// PreviousSecretID is the SecretID the token had before it was last
+	// rotated, it remains valid until PreviousSecretExpirationTime.
+	PreviousSecretID             string     `json:",omitempty"`
+	PreviousSecretExpirationTime *time.Time `json:",omitempty"`
+
+	// Usage is the approximate usage of the token, as recorded by the
+	// servers when token usage tracking is enabled. It is ignored on writes.
+	Usage *ACLTokenUsage `json:",omitempty"`
+
 	// DEPRECATED (ACL-Legacy-Compat)
 	// Rules are an artifact of legacy tokens deprecated in Consul 1.4
 	Rules string `json:"-"`
@@ -61,6 +81,19 @@ type ACLToken struct {
 	AuthMethodNamespace string `json:",omitempty"`
 }
 
+// ACLTokenUsage is the approximate usage of a token, as reported by the
+// agents that the token was presented to.
+type ACLTokenUsage struct {
+	// LastUsedTime is approximately when the token was last used.
+	LastUsedTime *time.Time `json:",omitempty"`
+
+	// SourceAddr is the address of the client that last used the token.
+	SourceAddr string `json:",omitempty"`
+
+	// Agent is the name of the agent that the token was last presented to.
+	Agent string `json:",omitempty"`
+}
+
 type ACLTokenExpanded struct {
 	ExpandedPolicies []ACLPolicy
 	ExpandedRoles    []ACLRole
@@ -85,6 +118,7 @@ type ACLTokenListEntry struct {
 	Roles             []*ACLTokenRoleLink   `json:",omitempty"`
 	ServiceIdentities []*ACLServiceIdentity `json:",omitempty"`
 	NodeIdentities    []*ACLNodeIdentity    `json:",omitempty"`
+	TemplatedPolicies []*ACLTemplatedPolicy `json:",omitempty"`
 	Local             bool
 	AuthMethod        string     `json:",omitempty"`
 	ExpirationTime    *time.Time `json:",omitempty"`
@@ -92,6 +126,10 @@ type ACLTokenListEntry struct {
 	Hash              []byte
 	Legacy            bool `json:"-"` // DEPRECATED
 
+	// Usage is the approximate usage of the token, as recorded by the
+	// servers when token usage tracking is enabled.
+	Usage *ACLTokenUsage `json:",omitempty"`
+
 	// Namespace is the namespace the ACLTokenListEntry is associated with.
 	// Namespacing is a Consul Enterprise feature.
 	Namespace string `json:",omitempty"`
@@ -145,6 +183,14 @@ type ACLNodeIdentity struct {
 	Datacenter string
 }
 
+// ACLTemplatedPolicy links a policy template, by ID or Name, with the values
+// of its variables.
+type ACLTemplatedPolicy struct {
+	ID        string            `json:",omitempty"`
+	Name      string            `json:",omitempty"`
+	Variables map[string]string `json:",omitempty"`
+}
+
 // ACLPolicy represents an ACL Policy.
 type ACLPolicy struct {
 	ID          string
@@ -152,6 +198,12 @@ type ACLPolicy struct {
 	Description string
 	Rules       string
 	Datacenters []string
+
+	// TemplateVariables makes the policy a template that tokens and roles
+	// link as a templated policy, with a value for each variable. The rules
+	// refer to the variables as ${var.<name>}.
+	TemplateVariables []string `json:",omitempty"`
+
 	Hash        []byte
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -166,13 +218,14 @@ type ACLPolicy struct {
 }
 
 type ACLPolicyListEntry struct {
-	ID          string
-	Name        string
-	Description string
-	Datacenters []string
-	Hash        []byte
-	CreateIndex uint64
-	ModifyIndex uint64
+	ID                string
+	Name              string
+	Description       string
+	Datacenters       []string
+	TemplateVariables []string `json:",omitempty"`
+	Hash              []byte
+	CreateIndex       uint64
+	ModifyIndex       uint64
 
 	// Namespace is the namespace the ACLPolicyListEntry is associated with.
 	// Namespacing is a Consul Enterprise feature.
@@ -193,6 +246,7 @@ type ACLRole struct {
 	Policies          []*ACLRolePolicyLink  `json:",omitempty"`
 	ServiceIdentities []*ACLServiceIdentity `json:",omitempty"`
 	NodeIdentities    []*ACLNodeIdentity    `json:",omitempty"`
+	TemplatedPolicies []*ACLTemplatedPolicy `json:",omitempty"`
 	Hash              []byte
 	CreateIndex       uint64
 	ModifyIndex       uint64
@@ -215,6 +269,11 @@ const (
 
 	// BindingRuleBindTypeRole binds to pre-existing roles with the given name.
 	BindingRuleBindTypeRole BindingRuleBindType = "role"
+
+	// BindingRuleBindTypeTemplatedPolicy binds to the pre-existing policy
+	// template with the given name, with the values of its variables given
+	// by BindVars.
+	BindingRuleBindTypeTemplatedPolicy BindingRuleBindType = "templated-policy"
 )
 
 type ACLBindingRule struct {
@@ -224,6 +283,7 @@ type ACLBindingRule struct {
 	Selector    string
 	BindType    BindingRuleBindType
 	BindName    string
+	BindVars    map[string]string `json:",omitempty"`
 
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -248,6 +308,12 @@ type ACLAuthMethod struct {
 	// This can be either 'local' or 'global'. If empty 'local' is assumed.
 	TokenLocality string `json:",omitempty"`
 
+	// TokenRenewable makes the tokens created by this method renewable, by
+	// MaxTokenTTL at a time. TokenMaxExpirationTTL limits how long they can
+	// be renewed for.
+	TokenRenewable        bool          `json:",omitempty"`
+	TokenMaxExpirationTTL time.Duration `json:",omitempty"`
+
 	// Configuration is arbitrary configuration for the auth method. This
 	// should only contain primitive values and containers (such as lists and
 	// maps).
@@ -272,15 +338,20 @@ type ACLAuthMethod struct {
 func (m *ACLAuthMethod) MarshalJSON() ([]byte, error) {
 	type Alias ACLAuthMethod
 	exported := &struct {
-		MaxTokenTTL string `json:",omitempty"`
+		MaxTokenTTL           string `json:",omitempty"`
+		TokenMaxExpirationTTL string `json:",omitempty"`
 		*Alias
 	}{
-		MaxTokenTTL: m.MaxTokenTTL.String(),
-		Alias:       (*Alias)(m),
+		MaxTokenTTL:           m.MaxTokenTTL.String(),
+		TokenMaxExpirationTTL: m.TokenMaxExpirationTTL.String(),
+		Alias:                 (*Alias)(m),
 	}
 	if m.MaxTokenTTL == 0 {
 		exported.MaxTokenTTL = ""
 	}
+	if m.TokenMaxExpirationTTL == 0 {
+		exported.TokenMaxExpirationTTL = ""
+	}
 
 	return json.Marshal(exported)
 }
@@ -288,7 +359,8 @@ func (m *ACLAuthMethod) MarshalJSON() ([]byte, error) {
 func (m *ACLAuthMethod) UnmarshalJSON(data []byte) error {
 	type Alias ACLAuthMethod
 	aux := &struct {
-		MaxTokenTTL string
+		MaxTokenTTL           string
+		TokenMaxExpirationTTL string
 		*Alias
 	}{
 		Alias: (*Alias)(m),
@@ -302,6 +374,11 @@ func (m *ACLAuthMethod) UnmarshalJSON(data []byte) error {
 			return err
 		}
 	}
+	if aux.TokenMaxExpirationTTL != "" {
+		if m.TokenMaxExpirationTTL, err = time.ParseDuration(aux.TokenMaxExpirationTTL); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -475,6 +552,34 @@ func (c *OIDCAuthMethodConfig) RenderToConfig() map[string]interface{} {
 	}
 }
 
+// CertAuthMethodConfig is the config for the built-in Consul auth method for
+// X.509 client certificates.
+type CertAuthMethodConfig struct {
+	CACerts            []string      `json:",omitempty"`
+	CRLs               []string      `json:",omitempty"`
+	AllowedCommonNames []string      `json:",omitempty"`
+	AllowedDNSSANs     []string      `json:",omitempty"`
+	AllowedURISANs     []string      `json:",omitempty"`
+	AllowedEmailSANs   []string      `json:",omitempty"`
+	MaxTokenAge        time.Duration `json:",omitempty"`
+	ClockSkewLeeway    time.Duration `json:",omitempty"`
+}
+
+// RenderToConfig converts this into a map[string]interface{} suitable for use
+// in the ACLAuthMethod.Config field.
+func (c *CertAuthMethodConfig) RenderToConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"CACerts":            c.CACerts,
+		"CRLs":               c.CRLs,
+		"AllowedCommonNames": c.AllowedCommonNames,
+		"AllowedDNSSANs":     c.AllowedDNSSANs,
+		"AllowedURISANs":     c.AllowedURISANs,
+		"AllowedEmailSANs":   c.AllowedEmailSANs,
+		"MaxTokenAge":        c.MaxTokenAge,
+		"ClockSkewLeeway":    c.ClockSkewLeeway,
+	}
+}
+
 type ACLLoginParams struct {
 	AuthMethod  string
 	BearerToken string
@@ -773,6 +878,64 @@ func (a *ACL) TokenClone(accessorID string, description string, q *WriteOptions)
 	return &out, wm, nil
 }
 
+// TokenRenew extends the ExpirationTime of a renewable token to the given
+// increment from now, or by its RenewalTTL if the increment is zero. Tokens
+// may renew themselves, renewing other tokens requires acl:write.
+func (a *ACL) TokenRenew(accessorID string, increment time.Duration, q *WriteOptions) (*ACLToken, *WriteMeta, error) {
+	if accessorID == "" {
+		return nil, nil, fmt.Errorf("Must specify a token AccessorID for Token Renewal")
+	}
+
+	r := a.c.newRequest("PUT", "/v1/acl/token/"+accessorID+"/renew")
+	r.setWriteOptions(q)
+	if increment != 0 {
+		r.obj = struct{ Increment string }{increment.String()}
+	}
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	wm := &WriteMeta{RequestTime: rtt}
+	var out ACLToken
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, wm, nil
+}
+
+// TokenRotate issues a new SecretID for the token with the given AccessorID.
+// The current SecretID remains valid for the grace period, a zero grace period
+// invalidates it immediately. The returned token carries the new SecretID.
+func (a *ACL) TokenRotate(accessorID string, gracePeriod time.Duration, q *WriteOptions) (*ACLToken, *WriteMeta, error) {
+	if accessorID == "" {
+		return nil, nil, fmt.Errorf("Must specify a token AccessorID for Token Rotation")
+	}
+
+	r := a.c.newRequest("PUT", "/v1/acl/token/"+accessorID+"/rotate")
+	r.setWriteOptions(q)
+	r.obj = struct{ GracePeriod string }{gracePeriod.String()}
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	wm := &WriteMeta{RequestTime: rtt}
+	var out ACLToken
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+
+	return &out, wm, nil
+}
+
 // TokenDelete removes a single ACL token. The accessorID parameter must be a valid
 // Accessor ID of an existing token.
 func (a *ACL) TokenDelete(accessorID string, q *WriteOptions) (*WriteMeta, error) {
@@ -1575,3 +1738,117 @@ func (a *ACL) OIDCCallback(auth *ACLOIDCCallbackParams, q *WriteOptions) (*ACLTo
 	}
 	return &out, wm, nil
 }
+
+// ACLAuthorizationRequest is an authorization to check, for example write
+// access to the "web" service.
+type ACLAuthorizationRequest struct {
+	Resource string
+	Segment  string `json:",omitempty"`
+	Access   string
+
+	// Namespace is the namespace of the resource.
+	Namespace string `json:",omitempty"`
+
+	// Partition is the partition of the resource.
+	Partition string `json:",omitempty"`
+}
+
+// ACLAuthorizeExplainRequest is used to explain the authorization decisions
+// made for a token.
+type ACLAuthorizeExplainRequest struct {
+	// AccessorID is the token to explain. When empty the token used to make
+	// the request is explained. Explaining another token requires acl:read.
+	AccessorID string `json:",omitempty"`
+
+	// Requests are the authorizations to explain.
+	Requests []ACLAuthorizationRequest
+
+	// Policies are simulated policies that are evaluated along with the ones
+	// linked to the token. They don't need to exist, only their Name and
+	// Rules are used.
+	Policies []*ACLPolicy `json:",omitempty"`
+
+	// PoliciesOnly only evaluates the simulated policies, ignoring everything
+	// that's linked to the token.
+	PoliciesOnly bool `json:",omitempty"`
+}
+
+// ACLAuthorizationSource is a rule that applies to an authorization request
+// and where it came from.
+type ACLAuthorizationSource struct {
+	// Type is one of "policy", "service-identity", "node-identity",
+	// "templated-policy" or "simulated".
+	Type string
+
+	// ID and Name identify the policy. For service and node identities Name
+	// is the name of the service or node instead, for templated policies ID
+	// and Name identify the template.
+	ID   string `json:",omitempty"`
+	Name string
+
+	// RoleID and RoleName are set when the source is linked to the token
+	// through a role.
+	RoleID   string `json:",omitempty"`
+	RoleName string `json:",omitempty"`
+
+	// Rule is the rule that applies, in policy syntax.
+	Rule string
+
+	// Decision is the decision the rule makes on its own, either "allow" or
+	// "deny".
+	Decision string
+}
+
+// ACLAuthorizationExplanation is the explanation of a single authorization.
+type ACLAuthorizationExplanation struct {
+	ACLAuthorizationRequest
+	Allow bool
+
+	// DefaultPolicy is set when no rule applies and the decision falls back
+	// to the default policy.
+	DefaultPolicy bool
+
+	// Source is the rule that made the decision. It's nil when the decision
+	// came from the default policy.
+	Source *ACLAuthorizationSource `json:",omitempty"`
+
+	// Matches are all the rules that apply, the most specific first.
+	Matches []ACLAuthorizationSource
+}
+
+type ACLAuthorizeExplainResponse struct {
+	// AccessorID is the token that was explained, empty when only simulated
+	// policies were evaluated.
+	AccessorID string
+
+	// DefaultPolicy is the default policy of the datacenter.
+	DefaultPolicy string
+
+	Explanations []ACLAuthorizationExplanation
+}
+
+// AuthorizeExplain explains the authorization decisions made for a token, and
+// which rule made each of them.
+func (a *ACL) AuthorizeExplain(req *ACLAuthorizeExplainRequest, q *QueryOptions) (*ACLAuthorizeExplainResponse, *QueryMeta, error) {
+	r := a.c.newRequest("POST", "/v1/acl/authorize/explain")
+	r.setQueryOptions(q)
+	r.obj = req
+
+	rtt, resp, err := a.c.doRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeResponseBody(resp)
+	if err := requireOK(resp); err != nil {
+		return nil, nil, err
+	}
+	qm := &QueryMeta{}
+	parseQueryMeta(resp, qm)
+	qm.RequestTime = rtt
+
+	var out ACLAuthorizeExplainResponse
+	if err := decodeBody(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return &out, qm, nil
+}
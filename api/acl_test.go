@@ -570,6 +570,65 @@ func TestAPI_ACLToken_Clone(t *testing.T) {
 	require.Equal(t, cloned, read)
 }
 
+func TestAPI_ACLToken_Renew(t *testing.T) {
+	t.Parallel()
+	c, s := makeACLClient(t)
+	defer s.Stop()
+
+	acl := c.ACL()
+
+	created, _, err := acl.TokenCreate(&ACLToken{
+		Description:      "renewable",
+		Policies:         []*ACLTokenPolicyLink{{Name: "global-management"}},
+		ExpirationTTL:    10 * time.Minute,
+		Renewable:        true,
+		MaxExpirationTTL: 2 * time.Hour,
+	}, nil)
+	require.NoError(t, err)
+	require.True(t, created.Renewable)
+	require.Equal(t, 10*time.Minute, created.RenewalTTL)
+	require.NotNil(t, created.MaxExpirationTime)
+
+	renewed, _, err := acl.TokenRenew(created.AccessorID, time.Hour, nil)
+	require.NoError(t, err)
+	require.NotNil(t, renewed)
+	require.Equal(t, created.AccessorID, renewed.AccessorID)
+	require.True(t, renewed.ExpirationTime.After(*created.ExpirationTime))
+
+	// Tokens can't be renewed past their max expiration time.
+	renewed, _, err = acl.TokenRenew(created.AccessorID, 12*time.Hour, nil)
+	require.NoError(t, err)
+	require.Equal(t, created.MaxExpirationTime.Unix(), renewed.ExpirationTime.Unix())
+}
+
+func TestAPI_ACLToken_Rotate(t *testing.T) {
+	t.Parallel()
+	c, s := makeACLClient(t)
+	defer s.Stop()
+
+	acl := c.ACL()
+
+	created, _, err := acl.TokenCreate(&ACLToken{
+		Description: "rotated",
+		Policies:    []*ACLTokenPolicyLink{{Name: "global-management"}},
+	}, nil)
+	require.NoError(t, err)
+
+	rotated, _, err := acl.TokenRotate(created.AccessorID, time.Hour, nil)
+	require.NoError(t, err)
+	require.NotNil(t, rotated)
+	require.Equal(t, created.AccessorID, rotated.AccessorID)
+	require.NotEqual(t, created.SecretID, rotated.SecretID)
+	require.Equal(t, created.SecretID, rotated.PreviousSecretID)
+	require.NotNil(t, rotated.PreviousSecretExpirationTime)
+
+	// The previous secret remains valid during the grace period.
+	self, _, err := acl.TokenReadSelf(&QueryOptions{Token: created.SecretID})
+	require.NoError(t, err)
+	require.Equal(t, created.AccessorID, self.AccessorID)
+	require.NotNil(t, self.ExpirationTime)
+}
+
 func TestAPI_AuthMethod_List(t *testing.T) {
 	t.Parallel()
 	c, s := makeACLClient(t)
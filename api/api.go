@@ -193,6 +193,10 @@ type QueryOptions struct {
 	// services. This currently affects prepared query execution.
 	Connect bool
 
+	// Limit is used in prepared query execution to return at most this many
+	// results, after they are sorted. 0 returns all the results.
+	Limit int
+
 	// ctx is an optional context pass through to the underlying HTTP
 	// request layer. Use Context() and WithContext() to manage this.
 	ctx context.Context
@@ -882,6 +886,9 @@ func (r *request) setQueryOptions(q *QueryOptions) {
 	if q.Connect {
 		r.params.Set("connect", "true")
 	}
+	if q.Limit != 0 {
+		r.params.Set("limit", strconv.Itoa(q.Limit))
+	}
 	if q.UseCache && !q.RequireConsistent {
 		r.params.Set("cached", "")
 
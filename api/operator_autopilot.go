@@ -34,8 +34,8 @@ type AutopilotConfiguration struct {
 	// applicable with Raft protocol version 3 or higher.
 	ServerStabilizationTime *ReadableDuration
 
-	// (Enterprise-only) RedundancyZoneTag is the node tag to use for separating
-	// servers into zones for redundancy. If left blank, this feature will be disabled.
+	// RedundancyZoneTag is the node tag to use for separating servers into
+	// zones for redundancy. If left blank, this feature will be disabled.
 	RedundancyZoneTag string
 
 	// (Enterprise-only) DisableUpgradeMigration will disable Autopilot's upgrade migration
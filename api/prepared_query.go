@@ -15,6 +15,16 @@ type QueryFailoverOptions struct {
 	// Targets is a fixed list of datacenters and peers to try. This field cannot
 	// be populated with NearestN or Datacenters.
 	Targets []QueryFailoverTarget
+
+	// MinHealthy is the minimum number of healthy nodes the local datacenter
+	// must return. If fewer are found, the local results are merged with the
+	// results of the failover targets, in order, until the threshold is met.
+	MinHealthy int
+
+	// MinHealthyPercent is like MinHealthy, but expressed as a percentage
+	// (0-100) of all the local nodes matching the query, including unhealthy
+	// ones. When both are set the higher threshold applies.
+	MinHealthyPercent int
 }
 
 // Deprecated: use QueryFailoverOptions instead.
@@ -78,6 +88,10 @@ type ServiceQuery struct {
 	// service entry to be returned.
 	ServiceMeta map[string]string
 
+	// Filter is an optional filter expression that is evaluated against each
+	// of the service instances. Only instances that match are returned.
+	Filter string `json:",omitempty"`
+
 	// Connect if true will filter the prepared query results to only
 	// include Connect-capable services. These include both native services
 	// and proxies for matching services. Note that if a proxy matches,
@@ -158,6 +172,30 @@ type PreparedQueryExecuteResponse struct {
 	// Failovers is a count of how many times we had to query a remote
 	// datacenter.
 	Failovers int
+
+	// Sources lists the datacenters and peers that contributed nodes to the
+	// results, in order, when a failover health threshold is set.
+	Sources []QueryResultSource `json:",omitempty"`
+}
+
+// QueryResultSource is a datacenter or peer that contributed nodes to the
+// results of a prepared query.
+type QueryResultSource struct {
+	// Datacenter is the datacenter the nodes came from. It is empty for
+	// nodes imported from a peer.
+	Datacenter string `json:",omitempty"`
+
+	// PeerName is the cluster peer the nodes came from.
+	PeerName string `json:",omitempty"`
+
+	// Nodes is the number of nodes from this source.
+	Nodes int
+}
+
+// PreparedQueryExplainResponse has the results when explaining a query.
+type PreparedQueryExplainResponse struct {
+	// Query has the fully-rendered query.
+	Query PreparedQueryDefinition
 }
 
 // PreparedQuery can be used to query the prepared query endpoints.
@@ -248,3 +286,15 @@ func (c *PreparedQuery) Execute(queryIDOrName string, q *QueryOptions) (*Prepare
 	}
 	return out, qm, nil
 }
+
+// Explain is used to show which query a name resolves to, along with the
+// fully-rendered query if it is a template. You can explain using a query ID
+// or name.
+func (c *PreparedQuery) Explain(queryIDOrName string, q *QueryOptions) (*PreparedQueryExplainResponse, *QueryMeta, error) {
+	var out *PreparedQueryExplainResponse
+	qm, err := c.c.query("/v1/query/"+queryIDOrName+"/explain", &out, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, qm, nil
+}
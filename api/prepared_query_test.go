@@ -118,6 +118,15 @@ func TestAPI_PreparedQuery(t *testing.T) {
 		t.Fatalf("bad datacenter: %v", results)
 	}
 
+	// Explain by name.
+	explain, _, err := query.Explain("my-query", nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if explain.Query.ID != def.ID || explain.Query.Service.Service != "redis" {
+		t.Fatalf("bad: %v", explain)
+	}
+
 	// Add new node with failing health check.
 	reg2 := reg
 	reg2.Node = "failingnode"
@@ -2,6 +2,7 @@ package acl
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/consul/acl"
@@ -207,6 +208,46 @@ func ExtractNodeIdentities(nodeIdents []string) ([]*api.ACLNodeIdentity, error)
 	return out, nil
 }
 
+// ExtractTemplatedPolicies parses -templated-policy arguments of the form
+// <name>[:<variable>=<value>[,<variable>=<value>...]].
+func ExtractTemplatedPolicies(templatedPolicies []string) ([]*api.ACLTemplatedPolicy, error) {
+	var out []*api.ACLTemplatedPolicy
+	for _, raw := range templatedPolicies {
+		name, rawVars, hasVars := strings.Cut(raw, ":")
+		if name == "" {
+			return nil, fmt.Errorf("Malformed -templated-policy argument: %q", raw)
+		}
+
+		tp := &api.ACLTemplatedPolicy{Name: name}
+		if hasVars {
+			tp.Variables = make(map[string]string)
+			for _, v := range strings.Split(rawVars, ",") {
+				varName, value, ok := strings.Cut(v, "=")
+				if !ok || varName == "" {
+					return nil, fmt.Errorf("Malformed -templated-policy argument: %q", raw)
+				}
+				tp.Variables[varName] = value
+			}
+		}
+		out = append(out, tp)
+	}
+	return out, nil
+}
+
+// FormatTemplatedPolicy returns the templated policy link in the same
+// <name>[:<variable>=<value>,...] form accepted by ExtractTemplatedPolicies.
+func FormatTemplatedPolicy(tp *api.ACLTemplatedPolicy) string {
+	if len(tp.Variables) == 0 {
+		return tp.Name
+	}
+	vars := make([]string, 0, len(tp.Variables))
+	for name, value := range tp.Variables {
+		vars = append(vars, name+"="+value)
+	}
+	sort.Strings(vars)
+	return tp.Name + ":" + strings.Join(vars, ",")
+}
+
 // TestKubernetesJWT_A is a valid service account jwt extracted from a minikube setup.
 //
 //	{
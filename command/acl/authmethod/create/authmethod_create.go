@@ -33,6 +33,8 @@ type cmd struct {
 	description    string
 	maxTokenTTL    time.Duration
 	tokenLocality  string
+	tokenRenewable bool
+	tokenMaxTTL    time.Duration
 	config         string
 
 	k8sHost              string
@@ -95,6 +97,20 @@ func (c *cmd) init() {
 		"Defines the kind of token that this auth method should produce. "+
 			"This can be either 'local' or 'global'. If empty the value of 'local' is assumed.",
 	)
+	c.flags.BoolVar(
+		&c.tokenRenewable,
+		"token-renewable",
+		false,
+		"Allow renewing the tokens created by this auth method, extending their "+
+			"expiration time by -max-token-ttl at a time. Requires -max-token-ttl.",
+	)
+	c.flags.DurationVar(
+		&c.tokenMaxTTL,
+		"token-max-expiration-ttl",
+		0,
+		"Duration of time since their creation after which tokens created by this "+
+			"auth method can't be renewed any further. Requires -token-renewable.",
+	)
 
 	c.flags.StringVar(
 		&c.k8sHost,
@@ -165,15 +181,19 @@ func (c *cmd) Run(args []string) int {
 	}
 
 	newAuthMethod := &api.ACLAuthMethod{
-		Type:          c.authMethodType,
-		Name:          c.name,
-		DisplayName:   c.displayName,
-		Description:   c.description,
-		TokenLocality: c.tokenLocality,
+		Type:           c.authMethodType,
+		Name:           c.name,
+		DisplayName:    c.displayName,
+		Description:    c.description,
+		TokenLocality:  c.tokenLocality,
+		TokenRenewable: c.tokenRenewable,
 	}
 	if c.maxTokenTTL > 0 {
 		newAuthMethod.MaxTokenTTL = c.maxTokenTTL
 	}
+	if c.tokenMaxTTL > 0 {
+		newAuthMethod.TokenMaxExpirationTTL = c.tokenMaxTTL
+	}
 
 	if err := c.enterprisePopulateAuthMethod(newAuthMethod); err != nil {
 		c.UI.Error(err.Error())
@@ -67,6 +67,12 @@ func (f *prettyFormatter) FormatAuthMethod(method *api.ACLAuthMethod) (string, e
 	if method.TokenLocality != "" {
 		buffer.WriteString(fmt.Sprintf("TokenLocality: %s\n", method.TokenLocality))
 	}
+	if method.TokenRenewable {
+		buffer.WriteString(fmt.Sprintf("TokenRenewable: %t\n", method.TokenRenewable))
+	}
+	if method.TokenMaxExpirationTTL > 0 {
+		buffer.WriteString(fmt.Sprintf("TokenMaxExpirationTTL: %s\n", method.TokenMaxExpirationTTL))
+	}
 	if len(method.NamespaceRules) > 0 {
 		buffer.WriteString(fmt.Sprintln("NamespaceRules:"))
 		for _, rule := range method.NamespaceRules {
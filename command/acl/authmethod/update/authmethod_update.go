@@ -29,11 +29,13 @@ type cmd struct {
 
 	name string
 
-	displayName   string
-	description   string
-	maxTokenTTL   time.Duration
-	tokenLocality string
-	config        string
+	displayName    string
+	description    string
+	maxTokenTTL    time.Duration
+	tokenLocality  string
+	tokenRenewable flags.BoolValue
+	tokenMaxTTL    time.Duration
+	config         string
 
 	k8sHost              string
 	k8sCACert            string
@@ -93,6 +95,20 @@ func (c *cmd) init() {
 		"Defines the kind of token that this auth method should produce. "+
 			"This can be either 'local' or 'global'. If empty the value of 'local' is assumed.",
 	)
+	c.flags.Var(
+		&c.tokenRenewable,
+		"token-renewable",
+		"Allow renewing the tokens created by this auth method, extending their "+
+			"expiration time by -max-token-ttl at a time. Only affects tokens created "+
+			"after the update.",
+	)
+	c.flags.DurationVar(
+		&c.tokenMaxTTL,
+		"token-max-expiration-ttl",
+		0,
+		"Duration of time since their creation after which tokens created by this "+
+			"auth method can't be renewed any further.",
+	)
 
 	c.flags.StringVar(
 		&c.config,
@@ -196,6 +212,10 @@ func (c *cmd) Run(args []string) int {
 		if c.maxTokenTTL > 0 {
 			method.MaxTokenTTL = c.maxTokenTTL
 		}
+		c.tokenRenewable.Merge(&method.TokenRenewable)
+		if c.tokenMaxTTL > 0 {
+			method.TokenMaxExpirationTTL = c.tokenMaxTTL
+		}
 
 		if err := c.enterprisePopulateAuthMethod(method); err != nil {
 			c.UI.Error(err.Error())
@@ -248,6 +268,10 @@ func (c *cmd) Run(args []string) int {
 		if c.maxTokenTTL > 0 {
 			method.MaxTokenTTL = c.maxTokenTTL
 		}
+		c.tokenRenewable.Merge(&method.TokenRenewable)
+		if c.tokenMaxTTL > 0 {
+			method.TokenMaxExpirationTTL = c.tokenMaxTTL
+		}
 		if c.tokenLocality != "" {
 			method.TokenLocality = c.tokenLocality
 		}
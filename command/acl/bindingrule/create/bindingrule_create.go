@@ -28,6 +28,7 @@ type cmd struct {
 	selector       string
 	bindType       string
 	bindName       string
+	bindVars       map[string]string
 
 	showMeta bool
 	format   string
@@ -68,7 +69,7 @@ func (c *cmd) init() {
 		&c.bindType,
 		"bind-type",
 		string(api.BindingRuleBindTypeService),
-		"Type of binding to perform (\"service\" or \"role\").",
+		"Type of binding to perform (\"service\", \"role\" or \"templated-policy\").",
 	)
 	c.flags.StringVar(
 		&c.bindName,
@@ -77,6 +78,13 @@ func (c *cmd) init() {
 		"Name to bind on match. Can use ${var} interpolation. "+
 			"This flag is required.",
 	)
+	c.flags.Var(
+		(*flags.FlagMapValue)(&c.bindVars),
+		"bind-var",
+		"Variable of the templated policy to bind on match, in the form "+
+			"NAME=VALUE. Can use ${var} interpolation. Only valid with the "+
+			"\"templated-policy\" bind type. This flag may be specified multiple times.",
+	)
 	c.flags.StringVar(
 		&c.format,
 		"format",
@@ -115,6 +123,7 @@ func (c *cmd) Run(args []string) int {
 		AuthMethod:  c.authMethodName,
 		BindType:    api.BindingRuleBindType(c.bindType),
 		BindName:    c.bindName,
+		BindVars:    c.bindVars,
 		Selector:    c.selector,
 	}
 
@@ -168,4 +177,12 @@ Usage: consul acl binding-rule create [options]
           -bind-type=service \
           -bind-name='k8s-${serviceaccount.name}' \
           -selector='serviceaccount.namespace==default and serviceaccount.name==web'
+
+  Bind a templated policy with variables computed from the identity:
+
+    $ consul acl binding-rule create \
+          -method=minikube \
+          -bind-type=templated-policy \
+          -bind-name=kv-app \
+          -bind-var='prefix=apps/${serviceaccount.name}'
 `
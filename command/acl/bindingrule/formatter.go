@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/consul/api"
 )
@@ -60,6 +62,9 @@ func (f *prettyFormatter) FormatBindingRule(rule *api.ACLBindingRule) (string, e
 	buffer.WriteString(fmt.Sprintf("Description:  %s\n", rule.Description))
 	buffer.WriteString(fmt.Sprintf("BindType:     %s\n", rule.BindType))
 	buffer.WriteString(fmt.Sprintf("BindName:     %s\n", rule.BindName))
+	if len(rule.BindVars) > 0 {
+		buffer.WriteString(fmt.Sprintf("BindVars:     %s\n", formatBindVars(rule.BindVars)))
+	}
 	buffer.WriteString(fmt.Sprintf("Selector:     %s\n", rule.Selector))
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("Create Index: %d\n", rule.CreateIndex))
@@ -93,6 +98,9 @@ func (f *prettyFormatter) formatBindingRuleListEntry(rule *api.ACLBindingRule) s
 	buffer.WriteString(fmt.Sprintf("   Description:  %s\n", rule.Description))
 	buffer.WriteString(fmt.Sprintf("   BindType:     %s\n", rule.BindType))
 	buffer.WriteString(fmt.Sprintf("   BindName:     %s\n", rule.BindName))
+	if len(rule.BindVars) > 0 {
+		buffer.WriteString(fmt.Sprintf("   BindVars:     %s\n", formatBindVars(rule.BindVars)))
+	}
 	buffer.WriteString(fmt.Sprintf("   Selector:     %s\n", rule.Selector))
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("   Create Index: %d\n", rule.CreateIndex))
@@ -102,6 +110,17 @@ func (f *prettyFormatter) formatBindingRuleListEntry(rule *api.ACLBindingRule) s
 	return buffer.String()
 }
 
+// formatBindVars returns the variables sorted by name in the NAME=VALUE form
+// accepted by the -bind-var flag.
+func formatBindVars(vars map[string]string) string {
+	out := make([]string, 0, len(vars))
+	for name, value := range vars {
+		out = append(out, name+"="+value)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ", ")
+}
+
 func newJSONFormatter(showMeta bool) Formatter {
 	return &jsonFormatter{showMeta}
 }
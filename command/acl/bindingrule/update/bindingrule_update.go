@@ -31,6 +31,7 @@ type cmd struct {
 	selector    string
 	bindType    string
 	bindName    string
+	bindVars    map[string]string
 
 	noMerge  bool
 	showMeta bool
@@ -73,7 +74,7 @@ func (c *cmd) init() {
 		&c.bindType,
 		"bind-type",
 		string(api.BindingRuleBindTypeService),
-		"Type of binding to perform (\"service\" or \"role\").",
+		"Type of binding to perform (\"service\", \"role\" or \"templated-policy\").",
 	)
 	c.flags.StringVar(
 		&c.bindName,
@@ -82,6 +83,13 @@ func (c *cmd) init() {
 		"Name to bind on match. Can use ${var} interpolation. "+
 			"This flag is required.",
 	)
+	c.flags.Var(
+		(*flags.FlagMapValue)(&c.bindVars),
+		"bind-var",
+		"Variable of the templated policy to bind on match, in the form "+
+			"NAME=VALUE. Can use ${var} interpolation. Only valid with the "+
+			"\"templated-policy\" bind type. This flag may be specified multiple times.",
+	)
 
 	c.flags.BoolVar(
 		&c.noMerge,
@@ -156,6 +164,7 @@ func (c *cmd) Run(args []string) int {
 			Description: c.description,
 			BindType:    api.BindingRuleBindType(c.bindType),
 			BindName:    c.bindName,
+			BindVars:    c.bindVars,
 			Selector:    c.selector,
 		}
 
@@ -171,6 +180,9 @@ func (c *cmd) Run(args []string) int {
 		if c.bindName != "" {
 			rule.BindName = c.bindName
 		}
+		if c.bindVars != nil {
+			rule.BindVars = c.bindVars
+		}
 		if isFlagSet(c.flags, "selector") {
 			rule.Selector = c.selector // empty is valid
 		}
@@ -26,10 +26,11 @@ type cmd struct {
 	http  *flags.HTTPFlags
 	help  string
 
-	name        string
-	description string
-	datacenters []string
-	rules       string
+	name              string
+	description       string
+	datacenters       []string
+	rules             string
+	templateVariables []string
 
 	showMeta bool
 	format   string
@@ -48,6 +49,9 @@ func (c *cmd) init() {
 	c.flags.StringVar(&c.rules, "rules", "", "The policy rules. May be prefixed with '@' "+
 		"to indicate that the value is a file path to load the rules from. '-' may also be "+
 		"given to indicate that the rules are available on stdin")
+	c.flags.Var((*flags.AppendSliceValue)(&c.templateVariables), "template-variable", "Name of a "+
+		"variable of the policy template. Setting it makes the policy a template that can only be "+
+		"linked as a templated policy. This flag may be specified multiple times")
 	c.flags.StringVar(
 		&c.format,
 		"format",
@@ -90,6 +94,8 @@ func (c *cmd) Run(args []string) int {
 		Description: c.description,
 		Datacenters: c.datacenters,
 		Rules:       rules,
+
+		TemplateVariables: c.templateVariables,
 	}
 
 	p, _, err := client.ACL().PolicyCreate(newPolicy, nil)
@@ -140,5 +146,11 @@ Usage: consul acl policy create -name NAME [options]
                                    -datacenter "dc1" \
                                    -datacenter "dc2" \
                                    -rules @rules.hcl
+
+    Create a policy template with a "prefix" variable:
+
+        $ consul acl policy create -name "kv-app" \
+                                   -template-variable "prefix" \
+                                   -rules 'key_prefix "${var.prefix}" { policy = "write" }'
 `
 )
@@ -60,6 +60,9 @@ func (f *prettyFormatter) FormatPolicy(policy *api.ACLPolicy) (string, error) {
 	}
 	buffer.WriteString(fmt.Sprintf("Description:  %s\n", policy.Description))
 	buffer.WriteString(fmt.Sprintf("Datacenters:  %s\n", strings.Join(policy.Datacenters, ", ")))
+	if len(policy.TemplateVariables) > 0 {
+		buffer.WriteString(fmt.Sprintf("Variables:    %s\n", strings.Join(policy.TemplateVariables, ", ")))
+	}
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("Hash:         %x\n", policy.Hash))
 		buffer.WriteString(fmt.Sprintf("Create Index: %d\n", policy.CreateIndex))
@@ -94,6 +97,9 @@ func (f *prettyFormatter) formatPolicyListEntry(policy *api.ACLPolicyListEntry)
 	}
 	buffer.WriteString(fmt.Sprintf("   Description:  %s\n", policy.Description))
 	buffer.WriteString(fmt.Sprintf("   Datacenters:  %s\n", strings.Join(policy.Datacenters, ", ")))
+	if len(policy.TemplateVariables) > 0 {
+		buffer.WriteString(fmt.Sprintf("   Variables:    %s\n", strings.Join(policy.TemplateVariables, ", ")))
+	}
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("   Hash:         %x\n", policy.Hash))
 		buffer.WriteString(fmt.Sprintf("   Create Index: %d\n", policy.CreateIndex))
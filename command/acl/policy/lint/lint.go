@@ -0,0 +1,383 @@
+package policylint
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+
+	"github.com/hashicorp/consul/acl"
+)
+
+// The checks reported by the linter.
+const (
+	CheckParseError       = "parse-error"
+	CheckUnknownRule      = "unknown-rule"
+	CheckDeprecatedSyntax = "deprecated-syntax"
+	CheckShadowedRule     = "shadowed-rule"
+	CheckUnknownReference = "unknown-reference"
+	CheckUnusedPolicy     = "unused-policy"
+)
+
+// Finding is an issue found in a policy.
+type Finding struct {
+	PolicyID   string
+	PolicyName string
+	Check      string
+	// Rule is the rule the finding is about, for example service_prefix "web".
+	Rule    string `json:",omitempty"`
+	Message string
+}
+
+// rule is a resource rule of a policy, with the exact and prefix variants of
+// each resource flattened in a single list.
+type rule struct {
+	resource string
+	name     string
+	prefix   bool
+	policy   string
+	// access is the access granted by the rule, it is compared to find the
+	// rules that have no effect.
+	access string
+}
+
+func (r rule) String() string {
+	kind := r.resource
+	if r.prefix {
+		kind += "_prefix"
+	}
+	return kind + " " + strconv.Quote(r.name)
+}
+
+// flattenRules returns the resource rules of the policy in the order they
+// are declared for each resource.
+func flattenRules(p *acl.Policy) []rule {
+	var rules []rule
+	add := func(resource, name string, prefix bool, policy, access string) {
+		rules = append(rules, rule{resource: resource, name: name, prefix: prefix, policy: policy, access: access})
+	}
+	policyAccess := func(policy string) string {
+		return fmt.Sprintf("policy = %q", policy)
+	}
+	serviceAccess := func(r *acl.ServiceRule) string {
+		if r.Intentions == "" {
+			return policyAccess(r.Policy)
+		}
+		return fmt.Sprintf("policy = %q, intentions = %q", r.Policy, r.Intentions)
+	}
+
+	for _, r := range p.Agents {
+		add("agent", r.Node, false, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.AgentPrefixes {
+		add("agent", r.Node, true, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.Keys {
+		add("key", r.Prefix, false, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.KeyPrefixes {
+		add("key", r.Prefix, true, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.Nodes {
+		add("node", r.Name, false, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.NodePrefixes {
+		add("node", r.Name, true, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.Services {
+		add("service", r.Name, false, r.Policy, serviceAccess(r))
+	}
+	for _, r := range p.ServicePrefixes {
+		add("service", r.Name, true, r.Policy, serviceAccess(r))
+	}
+	for _, r := range p.Sessions {
+		add("session", r.Node, false, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.SessionPrefixes {
+		add("session", r.Node, true, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.Events {
+		add("event", r.Event, false, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.EventPrefixes {
+		add("event", r.Event, true, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.PreparedQueries {
+		add("query", r.Prefix, false, r.Policy, policyAccess(r.Policy))
+	}
+	for _, r := range p.PreparedQueryPrefixes {
+		add("query", r.Prefix, true, r.Policy, policyAccess(r.Policy))
+	}
+	return rules
+}
+
+// lintSyntax reports the blocks and attributes that are silently ignored
+// when the rules are decoded, and the exact rules with an empty name that
+// only matched everything with the legacy syntax.
+func lintSyntax(rules string, parsed *acl.Policy) ([]Finding, error) {
+	file, err := hcl.Parse(rules)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, nil
+	}
+
+	var findings []Finding
+	known := knownKeys(reflect.TypeOf(acl.Policy{}))
+	for _, item := range root.Items {
+		key := itemKey(item)
+		nested, ok := known[key]
+		if !ok {
+			findings = append(findings, Finding{
+				Check:   CheckUnknownRule,
+				Rule:    key,
+				Message: fmt.Sprintf("%q is not a known rule and is ignored", key),
+			})
+			continue
+		}
+		if nested == nil {
+			continue
+		}
+
+		body, ok := item.Val.(*ast.ObjectType)
+		if !ok {
+			continue
+		}
+		for _, attr := range body.List.Items {
+			name := itemKey(attr)
+			if _, ok := nested[name]; !ok {
+				findings = append(findings, Finding{
+					Check:   CheckUnknownRule,
+					Rule:    describeItem(item),
+					Message: fmt.Sprintf("%q is not a known attribute of %q rules and is ignored", name, key),
+				})
+			}
+		}
+	}
+
+	for _, r := range flattenRules(parsed) {
+		if r.prefix || r.name != "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Check: CheckDeprecatedSyntax,
+			Rule:  r.String(),
+			Message: fmt.Sprintf("%s only matches an empty name. It matched every name with the "+
+				"legacy ACL syntax, use %s_prefix \"\" instead", r, r.resource),
+		})
+	}
+	return findings, nil
+}
+
+// knownKeys returns the keys decoded into the fields of the struct, with the
+// keys of the nested rules for the fields holding a list of rules.
+func knownKeys(t reflect.Type) map[string]map[string]struct{} {
+	out := make(map[string]map[string]struct{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("hcl"), ",")
+		if opts == "squash" {
+			for k, v := range knownKeys(f.Type) {
+				out[k] = v
+			}
+			continue
+		}
+		if opts == "key" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+
+		var nested map[string]map[string]struct{}
+		if f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Ptr {
+			nested = knownKeys(f.Type.Elem().Elem())
+		}
+		if nested == nil {
+			out[name] = nil
+			continue
+		}
+		attrs := make(map[string]struct{}, len(nested))
+		for k := range nested {
+			attrs[k] = struct{}{}
+		}
+		out[name] = attrs
+	}
+	return out
+}
+
+func itemKey(item *ast.ObjectItem) string {
+	if len(item.Keys) == 0 {
+		return ""
+	}
+	return strings.ToLower(strings.Trim(item.Keys[0].Token.Text, `"`))
+}
+
+func describeItem(item *ast.ObjectItem) string {
+	parts := make([]string, 0, len(item.Keys))
+	for _, k := range item.Keys {
+		parts = append(parts, k.Token.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// lintShadowed reports the rules that have no effect because another rule of
+// the same policy takes precedence over them or grants the same access.
+//
+// Exact rules take precedence over prefix rules, and the longest matching
+// prefix rule applies, so an exact or prefix rule granting the same access as
+// the longest shorter prefix rule matching its name changes nothing. Rules
+// declared more than once are merged and only the one with the highest
+// precedence applies.
+func lintShadowed(parsed *acl.Policy) []Finding {
+	var findings []Finding
+
+	type ruleKey struct {
+		resource string
+		name     string
+		prefix   bool
+	}
+	seen := make(map[ruleKey]rule)
+	prefixes := make(map[string][]rule)
+
+	for _, r := range flattenRules(parsed) {
+		key := ruleKey{r.resource, r.name, r.prefix}
+		if other, ok := seen[key]; ok {
+			findings = append(findings, Finding{
+				Check:   CheckShadowedRule,
+				Rule:    r.String(),
+				Message: fmt.Sprintf("%s is declared more than once, only the declaration with the highest precedence applies", r),
+			})
+			if policyPrecedence[r.policy] <= policyPrecedence[other.policy] {
+				continue
+			}
+		}
+		seen[key] = r
+	}
+
+	var unique []rule
+	for _, r := range seen {
+		unique = append(unique, r)
+		if r.prefix {
+			prefixes[r.resource] = append(prefixes[r.resource], r)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		if unique[i].resource != unique[j].resource {
+			return unique[i].resource < unique[j].resource
+		}
+		if unique[i].name != unique[j].name {
+			return unique[i].name < unique[j].name
+		}
+		return !unique[i].prefix && unique[j].prefix
+	})
+
+	for _, r := range unique {
+		var longest *rule
+		for i, p := range prefixes[r.resource] {
+			if !strings.HasPrefix(r.name, p.name) {
+				continue
+			}
+			// a prefix rule doesn't shadow itself
+			if r.prefix && p.name == r.name {
+				continue
+			}
+			if longest == nil || len(p.name) > len(longest.name) {
+				longest = &prefixes[r.resource][i]
+			}
+		}
+		if longest == nil || longest.access != r.access {
+			continue
+		}
+		findings = append(findings, Finding{
+			Check: CheckShadowedRule,
+			Rule:  r.String(),
+			Message: fmt.Sprintf("%s is shadowed by %s which already grants the same access (%s)",
+				r, longest, r.access),
+		})
+	}
+	return findings
+}
+
+// policyPrecedence orders the policies like the merging of rules does, the
+// rule with the highest precedence applies when a rule is declared twice.
+var policyPrecedence = map[string]int{
+	acl.PolicyRead:  1,
+	acl.PolicyList:  2,
+	acl.PolicyWrite: 3,
+	acl.PolicyDeny:  4,
+}
+
+// catalog tells whether the resources referenced by the rules exist.
+type catalog interface {
+	services() ([]string, error)
+	nodes() ([]string, error)
+	keyExists(key string, prefix bool) (bool, error)
+}
+
+// lintReferences reports the rules referencing services, nodes and keys that
+// don't exist in the catalog. Rules matching every name are skipped.
+func lintReferences(parsed *acl.Policy, cat catalog) ([]Finding, error) {
+	var findings []Finding
+	for _, r := range flattenRules(parsed) {
+		if r.name == "" {
+			continue
+		}
+
+		var (
+			exists bool
+			err    error
+			what   string
+		)
+		switch r.resource {
+		case "service":
+			what = "service"
+			exists, err = nameExists(cat.services, r)
+		case "node", "agent", "session":
+			what = "node"
+			exists, err = nameExists(cat.nodes, r)
+		case "key":
+			what = "key"
+			exists, err = cat.keyExists(r.name, r.prefix)
+		default:
+			// events and prepared queries are not tracked in the catalog
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+
+		msg := fmt.Sprintf("%s references the %s %q which doesn't exist", r, what, r.name)
+		if r.prefix {
+			msg = fmt.Sprintf("%s doesn't match any existing %s", r, what)
+		}
+		findings = append(findings, Finding{
+			Check:   CheckUnknownReference,
+			Rule:    r.String(),
+			Message: msg,
+		})
+	}
+	return findings, nil
+}
+
+func nameExists(list func() ([]string, error), r rule) (bool, error) {
+	names, err := list()
+	if err != nil {
+		return false, err
+	}
+	for _, name := range names {
+		if name == r.name || (r.prefix && strings.HasPrefix(name, r.name)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
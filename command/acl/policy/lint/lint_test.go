@@ -0,0 +1,124 @@
+package policylint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/acl"
+)
+
+func parseTestPolicy(t *testing.T, rules string) *acl.Policy {
+	t.Helper()
+
+	p, err := acl.NewPolicyFromSource(rules, nil, nil)
+	require.NoError(t, err)
+	return p
+}
+
+func findingRules(findings []Finding) []string {
+	var out []string
+	for _, f := range findings {
+		out = append(out, f.Check+": "+f.Rule)
+	}
+	return out
+}
+
+func TestLintShadowed(t *testing.T) {
+	p := parseTestPolicy(t, `
+service_prefix "" { policy = "read" }
+service "web" { policy = "read" }
+service "db" { policy = "write" }
+service_prefix "api-" { policy = "read" }
+service "api-v1" { policy = "read" intentions = "write" }
+key_prefix "app/" { policy = "write" }
+key_prefix "app/config/" { policy = "write" }
+key "app/config/db" { policy = "read" }
+node "n1" { policy = "read" }
+node "n1" { policy = "write" }
+`)
+
+	require.ElementsMatch(t, []string{
+		`shadowed-rule: node "n1"`,
+		`shadowed-rule: key_prefix "app/config/"`,
+		`shadowed-rule: service "web"`,
+		`shadowed-rule: service_prefix "api-"`,
+	}, findingRules(lintShadowed(p)))
+}
+
+func TestLintSyntax(t *testing.T) {
+	rules := `
+service "" { policy = "read" }
+service "web" {
+  policy = "read"
+  intention = "write"
+}
+servce_prefix "" { policy = "read" }
+operator = "read"
+`
+	// the unknown block and attribute are ignored by the parser
+	findings, err := lintSyntax(rules, parseTestPolicy(t, rules))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		`deprecated-syntax: service ""`,
+		`unknown-rule: service "web"`,
+		`unknown-rule: servce_prefix`,
+	}, findingRules(findings))
+
+	for _, f := range findings {
+		if f.Check == CheckUnknownRule && strings.HasPrefix(f.Rule, "service") {
+			require.Contains(t, f.Message, `"intention"`)
+		}
+	}
+}
+
+type testCatalog struct {
+	serviceNames []string
+	nodeNames    []string
+	keys         []string
+}
+
+func (c *testCatalog) services() ([]string, error) { return c.serviceNames, nil }
+func (c *testCatalog) nodes() ([]string, error)    { return c.nodeNames, nil }
+
+func (c *testCatalog) keyExists(key string, prefix bool) (bool, error) {
+	for _, k := range c.keys {
+		if k == key || (prefix && strings.HasPrefix(k, key)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestLintReferences(t *testing.T) {
+	p := parseTestPolicy(t, `
+service_prefix "" { policy = "read" }
+service "web" { policy = "write" }
+service "gone" { policy = "write" }
+service_prefix "api-" { policy = "write" }
+service_prefix "legacy-" { policy = "write" }
+node "node-1" { policy = "write" }
+agent "node-2" { policy = "read" }
+session_prefix "node-" { policy = "write" }
+key "app/config" { policy = "read" }
+key_prefix "app/" { policy = "write" }
+key_prefix "old/" { policy = "write" }
+event "deploy" { policy = "write" }
+`)
+
+	cat := &testCatalog{
+		serviceNames: []string{"web", "api-v1"},
+		nodeNames:    []string{"node-1"},
+		keys:         []string{"app/other"},
+	}
+	findings, err := lintReferences(p, cat)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		`unknown-reference: service "gone"`,
+		`unknown-reference: service_prefix "legacy-"`,
+		`unknown-reference: agent "node-2"`,
+		`unknown-reference: key "app/config"`,
+		`unknown-reference: key_prefix "old/"`,
+	}, findingRules(findings))
+}
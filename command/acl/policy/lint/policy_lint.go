@@ -0,0 +1,366 @@
+package policylint
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/flags"
+)
+
+const (
+	PrettyFormat string = "pretty"
+	JSONFormat   string = "json"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	skipReferences bool
+	format         string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.BoolVar(&c.skipReferences, "skip-references", false, "Do not check whether "+
+		"the services, nodes and keys referenced by the rules exist in the catalog.")
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		PrettyFormat,
+		fmt.Sprintf("Output format {%s|%s}", PrettyFormat, JSONFormat),
+	)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+// Report is the result of linting the policies.
+type Report struct {
+	PoliciesChecked int
+	Findings        []Finding
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if c.format != PrettyFormat && c.format != JSONFormat {
+		c.UI.Error(fmt.Sprintf("Invalid format: %s", c.format))
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	report, err := c.lint(client)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.format == JSONFormat {
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to marshal lint report: %v", err))
+			return 1
+		}
+		c.UI.Output(string(b))
+	} else {
+		c.UI.Info(formatReport(report))
+	}
+
+	if len(report.Findings) > 0 {
+		return 2
+	}
+	return 0
+}
+
+func (c *cmd) lint(client *api.Client) (*Report, error) {
+	entries, _, err := client.ACL().PolicyList(nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the policy list: %v", err)
+	}
+
+	linked, err := linkedPolicyIDs(client)
+	if err != nil {
+		return nil, err
+	}
+
+	localDC := ""
+	if self, err := client.Agent().Self(); err == nil {
+		localDC, _ = self["Config"]["Datacenter"].(string)
+	}
+
+	report := &Report{Findings: []Finding{}}
+	catalogs := make(map[string]*apiCatalog)
+	for _, entry := range entries {
+		policy, _, err := client.ACL().PolicyRead(entry.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read policy %q: %v", entry.Name, err)
+		}
+		if policy == nil {
+			// deleted since it was listed
+			continue
+		}
+		report.PoliciesChecked++
+
+		findings, err := c.lintPolicy(client, policy, localDC, catalogs)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to lint policy %q: %v", policy.Name, err)
+		}
+		if _, ok := linked[policy.ID]; !ok {
+			findings = append(findings, Finding{
+				Check:   CheckUnusedPolicy,
+				Message: "the policy is not linked to any token or role",
+			})
+		}
+
+		for _, f := range findings {
+			f.PolicyID = policy.ID
+			f.PolicyName = policy.Name
+			report.Findings = append(report.Findings, f)
+		}
+	}
+	return report, nil
+}
+
+func (c *cmd) lintPolicy(client *api.Client, policy *api.ACLPolicy, localDC string, catalogs map[string]*apiCatalog) ([]Finding, error) {
+	sp := &structs.ACLPolicy{
+		Rules:             policy.Rules,
+		TemplateVariables: policy.TemplateVariables,
+		EnterpriseMeta:    acl.NewEnterpriseMetaWithPartition(policy.Partition, policy.Namespace),
+	}
+
+	rules := policy.Rules
+	if sp.IsTemplate() {
+		// Templates are linted with each variable set to its own name, the
+		// referenced resources depend on the values given when linking them.
+		vars := make(map[string]string, len(sp.TemplateVariables))
+		for _, name := range sp.TemplateVariables {
+			vars[name] = name
+		}
+		rendered, err := sp.RenderTemplate(vars)
+		if err != nil {
+			return []Finding{{Check: CheckParseError, Message: err.Error()}}, nil
+		}
+		rules = rendered
+	}
+
+	parsed, err := acl.NewPolicyFromSource(rules, nil, sp.EnterprisePolicyMeta())
+	if err != nil {
+		return []Finding{{Check: CheckParseError, Message: err.Error()}}, nil
+	}
+
+	var findings []Finding
+	if rules != "" {
+		syntax, err := lintSyntax(rules, parsed)
+		if err != nil {
+			return []Finding{{Check: CheckParseError, Message: err.Error()}}, nil
+		}
+		findings = append(findings, syntax...)
+	}
+	findings = append(findings, lintShadowed(parsed)...)
+
+	if c.skipReferences || sp.IsTemplate() || !validInDatacenter(policy, localDC) {
+		return findings, nil
+	}
+
+	key := policy.Partition + "/" + policy.Namespace
+	cat, ok := catalogs[key]
+	if !ok {
+		cat = &apiCatalog{
+			client: client,
+			opts:   &api.QueryOptions{Partition: policy.Partition, Namespace: policy.Namespace},
+		}
+		catalogs[key] = cat
+	}
+	references, err := lintReferences(parsed, cat)
+	if err != nil {
+		return nil, err
+	}
+	return append(findings, references...), nil
+}
+
+// validInDatacenter returns whether the policy applies in the datacenter of
+// the agent, the catalog of other datacenters isn't checked.
+func validInDatacenter(policy *api.ACLPolicy, dc string) bool {
+	if len(policy.Datacenters) == 0 || dc == "" {
+		return true
+	}
+	for _, d := range policy.Datacenters {
+		if d == dc {
+			return true
+		}
+	}
+	return false
+}
+
+// linkedPolicyIDs returns the IDs of the policies and policy templates linked
+// to a token or a role.
+func linkedPolicyIDs(client *api.Client) (map[string]struct{}, error) {
+	linked := make(map[string]struct{})
+
+	tokens, _, err := client.ACL().TokenList(nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the token list: %v", err)
+	}
+	for _, token := range tokens {
+		for _, link := range token.Policies {
+			linked[link.ID] = struct{}{}
+		}
+		for _, link := range token.TemplatedPolicies {
+			linked[link.ID] = struct{}{}
+		}
+	}
+
+	roles, _, err := client.ACL().RoleList(nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the role list: %v", err)
+	}
+	for _, role := range roles {
+		for _, link := range role.Policies {
+			linked[link.ID] = struct{}{}
+		}
+		for _, link := range role.TemplatedPolicies {
+			linked[link.ID] = struct{}{}
+		}
+	}
+	return linked, nil
+}
+
+// apiCatalog looks up the resources of a partition and namespace with the
+// HTTP API. The services and nodes are only retrieved once.
+type apiCatalog struct {
+	client *api.Client
+	opts   *api.QueryOptions
+
+	serviceNames []string
+	nodeNames    []string
+}
+
+func (a *apiCatalog) services() ([]string, error) {
+	if a.serviceNames != nil {
+		return a.serviceNames, nil
+	}
+	services, _, err := a.client.Catalog().Services(a.opts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the services: %v", err)
+	}
+	a.serviceNames = make([]string, 0, len(services))
+	for name := range services {
+		a.serviceNames = append(a.serviceNames, name)
+	}
+	return a.serviceNames, nil
+}
+
+func (a *apiCatalog) nodes() ([]string, error) {
+	if a.nodeNames != nil {
+		return a.nodeNames, nil
+	}
+	nodes, _, err := a.client.Catalog().Nodes(&api.QueryOptions{Partition: a.opts.Partition})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve the nodes: %v", err)
+	}
+	a.nodeNames = make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		a.nodeNames = append(a.nodeNames, node.Node)
+	}
+	return a.nodeNames, nil
+}
+
+func (a *apiCatalog) keyExists(key string, prefix bool) (bool, error) {
+	if !prefix {
+		pair, _, err := a.client.KV().Get(key, a.opts)
+		if err != nil {
+			return false, fmt.Errorf("Failed to read key %q: %v", key, err)
+		}
+		return pair != nil, nil
+	}
+
+	// The separator limits the keys returned to the first level below the
+	// prefix, we only need to know there is one.
+	keys, _, err := a.client.KV().Keys(key, "/", a.opts)
+	if err != nil {
+		return false, fmt.Errorf("Failed to list keys with prefix %q: %v", key, err)
+	}
+	return len(keys) > 0, nil
+}
+
+func formatReport(report *Report) string {
+	var buffer bytes.Buffer
+
+	var current string
+	for _, f := range report.Findings {
+		if f.PolicyID != current {
+			buffer.WriteString(fmt.Sprintf("%s (%s):\n", f.PolicyName, f.PolicyID))
+			current = f.PolicyID
+		}
+		buffer.WriteString(fmt.Sprintf("   [%s] %s\n", f.Check, f.Message))
+	}
+
+	policies := make(map[string]struct{})
+	for _, f := range report.Findings {
+		policies[f.PolicyID] = struct{}{}
+	}
+	buffer.WriteString(fmt.Sprintf("Checked %d policies: %d issues found in %d policies",
+		report.PoliciesChecked, len(report.Findings), len(policies)))
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Lint ACL policies"
+	help     = `
+Usage: consul acl policy lint [options]
+
+  This command checks every ACL policy and reports:
+
+    - rules that fail to parse, and blocks or attributes that are ignored
+    - exact rules with an empty name, which matched every name with the
+      legacy ACL syntax but only match the empty name now
+    - rules that have no effect because a prefix rule of the same policy
+      already grants the same access, or because they are declared twice
+    - rules referencing services, nodes and keys that don't exist in the
+      catalog of the current datacenter
+    - policies that are not linked to any token or role
+
+  The command exits with 2 when issues are found, so it can be used in CI:
+
+          $ consul acl policy lint -format json
+
+  Only check the rules, without looking up the catalog:
+
+          $ consul acl policy lint -skip-references
+`
+)
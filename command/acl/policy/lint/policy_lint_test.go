@@ -0,0 +1,117 @@
+package policylint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestPolicyLintCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestPolicyLintCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+	writeOpts := &api.WriteOptions{Token: "root"}
+
+	err := client.Agent().ServiceRegister(&api.AgentServiceRegistration{Name: "web"})
+	require.NoError(t, err)
+
+	clean, _, err := client.ACL().PolicyCreate(&api.ACLPolicy{
+		Name:  "clean",
+		Rules: `service "web" { policy = "write" }`,
+	}, writeOpts)
+	require.NoError(t, err)
+
+	_, _, err = client.ACL().TokenCreate(&api.ACLToken{
+		Policies: []*api.ACLTokenPolicyLink{{ID: clean.ID}},
+	}, writeOpts)
+	require.NoError(t, err)
+
+	run := func(t *testing.T, args ...string) (int, *cli.MockUi) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		code := cmd.Run(append([]string{"-http-addr=" + a.HTTPAddr(), "-token=root"}, args...))
+		return code, ui
+	}
+
+	t.Run("no findings", func(t *testing.T) {
+		code, ui := run(t)
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Contains(t, ui.OutputWriter.String(), "0 issues found")
+	})
+
+	messy, _, err := client.ACL().PolicyCreate(&api.ACLPolicy{
+		Name: "messy",
+		Rules: `
+service_prefix "" { policy = "read" }
+service "db" { policy = "read" }
+service "legacy" { policy = "write" }
+node "" { policy = "read" }
+`,
+	}, writeOpts)
+	require.NoError(t, err)
+
+	t.Run("json", func(t *testing.T) {
+		code, ui := run(t, "-format=json")
+		require.Equal(t, 2, code, ui.ErrorWriter.String())
+
+		var report Report
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &report))
+		require.Equal(t, 3, report.PoliciesChecked)
+
+		var got []string
+		for _, f := range report.Findings {
+			require.Equal(t, messy.ID, f.PolicyID)
+			require.Equal(t, "messy", f.PolicyName)
+			got = append(got, f.Check+": "+f.Rule)
+		}
+		require.ElementsMatch(t, []string{
+			`deprecated-syntax: node ""`,
+			`shadowed-rule: service "db"`,
+			`unknown-reference: service "db"`,
+			`unknown-reference: service "legacy"`,
+			`unused-policy: `,
+		}, got)
+	})
+
+	t.Run("skip references", func(t *testing.T) {
+		code, ui := run(t, "-skip-references")
+		require.Equal(t, 2, code, ui.ErrorWriter.String())
+
+		output := ui.OutputWriter.String()
+		require.Contains(t, output, "messy ("+messy.ID+"):")
+		require.Contains(t, output, "[shadowed-rule]")
+		require.NotContains(t, output, "[unknown-reference]")
+		require.Contains(t, output, "Checked 3 policies: 3 issues found in 1 policies")
+	})
+}
@@ -54,5 +54,9 @@ Usage: consul acl policy <subcommand> [options] [args]
 
     $ consul acl policy delete -name "my-policy"
 
+  Lint all policies:
+
+    $ consul acl policy lint
+
   For more examples, ask for subcommand help or view the documentation.
 `
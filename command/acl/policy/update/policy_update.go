@@ -33,6 +33,7 @@ type cmd struct {
 	descriptionSet bool
 	description    string
 	datacenters    []string
+	templateVars   []string
 	rulesSet       bool
 	rules          string
 	noMerge        bool
@@ -55,6 +56,8 @@ func (c *cmd) init() {
 	c.flags.StringVar(&c.rules, "rules", "", "The policy rules. May be prefixed with '@' "+
 		"to indicate that the value is a file path to load the rules from. '-' may also be "+
 		"given to indicate that the rules are available on stdin")
+	c.flags.Var((*flags.AppendSliceValue)(&c.templateVars), "template-variable", "Name of a "+
+		"variable of the policy template. Overwrites the existing variables. This flag may be specified multiple times")
 	c.flags.BoolVar(&c.noMerge, "no-merge", false, "Do not merge the current policy "+
 		"information with what is provided to the command. Instead overwrite all fields "+
 		"with the exception of the policy ID which is immutable.")
@@ -125,6 +128,8 @@ func (c *cmd) Run(args []string) int {
 			Description: c.description,
 			Datacenters: c.datacenters,
 			Rules:       rules,
+
+			TemplateVariables: c.templateVars,
 		}
 	} else {
 		p, _, err := client.ACL().PolicyRead(policyID, nil)
@@ -139,6 +144,8 @@ func (c *cmd) Run(args []string) int {
 			Description: p.Description,
 			Datacenters: p.Datacenters,
 			Rules:       p.Rules,
+
+			TemplateVariables: p.TemplateVariables,
 		}
 
 		if c.nameSet {
@@ -153,6 +160,9 @@ func (c *cmd) Run(args []string) int {
 		if c.datacenters != nil {
 			updated.Datacenters = c.datacenters
 		}
+		if c.templateVars != nil {
+			updated.TemplateVariables = c.templateVars
+		}
 	}
 
 	p, _, err := client.ACL().PolicyUpdate(updated, nil)
@@ -25,12 +25,13 @@ type cmd struct {
 	http  *flags.HTTPFlags
 	help  string
 
-	name          string
-	description   string
-	policyIDs     []string
-	policyNames   []string
-	serviceIdents []string
-	nodeIdents    []string
+	name              string
+	description       string
+	policyIDs         []string
+	policyNames       []string
+	serviceIdents     []string
+	nodeIdents        []string
+	templatedPolicies []string
 
 	showMeta bool
 	format   string
@@ -52,6 +53,9 @@ func (c *cmd) init() {
 	c.flags.Var((*flags.AppendSliceValue)(&c.nodeIdents), "node-identity", "Name of a "+
 		"node identity to use for this role. May be specified multiple times. Format is "+
 		"NODENAME:DATACENTER")
+	c.flags.Var((*flags.AppendSliceValue)(&c.templatedPolicies), "templated-policy", "Name of a "+
+		"templated policy to use for this role. May be specified multiple times. Format is "+
+		"NAME or NAME:VAR1=VALUE1,VAR2=VALUE2,...")
 	c.flags.StringVar(
 		&c.format,
 		"format",
@@ -76,8 +80,9 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
-	if len(c.policyNames) == 0 && len(c.policyIDs) == 0 && len(c.serviceIdents) == 0 && len(c.nodeIdents) == 0 {
-		c.UI.Error(fmt.Sprintf("Cannot create a role without specifying -policy-name, -policy-id, -service-identity, or -node-identity at least once"))
+	if len(c.policyNames) == 0 && len(c.policyIDs) == 0 && len(c.serviceIdents) == 0 && len(c.nodeIdents) == 0 &&
+		len(c.templatedPolicies) == 0 {
+		c.UI.Error(fmt.Sprintf("Cannot create a role without specifying -policy-name, -policy-id, -service-identity, -node-identity, or -templated-policy at least once"))
 		return 1
 	}
 
@@ -121,6 +126,13 @@ func (c *cmd) Run(args []string) int {
 	}
 	newRole.NodeIdentities = parsedNodeIdents
 
+	parsedTemplatedPolicies, err := acl.ExtractTemplatedPolicies(c.templatedPolicies)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	newRole.TemplatedPolicies = parsedTemplatedPolicies
+
 	r, _, err := client.ACL().RoleCreate(newRole, nil)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Failed to create new role: %v", err))
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/acl"
 )
 
 const (
@@ -86,6 +87,12 @@ func (f *prettyFormatter) FormatRole(role *api.ACLRole) (string, error) {
 			buffer.WriteString(fmt.Sprintf("   %s (Datacenter: %s)\n", nodeid.NodeName, nodeid.Datacenter))
 		}
 	}
+	if len(role.TemplatedPolicies) > 0 {
+		buffer.WriteString(fmt.Sprintln("Templated Policies:"))
+		for _, templated := range role.TemplatedPolicies {
+			buffer.WriteString(fmt.Sprintf("   %s\n", acl.FormatTemplatedPolicy(templated)))
+		}
+	}
 
 	return buffer.String(), nil
 }
@@ -141,6 +148,13 @@ func (f *prettyFormatter) formatRoleListEntry(role *api.ACLRole) string {
 		}
 	}
 
+	if len(role.TemplatedPolicies) > 0 {
+		buffer.WriteString(fmt.Sprintln("   Templated Policies:"))
+		for _, templated := range role.TemplatedPolicies {
+			buffer.WriteString(fmt.Sprintf("      %s\n", acl.FormatTemplatedPolicy(templated)))
+		}
+	}
+
 	return buffer.String()
 }
 
@@ -35,7 +35,10 @@ type cmd struct {
 	roleNames     []string
 	serviceIdents []string
 	nodeIdents    []string
+	templated     []string
 	expirationTTL time.Duration
+	renewable     bool
+	maxTTL        time.Duration
 	local         bool
 	showMeta      bool
 	format        string
@@ -65,8 +68,16 @@ func (c *cmd) init() {
 	c.flags.Var((*flags.AppendSliceValue)(&c.nodeIdents), "node-identity", "Name of a "+
 		"node identity to use for this token. May be specified multiple times. Format is "+
 		"NODENAME:DATACENTER")
+	c.flags.Var((*flags.AppendSliceValue)(&c.templated), "templated-policy", "Name of a "+
+		"policy template to use for this token, with the values of its variables. May be "+
+		"specified multiple times. Format is TEMPLATENAME:VARIABLE1=VALUE1,VARIABLE2=VALUE2,...")
 	c.flags.DurationVar(&c.expirationTTL, "expires-ttl", 0, "Duration of time this "+
 		"token should be valid for")
+	c.flags.BoolVar(&c.renewable, "renewable", false, "Allow renewing the token to extend "+
+		"its expiration time by the -expires-ttl, which is required")
+	c.flags.DurationVar(&c.maxTTL, "max-ttl", 0, "Duration of time since its creation "+
+		"after which a renewable token can't be renewed any further. By default renewable "+
+		"tokens can be renewed indefinitely")
 	c.flags.StringVar(
 		&c.format,
 		"format",
@@ -87,8 +98,9 @@ func (c *cmd) Run(args []string) int {
 
 	if len(c.policyNames) == 0 && len(c.policyIDs) == 0 &&
 		len(c.roleNames) == 0 && len(c.roleIDs) == 0 &&
-		len(c.serviceIdents) == 0 && len(c.nodeIdents) == 0 {
-		c.UI.Error(fmt.Sprintf("Cannot create a token without specifying -policy-name, -policy-id, -role-name, -role-id, -service-identity, or -node-identity at least once"))
+		len(c.serviceIdents) == 0 && len(c.nodeIdents) == 0 &&
+		len(c.templated) == 0 {
+		c.UI.Error(fmt.Sprintf("Cannot create a token without specifying -policy-name, -policy-id, -role-name, -role-id, -service-identity, -node-identity, or -templated-policy at least once"))
 		return 1
 	}
 
@@ -107,6 +119,13 @@ func (c *cmd) Run(args []string) int {
 	if c.expirationTTL > 0 {
 		newToken.ExpirationTTL = c.expirationTTL
 	}
+	if c.renewable {
+		newToken.Renewable = true
+		newToken.MaxExpirationTTL = c.maxTTL
+	} else if c.maxTTL != 0 {
+		c.UI.Error("The -max-ttl flag requires -renewable")
+		return 1
+	}
 
 	parsedServiceIdents, err := acl.ExtractServiceIdentities(c.serviceIdents)
 	if err != nil {
@@ -122,6 +141,13 @@ func (c *cmd) Run(args []string) int {
 	}
 	newToken.NodeIdentities = parsedNodeIdents
 
+	parsedTemplated, err := acl.ExtractTemplatedPolicies(c.templated)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	newToken.TemplatedPolicies = parsedTemplated
+
 	for _, policyName := range c.policyNames {
 		// We could resolve names to IDs here but there isn't any reason why its would be better
 		// than allowing the agent to do it.
@@ -201,5 +227,14 @@ Usage: consul acl token create [options]
                                     -role-name "db-updater" \
                                     -service-identity "web" \
                                     -service-identity "db:east,west"
+
+  Create a token from the "kv-app" policy template:
+
+          $ consul acl token create -templated-policy "kv-app:prefix=apps/web"
+
+  Create a token that expires after an hour unless it's renewed, for at most a day:
+
+          $ consul acl token create -expires-ttl 1h -renewable -max-ttl 24h \
+                                    -policy-name "web-deploy"
 `
 )
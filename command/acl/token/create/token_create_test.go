@@ -102,6 +102,39 @@ func TestTokenCreateCommand_Pretty(t *testing.T) {
 		require.Equal(t, a.Config.NodeName, nodes[0].Node)
 	})
 
+	// create with a templated policy
+	t.Run("templated-policy", func(t *testing.T) {
+		template, _, err := client.ACL().PolicyCreate(
+			&api.ACLPolicy{
+				Name:              "node-template",
+				Rules:             `node "${var.name}" { policy = "read" }`,
+				TemplateVariables: []string{"name"},
+			},
+			&api.WriteOptions{Token: "root"},
+		)
+		require.NoError(t, err)
+
+		token := run(t, []string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-templated-policy=" + template.Name + ":name=" + a.Config.NodeName,
+		})
+		require.Len(t, token.TemplatedPolicies, 1)
+		require.Equal(t, template.ID, token.TemplatedPolicies[0].ID)
+		require.Equal(t, map[string]string{"name": a.Config.NodeName}, token.TemplatedPolicies[0].Variables)
+
+		conf := api.DefaultConfig()
+		conf.Address = a.HTTPAddr()
+		conf.Token = token.SecretID
+		client, err := api.NewClient(conf)
+		require.NoError(t, err)
+
+		nodes, _, err := client.Catalog().Nodes(nil)
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+		require.Equal(t, a.Config.NodeName, nodes[0].Node)
+	})
+
 	// create with accessor and secret
 	t.Run("predefined-ids", func(t *testing.T) {
 		token := run(t, []string{
@@ -0,0 +1,251 @@
+package tokenexplain
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/acl"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/helpers"
+)
+
+const (
+	PrettyFormat string = "pretty"
+	JSONFormat   string = "json"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	tokenAccessorID string
+	resource        string
+	access          string
+	simulateRules   []string
+	simulateOnly    bool
+	format          string
+
+	testStdin io.Reader
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.tokenAccessorID, "accessor-id", "", "The Accessor ID of the token to explain. "+
+		"It may be specified as a unique ID prefix but will error if the prefix "+
+		"matches multiple token Accessor IDs. Defaults to the token used for the request. "+
+		"Explaining another token requires acl:read.")
+	c.flags.StringVar(&c.resource, "resource", "", "The resource to explain access to, "+
+		"as <resource>[:<segment>]. For example service:web, key:foo/bar or operator.")
+	c.flags.StringVar(&c.access, "access", "", "The access level to explain. "+
+		"One of read, write, or for keys also list and write-prefix.")
+	c.flags.Var((*flags.AppendSliceValue)(&c.simulateRules), "simulate-rules", "Rules of a "+
+		"policy that doesn't exist yet to evaluate along with the token's policies. "+
+		"May be prefixed with '@' to indicate that the value is a file path to load the "+
+		"rules from. '-' may also be given to indicate that the rules are available on stdin. "+
+		"May be specified multiple times.")
+	c.flags.BoolVar(&c.simulateOnly, "simulate-only", false, "Only evaluate the simulated "+
+		"policies, ignoring the policies, roles and identities of the token.")
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		PrettyFormat,
+		fmt.Sprintf("Output format {%s|%s}", PrettyFormat, JSONFormat),
+	)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if c.resource == "" {
+		c.UI.Error("Must specify the -resource parameter")
+		return 1
+	}
+	if c.access == "" {
+		c.UI.Error("Must specify the -access parameter")
+		return 1
+	}
+	if c.simulateOnly && len(c.simulateRules) == 0 {
+		c.UI.Error("Must specify at least one -simulate-rules parameter with -simulate-only")
+		return 1
+	}
+	if c.format != PrettyFormat && c.format != JSONFormat {
+		c.UI.Error(fmt.Sprintf("Invalid format: %s", c.format))
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	resource, segment, _ := strings.Cut(c.resource, ":")
+	req := &api.ACLAuthorizeExplainRequest{
+		Requests: []api.ACLAuthorizationRequest{
+			{
+				Resource: resource,
+				Segment:  segment,
+				Access:   c.access,
+			},
+		},
+		PoliciesOnly: c.simulateOnly,
+	}
+
+	if c.tokenAccessorID != "" && !c.simulateOnly {
+		req.AccessorID, err = acl.GetTokenAccessorIDFromPartial(client, c.tokenAccessorID)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error determining token ID: %v", err))
+			return 1
+		}
+	}
+
+	for i, source := range c.simulateRules {
+		rules, err := helpers.LoadDataSource(source, c.testStdin)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error loading simulated rules: %v", err))
+			return 1
+		}
+		req.Policies = append(req.Policies, &api.ACLPolicy{
+			Name:  fmt.Sprintf("simulated-%d", i+1),
+			Rules: rules,
+		})
+	}
+
+	resp, _, err := client.ACL().AuthorizeExplain(req, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error explaining authorization: %v", err))
+		return 1
+	}
+
+	if c.format == JSONFormat {
+		b, err := json.MarshalIndent(resp, "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to marshal explanation: %v", err))
+			return 1
+		}
+		c.UI.Output(string(b))
+		return 0
+	}
+
+	c.UI.Info(formatExplanation(resp))
+	return 0
+}
+
+func formatExplanation(resp *api.ACLAuthorizeExplainResponse) string {
+	var buffer bytes.Buffer
+
+	if resp.AccessorID != "" {
+		buffer.WriteString(fmt.Sprintf("AccessorID:      %s\n", resp.AccessorID))
+	} else {
+		buffer.WriteString("AccessorID:      (simulated policies only)\n")
+	}
+
+	for _, e := range resp.Explanations {
+		resource := e.Resource
+		if e.Segment != "" {
+			resource += ":" + e.Segment
+		}
+		decision := "deny"
+		if e.Allow {
+			decision = "allow"
+		}
+
+		buffer.WriteString(fmt.Sprintf("Resource:        %s\n", resource))
+		buffer.WriteString(fmt.Sprintf("Access:          %s\n", e.Access))
+		buffer.WriteString(fmt.Sprintf("Decision:        %s\n", decision))
+		if e.DefaultPolicy || e.Source == nil {
+			buffer.WriteString(fmt.Sprintf("Decided By:      default policy %q\n", resp.DefaultPolicy))
+		} else {
+			buffer.WriteString(fmt.Sprintf("Decided By:      %s\n", formatSource(e.Source)))
+			buffer.WriteString(fmt.Sprintf("Rule:            %s\n", e.Source.Rule))
+		}
+
+		if len(e.Matches) > 0 {
+			buffer.WriteString("Matching Rules:\n")
+			for _, m := range e.Matches {
+				buffer.WriteString(fmt.Sprintf("   %-5s  %s - %s\n", m.Decision, m.Rule, formatSource(&m)))
+			}
+		}
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func formatSource(s *api.ACLAuthorizationSource) string {
+	var out string
+	switch s.Type {
+	case "service-identity":
+		out = fmt.Sprintf("service identity %q", s.Name)
+	case "node-identity":
+		out = fmt.Sprintf("node identity %q", s.Name)
+	case "simulated":
+		out = fmt.Sprintf("simulated policy %q", s.Name)
+	default:
+		out = fmt.Sprintf("policy %q", s.Name)
+	}
+	if s.RoleName != "" {
+		out += fmt.Sprintf(" (via role %q)", s.RoleName)
+	}
+	return out
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Explain the authorization decisions made for an ACL token"
+	help     = `
+Usage: consul acl token explain [options] -resource RESOURCE -access ACCESS
+
+  This command explains whether a token is allowed to access a resource,
+  and which rule of which policy, role, service identity or node identity
+  made that decision. When no rule applies the default policy is used.
+
+  Explain the token used for the request:
+
+          $ consul acl token explain -resource service:web -access write
+
+  Explain another token:
+
+          $ consul acl token explain -accessor-id 4be56c77-82 \
+                                     -resource key:app/config -access read
+
+  Check what a token would be allowed to do with an additional policy:
+
+          $ consul acl token explain -accessor-id 4be56c77-82 \
+                                     -resource service:web -access write \
+                                     -simulate-rules @web-write.hcl
+
+  Check what a policy that doesn't exist yet allows on its own:
+
+          $ consul acl token explain -simulate-only -simulate-rules @web-write.hcl \
+                                     -resource service:web -access write
+`
+)
@@ -0,0 +1,133 @@
+package tokenexplain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestTokenExplainCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestTokenExplainCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		default_policy = "deny"
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+
+	policy, _, err := client.ACL().PolicyCreate(
+		&api.ACLPolicy{Name: "web-read", Rules: `service "web" { policy = "read" }`},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	token, _, err := client.ACL().TokenCreate(
+		&api.ACLToken{Policies: []*api.ACLTokenPolicyLink{{ID: policy.ID}}},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	run := func(t *testing.T, args ...string) (int, *cli.MockUi) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		code := cmd.Run(append([]string{"-http-addr=" + a.HTTPAddr()}, args...))
+		return code, ui
+	}
+
+	t.Run("own token", func(t *testing.T) {
+		code, ui := run(t, "-token="+token.SecretID, "-resource=service:web", "-access=read")
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		output := ui.OutputWriter.String()
+		require.Contains(t, output, token.AccessorID)
+		require.Contains(t, output, "Decision:        allow")
+		require.Contains(t, output, `Decided By:      policy "web-read"`)
+		require.Contains(t, output, `Rule:            service "web" { policy = "read" }`)
+	})
+
+	t.Run("default policy", func(t *testing.T) {
+		code, ui := run(t, "-token=root", "-accessor-id="+token.AccessorID, "-resource=service:web", "-access=write")
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		output := ui.OutputWriter.String()
+		require.Contains(t, output, "Decision:        deny")
+		require.Contains(t, output, `Decided By:      policy "web-read"`)
+
+		code, ui = run(t, "-token=root", "-accessor-id="+token.AccessorID, "-resource=key:foo", "-access=read")
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Contains(t, ui.OutputWriter.String(), `Decided By:      default policy "deny"`)
+	})
+
+	t.Run("simulated", func(t *testing.T) {
+		code, ui := run(t,
+			"-token="+token.SecretID,
+			"-resource=service:web",
+			"-access=write",
+			"-simulate-rules", `service_prefix "w" { policy = "write" }`,
+			"-format=json",
+		)
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		var resp api.ACLAuthorizeExplainResponse
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &resp))
+		require.Len(t, resp.Explanations, 1)
+
+		// The exact match from the token's policy is more specific.
+		explanation := resp.Explanations[0]
+		require.False(t, explanation.Allow)
+		require.Equal(t, "policy", explanation.Source.Type)
+		require.Len(t, explanation.Matches, 2)
+		require.Equal(t, "simulated", explanation.Matches[1].Type)
+		require.Equal(t, "allow", explanation.Matches[1].Decision)
+	})
+
+	t.Run("simulate only", func(t *testing.T) {
+		code, ui := run(t,
+			"-token="+token.SecretID,
+			"-resource=service:web",
+			"-access=write",
+			"-simulate-only",
+			"-simulate-rules", `service_prefix "w" { policy = "write" }`,
+		)
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		output := ui.OutputWriter.String()
+		require.Contains(t, output, "(simulated policies only)")
+		require.Contains(t, output, "Decision:        allow")
+		require.Contains(t, output, `Decided By:      simulated policy "simulated-1"`)
+	})
+
+	t.Run("missing resource", func(t *testing.T) {
+		code, ui := run(t, "-token=root", "-access=read")
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "Must specify the -resource parameter")
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/consul/acl"
 	"github.com/hashicorp/consul/agent/structs"
 	"github.com/hashicorp/consul/api"
+	cmdacl "github.com/hashicorp/consul/command/acl"
 )
 
 const (
@@ -73,6 +74,16 @@ func (f *prettyFormatter) FormatToken(token *api.ACLToken) (string, error) {
 	if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
 		buffer.WriteString(fmt.Sprintf("Expiration Time:  %v\n", *token.ExpirationTime))
 	}
+	if token.Renewable {
+		buffer.WriteString(fmt.Sprintf("Renewal TTL:      %s\n", token.RenewalTTL))
+	}
+	if token.MaxExpirationTime != nil && !token.MaxExpirationTime.IsZero() {
+		buffer.WriteString(fmt.Sprintf("Max Expiration:   %v\n", *token.MaxExpirationTime))
+	}
+	if token.PreviousSecretID != "" && token.PreviousSecretExpirationTime != nil {
+		buffer.WriteString(fmt.Sprintf("Previous Secret:  %s (Expires: %v)\n", token.PreviousSecretID, *token.PreviousSecretExpirationTime))
+	}
+	formatTokenUsage(&buffer, token.Usage)
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("Hash:             %x\n", token.Hash))
 		buffer.WriteString(fmt.Sprintf("Create Index:     %d\n", token.CreateIndex))
@@ -106,6 +117,12 @@ func (f *prettyFormatter) FormatToken(token *api.ACLToken) (string, error) {
 			buffer.WriteString(fmt.Sprintf("   %s (Datacenter: %s)\n", nodeid.NodeName, nodeid.Datacenter))
 		}
 	}
+	if len(token.TemplatedPolicies) > 0 {
+		buffer.WriteString(fmt.Sprintln("Templated Policies:"))
+		for _, templated := range token.TemplatedPolicies {
+			buffer.WriteString(fmt.Sprintf("   %s\n", cmdacl.FormatTemplatedPolicy(templated)))
+		}
+	}
 
 	return buffer.String(), nil
 }
@@ -130,6 +147,16 @@ func (f *prettyFormatter) FormatTokenExpanded(token *api.ACLTokenExpanded) (stri
 	if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
 		buffer.WriteString(fmt.Sprintf("Expiration Time:  %v\n", *token.ExpirationTime))
 	}
+	if token.Renewable {
+		buffer.WriteString(fmt.Sprintf("Renewal TTL:      %s\n", token.RenewalTTL))
+	}
+	if token.MaxExpirationTime != nil && !token.MaxExpirationTime.IsZero() {
+		buffer.WriteString(fmt.Sprintf("Max Expiration:   %v\n", *token.MaxExpirationTime))
+	}
+	if token.PreviousSecretID != "" && token.PreviousSecretExpirationTime != nil {
+		buffer.WriteString(fmt.Sprintf("Previous Secret:  %s (Expires: %v)\n", token.PreviousSecretID, *token.PreviousSecretExpirationTime))
+	}
+	formatTokenUsage(&buffer, token.Usage)
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("Hash:             %x\n", token.Hash))
 		buffer.WriteString(fmt.Sprintf("Create Index:     %d\n", token.CreateIndex))
@@ -198,6 +225,12 @@ func (f *prettyFormatter) FormatTokenExpanded(token *api.ACLTokenExpanded) (stri
 			formatNodeIdentity(nodeIdentity, WHITESPACE_2)
 		}
 	}
+	if len(token.ACLToken.TemplatedPolicies) > 0 {
+		buffer.WriteString("Templated Policies:\n")
+		for _, templated := range token.ACLToken.TemplatedPolicies {
+			buffer.WriteString(fmt.Sprintf(WHITESPACE_2+"%s\n", cmdacl.FormatTemplatedPolicy(templated)))
+		}
+	}
 
 	formatRole := func(role api.ACLRole, indent string) {
 		buffer.WriteString(fmt.Sprintf(indent+"Role Name: %s\n", role.Name))
@@ -224,6 +257,13 @@ func (f *prettyFormatter) FormatTokenExpanded(token *api.ACLTokenExpanded) (stri
 				formatNodeIdentity(nodeIdentity, indent+WHITESPACE_4)
 			}
 		}
+
+		if len(role.TemplatedPolicies) > 0 {
+			buffer.WriteString(indent + WHITESPACE_2 + "Templated Policies:\n")
+			for _, templated := range role.TemplatedPolicies {
+				buffer.WriteString(fmt.Sprintf(indent+WHITESPACE_4+"%s\n", cmdacl.FormatTemplatedPolicy(templated)))
+			}
+		}
 	}
 	if len(token.ACLToken.Roles) > 0 {
 		buffer.WriteString("Roles:\n")
@@ -299,6 +339,7 @@ func (f *prettyFormatter) formatTokenListEntry(token *api.ACLTokenListEntry) str
 	if token.ExpirationTime != nil && !token.ExpirationTime.IsZero() {
 		buffer.WriteString(fmt.Sprintf("Expiration Time:  %v\n", *token.ExpirationTime))
 	}
+	formatTokenUsage(&buffer, token.Usage)
 	if f.showMeta {
 		buffer.WriteString(fmt.Sprintf("Hash:             %x\n", token.Hash))
 		buffer.WriteString(fmt.Sprintf("Create Index:     %d\n", token.CreateIndex))
@@ -332,6 +373,12 @@ func (f *prettyFormatter) formatTokenListEntry(token *api.ACLTokenListEntry) str
 			buffer.WriteString(fmt.Sprintf("   %s (Datacenter: %s)\n", nodeid.NodeName, nodeid.Datacenter))
 		}
 	}
+	if len(token.TemplatedPolicies) > 0 {
+		buffer.WriteString(fmt.Sprintln("Templated Policies:"))
+		for _, templated := range token.TemplatedPolicies {
+			buffer.WriteString(fmt.Sprintf("   %s\n", cmdacl.FormatTemplatedPolicy(templated)))
+		}
+	}
 	return buffer.String()
 }
 
@@ -366,3 +413,16 @@ func (f *jsonFormatter) FormatTokenExpanded(token *api.ACLTokenExpanded) (string
 	}
 	return string(b), nil
 }
+
+func formatTokenUsage(buffer *bytes.Buffer, usage *api.ACLTokenUsage) {
+	if usage == nil || usage.LastUsedTime == nil {
+		return
+	}
+	buffer.WriteString(fmt.Sprintf("Last Used:        %v\n", *usage.LastUsedTime))
+	if usage.SourceAddr != "" {
+		buffer.WriteString(fmt.Sprintf("Last Used From:   %s\n", usage.SourceAddr))
+	}
+	if usage.Agent != "" {
+		buffer.WriteString(fmt.Sprintf("Last Used Agent:  %s\n", usage.Agent))
+	}
+}
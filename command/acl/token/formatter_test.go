@@ -57,6 +57,37 @@ func TestFormatToken(t *testing.T) {
 				ModifyIndex: 100,
 			},
 		},
+		"rotated": {
+			token: api.ACLToken{
+				AccessorID:                   "fbd2447f-7479-4329-ad13-b021d74f86ba",
+				SecretID:                     "869c6e91-4de9-4dab-b56e-87548435f9c6",
+				Description:                  "test token",
+				Local:                        false,
+				CreateTime:                   time.Date(2020, 5, 22, 18, 52, 31, 0, time.UTC),
+				ExpirationTime:               timeRef(time.Date(2020, 5, 24, 18, 52, 31, 0, time.UTC)),
+				PreviousSecretID:             "b1bd4e3c-fb0e-4a13-a2fc-0ba1e5e3a38d",
+				PreviousSecretExpirationTime: timeRef(time.Date(2020, 5, 23, 18, 52, 31, 0, time.UTC)),
+				Hash:                         []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
+				CreateIndex:                  42,
+				ModifyIndex:                  100,
+			},
+		},
+		"renewable": {
+			token: api.ACLToken{
+				AccessorID:        "fbd2447f-7479-4329-ad13-b021d74f86ba",
+				SecretID:          "869c6e91-4de9-4dab-b56e-87548435f9c6",
+				Description:       "test token",
+				Local:             false,
+				CreateTime:        time.Date(2020, 5, 22, 18, 52, 31, 0, time.UTC),
+				ExpirationTime:    timeRef(time.Date(2020, 5, 22, 19, 52, 31, 0, time.UTC)),
+				Renewable:         true,
+				RenewalTTL:        time.Hour,
+				MaxExpirationTime: timeRef(time.Date(2020, 5, 29, 18, 52, 31, 0, time.UTC)),
+				Hash:              []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
+				CreateIndex:       42,
+				ModifyIndex:       100,
+			},
+		},
 		"complex": {
 			token: api.ACLToken{
 				AccessorID:          "fbd2447f-7479-4329-ad13-b021d74f86ba",
@@ -71,6 +102,11 @@ func TestFormatToken(t *testing.T) {
 				Hash:                []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
 				CreateIndex:         5,
 				ModifyIndex:         10,
+				Usage: &api.ACLTokenUsage{
+					LastUsedTime: timeRef(time.Date(2020, 5, 22, 19, 12, 31, 0, time.UTC)),
+					SourceAddr:   "10.0.1.7",
+					Agent:        "node-1",
+				},
 				Policies: []*api.ACLLink{
 					{
 						ID:   "beb04680-815b-4d7c-9e33-3d707c24672c",
@@ -174,6 +210,11 @@ func TestFormatTokenList(t *testing.T) {
 					Hash:                []byte{'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h'},
 					CreateIndex:         5,
 					ModifyIndex:         10,
+					Usage: &api.ACLTokenUsage{
+						LastUsedTime: timeRef(time.Date(2020, 5, 22, 19, 12, 31, 0, time.UTC)),
+						SourceAddr:   "10.0.1.7",
+						Agent:        "node-1",
+					},
 					Policies: []*api.ACLLink{
 						{
 							ID:   "beb04680-815b-4d7c-9e33-3d707c24672c",
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/command/acl/token"
 	"github.com/hashicorp/consul/command/flags"
 	"github.com/mitchellh/cli"
@@ -24,6 +25,7 @@ type cmd struct {
 
 	showMeta bool
 	format   string
+	filter   string
 }
 
 func (c *cmd) init() {
@@ -36,6 +38,7 @@ func (c *cmd) init() {
 		token.PrettyFormat,
 		fmt.Sprintf("Output format {%s}", strings.Join(token.GetSupportedFormats(), "|")),
 	)
+	c.flags.StringVar(&c.filter, "filter", "", "Filter to use with the request")
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.http.ClientFlags())
 	flags.Merge(c.flags, c.http.ServerFlags())
@@ -54,7 +57,7 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
-	tokens, _, err := client.ACL().TokenList(nil)
+	tokens, _, err := client.ACL().TokenList(&api.QueryOptions{Filter: c.filter})
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Failed to retrieve the token list: %v", err))
 		return 1
@@ -93,5 +96,9 @@ Usage: consul acl token list [options]
   List all the ACL tokens
 
           $ consul acl token list
+
+  List the tokens that haven't been used in the last 90 days:
+
+          $ consul acl token list -filter 'Usage.UnusedFor == "90d"'
 `
 )
@@ -134,3 +134,50 @@ func TestTokenListCommand_JSON(t *testing.T) {
 	}
 	require.Subset(t, respIDs, tokenIds)
 }
+
+func TestTokenListCommand_Filter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+	for i := 0; i < 3; i++ {
+		_, _, err := client.ACL().TokenCreate(
+			&api.ACLToken{Description: fmt.Sprintf("test token %d", i)},
+			&api.WriteOptions{Token: "root"},
+		)
+		require.NoError(t, err)
+	}
+
+	ui := cli.NewMockUi()
+	cmd := New(ui)
+
+	args := []string{
+		"-http-addr=" + a.HTTPAddr(),
+		"-token=root",
+		"-format=json",
+		"-filter", `Description == "test token 1"`,
+	}
+
+	code := cmd.Run(args)
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	var tokens []*api.ACLTokenListEntry
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &tokens))
+	require.Len(t, tokens, 1)
+	require.Equal(t, "test token 1", tokens[0].Description)
+}
@@ -0,0 +1,143 @@
+package tokenrenew
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/command/acl"
+	"github.com/hashicorp/consul/command/acl/token"
+	"github.com/hashicorp/consul/command/flags"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	tokenAccessorID string
+	self            bool
+	increment       time.Duration
+	format          string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.tokenAccessorID, "accessor-id", "", "The Accessor ID of the token to renew. "+
+		"It may be specified as a unique ID prefix but will error if the prefix "+
+		"matches multiple token Accessor IDs")
+	c.flags.BoolVar(&c.self, "self", false, "Indicates that the current HTTP token "+
+		"should be renewed instead of expecting a -accessor-id option")
+	c.flags.DurationVar(&c.increment, "increment", 0, "Duration from now the token "+
+		"should expire after, such as \"1h\". Defaults to the TTL the token was "+
+		"created with. The expiration time can't be extended past the max TTL of the token.")
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		token.PrettyFormat,
+		fmt.Sprintf("Output format {%s}", strings.Join(token.GetSupportedFormats(), "|")),
+	)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if c.tokenAccessorID == "" && !c.self {
+		c.UI.Error("Cannot renew a token without specifying the -accessor-id or -self parameter")
+		return 1
+	}
+	if c.tokenAccessorID != "" && c.self {
+		c.UI.Error("Cannot use both -accessor-id and -self")
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	var tok string
+	if c.self {
+		// Tokens renewing themselves may not be able to list tokens to
+		// resolve a prefix, so read the accessor ID of the HTTP token.
+		self, _, err := client.ACL().TokenReadSelf(nil)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading token: %v", err))
+			return 1
+		}
+		tok = self.AccessorID
+	} else {
+		tok, err = acl.GetTokenAccessorIDFromPartial(client, c.tokenAccessorID)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error determining token Accessor ID: %v", err))
+			return 1
+		}
+	}
+
+	t, _, err := client.ACL().TokenRenew(tok, c.increment, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error renewing token: %v", err))
+		return 1
+	}
+
+	formatter, err := token.NewFormatter(c.format, false)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	out, err := formatter.FormatToken(t)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	if out != "" {
+		c.UI.Info(out)
+	}
+
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Extend the expiration time of a renewable ACL token"
+	help     = `
+Usage: consul acl token renew [options]
+
+    This command extends the expiration time of a token created with
+    -renewable. By default the token expires after the TTL it was created
+    with, counted from now. It can't be renewed past its max TTL.
+
+    Renew a token:
+
+        $ consul acl token renew -accessor-id abcd
+
+    Renew the token used by this command for another hour:
+
+        $ consul acl token renew -self -increment 1h
+`
+)
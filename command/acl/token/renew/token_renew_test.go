@@ -0,0 +1,125 @@
+package tokenrenew
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestTokenRenewCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestTokenRenewCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+
+	createToken := func(t *testing.T, renewable bool) *api.ACLToken {
+		token, _, err := client.ACL().TokenCreate(
+			&api.ACLToken{
+				Description:   "test",
+				ExpirationTTL: 10 * time.Minute,
+				Renewable:     renewable,
+			},
+			&api.WriteOptions{Token: "root"},
+		)
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("accessor id", func(t *testing.T) {
+		token := createToken(t, true)
+
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-accessor-id=" + token.AccessorID,
+			"-increment=1h",
+			"-format=json",
+		})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Empty(t, ui.ErrorWriter.String())
+
+		var renewed api.ACLToken
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &renewed))
+		require.Equal(t, token.AccessorID, renewed.AccessorID)
+		require.NotNil(t, renewed.ExpirationTime)
+		require.WithinDuration(t, time.Now().Add(time.Hour), *renewed.ExpirationTime, time.Minute)
+	})
+
+	t.Run("self", func(t *testing.T) {
+		token := createToken(t, true)
+
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		// The token has no policies, it can still renew itself.
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=" + token.SecretID,
+			"-self",
+		})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Contains(t, ui.OutputWriter.String(), token.AccessorID)
+		require.Contains(t, ui.OutputWriter.String(), "Renewal TTL:      10m0s")
+	})
+
+	t.Run("not renewable", func(t *testing.T) {
+		token := createToken(t, false)
+
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-accessor-id=" + token.AccessorID,
+		})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "is not renewable")
+	})
+
+	t.Run("missing accessor id", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+		})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "-accessor-id")
+	})
+}
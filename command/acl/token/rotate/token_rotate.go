@@ -0,0 +1,120 @@
+package tokenrotate
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/command/acl"
+	"github.com/hashicorp/consul/command/acl/token"
+	"github.com/hashicorp/consul/command/flags"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	tokenAccessorID string
+	grace           time.Duration
+	format          string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.tokenAccessorID, "accessor-id", "", "The Accessor ID of the token to rotate. "+
+		"It may be specified as a unique ID prefix but will error if the prefix "+
+		"matches multiple token Accessor IDs")
+	c.flags.DurationVar(&c.grace, "grace", 0, "Duration the current SecretID remains valid "+
+		"after the rotation, such as \"24h\". By default the current SecretID is "+
+		"invalidated immediately.")
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		token.PrettyFormat,
+		fmt.Sprintf("Output format {%s}", strings.Join(token.GetSupportedFormats(), "|")),
+	)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if c.tokenAccessorID == "" {
+		c.UI.Error("Cannot rotate a token without specifying the -accessor-id parameter")
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	tok, err := acl.GetTokenAccessorIDFromPartial(client, c.tokenAccessorID)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error determining token Accessor ID: %v", err))
+		return 1
+	}
+
+	t, _, err := client.ACL().TokenRotate(tok, c.grace, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error rotating token: %v", err))
+		return 1
+	}
+
+	formatter, err := token.NewFormatter(c.format, false)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	out, err := formatter.FormatToken(t)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	if out != "" {
+		c.UI.Info(out)
+	}
+
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Issue a new SecretID for an ACL token"
+	help     = `
+Usage: consul acl token rotate [options]
+
+    This command issues a new SecretID for a token, keeping its AccessorID,
+    policies and roles. The current SecretID can still be used for the given
+    grace period, which leaves time to hand out the new SecretID.
+
+    Example:
+
+        $ consul acl token rotate -accessor-id abcd -grace 24h
+`
+)
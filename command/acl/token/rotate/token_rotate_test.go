@@ -0,0 +1,118 @@
+package tokenrotate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestTokenRotateCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestTokenRotateCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+
+	createToken := func(t *testing.T) *api.ACLToken {
+		token, _, err := client.ACL().TokenCreate(
+			&api.ACLToken{Description: "test"},
+			&api.WriteOptions{Token: "root"},
+		)
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("with grace period", func(t *testing.T) {
+		token := createToken(t)
+
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-accessor-id=" + token.AccessorID,
+			"-grace=24h",
+			"-format=json",
+		})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Empty(t, ui.ErrorWriter.String())
+
+		var rotated api.ACLToken
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &rotated))
+		require.Equal(t, token.AccessorID, rotated.AccessorID)
+		require.NotEqual(t, token.SecretID, rotated.SecretID)
+		require.Equal(t, token.SecretID, rotated.PreviousSecretID)
+		require.NotNil(t, rotated.PreviousSecretExpirationTime)
+		require.WithinDuration(t, time.Now().Add(24*time.Hour), *rotated.PreviousSecretExpirationTime, time.Minute)
+
+		// Both secrets can be used during the grace period.
+		for _, secret := range []string{token.SecretID, rotated.SecretID} {
+			self, _, err := client.ACL().TokenReadSelf(&api.QueryOptions{Token: secret})
+			require.NoError(t, err)
+			require.Equal(t, token.AccessorID, self.AccessorID)
+		}
+	})
+
+	t.Run("without grace period", func(t *testing.T) {
+		token := createToken(t)
+
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+			"-accessor-id=" + token.AccessorID,
+		})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Contains(t, ui.OutputWriter.String(), token.AccessorID)
+		require.NotContains(t, ui.OutputWriter.String(), token.SecretID)
+
+		_, _, err := client.ACL().TokenReadSelf(&api.QueryOptions{Token: token.SecretID})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ACL not found")
+	})
+
+	t.Run("missing accessor id", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-token=root",
+		})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "-accessor-id")
+	})
+}
@@ -53,5 +53,17 @@ Usage: consul acl token <subcommand> [options] [args]
 
     $ consul acl token delete -accessor-id 986193
 
+  Issue a new secret for a token, keeping the current one valid for a day:
+
+    $ consul acl token rotate -accessor-id 986193 -grace 24h
+
+  Extend the expiration time of a renewable token:
+
+    $ consul acl token renew -accessor-id 986193
+
+  Explain why a token can or can't write to a service:
+
+    $ consul acl token explain -accessor-id 986193 -resource service:web -access write
+
   For more examples, ask for subcommand help or view the documentation.
 `
@@ -25,22 +25,24 @@ type cmd struct {
 	http  *flags.HTTPFlags
 	help  string
 
-	tokenAccessorID     string
-	policyIDs           []string
-	appendPolicyIDs     []string
-	policyNames         []string
-	appendPolicyNames   []string
-	roleIDs             []string
-	appendRoleIDs       []string
-	roleNames           []string
-	appendRoleNames     []string
-	serviceIdents       []string
-	nodeIdents          []string
-	appendNodeIdents    []string
-	appendServiceIdents []string
-	description         string
-	showMeta            bool
-	format              string
+	tokenAccessorID         string
+	policyIDs               []string
+	appendPolicyIDs         []string
+	policyNames             []string
+	appendPolicyNames       []string
+	roleIDs                 []string
+	appendRoleIDs           []string
+	roleNames               []string
+	appendRoleNames         []string
+	serviceIdents           []string
+	nodeIdents              []string
+	appendNodeIdents        []string
+	appendServiceIdents     []string
+	templatedPolicies       []string
+	appendTemplatedPolicies []string
+	description             string
+	showMeta                bool
+	format                  string
 
 	// DEPRECATED
 	mergeServiceIdents bool
@@ -86,6 +88,12 @@ func (c *cmd) init() {
 	c.flags.Var((*flags.AppendSliceValue)(&c.appendNodeIdents), "append-node-identity", "Name of a "+
 		"node identity to use for this token. This token retains existing node identities. May be "+
 		"specified multiple times. Format is NODENAME:DATACENTER")
+	c.flags.Var((*flags.AppendSliceValue)(&c.templatedPolicies), "templated-policy", "Name of a "+
+		"templated policy to use for this token. Overwrites existing templated policies. May be specified "+
+		"multiple times. Format is NAME or NAME:VAR1=VALUE1,VAR2=VALUE2,...")
+	c.flags.Var((*flags.AppendSliceValue)(&c.appendTemplatedPolicies), "append-templated-policy", "Name of a "+
+		"templated policy to add to this token. The token retains existing templated policies. May be "+
+		"specified multiple times. Format is NAME or NAME:VAR1=VALUE1,VAR2=VALUE2,...")
 	c.flags.StringVar(
 		&c.format,
 		"format",
@@ -192,6 +200,25 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	hasAppendTemplatedFields := len(c.appendTemplatedPolicies) > 0
+	hasTemplatedFields := len(c.templatedPolicies) > 0
+
+	if hasAppendTemplatedFields && hasTemplatedFields {
+		c.UI.Error("Cannot combine the use of templated-policy flag with append-templated-policy. " +
+			"To set or overwrite existing templated policies, use -templated-policy. " +
+			"To append to existing templated policies, use -append-templated-policy.")
+		return 1
+	}
+
+	parsedTemplatedPolicies, err := acl.ExtractTemplatedPolicies(c.templatedPolicies)
+	if hasAppendTemplatedFields {
+		parsedTemplatedPolicies, err = acl.ExtractTemplatedPolicies(c.appendTemplatedPolicies)
+	}
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	if c.mergePolicies {
 		c.UI.Warn("merge-policies is deprecated and will be removed in a future Consul version. " +
 			"Use `append-policy-name` or `append-policy-id` instead.")
@@ -381,6 +408,12 @@ func (c *cmd) Run(args []string) int {
 		t.NodeIdentities = parsedNodeIdents
 	}
 
+	if hasAppendTemplatedFields {
+		t.TemplatedPolicies = append(t.TemplatedPolicies, parsedTemplatedPolicies...)
+	} else if hasTemplatedFields {
+		t.TemplatedPolicies = parsedTemplatedPolicies
+	}
+
 	t, _, err = client.ACL().TokenUpdate(t, nil)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Failed to update token %s: %v", tok, err))
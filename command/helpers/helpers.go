@@ -135,3 +135,41 @@ func newDecodeConfigEntry(raw map[string]interface{}) (api.ConfigEntry, error) {
 
 	return entry, nil
 }
+
+// ParsePreparedQuery decodes a prepared query definition from HCL or JSON.
+func ParsePreparedQuery(data string) (*api.PreparedQueryDefinition, error) {
+	var raw map[string]interface{}
+	if err := hclDecode(&raw, data); err != nil {
+		return nil, fmt.Errorf("Failed to decode prepared query input: %v", err)
+	}
+
+	var query api.PreparedQueryDefinition
+	var md mapstructure.Metadata
+	decodeConf := &mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			decode.HookWeakDecodeFromSlice,
+			decode.HookTranslateKeys,
+		),
+		Metadata:         &md,
+		Result:           &query,
+		WeaklyTypedInput: true,
+	}
+
+	decoder, err := mapstructure.NewDecoder(decodeConf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(raw); err != nil {
+		return nil, err
+	}
+
+	for _, k := range md.Unused {
+		err = multierror.Append(err, fmt.Errorf("invalid prepared query key %q", k))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &query, nil
+}
@@ -3264,6 +3264,94 @@ func TestParseConfigEntry(t *testing.T) {
 	}
 }
 
+func TestParsePreparedQuery(t *testing.T) {
+	t.Parallel()
+
+	expect := &api.PreparedQueryDefinition{
+		Name: "geo-db",
+		Template: api.QueryTemplate{
+			Type:   "name_prefix_match",
+			Regexp: "^geo-db-(.*?)-([^\\-]+?)$",
+		},
+		Service: api.ServiceQuery{
+			Service: "mysql-${match(1)}",
+			Failover: api.QueryFailoverOptions{
+				NearestN: 3,
+				Targets:  []api.QueryFailoverTarget{{Peer: "cluster-01"}},
+			},
+			OnlyPassing: true,
+			Tags:        []string{"${match(2)}"},
+			NodeMeta:    map[string]string{"instance_type": "m3.large"},
+		},
+		DNS: api.QueryDNSOptions{TTL: "10s"},
+	}
+
+	for name, data := range map[string]string{
+		"hcl": `
+			Name = "geo-db"
+			Template {
+				Type = "name_prefix_match"
+				Regexp = "^geo-db-(.*?)-([^\\-]+?)$"
+			}
+			Service {
+				Service = "mysql-${match(1)}"
+				Failover {
+					NearestN = 3
+					Targets = [{ Peer = "cluster-01" }]
+				}
+				OnlyPassing = true
+				Tags = ["${match(2)}"]
+				NodeMeta {
+					instance_type = "m3.large"
+				}
+			}
+			DNS {
+				TTL = "10s"
+			}
+		`,
+		"json": `
+		{
+			"Name": "geo-db",
+			"Template": {
+				"Type": "name_prefix_match",
+				"Regexp": "^geo-db-(.*?)-([^\\-]+?)$"
+			},
+			"Service": {
+				"Service": "mysql-${match(1)}",
+				"Failover": {
+					"NearestN": 3,
+					"Targets": [{ "Peer": "cluster-01" }]
+				},
+				"OnlyPassing": true,
+				"Tags": ["${match(2)}"],
+				"NodeMeta": {"instance_type": "m3.large"}
+			},
+			"DNS": {
+				"TTL": "10s"
+			}
+		}
+		`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParsePreparedQuery(data)
+			require.NoError(t, err)
+			require.Equal(t, expect, got)
+		})
+	}
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := ParsePreparedQuery(`
+			Name = "web"
+			Service {
+				Service = "web"
+				OnlyPasing = true
+			}
+		`)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `invalid prepared query key "Service.OnlyPasing"`)
+	})
+}
+
 func requireContainsLower(t *testing.T, haystack, needle string) {
 	t.Helper()
 	require.Contains(t, strings.ToLower(haystack), strings.ToLower(needle))
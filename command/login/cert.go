@@ -0,0 +1,60 @@
+package login
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/consul/agent/consul/authmethod/certauth"
+)
+
+type CertLogin struct {
+	certFile string
+	keyFile  string
+}
+
+func (c *CertLogin) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.StringVar(&c.certFile, "cert", "",
+		"Path to a PEM encoded client certificate to login to the cert auth method with. "+
+			"Intermediate certificates may follow the client certificate in the same file. "+
+			"Requires -key. [cert only]")
+
+	fs.StringVar(&c.keyFile, "key", "",
+		"Path to the PEM encoded private key of the client certificate. "+
+			"Requires -cert. [cert only]")
+	return fs
+}
+
+// enabled returns whether the flags to login with a client certificate were
+// given.
+func (c *CertLogin) enabled() bool {
+	return c.certFile != "" || c.keyFile != ""
+}
+
+// checkFlags validates flags for the cert auth method.
+func (c *CertLogin) checkFlags() error {
+	if c.certFile != "" && c.keyFile == "" {
+		return fmt.Errorf("Missing '-key' flag")
+	}
+	if c.keyFile != "" && c.certFile == "" {
+		return fmt.Errorf("Missing '-cert' flag")
+	}
+	return nil
+}
+
+// createCertBearerToken generates a bearer token string for the cert auth
+// method. The token is signed with the private key of the client certificate
+// so it can't be created by anyone who only holds the certificate, and it's
+// only valid for the given auth method and for a short time.
+func (c *CertLogin) createCertBearerToken(authMethod string) (string, error) {
+	certPEM, err := os.ReadFile(c.certFile)
+	if err != nil {
+		return "", err
+	}
+	keyPEM, err := os.ReadFile(c.keyFile)
+	if err != nil {
+		return "", err
+	}
+	return certauth.NewBearerToken(authMethod, certPEM, keyPEM)
+}
@@ -36,7 +36,8 @@ type cmd struct {
 	tokenSinkFile   string
 	meta            map[string]string
 
-	aws AWSLogin
+	aws  AWSLogin
+	cert CertLogin
 
 	enterpriseCmd
 }
@@ -63,6 +64,7 @@ func (c *cmd) init() {
 
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.aws.flags())
+	flags.Merge(c.flags, c.cert.flags())
 	flags.Merge(c.flags, c.http.ClientFlags())
 	flags.Merge(c.flags, c.http.ServerFlags())
 	flags.Merge(c.flags, c.http.MultiTenancyFlags())
@@ -96,7 +98,28 @@ func (c *cmd) bearerTokenLogin() int {
 		return 1
 	}
 
-	if c.aws.autoBearerToken {
+	if err := c.cert.checkFlags(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.cert.enabled() {
+		if c.bearerTokenFile != "" {
+			c.UI.Error("Cannot use '-bearer-token-file' flag with '-cert'")
+			return 1
+		}
+		if c.aws.autoBearerToken {
+			c.UI.Error("Cannot use '-aws-auto-bearer-token' flag with '-cert'")
+			return 1
+		}
+
+		if token, err := c.cert.createCertBearerToken(c.authMethodName); err != nil {
+			c.UI.Error(fmt.Sprintf("Error with cert auth method: %s", err))
+			return 1
+		} else {
+			c.bearerToken = token
+		}
+	} else if c.aws.autoBearerToken {
 		if c.bearerTokenFile != "" {
 			c.UI.Error("Cannot use '-bearer-token-file' flag with '-aws-auto-bearer-token'")
 			return 1
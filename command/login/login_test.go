@@ -1,6 +1,8 @@
 package login
 
 import (
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/hashicorp/consul-awsauth/iamauthtest"
 	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/agent/consul/authmethod/certauth"
 	"github.com/hashicorp/consul/agent/consul/authmethod/kubeauth"
 	"github.com/hashicorp/consul/agent/consul/authmethod/testauth"
 	"github.com/hashicorp/consul/api"
@@ -675,6 +678,117 @@ func TestLoginCommand_aws_iam(t *testing.T) {
 	}
 }
 
+func TestLoginCommand_cert(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	testDir := testutil.TempDir(t, "acl")
+
+	a := newTestAgent(t)
+	client := a.Client()
+
+	ca := certauth.NewTestCA(t, "Test Root CA")
+	certPEM, keyPEM, _ := ca.IssueClientCert(t, &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "web-1",
+			OrganizationalUnit: []string{"web"},
+		},
+		DNSNames: []string{"web-1.example.org"},
+	})
+
+	certFile := filepath.Join(testDir, "client.pem")
+	require.NoError(t, os.WriteFile(certFile, []byte(certPEM), 0600))
+	keyFile := filepath.Join(testDir, "client-key.pem")
+	require.NoError(t, os.WriteFile(keyFile, []byte(keyPEM), 0600))
+
+	_, _, err := client.ACL().AuthMethodCreate(
+		&api.ACLAuthMethod{
+			Name: "cert",
+			Type: "cert",
+			Config: map[string]interface{}{
+				"CACerts":        []string{ca.CertPEM},
+				"AllowedDNSSANs": []string{"*.example.org"},
+			},
+		},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	_, _, err = client.ACL().BindingRuleCreate(&api.ACLBindingRule{
+		AuthMethod: "cert",
+		BindType:   api.BindingRuleBindTypeService,
+		BindName:   "${subject.common_name}",
+		Selector:   `"web" in subject.organizational_unit`,
+	},
+		&api.WriteOptions{Token: "root"},
+	)
+	require.NoError(t, err)
+
+	tokenSinkFile := filepath.Join(testDir, "test.token")
+
+	t.Run("key is required", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-method=cert",
+			"-token-sink-file", tokenSinkFile,
+			"-cert", certFile,
+		})
+		require.Equal(t, 1, code, "err: %s", ui.ErrorWriter.String())
+		require.Contains(t, ui.ErrorWriter.String(), "Missing '-key' flag")
+	})
+
+	t.Run("cannot combine with bearer token file", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-method=cert",
+			"-token-sink-file", tokenSinkFile,
+			"-cert", certFile,
+			"-key", keyFile,
+			"-bearer-token-file", certFile,
+		})
+		require.Equal(t, 1, code, "err: %s", ui.ErrorWriter.String())
+		require.Contains(t, ui.ErrorWriter.String(), "Cannot use '-bearer-token-file' flag with '-cert'")
+	})
+
+	t.Run("login", func(t *testing.T) {
+		defer os.Remove(tokenSinkFile)
+
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+
+		code := cmd.Run([]string{
+			"-http-addr=" + a.HTTPAddr(),
+			"-method=cert",
+			"-token-sink-file", tokenSinkFile,
+			"-cert", certFile,
+			"-key", keyFile,
+		})
+		require.Equal(t, 0, code, "err: %s", ui.ErrorWriter.String())
+		require.Empty(t, ui.ErrorWriter.String())
+		require.Empty(t, ui.OutputWriter.String())
+
+		raw, err := os.ReadFile(tokenSinkFile)
+		require.NoError(t, err)
+
+		token := strings.TrimSpace(string(raw))
+		require.Len(t, token, 36, "must be a valid uid: %s", token)
+
+		tokenRead, _, err := client.ACL().TokenReadSelf(&api.QueryOptions{Token: token})
+		require.NoError(t, err)
+		require.Len(t, tokenRead.ServiceIdentities, 1)
+		require.Equal(t, "web-1", tokenRead.ServiceIdentities[0].ServiceName)
+	})
+}
+
 func newTestAgent(t *testing.T) *agent.TestAgent {
 	a := agent.NewTestAgent(t, `
 	primary_datacenter = "dc1"
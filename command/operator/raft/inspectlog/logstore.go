@@ -0,0 +1,138 @@
+package inspectlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	raftwal "github.com/hashicorp/raft-wal"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// lockTimeout is how long to wait for the lock of the log store, it is
+	// held by a running server.
+	lockTimeout = time.Second
+
+	// walMetaDB is the file of the metadata of the WAL, it is locked by the
+	// server using the WAL.
+	walMetaDB = "wal-meta.db"
+)
+
+// logStore is a Raft log store opened for reading.
+type logStore interface {
+	raft.LogStore
+	io.Closer
+}
+
+// openLogStore opens the log store of the Raft state in the data directory
+// without changing it. It picks the backend like the servers do: raft.db is
+// used when it exists, the WAL otherwise. The returned function removes the
+// temporary files created to read the store.
+func openLogStore(dataDir string) (logStore, string, func(), error) {
+	// The Raft state is stored in the "raft" directory of the data directory.
+	path := filepath.Join(dataDir, "raft")
+	noop := func() {}
+
+	boltFile := filepath.Join(path, "raft.db")
+	if _, err := os.Stat(boltFile); err == nil {
+		store, err := raftboltdb.New(raftboltdb.Options{
+			Path: boltFile,
+			BoltOptions: &bbolt.Options{
+				ReadOnly: true,
+				Timeout:  lockTimeout,
+			},
+		})
+		if err != nil {
+			return nil, "", nil, lockError(boltFile, err)
+		}
+		return store, "boltdb", noop, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, "", nil, err
+	}
+
+	walDir := filepath.Join(path, "wal")
+	if _, err := os.Stat(filepath.Join(walDir, walMetaDB)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", nil, fmt.Errorf("no Raft log store found in %s", path)
+		}
+		return nil, "", nil, err
+	}
+
+	// The WAL can't be opened read-only, since it recovers its last segment
+	// when it is opened. It is copied once the lock of its metadata shows the
+	// server is stopped, and the copy is opened instead.
+	meta, err := bbolt.Open(filepath.Join(walDir, walMetaDB), 0, &bbolt.Options{
+		ReadOnly: true,
+		Timeout:  lockTimeout,
+	})
+	if err != nil {
+		return nil, "", nil, lockError(filepath.Join(walDir, walMetaDB), err)
+	}
+	defer meta.Close()
+
+	tmp, err := os.MkdirTemp("", "consul-inspect-log-")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+	if err := copyDir(walDir, tmp); err != nil {
+		cleanup()
+		return nil, "", nil, fmt.Errorf("failed to copy the WAL: %w", err)
+	}
+	wal, err := raftwal.Open(tmp)
+	if err != nil {
+		cleanup()
+		return nil, "", nil, fmt.Errorf("failed to open the WAL: %w", err)
+	}
+	return wal, "wal", cleanup, nil
+}
+
+func lockError(path string, err error) error {
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return fmt.Errorf("%s is locked, the server using it must be stopped", path)
+	}
+	return fmt.Errorf("failed to open %s: %w", path, err)
+}
+
+// copyDir copies the regular files of the source directory to the
+// destination directory.
+func copyDir(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
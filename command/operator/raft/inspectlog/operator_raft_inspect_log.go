@@ -0,0 +1,287 @@
+package inspectlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/agent/consul/fsm"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/command/flags"
+)
+
+const (
+	PrettyFormat string = "pretty"
+	JSONFormat   string = "json"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	help  string
+
+	// flags
+	dataDir  string
+	minIndex uint64
+	maxIndex uint64
+	types    flags.AppendSliceValue
+	decode   bool
+	format   string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.dataDir, "data-dir", "",
+		"The data directory of the stopped server. This is required.")
+	c.flags.Uint64Var(&c.minIndex, "min-index", 0,
+		"Only list the entries with this index or a higher one.")
+	c.flags.Uint64Var(&c.maxIndex, "max-index", 0,
+		"Only list the entries with this index or a lower one.")
+	c.flags.Var(&c.types, "type",
+		"Only list the entries of this type, such as \"Register\", \"KVS\" or "+
+			"\"Configuration\". May be specified multiple times.")
+	c.flags.BoolVar(&c.decode, "decode", false,
+		"Decode the payload of the entries applied to the state store.")
+	c.flags.StringVar(&c.format, "format", PrettyFormat,
+		fmt.Sprintf("Output format {%s}", strings.Join([]string{PrettyFormat, JSONFormat}, "|")))
+
+	c.help = flags.Usage(help, c.flags)
+}
+
+// Entry is a Raft log entry.
+type Entry struct {
+	Index      uint64
+	Term       uint64
+	Type       string
+	Size       int
+	AppendedAt time.Time `json:",omitempty"`
+
+	// Payload is the decoded request of the entries applied to the state
+	// store, when -decode is set.
+	Payload     interface{} `json:",omitempty"`
+	DecodeError string      `json:",omitempty"`
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		c.UI.Error(fmt.Sprintf("Failed to parse args: %v", err))
+		return 1
+	}
+	if len(c.flags.Args()) > 0 {
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 0, got %d)", len(c.flags.Args())))
+		return 1
+	}
+	if c.dataDir == "" {
+		c.UI.Error("Missing the -data-dir flag")
+		return 1
+	}
+	if c.maxIndex != 0 && c.maxIndex < c.minIndex {
+		c.UI.Error("The -max-index flag must not be lower than -min-index")
+		return 1
+	}
+	if c.format != PrettyFormat && c.format != JSONFormat {
+		c.UI.Error(fmt.Sprintf("Unknown format: %s", c.format))
+		return 1
+	}
+	types := make(map[string]struct{})
+	for _, typ := range c.types {
+		types[strings.ToLower(typ)] = struct{}{}
+	}
+
+	store, backend, cleanup, err := openLogStore(c.dataDir)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error opening the Raft log: %s", err))
+		return 1
+	}
+	defer cleanup()
+	defer store.Close()
+
+	entries, err := c.readEntries(store, types)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading the Raft log: %s", err))
+		return 1
+	}
+
+	if c.format == JSONFormat {
+		if entries == nil {
+			entries = []Entry{}
+		}
+		out, err := json.MarshalIndent(entries, "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error encoding the entries: %s", err))
+			return 1
+		}
+		c.UI.Output(string(out))
+		return 0
+	}
+
+	c.UI.Info(fmt.Sprintf("Reading the %s log store, %d matching entries", backend, len(entries)))
+	if len(entries) > 0 {
+		out, err := formatEntries(entries, c.decode)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+		c.UI.Output(out)
+	}
+	return 0
+}
+
+// readEntries returns the entries of the store within the index range and
+// matching the types.
+func (c *cmd) readEntries(store raft.LogStore, types map[string]struct{}) ([]Entry, error) {
+	first, err := store.FirstIndex()
+	if err != nil {
+		return nil, err
+	}
+	last, err := store.LastIndex()
+	if err != nil {
+		return nil, err
+	}
+	if last == 0 {
+		return nil, nil
+	}
+	if c.minIndex > first {
+		first = c.minIndex
+	}
+	if c.maxIndex != 0 && c.maxIndex < last {
+		last = c.maxIndex
+	}
+
+	var entries []Entry
+	for index := first; index <= last; index++ {
+		var log raft.Log
+		if err := store.GetLog(index, &log); err != nil {
+			if errors.Is(err, raft.ErrLogNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read entry %d: %w", index, err)
+		}
+
+		entry := Entry{
+			Index:      log.Index,
+			Term:       log.Term,
+			Size:       len(log.Data),
+			AppendedAt: log.AppendedAt,
+		}
+		msg, isCommand := entryType(&log, &entry)
+		if len(types) > 0 {
+			if _, ok := types[strings.ToLower(entry.Type)]; !ok {
+				continue
+			}
+		}
+		if c.decode && isCommand {
+			payload, err := fsm.DecodeCommand(msg, log.Data[1:])
+			if err != nil {
+				entry.DecodeError = err.Error()
+			} else {
+				entry.Payload = payload
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// entryType sets the type of the entry, it returns the message type of the
+// entries applied to the state store.
+func entryType(log *raft.Log, entry *Entry) (structs.MessageType, bool) {
+	switch {
+	case log.Type != raft.LogCommand:
+		entry.Type = strings.TrimPrefix(log.Type.String(), "Log")
+		return 0, false
+	case len(log.Extensions) > 0:
+		// Large commands are split in chunks, applied once they are all
+		// received.
+		entry.Type = "Chunk"
+		return 0, false
+	case len(log.Data) == 0:
+		entry.Type = "Empty"
+		return 0, false
+	}
+
+	msg := structs.MessageType(log.Data[0]) &^ structs.IgnoreUnknownTypeFlag
+	entry.Type = msg.String()
+	return msg, true
+}
+
+func formatEntries(entries []Entry, decode bool) (string, error) {
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+
+	header := "Index\tTerm\tType\tSize\tAppended At"
+	if decode {
+		header += "\tPayload"
+	}
+	fmt.Fprintln(tw, header)
+
+	for _, entry := range entries {
+		appended := "-"
+		if !entry.AppendedAt.IsZero() {
+			appended = entry.AppendedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%d\t%s", entry.Index, entry.Term, entry.Type, entry.Size, appended)
+		if decode {
+			switch {
+			case entry.DecodeError != "":
+				fmt.Fprintf(tw, "\terror: %s", entry.DecodeError)
+			case entry.Payload != nil:
+				payload, err := json.Marshal(entry.Payload)
+				if err != nil {
+					return "", fmt.Errorf("Error encoding the payload of entry %d: %s", entry.Index, err)
+				}
+				fmt.Fprintf(tw, "\t%s", payload)
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+const synopsis = "Lists the entries of the Raft log of a stopped server"
+const help = `
+Usage: consul operator raft inspect-log -data-dir=<path> [options]
+
+  Lists the entries of the Raft log stored in the data directory of a stopped
+  server, with their index, term, type and size. The log store is opened
+  without being changed, with the backend the server uses: raft.db when it
+  exists, the WAL otherwise.
+
+  The payload of the entries applied to the state store can be decoded with
+  -decode, which can help to find a bad write. Decoded payloads may hold
+  secrets, such as ACL token secrets.
+
+  To list the KV writes between the indexes 100 and 200, with their payload:
+
+    $ consul operator raft inspect-log -data-dir=/opt/consul \
+        -min-index=100 -max-index=200 -type=KVS -decode
+`
@@ -0,0 +1,180 @@
+package inspectlog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	raftwal "github.com/hashicorp/raft-wal"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+)
+
+func TestOperatorRaftInspectLogCommand_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestOperatorRaftInspectLogCommand_Validation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args   []string
+		output string
+	}{
+		"no data dir": {
+			[]string{},
+			"Missing the -data-dir flag",
+		},
+		"extra args": {
+			[]string{"-data-dir=/tmp", "foo"},
+			"Too many arguments (expected 0, got 1)",
+		},
+		"bad range": {
+			[]string{"-data-dir=/tmp", "-min-index=10", "-max-index=5"},
+			"The -max-index flag must not be lower than -min-index",
+		},
+		"bad format": {
+			[]string{"-data-dir=/tmp", "-format=yaml"},
+			"Unknown format: yaml",
+		},
+		"no log store": {
+			[]string{"-data-dir=" + t.TempDir()},
+			"no Raft log store found in",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			code := New(ui).Run(tc.args)
+			require.Equal(t, 1, code)
+			require.Contains(t, ui.ErrorWriter.String(), tc.output)
+		})
+	}
+}
+
+// testLogs returns log entries of the different types.
+func testLogs(t *testing.T) []*raft.Log {
+	kvs, err := structs.Encode(structs.KVSRequestType, &structs.KVSRequest{
+		Datacenter: "dc1",
+		Op:         api.KVSet,
+		DirEnt:     structs.DirEntry{Key: "web/config", Value: []byte("hello")},
+	})
+	require.NoError(t, err)
+	register, err := structs.Encode(structs.RegisterRequestType, &structs.RegisterRequest{
+		Datacenter: "dc1",
+		Node:       "node1",
+		Address:    "127.0.0.1",
+	})
+	require.NoError(t, err)
+
+	appendedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	return []*raft.Log{
+		{Index: 1, Term: 1, Type: raft.LogConfiguration, Data: []byte{0}, AppendedAt: appendedAt},
+		{Index: 2, Term: 2, Type: raft.LogNoop, AppendedAt: appendedAt},
+		{Index: 3, Term: 2, Type: raft.LogCommand, Data: register, AppendedAt: appendedAt},
+		{Index: 4, Term: 2, Type: raft.LogCommand, Data: kvs, AppendedAt: appendedAt},
+		{Index: 5, Term: 2, Type: raft.LogCommand, Data: []byte{byte(structs.MessageType(100) | structs.IgnoreUnknownTypeFlag)}, AppendedAt: appendedAt},
+	}
+}
+
+func TestOperatorRaftInspectLogCommand(t *testing.T) {
+	t.Parallel()
+
+	newBoltDB := func(t *testing.T, dir string) raft.LogStore {
+		store, err := raftboltdb.New(raftboltdb.Options{Path: filepath.Join(dir, "raft.db")})
+		require.NoError(t, err)
+		return store
+	}
+	newWAL := func(t *testing.T, dir string) raft.LogStore {
+		walDir := filepath.Join(dir, "wal")
+		require.NoError(t, os.MkdirAll(walDir, 0755))
+		store, err := raftwal.Open(walDir)
+		require.NoError(t, err)
+		return store
+	}
+
+	for _, tc := range []struct {
+		backend string
+		open    func(t *testing.T, dir string) raft.LogStore
+	}{
+		{"boltdb", newBoltDB},
+		{"wal", newWAL},
+	} {
+		tc := tc
+		t.Run(tc.backend, func(t *testing.T) {
+			dataDir := t.TempDir()
+			raftDir := filepath.Join(dataDir, "raft")
+			require.NoError(t, os.MkdirAll(raftDir, 0755))
+
+			store := tc.open(t, raftDir)
+			require.NoError(t, store.StoreLogs(testLogs(t)))
+
+			// The store is locked while the server is running.
+			ui := cli.NewMockUi()
+			code := New(ui).Run([]string{"-data-dir=" + dataDir})
+			require.Equal(t, 1, code)
+			require.Contains(t, ui.ErrorWriter.String(), "the server using it must be stopped")
+
+			require.NoError(t, store.(interface{ Close() error }).Close())
+
+			// The WAL doesn't release the lock of its metadata when it is
+			// closed, the stopped server is simulated with a copy.
+			stoppedDir := t.TempDir()
+			stoppedRaftDir := filepath.Join(stoppedDir, "raft")
+			require.NoError(t, os.MkdirAll(stoppedRaftDir, 0755))
+			if tc.backend == "wal" {
+				require.NoError(t, os.Mkdir(filepath.Join(stoppedRaftDir, "wal"), 0755))
+				require.NoError(t, copyDir(filepath.Join(raftDir, "wal"), filepath.Join(stoppedRaftDir, "wal")))
+			} else {
+				require.NoError(t, copyDir(raftDir, stoppedRaftDir))
+			}
+			dataDir, raftDir = stoppedDir, stoppedRaftDir
+
+			ui = cli.NewMockUi()
+			code = New(ui).Run([]string{"-data-dir=" + dataDir})
+			require.Equal(t, 0, code, ui.ErrorWriter.String())
+			output := ui.OutputWriter.String()
+			require.Contains(t, output, "Reading the "+tc.backend+" log store, 5 matching entries")
+			require.Contains(t, output, "1      1     Configuration")
+			require.Contains(t, output, "2      2     Noop")
+			require.Contains(t, output, "3      2     Register")
+			require.Contains(t, output, "4      2     KVS")
+			require.Contains(t, output, "5      2     Unknown(100)")
+			require.Contains(t, output, "2026-10-18T12:00:00Z")
+
+			// Filter and decode the entries.
+			ui = cli.NewMockUi()
+			code = New(ui).Run([]string{"-data-dir=" + dataDir, "-format=json", "-decode",
+				"-min-index=2", "-max-index=5", "-type=kvs", "-type=Unknown(100)"})
+			require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+			var entries []map[string]interface{}
+			require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &entries))
+			require.Len(t, entries, 2)
+			require.Equal(t, float64(4), entries[0]["Index"])
+			require.Equal(t, "KVS", entries[0]["Type"])
+			payload := entries[0]["Payload"].(map[string]interface{})
+			require.Equal(t, "set", payload["Op"])
+			require.Equal(t, "web/config", payload["DirEnt"].(map[string]interface{})["Key"])
+			require.Equal(t, "Unknown(100)", entries[1]["Type"])
+			require.Equal(t, "unknown message type Unknown(100)", entries[1]["DecodeError"])
+
+			// The data directory is unchanged.
+			files, err := os.ReadDir(raftDir)
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+		})
+	}
+}
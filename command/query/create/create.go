@@ -0,0 +1,113 @@
+package create
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/helpers"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	testStdin io.Reader
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("Must provide exactly one positional argument to specify the prepared query definition")
+		return 1
+	}
+
+	data, err := helpers.LoadDataSourceNoRaw(args[0], c.testStdin)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to load data: %v", err))
+		return 1
+	}
+
+	query, err := helpers.ParsePreparedQuery(data)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if query.ID != "" {
+		c.UI.Error("Prepared query definitions for create must not contain an ID, use 'consul query update' instead")
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	id, _, err := client.PreparedQuery().Create(query, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error creating prepared query: %v", err))
+		return 1
+	}
+
+	c.UI.Info(id)
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Create a prepared query"
+	help     = `
+Usage: consul query create [options] <file or ->
+
+  Creates a prepared query from a definition in HCL or JSON format and
+  prints the ID of the new query. The definition uses the same fields as
+  the /v1/query HTTP API. Pass '-' to read the definition from stdin.
+
+  Example:
+
+    $ cat web-query.hcl
+    Name = "web"
+    Service {
+      Service     = "web"
+      OnlyPassing = true
+      Failover {
+        NearestN = 2
+      }
+    }
+
+    $ consul query create web-query.hcl
+`
+)
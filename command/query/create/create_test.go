@@ -0,0 +1,93 @@
+package create
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestCreateCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestCreateCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	client := a.Client()
+
+	t.Run("from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "query.hcl")
+		require.NoError(t, os.WriteFile(path, []byte(`
+			Name = "web"
+			Service {
+				Service = "web"
+				OnlyPassing = true
+				Failover {
+					NearestN = 2
+				}
+			}
+		`), 0600))
+
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		code := cmd.Run([]string{"-http-addr=" + a.HTTPAddr(), path})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		id := strings.TrimSpace(ui.OutputWriter.String())
+		queries, _, err := client.PreparedQuery().Get(id, nil)
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+		require.Equal(t, "web", queries[0].Name)
+		require.True(t, queries[0].Service.OnlyPassing)
+		require.Equal(t, 2, queries[0].Service.Failover.NearestN)
+	})
+
+	t.Run("from stdin", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`{"Name": "db", "Service": {"Service": "db"}}`)
+		code := cmd.Run([]string{"-http-addr=" + a.HTTPAddr(), "-"})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		queries, _, err := client.PreparedQuery().Get(strings.TrimSpace(ui.OutputWriter.String()), nil)
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+		require.Equal(t, "db", queries[0].Service.Service)
+	})
+
+	t.Run("invalid definition", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`{"Name": "db", "Servce": {"Service": "db"}}`)
+		code := cmd.Run([]string{"-http-addr=" + a.HTTPAddr(), "-"})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), `invalid prepared query key "Servce"`)
+	})
+
+	t.Run("ID not allowed", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`{"ID": "8f246b77-f3e1-ff88-5b48-8ec93abf3e05", "Service": {"Service": "db"}}`)
+		code := cmd.Run([]string{"-http-addr=" + a.HTTPAddr(), "-"})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "must not contain an ID")
+	})
+}
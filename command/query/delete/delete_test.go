@@ -0,0 +1,58 @@
+package delete
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestDeleteCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestDeleteCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	client := a.Client()
+
+	t.Run("id is required", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr()})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "Must provide exactly one positional argument")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		id, _, err := client.PreparedQuery().Create(&api.PreparedQueryDefinition{
+			Name:    "web",
+			Service: api.ServiceQuery{Service: "web"},
+		}, nil)
+		require.NoError(t, err)
+
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), id})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Contains(t, ui.OutputWriter.String(), "Prepared query deleted: "+id)
+
+		queries, _, err := client.PreparedQuery().List(nil)
+		require.NoError(t, err)
+		require.Empty(t, queries)
+	})
+}
@@ -0,0 +1,173 @@
+package execute
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/ryanuber/columnize"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/query"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	near    string
+	limit   int
+	connect bool
+	format  string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.near, "near", "",
+		"Node name to sort the results near, by estimated round trip time. "+
+			"Use \"_agent\" to sort near the agent servicing the request. "+
+			"Overrides the Near field of the query.")
+	c.flags.IntVar(&c.limit, "limit", 0,
+		"Maximum number of results to return. The results are limited by the "+
+			"servers after sorting, so combined with -near this returns the "+
+			"closest results. "+
+			"The default of 0 returns all results.")
+	c.flags.BoolVar(&c.connect, "connect", false,
+		"Only return Connect-capable services: native services and proxies "+
+			"for the queried service.")
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		query.FormatPretty,
+		fmt.Sprintf("Output format {%s}", strings.Join(query.GetSupportedFormats(), "|")),
+	)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("Must provide exactly one positional argument to specify the prepared query ID or name")
+		return 1
+	}
+	idOrName := args[0]
+
+	if c.limit < 0 {
+		c.UI.Error("The -limit flag must not be negative")
+		return 1
+	}
+
+	if !query.FormatIsValid(c.format) {
+		c.UI.Error(fmt.Sprintf("Invalid format, valid formats are {%s}", strings.Join(query.GetSupportedFormats(), "|")))
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	resp, _, err := client.PreparedQuery().Execute(idOrName, &api.QueryOptions{
+		Near:    c.near,
+		Connect: c.connect,
+		Limit:   c.limit,
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error executing prepared query %q: %v", idOrName, err))
+		return 1
+	}
+
+	if c.format == query.FormatJSON {
+		b, err := json.MarshalIndent(resp, "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error marshalling JSON: %s", err))
+			return 1
+		}
+		c.UI.Output(string(b))
+		return 0
+	}
+
+	c.UI.Output(formatResponse(resp))
+	return 0
+}
+
+func formatResponse(resp *api.PreparedQueryExecuteResponse) string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(fmt.Sprintf("Service:     %s\n", resp.Service))
+	buffer.WriteString(fmt.Sprintf("Datacenter:  %s\n", resp.Datacenter))
+	buffer.WriteString(fmt.Sprintf("Failovers:   %d\n", resp.Failovers))
+	if len(resp.Sources) > 0 {
+		sources := make([]string, 0, len(resp.Sources))
+		for _, src := range resp.Sources {
+			name := "dc:" + src.Datacenter
+			if src.PeerName != "" {
+				name = "peer:" + src.PeerName
+			}
+			sources = append(sources, fmt.Sprintf("%s (%d)", name, src.Nodes))
+		}
+		buffer.WriteString(fmt.Sprintf("Sources:     %s\n", strings.Join(sources, ", ")))
+	}
+	buffer.WriteString("\n")
+
+	if len(resp.Nodes) == 0 {
+		buffer.WriteString("No healthy instances found.")
+		return buffer.String()
+	}
+
+	result := make([]string, 0, len(resp.Nodes)+1)
+	result = append(result, "Node\x1fAddress\x1fPort\x1fTags")
+	for _, n := range resp.Nodes {
+		addr := n.Service.Address
+		if addr == "" {
+			addr = n.Node.Address
+		}
+		result = append(result, fmt.Sprintf("%s\x1f%s\x1f%d\x1f%s",
+			n.Node.Node, addr, n.Service.Port, strings.Join(n.Service.Tags, ",")))
+	}
+	buffer.WriteString(columnize.Format(result, &columnize.Config{Delim: string([]byte{0x1f})}))
+	return buffer.String()
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Execute a prepared query"
+	help     = `
+Usage: consul query execute [options] <query ID or name>
+
+  Executes a prepared query by ID or name and prints the healthy service
+  instances it returns, along with the datacenter they came from.
+
+  Return the three instances closest to the agent:
+
+    $ consul query execute -near _agent -limit 3 web
+`
+)
@@ -0,0 +1,87 @@
+package execute
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestExecuteCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestExecuteCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	client := a.Client()
+
+	for i := 1; i <= 3; i++ {
+		_, err := client.Catalog().Register(&api.CatalogRegistration{
+			Node:    fmt.Sprintf("node%d", i),
+			Address: fmt.Sprintf("10.0.0.%d", i),
+			Service: &api.AgentService{
+				Service: "web",
+				Port:    8080,
+				Tags:    []string{"v1"},
+			},
+		}, nil)
+		require.NoError(t, err)
+	}
+
+	_, _, err := client.PreparedQuery().Create(&api.PreparedQueryDefinition{
+		Name:    "web",
+		Service: api.ServiceQuery{Service: "web"},
+	}, nil)
+	require.NoError(t, err)
+
+	t.Run("pretty", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "web"})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		output := ui.OutputWriter.String()
+		require.Contains(t, output, "Service:     web")
+		require.Contains(t, output, "Datacenter:  dc1")
+		require.Contains(t, output, "Failovers:   0")
+		for i := 1; i <= 3; i++ {
+			require.Regexp(t, fmt.Sprintf(`node%d\s+10\.0\.0\.%d\s+8080\s+v1`, i, i), output)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-format=json", "-near=_agent", "-limit=2", "web"})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		var resp api.PreparedQueryExecuteResponse
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &resp))
+		require.Equal(t, "web", resp.Service)
+		require.Len(t, resp.Nodes, 2)
+	})
+
+	t.Run("unknown query", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "nope"})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "Error executing prepared query")
+	})
+}
@@ -0,0 +1,110 @@
+package explain
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/query"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	format string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		query.FormatPretty,
+		fmt.Sprintf("Output format {%s}", strings.Join(query.GetSupportedFormats(), "|")),
+	)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("Must provide exactly one positional argument to specify the prepared query ID or name")
+		return 1
+	}
+	idOrName := args[0]
+
+	if !query.FormatIsValid(c.format) {
+		c.UI.Error(fmt.Sprintf("Invalid format, valid formats are {%s}", strings.Join(query.GetSupportedFormats(), "|")))
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	resp, _, err := client.PreparedQuery().Explain(idOrName, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error explaining prepared query %q: %v", idOrName, err))
+		return 1
+	}
+
+	if c.format == query.FormatJSON {
+		b, err := json.MarshalIndent(query.RedactToken(&resp.Query), "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error marshalling JSON: %s", err))
+			return 1
+		}
+		c.UI.Output(string(b))
+		return 0
+	}
+
+	c.UI.Output(query.FormatQuery(&resp.Query))
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Explain how a name resolves to a prepared query"
+	help     = `
+Usage: consul query explain [options] <query ID or name>
+
+  Shows the prepared query that an ID or name resolves to, without executing
+  it. For template queries, the output is the query after the name has been
+  matched against the template and interpolated, which is the query that
+  'consul query execute' would run.
+
+  Example:
+
+    $ consul query explain geo-db-customer-primary
+`
+)
@@ -0,0 +1,77 @@
+package explain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestExplainCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestExplainCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	id, _, err := a.Client().PreparedQuery().Create(&api.PreparedQueryDefinition{
+		Name: "geo-db",
+		Template: api.QueryTemplate{
+			Type:   "name_prefix_match",
+			Regexp: "^geo-db-(.*?)-([^\\-]+?)$",
+		},
+		Service: api.ServiceQuery{
+			Service: "mysql-${match(1)}",
+			Tags:    []string{"${match(2)}"},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	t.Run("pretty", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "geo-db-customer-primary"})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		output := ui.OutputWriter.String()
+		require.Regexp(t, `ID:\s+`+id, output)
+		require.Regexp(t, `Template Type:\s+name_prefix_match`, output)
+		require.Regexp(t, `Service:\s+mysql-customer`, output)
+		require.Regexp(t, `Tags:\s+primary`, output)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-format=json", "geo-db-customer-primary"})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		var query api.PreparedQueryDefinition
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &query))
+		require.Equal(t, "mysql-customer", query.Service.Service)
+		require.Equal(t, []string{"primary"}, query.Service.Tags)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "web"})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "Error explaining prepared query")
+	})
+}
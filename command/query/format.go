@@ -0,0 +1,94 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul/agent/structs/aclfilter"
+	"github.com/hashicorp/consul/api"
+)
+
+// RedactToken returns a copy of the query with its token hidden, as the
+// servers do for the clients which are not allowed to read it.
+func RedactToken(q *api.PreparedQueryDefinition) *api.PreparedQueryDefinition {
+	if q.Token == "" || q.Token == aclfilter.RedactedToken {
+		return q
+	}
+	clone := *q
+	clone.Token = aclfilter.RedactedToken
+	return &clone
+}
+
+// FormatQuery returns a human readable representation of a prepared query
+// definition. Empty fields are omitted and the token is redacted.
+func FormatQuery(q *api.PreparedQueryDefinition) string {
+	var buffer bytes.Buffer
+	q = RedactToken(q)
+
+	writeField := func(name, value string) {
+		if value != "" {
+			buffer.WriteString(fmt.Sprintf("%-20s%s\n", name+":", value))
+		}
+	}
+
+	writeField("ID", q.ID)
+	writeField("Name", q.Name)
+	writeField("Session", q.Session)
+	writeField("Token", q.Token)
+	if q.Template.Type != "" {
+		writeField("Template Type", q.Template.Type)
+		writeField("Template Regexp", q.Template.Regexp)
+		if q.Template.RemoveEmptyTags {
+			writeField("Remove Empty Tags", "true")
+		}
+	}
+
+	s := q.Service
+	writeField("Service", s.Service)
+	writeField("Namespace", s.Namespace)
+	writeField("Near", s.Near)
+	writeField("Only Passing", fmt.Sprintf("%t", s.OnlyPassing))
+	if s.Connect {
+		writeField("Connect", "true")
+	}
+	writeField("Tags", strings.Join(s.Tags, ", "))
+	writeField("Node Meta", formatMeta(s.NodeMeta))
+	writeField("Service Meta", formatMeta(s.ServiceMeta))
+	writeField("Filter", s.Filter)
+	writeField("Ignore Check IDs", strings.Join(s.IgnoreCheckIDs, ", "))
+	if s.Failover.NearestN > 0 {
+		writeField("Failover NearestN", fmt.Sprintf("%d", s.Failover.NearestN))
+	}
+	writeField("Failover DCs", strings.Join(s.Failover.Datacenters, ", "))
+	if len(s.Failover.Targets) > 0 {
+		targets := make([]string, 0, len(s.Failover.Targets))
+		for _, t := range s.Failover.Targets {
+			if t.Peer != "" {
+				targets = append(targets, "peer:"+t.Peer)
+			} else {
+				targets = append(targets, "dc:"+t.Datacenter)
+			}
+		}
+		writeField("Failover Targets", strings.Join(targets, ", "))
+	}
+	if s.Failover.MinHealthy > 0 {
+		writeField("Min Healthy", fmt.Sprintf("%d", s.Failover.MinHealthy))
+	}
+	if s.Failover.MinHealthyPercent > 0 {
+		writeField("Min Healthy %", fmt.Sprintf("%d", s.Failover.MinHealthyPercent))
+	}
+	writeField("DNS TTL", q.DNS.TTL)
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func formatMeta(meta map[string]string) string {
+	pairs := make([]string, 0, len(meta))
+	for k, v := range meta {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,133 @@
+package list
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/ryanuber/columnize"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/query"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	format string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		query.FormatPretty,
+		fmt.Sprintf("Output format {%s}", strings.Join(query.GetSupportedFormats(), "|")),
+	)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if !query.FormatIsValid(c.format) {
+		c.UI.Error(fmt.Sprintf("Invalid format, valid formats are {%s}", strings.Join(query.GetSupportedFormats(), "|")))
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	queries, _, err := client.PreparedQuery().List(nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error listing prepared queries: %v", err))
+		return 1
+	}
+
+	list := make(queryList, 0, len(queries))
+	for _, q := range queries {
+		list = append(list, query.RedactToken(q))
+	}
+	sort.Sort(list)
+
+	if c.format == query.FormatJSON {
+		b, err := json.MarshalIndent(list, "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error marshalling JSON: %s", err))
+			return 1
+		}
+		c.UI.Output(string(b))
+		return 0
+	}
+
+	if len(list) == 0 {
+		c.UI.Info("There are no prepared queries.")
+		return 0
+	}
+
+	result := make([]string, 0, len(list)+1)
+	result = append(result, "ID\x1fName\x1fService\x1fTemplate")
+	for _, q := range list {
+		result = append(result, fmt.Sprintf("%s\x1f%s\x1f%s\x1f%s", q.ID, q.Name, q.Service.Service, q.Template.Type))
+	}
+	c.UI.Output(columnize.Format(result, &columnize.Config{Delim: string([]byte{0x1f})}))
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "List prepared queries"
+	help     = `
+Usage: consul query list [options]
+
+  Lists all prepared queries, sorted by name. The results are filtered
+  according to ACL policy configuration.
+
+  Example:
+
+    $ consul query list
+`
+)
+
+// queryList applies sort.Interface to a list of prepared queries for sorting
+// by name, then ID.
+type queryList []*api.PreparedQueryDefinition
+
+func (l queryList) Len() int { return len(l) }
+func (l queryList) Less(i, j int) bool {
+	if l[i].Name != l[j].Name {
+		return l[i].Name < l[j].Name
+	}
+	return l[i].ID < l[j].ID
+}
+func (l queryList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
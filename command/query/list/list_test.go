@@ -0,0 +1,78 @@
+package list
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestListCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestListCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	client := a.Client()
+
+	t.Run("no queries", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr()})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Contains(t, ui.OutputWriter.String(), "There are no prepared queries.")
+	})
+
+	for _, def := range []*api.PreparedQueryDefinition{
+		{Name: "web", Service: api.ServiceQuery{Service: "web"}},
+		{
+			Name:     "geo-db",
+			Service:  api.ServiceQuery{Service: "${match(1)}"},
+			Template: api.QueryTemplate{Type: "name_prefix_match"},
+		},
+	} {
+		_, _, err := client.PreparedQuery().Create(def, nil)
+		require.NoError(t, err)
+	}
+
+	t.Run("pretty", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr()})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		lines := strings.Split(strings.TrimSpace(ui.OutputWriter.String()), "\n")
+		require.Len(t, lines, 3)
+		require.Regexp(t, `^ID\s+Name\s+Service\s+Template$`, lines[0])
+		require.Regexp(t, `geo-db\s+\$\{match\(1\)\}\s+name_prefix_match$`, lines[1])
+		require.Regexp(t, `web\s+web\s*$`, lines[2])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-format=json"})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		var queries []*api.PreparedQueryDefinition
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &queries))
+		require.Len(t, queries, 2)
+		require.Equal(t, "geo-db", queries[0].Name)
+		require.Equal(t, "web", queries[1].Name)
+	})
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/command/flags"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatPretty = "pretty"
+)
+
+func GetSupportedFormats() []string {
+	return []string{FormatPretty, FormatJSON}
+}
+
+func FormatIsValid(f string) bool {
+	return f == FormatPretty || f == FormatJSON
+}
+
+func New() *cmd {
+	return &cmd{}
+}
+
+type cmd struct{}
+
+func (c *cmd) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(help, nil)
+}
+
+const synopsis = "Create, manage and execute prepared queries"
+const help = `
+Usage: consul query <subcommand> [options] [args]
+
+  This command has subcommands for interacting with prepared queries.
+  Prepared query definitions are read from HCL or JSON files using the same
+  fields as the /v1/query HTTP API. Here are some simple examples, and more
+  detailed examples are available in the subcommands or the documentation.
+
+  Create a prepared query:
+
+    $ consul query create web-query.hcl
+
+  List all prepared queries:
+
+    $ consul query list
+
+  Read a prepared query:
+
+    $ consul query read 8f246b77-f3e1-ff88-5b48-8ec93abf3e05
+
+  Update a prepared query:
+
+    $ consul query update -id 8f246b77-f3e1-ff88-5b48-8ec93abf3e05 web-query.hcl
+
+  Delete a prepared query:
+
+    $ consul query delete 8f246b77-f3e1-ff88-5b48-8ec93abf3e05
+
+  Execute a prepared query by ID or name:
+
+    $ consul query execute -near _agent -limit 3 web
+
+  Show how a name resolves to a prepared query template:
+
+    $ consul query explain geo-db-customer-primary
+
+  For more examples, ask for subcommand help or view the documentation.
+`
@@ -0,0 +1,112 @@
+package read
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/query"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	format string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		query.FormatPretty,
+		fmt.Sprintf("Output format {%s}", strings.Join(query.GetSupportedFormats(), "|")),
+	)
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("Must provide exactly one positional argument to specify the prepared query ID")
+		return 1
+	}
+	id := args[0]
+
+	if !query.FormatIsValid(c.format) {
+		c.UI.Error(fmt.Sprintf("Invalid format, valid formats are {%s}", strings.Join(query.GetSupportedFormats(), "|")))
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	queries, _, err := client.PreparedQuery().Get(id, nil)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading prepared query %q: %v", id, err))
+		return 1
+	}
+	if len(queries) != 1 {
+		c.UI.Error(fmt.Sprintf("Prepared query %q not found", id))
+		return 1
+	}
+
+	if c.format == query.FormatJSON {
+		b, err := json.MarshalIndent(query.RedactToken(queries[0]), "", "    ")
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error marshalling JSON: %s", err))
+			return 1
+		}
+		c.UI.Output(string(b))
+		return 0
+	}
+
+	c.UI.Output(query.FormatQuery(queries[0]))
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Read a prepared query"
+	help     = `
+Usage: consul query read [options] <query ID>
+
+  Reads the definition of a prepared query. Use -format=json to output a
+  definition that can be edited and passed to 'consul query update'.
+
+  Example:
+
+    $ consul query read 8f246b77-f3e1-ff88-5b48-8ec93abf3e05
+`
+)
@@ -0,0 +1,85 @@
+package read
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestReadCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestReadCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+
+	def := &api.PreparedQueryDefinition{
+		Name:  "web",
+		Token: "0f6b6f8f-dc6e-4b37-9a1f-5b4e0d3b1f6e",
+		Service: api.ServiceQuery{
+			Service:  "web",
+			Tags:     []string{"primary", "!canary"},
+			NodeMeta: map[string]string{"rack": "a1"},
+			Failover: api.QueryFailoverOptions{
+				Targets: []api.QueryFailoverTarget{{Datacenter: "dc2"}, {Peer: "cluster-01"}},
+			},
+		},
+		DNS: api.QueryDNSOptions{TTL: "10s"},
+	}
+	id, _, err := a.Client().PreparedQuery().Create(def, nil)
+	require.NoError(t, err)
+
+	t.Run("pretty", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), id})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		output := ui.OutputWriter.String()
+		require.Regexp(t, `ID:\s+`+id, output)
+		require.Regexp(t, `Tags:\s+primary, !canary`, output)
+		require.Regexp(t, `Node Meta:\s+rack=a1`, output)
+		require.Regexp(t, `Failover Targets:\s+dc:dc2, peer:cluster-01`, output)
+		require.Regexp(t, `DNS TTL:\s+10s`, output)
+		require.Regexp(t, `Token:\s+<hidden>`, output)
+		require.NotContains(t, output, def.Token)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-format=json", id})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		var got api.PreparedQueryDefinition
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &got))
+		expected := *def
+		expected.ID = id
+		expected.Token = "<hidden>"
+		require.Equal(t, expected, got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "8f246b77-f3e1-ff88-5b48-8ec93abf3e05"})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "Error reading prepared query")
+	})
+}
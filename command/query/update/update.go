@@ -0,0 +1,129 @@
+package update
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/agent/structs/aclfilter"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/helpers"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	id        string
+	testStdin io.Reader
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.id, "id", "",
+		"The ID of the prepared query to update. Defaults to the ID in the definition.")
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	flags.Merge(c.flags, c.http.MultiTenancyFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("Must provide exactly one positional argument to specify the prepared query definition")
+		return 1
+	}
+
+	data, err := helpers.LoadDataSourceNoRaw(args[0], c.testStdin)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to load data: %v", err))
+		return 1
+	}
+
+	query, err := helpers.ParsePreparedQuery(data)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	switch {
+	case c.id != "" && query.ID != "" && c.id != query.ID:
+		c.UI.Error(fmt.Sprintf("The -id flag %q does not match the ID %q in the definition", c.id, query.ID))
+		return 1
+	case c.id != "":
+		query.ID = c.id
+	case query.ID == "":
+		c.UI.Error("Must specify the -id flag or an ID in the definition")
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	// The output of 'consul query read' hides the token, keep the current
+	// one in that case.
+	if query.Token == aclfilter.RedactedToken {
+		existing, _, err := client.PreparedQuery().Get(query.ID, nil)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading prepared query %q: %v", query.ID, err))
+			return 1
+		}
+		if len(existing) != 1 {
+			c.UI.Error(fmt.Sprintf("Prepared query %q not found", query.ID))
+			return 1
+		}
+		query.Token = existing[0].Token
+	}
+
+	if _, err := client.PreparedQuery().Update(query, nil); err != nil {
+		c.UI.Error(fmt.Sprintf("Error updating prepared query %q: %v", query.ID, err))
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("Prepared query updated: %s", query.ID))
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return flags.Usage(c.help, nil)
+}
+
+const (
+	synopsis = "Update a prepared query"
+	help     = `
+Usage: consul query update [options] <file or ->
+
+  Replaces a prepared query with a definition in HCL or JSON format. The
+  query to update is given by the -id flag, or by the ID in the definition.
+  The whole query is replaced, so the definition must contain every field
+  that should be kept. The output of 'consul query read -format=json' can be
+  edited and passed back to this command.
+
+  Example:
+
+    $ consul query update -id 8f246b77-f3e1-ff88-5b48-8ec93abf3e05 web-query.hcl
+`
+)
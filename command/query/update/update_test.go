@@ -0,0 +1,113 @@
+package update
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestUpdateCommand_noTabs(t *testing.T) {
+	t.Parallel()
+
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestUpdateCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	testrpc.WaitForTestAgent(t, a.RPC, "dc1")
+	client := a.Client()
+
+	id, _, err := client.PreparedQuery().Create(&api.PreparedQueryDefinition{
+		Name:    "web",
+		Service: api.ServiceQuery{Service: "web"},
+	}, nil)
+	require.NoError(t, err)
+
+	t.Run("id flag", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`
+			Name = "web"
+			Service {
+				Service = "web-v2"
+			}
+		`)
+		code := cmd.Run([]string{"-http-addr=" + a.HTTPAddr(), "-id=" + id, "-"})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+		require.Contains(t, ui.OutputWriter.String(), "Prepared query updated: "+id)
+
+		queries, _, err := client.PreparedQuery().Get(id, nil)
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+		require.Equal(t, "web-v2", queries[0].Service.Service)
+	})
+
+	t.Run("id in definition", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`{"ID": "` + id + `", "Name": "web", "Service": {"Service": "web-v3"}}`)
+		code := cmd.Run([]string{"-http-addr=" + a.HTTPAddr(), "-"})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		queries, _, err := client.PreparedQuery().Get(id, nil)
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+		require.Equal(t, "web-v3", queries[0].Service.Service)
+	})
+
+	t.Run("redacted token", func(t *testing.T) {
+		token := "0f6b6f8f-dc6e-4b37-9a1f-5b4e0d3b1f6e"
+		_, err := client.PreparedQuery().Update(&api.PreparedQueryDefinition{
+			ID:      id,
+			Name:    "web",
+			Token:   token,
+			Service: api.ServiceQuery{Service: "web"},
+		}, nil)
+		require.NoError(t, err)
+
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`{"ID": "` + id + `", "Name": "web", "Token": "<hidden>", "Service": {"Service": "web-v4"}}`)
+		code := cmd.Run([]string{"-http-addr=" + a.HTTPAddr(), "-"})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		queries, _, err := client.PreparedQuery().Get(id, nil)
+		require.NoError(t, err)
+		require.Len(t, queries, 1)
+		require.Equal(t, "web-v4", queries[0].Service.Service)
+		require.Equal(t, token, queries[0].Token)
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`{"Service": {"Service": "web"}}`)
+		code := cmd.Run([]string{"-http-addr=" + a.HTTPAddr(), "-"})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "Must specify the -id flag")
+	})
+
+	t.Run("mismatched id", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		cmd := New(ui)
+		cmd.testStdin = strings.NewReader(`{"ID": "` + id + `", "Service": {"Service": "web"}}`)
+		code := cmd.Run([]string{"-http-addr=" + a.HTTPAddr(), "-id=8f246b77-f3e1-ff88-5b48-8ec93abf3e05", "-"})
+		require.Equal(t, 1, code)
+		require.Contains(t, ui.ErrorWriter.String(), "does not match")
+	})
+}
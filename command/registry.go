@@ -24,6 +24,7 @@ import (
 	aclpolicy "github.com/hashicorp/consul/command/acl/policy"
 	aclpcreate "github.com/hashicorp/consul/command/acl/policy/create"
 	aclpdelete "github.com/hashicorp/consul/command/acl/policy/delete"
+	aclplint "github.com/hashicorp/consul/command/acl/policy/lint"
 	aclplist "github.com/hashicorp/consul/command/acl/policy/list"
 	aclpread "github.com/hashicorp/consul/command/acl/policy/read"
 	aclpupdate "github.com/hashicorp/consul/command/acl/policy/update"
@@ -37,8 +38,11 @@ import (
 	acltclone "github.com/hashicorp/consul/command/acl/token/clone"
 	acltcreate "github.com/hashicorp/consul/command/acl/token/create"
 	acltdelete "github.com/hashicorp/consul/command/acl/token/delete"
+	acltexplain "github.com/hashicorp/consul/command/acl/token/explain"
 	acltlist "github.com/hashicorp/consul/command/acl/token/list"
 	acltread "github.com/hashicorp/consul/command/acl/token/read"
+	acltrenew "github.com/hashicorp/consul/command/acl/token/renew"
+	acltrotate "github.com/hashicorp/consul/command/acl/token/rotate"
 	acltupdate "github.com/hashicorp/consul/command/acl/token/update"
 	"github.com/hashicorp/consul/command/agent"
 	"github.com/hashicorp/consul/command/catalog"
@@ -93,6 +97,7 @@ import (
 	operautoset "github.com/hashicorp/consul/command/operator/autopilot/set"
 	operautostate "github.com/hashicorp/consul/command/operator/autopilot/state"
 	operraft "github.com/hashicorp/consul/command/operator/raft"
+	operraftinspect "github.com/hashicorp/consul/command/operator/raft/inspectlog"
 	operraftlist "github.com/hashicorp/consul/command/operator/raft/listpeers"
 	operraftremove "github.com/hashicorp/consul/command/operator/raft/removepeer"
 	"github.com/hashicorp/consul/command/operator/raft/transferleader"
@@ -104,12 +109,24 @@ import (
 	peergenerate "github.com/hashicorp/consul/command/peering/generate"
 	peerlist "github.com/hashicorp/consul/command/peering/list"
 	peerread "github.com/hashicorp/consul/command/peering/read"
+	"github.com/hashicorp/consul/command/query"
+	querycreate "github.com/hashicorp/consul/command/query/create"
+	querydelete "github.com/hashicorp/consul/command/query/delete"
+	queryexecute "github.com/hashicorp/consul/command/query/execute"
+	queryexplain "github.com/hashicorp/consul/command/query/explain"
+	querylist "github.com/hashicorp/consul/command/query/list"
+	queryread "github.com/hashicorp/consul/command/query/read"
+	queryupdate "github.com/hashicorp/consul/command/query/update"
 	"github.com/hashicorp/consul/command/reload"
 	"github.com/hashicorp/consul/command/rtt"
 	"github.com/hashicorp/consul/command/services"
 	svcsderegister "github.com/hashicorp/consul/command/services/deregister"
 	svcsregister "github.com/hashicorp/consul/command/services/register"
 	"github.com/hashicorp/consul/command/snapshot"
+	snapagent "github.com/hashicorp/consul/command/snapshot/agent"
+	snapdiff "github.com/hashicorp/consul/command/snapshot/diff"
+	snapexport "github.com/hashicorp/consul/command/snapshot/export"
+	snapextract "github.com/hashicorp/consul/command/snapshot/extract"
 	snapinspect "github.com/hashicorp/consul/command/snapshot/inspect"
 	snaprestore "github.com/hashicorp/consul/command/snapshot/restore"
 	snapsave "github.com/hashicorp/consul/command/snapshot/save"
@@ -143,6 +160,7 @@ func RegisteredCommands(ui cli.Ui) map[string]mcli.CommandFactory {
 		entry{"acl policy read", func(ui cli.Ui) (cli.Command, error) { return aclpread.New(ui), nil }},
 		entry{"acl policy update", func(ui cli.Ui) (cli.Command, error) { return aclpupdate.New(ui), nil }},
 		entry{"acl policy delete", func(ui cli.Ui) (cli.Command, error) { return aclpdelete.New(ui), nil }},
+		entry{"acl policy lint", func(ui cli.Ui) (cli.Command, error) { return aclplint.New(ui), nil }},
 		entry{"acl set-agent-token", func(ui cli.Ui) (cli.Command, error) { return aclagent.New(ui), nil }},
 		entry{"acl token", func(cli.Ui) (cli.Command, error) { return acltoken.New(), nil }},
 		entry{"acl token create", func(ui cli.Ui) (cli.Command, error) { return acltcreate.New(ui), nil }},
@@ -151,6 +169,9 @@ func RegisteredCommands(ui cli.Ui) map[string]mcli.CommandFactory {
 		entry{"acl token read", func(ui cli.Ui) (cli.Command, error) { return acltread.New(ui), nil }},
 		entry{"acl token update", func(ui cli.Ui) (cli.Command, error) { return acltupdate.New(ui), nil }},
 		entry{"acl token delete", func(ui cli.Ui) (cli.Command, error) { return acltdelete.New(ui), nil }},
+		entry{"acl token rotate", func(ui cli.Ui) (cli.Command, error) { return acltrotate.New(ui), nil }},
+		entry{"acl token renew", func(ui cli.Ui) (cli.Command, error) { return acltrenew.New(ui), nil }},
+		entry{"acl token explain", func(ui cli.Ui) (cli.Command, error) { return acltexplain.New(ui), nil }},
 		entry{"acl role", func(cli.Ui) (cli.Command, error) { return aclrole.New(), nil }},
 		entry{"acl role create", func(ui cli.Ui) (cli.Command, error) { return aclrcreate.New(ui), nil }},
 		entry{"acl role list", func(ui cli.Ui) (cli.Command, error) { return aclrlist.New(ui), nil }},
@@ -222,6 +243,7 @@ func RegisteredCommands(ui cli.Ui) map[string]mcli.CommandFactory {
 		entry{"operator autopilot set-config", func(ui cli.Ui) (cli.Command, error) { return operautoset.New(ui), nil }},
 		entry{"operator autopilot state", func(ui cli.Ui) (cli.Command, error) { return operautostate.New(ui), nil }},
 		entry{"operator raft", func(cli.Ui) (cli.Command, error) { return operraft.New(), nil }},
+		entry{"operator raft inspect-log", func(ui cli.Ui) (cli.Command, error) { return operraftinspect.New(ui), nil }},
 		entry{"operator raft list-peers", func(ui cli.Ui) (cli.Command, error) { return operraftlist.New(ui), nil }},
 		entry{"operator raft remove-peer", func(ui cli.Ui) (cli.Command, error) { return operraftremove.New(ui), nil }},
 		entry{"operator raft transfer-leader", func(ui cli.Ui) (cli.Command, error) { return transferleader.New(ui), nil }},
@@ -233,12 +255,24 @@ func RegisteredCommands(ui cli.Ui) map[string]mcli.CommandFactory {
 		entry{"peering establish", func(ui cli.Ui) (cli.Command, error) { return peerestablish.New(ui), nil }},
 		entry{"peering list", func(ui cli.Ui) (cli.Command, error) { return peerlist.New(ui), nil }},
 		entry{"peering read", func(ui cli.Ui) (cli.Command, error) { return peerread.New(ui), nil }},
+		entry{"query", func(cli.Ui) (cli.Command, error) { return query.New(), nil }},
+		entry{"query create", func(ui cli.Ui) (cli.Command, error) { return querycreate.New(ui), nil }},
+		entry{"query delete", func(ui cli.Ui) (cli.Command, error) { return querydelete.New(ui), nil }},
+		entry{"query execute", func(ui cli.Ui) (cli.Command, error) { return queryexecute.New(ui), nil }},
+		entry{"query explain", func(ui cli.Ui) (cli.Command, error) { return queryexplain.New(ui), nil }},
+		entry{"query list", func(ui cli.Ui) (cli.Command, error) { return querylist.New(ui), nil }},
+		entry{"query read", func(ui cli.Ui) (cli.Command, error) { return queryread.New(ui), nil }},
+		entry{"query update", func(ui cli.Ui) (cli.Command, error) { return queryupdate.New(ui), nil }},
 		entry{"reload", func(ui cli.Ui) (cli.Command, error) { return reload.New(ui), nil }},
 		entry{"rtt", func(ui cli.Ui) (cli.Command, error) { return rtt.New(ui), nil }},
 		entry{"services", func(cli.Ui) (cli.Command, error) { return services.New(), nil }},
 		entry{"services register", func(ui cli.Ui) (cli.Command, error) { return svcsregister.New(ui), nil }},
 		entry{"services deregister", func(ui cli.Ui) (cli.Command, error) { return svcsderegister.New(ui), nil }},
 		entry{"snapshot", func(cli.Ui) (cli.Command, error) { return snapshot.New(), nil }},
+		entry{"snapshot agent", func(ui cli.Ui) (cli.Command, error) { return snapagent.New(ui, MakeShutdownCh()), nil }},
+		entry{"snapshot diff", func(ui cli.Ui) (cli.Command, error) { return snapdiff.New(ui), nil }},
+		entry{"snapshot export", func(ui cli.Ui) (cli.Command, error) { return snapexport.New(ui), nil }},
+		entry{"snapshot extract", func(ui cli.Ui) (cli.Command, error) { return snapextract.New(ui), nil }},
 		entry{"snapshot inspect", func(ui cli.Ui) (cli.Command, error) { return snapinspect.New(ui), nil }},
 		entry{"snapshot restore", func(ui cli.Ui) (cli.Command, error) { return snaprestore.New(ui), nil }},
 		entry{"snapshot save", func(ui cli.Ui) (cli.Command, error) { return snapsave.New(ui), nil }},
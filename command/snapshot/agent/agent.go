@@ -0,0 +1,285 @@
+package snapshotagent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/snapshot"
+)
+
+const (
+	// checkTTL is the TTL of the health check of the agent, the status is
+	// refreshed every checkTTL/3 so the check only turns critical when the
+	// agent stops running.
+	checkTTL = 30 * time.Second
+
+	// lockRetryTime is the time to wait before trying to acquire the lock
+	// again after an error.
+	lockRetryTime = 10 * time.Second
+)
+
+// snapshotAgent takes a snapshot on an interval while it holds the lock, so
+// only one of the agents running in a cluster saves snapshots.
+type snapshotAgent struct {
+	client  *api.Client
+	storage Storage
+	logger  hclog.Logger
+
+	interval  time.Duration
+	retain    int
+	retainAge time.Duration
+	stale     bool
+	lockKey   string
+	service   string
+
+	// encryptionKey encrypts the snapshots when set.
+	encryptionKey *snapshot.EncryptionKey
+
+	// now is replaced in tests.
+	now func() time.Time
+
+	statusLock sync.Mutex
+	status     string
+	output     string
+}
+
+func (a *snapshotAgent) checkID() string {
+	return "service:" + a.service
+}
+
+// run takes snapshots until the context is canceled.
+func (a *snapshotAgent) run(ctx context.Context) error {
+	if a.now == nil {
+		a.now = time.Now
+	}
+
+	err := a.client.Agent().ServiceRegister(&api.AgentServiceRegistration{
+		ID:   a.service,
+		Name: a.service,
+		Check: &api.AgentServiceCheck{
+			CheckID: a.checkID(),
+			Name:    "Consul snapshot agent",
+			TTL:     checkTTL.String(),
+			Status:  api.HealthWarning,
+			Notes:   "Reports whether the last snapshot succeeded",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register the %q service: %w", a.service, err)
+	}
+	defer func() {
+		if err := a.client.Agent().ServiceDeregister(a.service); err != nil {
+			a.logger.Error("failed to deregister service", "service", a.service, "error", err)
+		}
+	}()
+
+	a.setStatus(api.HealthPassing, "Waiting to acquire the snapshot lock")
+	go a.heartbeat(ctx)
+
+	for {
+		lock, err := a.client.LockOpts(&api.LockOptions{
+			Key:         a.lockKey,
+			SessionName: "Consul snapshot agent",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set up the snapshot lock: %w", err)
+		}
+
+		a.logger.Info("waiting to acquire the snapshot lock", "key", a.lockKey)
+		lostCh, err := lock.Lock(ctx.Done())
+		if err != nil {
+			a.logger.Error("failed to acquire the snapshot lock", "error", err)
+			a.setStatus(api.HealthCritical, fmt.Sprintf("Failed to acquire the snapshot lock: %v", err))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(lockRetryTime):
+				continue
+			}
+		}
+		if lostCh == nil {
+			return nil
+		}
+
+		a.logger.Info("acquired the snapshot lock, taking snapshots", "interval", a.interval, "storage", a.storage)
+		a.takeSnapshots(ctx, lostCh)
+
+		if err := lock.Unlock(); err != nil && err != api.ErrLockNotHeld {
+			a.logger.Error("failed to release the snapshot lock", "error", err)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		a.setStatus(api.HealthPassing, "Waiting to acquire the snapshot lock")
+	}
+}
+
+// takeSnapshots takes a snapshot on the interval until the context is
+// canceled or the lock is lost. The first snapshot is taken right away unless
+// the last one stored is more recent than the interval, so restarting the
+// agent or moving the lock doesn't take extra snapshots.
+func (a *snapshotAgent) takeSnapshots(ctx context.Context, lostCh <-chan struct{}) {
+	next := a.now()
+	if snaps, err := a.storage.List(ctx); err != nil {
+		a.logger.Warn("failed to list the stored snapshots", "error", err)
+	} else if len(snaps) > 0 {
+		sortNewestFirst(snaps)
+		next = snaps[0].Time.Add(a.interval)
+	}
+
+	for {
+		timer := time.NewTimer(next.Sub(a.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-lostCh:
+			timer.Stop()
+			a.logger.Warn("lost the snapshot lock")
+			return
+		case <-timer.C:
+		}
+
+		next = a.now().Add(a.interval)
+		name, err := a.snapshot(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			a.logger.Error("failed to take snapshot", "error", err)
+			a.setStatus(api.HealthCritical, fmt.Sprintf("Failed to take snapshot: %v", err))
+			continue
+		}
+		a.logger.Info("saved snapshot", "name", name)
+
+		if err := a.applyRetention(ctx); err != nil {
+			a.logger.Error("failed to apply snapshot retention", "error", err)
+			a.setStatus(api.HealthWarning, fmt.Sprintf("Saved snapshot %s but failed to apply retention: %v", name, err))
+			continue
+		}
+		a.setStatus(api.HealthPassing, fmt.Sprintf("Saved snapshot %s", name))
+	}
+}
+
+// snapshot saves a snapshot, verifies it and stores it. It returns the name
+// of the stored snapshot.
+func (a *snapshotAgent) snapshot(ctx context.Context) (string, error) {
+	opts := &api.QueryOptions{AllowStale: a.stale}
+	snap, _, err := a.client.Snapshot().Save(opts.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer snap.Close()
+
+	// The snapshot is kept in a temporary file so it can be verified before
+	// being stored.
+	f, err := os.CreateTemp("", "consul-snapshot-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	var keys []*snapshot.EncryptionKey
+	if a.encryptionKey != nil {
+		keys = append(keys, a.encryptionKey)
+		if err := snapshot.Encrypt(f, snap, a.encryptionKey); err != nil {
+			return "", fmt.Errorf("failed to encrypt snapshot: %w", err)
+		}
+	} else if _, err := io.Copy(f, snap); err != nil {
+		return "", fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	if _, err := snapshot.Verify(f, keys...); err != nil {
+		return "", fmt.Errorf("failed to verify snapshot: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	name := snapshotName(a.now())
+	if err := a.storage.Put(ctx, name, f); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// applyRetention deletes the snapshots beyond the retained count and the ones
+// older than the retained age. The most recent snapshot is always kept.
+func (a *snapshotAgent) applyRetention(ctx context.Context) error {
+	if a.retain <= 0 && a.retainAge <= 0 {
+		return nil
+	}
+
+	snaps, err := a.storage.List(ctx)
+	if err != nil {
+		return err
+	}
+	sortNewestFirst(snaps)
+
+	now := a.now()
+	for i, snap := range snaps {
+		if i == 0 {
+			continue
+		}
+		tooMany := a.retain > 0 && i >= a.retain
+		tooOld := a.retainAge > 0 && now.Sub(snap.Time) > a.retainAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := a.storage.Delete(ctx, snap.Name); err != nil {
+			return err
+		}
+		a.logger.Info("deleted snapshot", "name", snap.Name)
+	}
+	return nil
+}
+
+func sortNewestFirst(snaps []SnapshotInfo) {
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].Time.After(snaps[j].Time)
+	})
+}
+
+// setStatus updates the status of the health check.
+func (a *snapshotAgent) setStatus(status, output string) {
+	a.statusLock.Lock()
+	a.status, a.output = status, output
+	a.statusLock.Unlock()
+
+	a.updateTTL()
+}
+
+func (a *snapshotAgent) updateTTL() {
+	a.statusLock.Lock()
+	status, output := a.status, a.output
+	a.statusLock.Unlock()
+
+	if err := a.client.Agent().UpdateTTL(a.checkID(), output, status); err != nil {
+		a.logger.Warn("failed to update the health check", "check", a.checkID(), "error", err)
+	}
+}
+
+// heartbeat refreshes the health check before its TTL expires.
+func (a *snapshotAgent) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(checkTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.updateTTL()
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package snapshotagent
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/snapshot"
+)
+
+func New(ui cli.Ui, shutdownCh <-chan struct{}) *cmd {
+	c := &cmd{UI: ui, shutdownCh: shutdownCh}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	shutdownCh <-chan struct{}
+
+	// flags
+	interval  time.Duration
+	retain    int
+	retainAge time.Duration
+	lockKey   string
+	service   string
+	logLevel  string
+	localPath string
+	s3        S3Config
+
+	encryptKeyFile string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.DurationVar(&c.interval, "interval", time.Hour,
+		"The time between two snapshots. The default value is 1h.")
+	c.flags.IntVar(&c.retain, "retain", 30,
+		"The number of snapshots to keep, older snapshots are deleted after a "+
+			"new one is saved. 0 keeps every snapshot. The default value is 30.")
+	c.flags.DurationVar(&c.retainAge, "retain-age", 0,
+		"Delete the snapshots older than this duration after a new one is "+
+			"saved. The most recent snapshot is always kept. By default snapshots "+
+			"are only deleted according to -retain.")
+	c.flags.StringVar(&c.lockKey, "lock-key", "consul-snapshot/lock",
+		"The KV key used to elect the agent saving the snapshots when several "+
+			"agents are running.")
+	c.flags.StringVar(&c.service, "service", "consul-snapshot",
+		"The name of the service registered with a TTL check reporting the "+
+			"health of the agent.")
+	c.flags.StringVar(&c.logLevel, "log-level", "info",
+		"The level of the logs: trace, debug, info, warn or error.")
+	c.flags.StringVar(&c.encryptKeyFile, "encrypt-key-file", "",
+		"Encrypt the snapshots with the first key of this file. The file holds "+
+			"base64-encoded 32-byte keys, one per line, like the ones generated "+
+			"by \"consul keygen\".")
+	c.flags.StringVar(&c.localPath, "local-path", ".",
+		"The directory where the snapshots are saved when they are not "+
+			"uploaded to S3.")
+	c.flags.StringVar(&c.s3.Bucket, "aws-s3-bucket", "",
+		"Upload the snapshots to this S3 bucket instead of saving them locally. "+
+			"The credentials are read from the environment, the shared "+
+			"credentials file or the instance metadata.")
+	c.flags.StringVar(&c.s3.KeyPrefix, "aws-s3-key-prefix", "consul-snapshot",
+		"The prefix of the keys of the snapshots in the S3 bucket.")
+	c.flags.StringVar(&c.s3.Region, "aws-s3-region", "",
+		"The region of the S3 bucket.")
+	c.flags.StringVar(&c.s3.Endpoint, "aws-s3-endpoint", "",
+		"The endpoint of a S3-compatible service to upload the snapshots to.")
+	c.flags.BoolVar(&c.s3.ForcePathStyle, "aws-s3-force-path-style", false,
+		"Use path-style addressing of the bucket, required by most "+
+			"S3-compatible services.")
+	c.flags.BoolVar(&c.s3.ServerSideEncryption, "aws-s3-server-side-encryption", false,
+		"Enable server-side AES256 encryption of the snapshots.")
+
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if len(c.flags.Args()) > 0 {
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 0, got %d)", len(c.flags.Args())))
+		return 1
+	}
+	if c.interval <= 0 {
+		c.UI.Error("The -interval must be greater than 0")
+		return 1
+	}
+	if c.retain < 0 {
+		c.UI.Error("The -retain must not be negative")
+		return 1
+	}
+	if c.retainAge < 0 {
+		c.UI.Error("The -retain-age must not be negative")
+		return 1
+	}
+	level := hclog.LevelFromString(c.logLevel)
+	if level == hclog.NoLevel {
+		c.UI.Error(fmt.Sprintf("Invalid log level: %s", c.logLevel))
+		return 1
+	}
+
+	var encryptionKey *snapshot.EncryptionKey
+	if c.encryptKeyFile != "" {
+		keys, err := snapshot.ReadEncryptionKeyFile(c.encryptKeyFile)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading encryption key: %s", err))
+			return 1
+		}
+		encryptionKey = keys[0]
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	var storage Storage = &LocalStorage{Path: c.localPath}
+	if c.s3.Bucket != "" {
+		storage, err = NewS3Storage(c.s3)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error configuring S3 storage: %s", err))
+			return 1
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.shutdownCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	agent := &snapshotAgent{
+		client:        client,
+		storage:       storage,
+		interval:      c.interval,
+		retain:        c.retain,
+		retainAge:     c.retainAge,
+		stale:         c.http.Stale(),
+		lockKey:       c.lockKey,
+		service:       c.service,
+		encryptionKey: encryptionKey,
+		logger: hclog.New(&hclog.LoggerOptions{
+			Name:   "snapshot-agent",
+			Level:  level,
+			Output: &cli.UiWriter{Ui: c.UI},
+		}),
+	}
+	if err := agent.run(ctx); err != nil {
+		c.UI.Error(fmt.Sprintf("Error running snapshot agent: %s", err))
+		return 1
+	}
+	return 0
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+const synopsis = "Periodically saves snapshots of Consul server state"
+const help = `
+Usage: consul snapshot agent [options]
+
+  Runs a long-running process that saves a snapshot of the state of the
+  Consul servers on an interval, verifies it and stores it locally or in an
+  S3 bucket. Old snapshots are deleted according to -retain and -retain-age.
+
+  Several agents can run for high availability: they use a lock in the KV
+  store so only one of them saves snapshots at a time. Each agent registers
+  a service with a TTL check reporting whether its last snapshot succeeded.
+
+  If ACLs are enabled, the token must have management privileges to save
+  snapshots, and permissions to write the lock key, create sessions and
+  register the service.
+
+  Save a snapshot every hour in the current directory, keeping the last 30:
+
+      $ consul snapshot agent
+
+  Upload a snapshot every 30 minutes to a S3-compatible service, keeping the
+  snapshots of the last week:
+
+      $ consul snapshot agent -interval=30m -retain=0 -retain-age=168h \
+          -aws-s3-bucket=backups -aws-s3-endpoint=https://minio.example.com \
+          -aws-s3-force-path-style
+
+  For a full list of options and examples, please see the Consul documentation.
+`
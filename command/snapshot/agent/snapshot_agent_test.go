@@ -0,0 +1,228 @@
+package snapshotagent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/hashicorp/consul/snapshot"
+)
+
+func TestSnapshotAgentCommand_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi(), nil).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestSnapshotAgentCommand_Validation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args   []string
+		output string
+	}{
+		"extra args": {
+			[]string{"foo"},
+			"Too many arguments",
+		},
+		"zero interval": {
+			[]string{"-interval=0"},
+			"-interval must be greater than 0",
+		},
+		"negative retain": {
+			[]string{"-retain=-1"},
+			"-retain must not be negative",
+		},
+		"negative retain age": {
+			[]string{"-retain-age=-1h"},
+			"-retain-age must not be negative",
+		},
+		"invalid log level": {
+			[]string{"-log-level=loud"},
+			"Invalid log level",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			c := New(ui, nil)
+
+			code := c.Run(tc.args)
+			require.Equal(t, 1, code)
+			require.Contains(t, ui.ErrorWriter.String(), tc.output)
+		})
+	}
+}
+
+func TestSnapshotAgent_Retention(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 3, 4, 12, 0, 0, 0, time.UTC)
+	ages := []time.Duration{0, time.Hour, 2 * time.Hour, 3 * time.Hour, 4 * time.Hour}
+
+	cases := map[string]struct {
+		retain    int
+		retainAge time.Duration
+		remaining int
+	}{
+		"unlimited":   {0, 0, 5},
+		"count":       {3, 0, 3},
+		"age":         {0, 150 * time.Minute, 3},
+		"count first": {2, 150 * time.Minute, 2},
+		"age first":   {4, 90 * time.Minute, 2},
+		// the most recent snapshot is always kept
+		"all expired": {0, time.Minute, 1},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			storage := &LocalStorage{Path: testutil.TempDir(t, "snapshot-agent")}
+			for _, age := range ages {
+				// the newest snapshot is a bit old to check it is kept anyway
+				name := snapshotName(now.Add(-age - time.Minute))
+				require.NoError(t, storage.Put(context.Background(), name, strings.NewReader("")))
+			}
+
+			a := &snapshotAgent{
+				storage:   storage,
+				logger:    hclog.NewNullLogger(),
+				retain:    tc.retain,
+				retainAge: tc.retainAge,
+				now:       func() time.Time { return now },
+			}
+			require.NoError(t, a.applyRetention(context.Background()))
+
+			snaps, err := storage.List(context.Background())
+			require.NoError(t, err)
+			require.Len(t, snaps, tc.remaining)
+
+			sortNewestFirst(snaps)
+			for i, snap := range snaps {
+				require.True(t, now.Add(-ages[i]-time.Minute).Equal(snap.Time))
+			}
+		})
+	}
+}
+
+type runningAgent struct {
+	ui         *cli.MockUi
+	shutdownCh chan struct{}
+	codeCh     chan int
+}
+
+func runSnapshotAgent(a *agent.TestAgent, args ...string) *runningAgent {
+	r := &runningAgent{
+		ui:         cli.NewMockUi(),
+		shutdownCh: make(chan struct{}),
+		codeCh:     make(chan int, 1),
+	}
+	c := New(r.ui, r.shutdownCh)
+	go func() {
+		r.codeCh <- c.Run(append([]string{"-http-addr=" + a.HTTPAddr()}, args...))
+	}()
+	return r
+}
+
+func (r *runningAgent) stop(t *testing.T) {
+	close(r.shutdownCh)
+	select {
+	case code := <-r.codeCh:
+		require.Equal(t, 0, code, r.ui.ErrorWriter.String())
+	case <-time.After(10 * time.Second):
+		t.Fatal("snapshot agent didn't stop")
+	}
+}
+
+func listSnapshots(t require.TestingT, dir string) []string {
+	snaps, err := (&LocalStorage{Path: dir}).List(context.Background())
+	require.NoError(t, err)
+	names := make([]string, 0, len(snaps))
+	for _, snap := range snaps {
+		names = append(names, snap.Name)
+	}
+	return names
+}
+
+func TestSnapshotAgentCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	client := a.Client()
+
+	dir := testutil.TempDir(t, "snapshot-agent")
+	r := runSnapshotAgent(a, "-interval=200ms", "-retain=2", "-local-path="+dir, "-service=backups")
+
+	retry.Run(t, func(r *retry.R) {
+		checks, err := client.Agent().Checks()
+		require.NoError(r, err)
+		check, ok := checks["service:backups"]
+		require.True(r, ok)
+		require.Equal(r, api.HealthPassing, check.Status)
+		require.Contains(r, check.Output, "Saved snapshot")
+	})
+
+	// wait for enough snapshots for the retention to delete some of them
+	time.Sleep(time.Second)
+	r.stop(t)
+
+	require.Contains(t, r.ui.OutputWriter.String(), "deleted snapshot")
+	names := listSnapshots(t, dir)
+	require.Len(t, names, 2)
+
+	f, err := os.Open(filepath.Join(dir, names[0]))
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = snapshot.Verify(f)
+	require.NoError(t, err)
+
+	// the service is deregistered when the agent stops
+	services, err := client.Agent().Services()
+	require.NoError(t, err)
+	require.NotContains(t, services, "backups")
+}
+
+func TestSnapshotAgentCommand_Lock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+
+	dir1 := testutil.TempDir(t, "snapshot-agent")
+	r1 := runSnapshotAgent(a, "-interval=1h", "-local-path="+dir1, "-service=backups-1")
+	retry.Run(t, func(r *retry.R) {
+		require.Len(r, listSnapshots(r, dir1), 1)
+	})
+
+	// the second agent waits for the lock held by the first one
+	dir2 := testutil.TempDir(t, "snapshot-agent")
+	r2 := runSnapshotAgent(a, "-interval=1h", "-local-path="+dir2, "-service=backups-2")
+	time.Sleep(500 * time.Millisecond)
+	require.Empty(t, listSnapshots(t, dir2))
+
+	r1.stop(t)
+	retry.Run(t, func(r *retry.R) {
+		require.Len(r, listSnapshots(r, dir2), 1)
+	})
+	r2.stop(t)
+	require.Len(t, listSnapshots(t, dir1), 1)
+}
@@ -0,0 +1,107 @@
+package snapshotagent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rboyer/safeio"
+)
+
+const (
+	snapshotPrefix     = "consul-"
+	snapshotSuffix     = ".snap"
+	snapshotTimeFormat = "20060102-150405.000"
+)
+
+// Storage is where the snapshot agent keeps the snapshots it takes.
+type Storage interface {
+	// Put stores the snapshot read from r under the given name.
+	Put(ctx context.Context, name string, r io.ReadSeeker) error
+
+	// List returns the snapshots taken by the agent. Other objects found in
+	// the storage are ignored, so retention never deletes them.
+	List(ctx context.Context) ([]SnapshotInfo, error)
+
+	// Delete removes the snapshot with the given name.
+	Delete(ctx context.Context, name string) error
+
+	// String describes the storage in the logs.
+	String() string
+}
+
+// SnapshotInfo describes a snapshot stored by the agent.
+type SnapshotInfo struct {
+	Name string
+	Time time.Time
+}
+
+// snapshotName returns the name of a snapshot taken at the given time.
+func snapshotName(t time.Time) string {
+	return snapshotPrefix + t.UTC().Format(snapshotTimeFormat) + snapshotSuffix
+}
+
+// parseSnapshotName returns the time the snapshot was taken at, and false if
+// the name wasn't generated by snapshotName.
+func parseSnapshotName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+		return time.Time{}, false
+	}
+	ts := strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotSuffix)
+	t, err := time.Parse(snapshotTimeFormat, ts)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// LocalStorage keeps the snapshots in a directory of the local filesystem.
+type LocalStorage struct {
+	Path string
+}
+
+func (s *LocalStorage) Put(_ context.Context, name string, r io.ReadSeeker) error {
+	if err := os.MkdirAll(s.Path, 0700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if _, err := safeio.WriteToFile(r, filepath.Join(s.Path, name), 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) List(_ context.Context) ([]SnapshotInfo, error) {
+	entries, err := os.ReadDir(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var out []SnapshotInfo
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if t, ok := parseSnapshotName(entry.Name()); ok {
+			out = append(out, SnapshotInfo{Name: entry.Name(), Time: t})
+		}
+	}
+	return out, nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, name string) error {
+	if err := os.Remove(filepath.Join(s.Path, name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) String() string {
+	return "local path " + s.Path
+}
@@ -0,0 +1,121 @@
+package snapshotagent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// S3Config configures the storage of the snapshots in an S3 bucket.
+type S3Config struct {
+	Bucket    string
+	KeyPrefix string
+	Region    string
+
+	// Endpoint overrides the AWS endpoint to store the snapshots in a
+	// S3-compatible service.
+	Endpoint string
+
+	// ForcePathStyle puts the bucket in the path of the requests instead of
+	// the host name, most S3-compatible services require it.
+	ForcePathStyle bool
+
+	// ServerSideEncryption enables AES256 encryption at rest of the snapshots.
+	ServerSideEncryption bool
+}
+
+// S3Storage keeps the snapshots in an S3 bucket, or in a bucket of a
+// S3-compatible service.
+type S3Storage struct {
+	client s3iface.S3API
+	config S3Config
+}
+
+// NewS3Storage returns a storage using the credentials found in the
+// environment, the shared credentials file or the instance metadata, like the
+// other AWS integrations do.
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	awsConfig := aws.Config{
+		S3ForcePathStyle: aws.Bool(config.ForcePathStyle),
+	}
+	if config.Region != "" {
+		awsConfig.Region = aws.String(config.Region)
+	}
+	if config.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Endpoint)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return &S3Storage{client: s3.New(sess), config: config}, nil
+}
+
+func (s *S3Storage) key(name string) string {
+	return path.Join(s.config.KeyPrefix, name)
+}
+
+func (s *S3Storage) Put(ctx context.Context, name string, r io.ReadSeeker) error {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(s.key(name)),
+		Body:   r,
+	}
+	if s.config.ServerSideEncryption {
+		input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAes256)
+	}
+	if _, err := s.client.PutObjectWithContext(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) List(ctx context.Context) ([]SnapshotInfo, error) {
+	prefix := s.key(snapshotPrefix)
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.config.Bucket),
+		Prefix: aws.String(prefix),
+	}
+
+	var out []SnapshotInfo
+	err := s.client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			name := strings.TrimPrefix(aws.StringValue(obj.Key), s.key(""))
+			name = strings.TrimPrefix(name, "/")
+			if t, ok := parseSnapshotName(name); ok {
+				out = append(out, SnapshotInfo{Name: name, Time: t})
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	return out, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, name string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) String() string {
+	return "S3 bucket " + path.Join(s.config.Bucket, s.config.KeyPrefix)
+}
@@ -0,0 +1,204 @@
+package snapshotagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+)
+
+func TestSnapshotName(t *testing.T) {
+	now := time.Date(2022, 3, 4, 5, 6, 7, 890000000, time.UTC)
+
+	name := snapshotName(now)
+	require.Equal(t, "consul-20220304-050607.890.snap", name)
+
+	parsed, ok := parseSnapshotName(name)
+	require.True(t, ok)
+	require.True(t, now.Equal(parsed))
+
+	for _, name := range []string{"backup.snap", "consul-latest.snap", "consul-20220304-050607.890.snap.unverified"} {
+		_, ok := parseSnapshotName(name)
+		require.False(t, ok, name)
+	}
+}
+
+// testStorage stores three snapshots and an unrelated object, and checks that
+// only the snapshots are listed and deleted.
+func testStorage(t *testing.T, s Storage, putOther func(name string)) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		name := snapshotName(now.Add(time.Duration(i) * time.Minute))
+		names = append(names, name)
+		require.NoError(t, s.Put(ctx, name, strings.NewReader("snapshot "+name)))
+	}
+	putOther("backup.snap")
+
+	snaps, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, snaps, 3)
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Time.Before(snaps[j].Time) })
+	for i, snap := range snaps {
+		require.Equal(t, names[i], snap.Name)
+		require.True(t, now.Add(time.Duration(i)*time.Minute).Equal(snap.Time))
+	}
+
+	require.NoError(t, s.Delete(ctx, names[0]))
+	snaps, err = s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	for _, snap := range snaps {
+		require.NotEqual(t, names[0], snap.Name)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	dir := testutil.TempDir(t, "snapshot-agent")
+	path := filepath.Join(dir, "snapshots")
+	s := &LocalStorage{Path: path}
+
+	// the directory is created by the first snapshot
+	snaps, err := s.List(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, snaps)
+
+	testStorage(t, s, func(name string) {
+		require.NoError(t, os.WriteFile(filepath.Join(path, name), []byte("other"), 0600))
+	})
+
+	name := snapshotName(time.Now())
+	require.NoError(t, s.Put(context.Background(), name, strings.NewReader("content")))
+	fi, err := os.Stat(filepath.Join(path, name))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode())
+
+	_, err = os.Stat(filepath.Join(path, "backup.snap"))
+	require.NoError(t, err)
+}
+
+// fakeS3 is a S3-compatible server with path-style addressing that supports
+// the requests made by S3Storage.
+type fakeS3 struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+	headers map[string]http.Header
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{
+		objects: make(map[string][]byte),
+		headers: make(map[string]http.Header),
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+type listBucketResult struct {
+	XMLName     xml.Name `xml:"ListBucketResult"`
+	Name        string
+	Prefix      string
+	KeyCount    int
+	IsTruncated bool
+	Contents    []listObject
+}
+
+type listObject struct {
+	Key  string
+	Size int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objectKey := bucket + "/" + key
+
+	switch {
+	case r.Method == http.MethodPut && key != "":
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		f.objects[objectKey] = body
+		f.headers[objectKey] = r.Header.Clone()
+	case r.Method == http.MethodDelete && key != "":
+		delete(f.objects, objectKey)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && key == "":
+		prefix := r.URL.Query().Get("prefix")
+		result := listBucketResult{Name: bucket, Prefix: prefix}
+		for k, v := range f.objects {
+			if strings.HasPrefix(k, bucket+"/"+prefix) {
+				result.Contents = append(result.Contents, listObject{
+					Key:  strings.TrimPrefix(k, bucket+"/"),
+					Size: len(v),
+				})
+			}
+		}
+		result.KeyCount = len(result.Contents)
+		w.Header().Set("Content-Type", "application/xml")
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodGet:
+		body, ok := f.objects[objectKey]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) object(key string) ([]byte, http.Header) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.objects[key], f.headers[key]
+}
+
+func TestS3Storage(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "access")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	fake, srv := newFakeS3(t)
+	s, err := NewS3Storage(S3Config{
+		Bucket:               "backups",
+		KeyPrefix:            "consul/dc1",
+		Region:               "us-east-1",
+		Endpoint:             srv.URL,
+		ForcePathStyle:       true,
+		ServerSideEncryption: true,
+	})
+	require.NoError(t, err)
+
+	testStorage(t, s, func(name string) {
+		fake.lock.Lock()
+		defer fake.lock.Unlock()
+		fake.objects["backups/consul/dc1/"+name] = []byte("other")
+	})
+
+	name := snapshotName(time.Now())
+	require.NoError(t, s.Put(context.Background(), name, bytes.NewReader([]byte("content"))))
+	body, header := fake.object("backups/consul/dc1/" + name)
+	require.Equal(t, "content", string(body))
+	require.Equal(t, "AES256", header.Get("X-Amz-Server-Side-Encryption"))
+}
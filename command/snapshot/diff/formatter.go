@@ -0,0 +1,100 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	PrettyFormat string = "pretty"
+	JSONFormat   string = "json"
+)
+
+type Formatter interface {
+	Format(*OutputFormat) (string, error)
+}
+
+func GetSupportedFormats() []string {
+	return []string{PrettyFormat, JSONFormat}
+}
+
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case PrettyFormat:
+		return newPrettyFormatter(), nil
+	case JSONFormat:
+		return newJSONFormatter(), nil
+	default:
+		return nil, fmt.Errorf("Unknown format: %s", format)
+	}
+}
+
+type prettyFormatter struct{}
+
+func newPrettyFormatter() Formatter {
+	return &prettyFormatter{}
+}
+
+var changeSymbols = map[string]string{
+	ChangeAdded:   "+",
+	ChangeRemoved: "-",
+	ChangeChanged: "~",
+}
+
+func (_ *prettyFormatter) Format(info *OutputFormat) (string, error) {
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 8, 8, 6, ' ', 0)
+
+	fmt.Fprintf(tw, " From\t%s (index %d, term %d)", info.From.ID, info.From.Index, info.From.Term)
+	fmt.Fprintf(tw, "\n To\t%s (index %d, term %d)", info.To.ID, info.To.Index, info.To.Term)
+	fmt.Fprintf(tw, "\n")
+
+	if len(info.Changes) == 0 {
+		fmt.Fprintf(tw, "\n No differences")
+		if err := tw.Flush(); err != nil {
+			return b.String(), err
+		}
+		return b.String(), nil
+	}
+
+	counts := make(map[string]int)
+	typ := ""
+	for _, change := range info.Changes {
+		if change.Type != typ {
+			typ = change.Type
+			fmt.Fprintf(tw, "\n %s", typ)
+		}
+		fmt.Fprintf(tw, "\n   %s %s", changeSymbols[change.Change], change.Name)
+		if len(change.Fields) > 0 {
+			fmt.Fprintf(tw, " (%s)", strings.Join(change.Fields, ", "))
+		}
+		counts[change.Change]++
+	}
+	fmt.Fprintf(tw, "\n\n %d added, %d removed, %d changed",
+		counts[ChangeAdded], counts[ChangeRemoved], counts[ChangeChanged])
+
+	if err := tw.Flush(); err != nil {
+		return b.String(), err
+	}
+	return b.String(), nil
+}
+
+type jsonFormatter struct{}
+
+func newJSONFormatter() Formatter {
+	return &jsonFormatter{}
+}
+
+func (_ *jsonFormatter) Format(info *OutputFormat) (string, error) {
+	if info.Changes == nil {
+		info.Changes = []Change{}
+	}
+	b, err := json.MarshalIndent(info, "", "   ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to marshal snapshot differences: %v", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,50 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	info := OutputFormat{
+		From: MetadataInfo{ID: "2-10-1000", Index: 10, Term: 2},
+		To:   MetadataInfo{ID: "2-20-2000", Index: 20, Term: 2},
+		Changes: []Change{
+			{Type: TypeKV, Name: "web/a", Change: ChangeAdded},
+			{Type: TypeKV, Name: "web/b", Change: ChangeChanged, Fields: []string{"Flags", "Value"}},
+			{Type: TypeService, Name: "db", Change: ChangeRemoved},
+			{Type: TypeACLPolicy, Name: "web", Change: ChangeChanged, Fields: []string{"Rules"}},
+		},
+	}
+
+	formatters := map[string]Formatter{
+		"pretty": newPrettyFormatter(),
+		"json":   newJSONFormatter(),
+	}
+
+	for fmtName, formatter := range formatters {
+		t.Run(fmtName, func(t *testing.T) {
+			actual, err := formatter.Format(&info)
+			require.NoError(t, err)
+
+			expected := golden(t, fmtName, actual)
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestFormat_NoDifferences(t *testing.T) {
+	info := OutputFormat{
+		From: MetadataInfo{ID: "2-10-1000", Index: 10, Term: 2},
+		To:   MetadataInfo{ID: "2-10-1000", Index: 10, Term: 2},
+	}
+
+	out, err := newPrettyFormatter().Format(&info)
+	require.NoError(t, err)
+	require.Contains(t, out, "No differences")
+
+	out, err = newJSONFormatter().Format(&info)
+	require.NoError(t, err)
+	require.Contains(t, out, `"Changes": []`)
+}
@@ -0,0 +1,424 @@
+package diff
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/raft"
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/snapshot/snapshotstate"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	help  string
+
+	// flags
+	format         string
+	decryptKeyFile string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		PrettyFormat,
+		fmt.Sprintf("Output format {%s}", strings.Join(GetSupportedFormats(), "|")))
+	c.flags.StringVar(&c.decryptKeyFile, "decrypt-key-file", "",
+		"Decrypt encrypted snapshots with the matching keys of this file. The "+
+			"file holds base64-encoded keys, one per line.")
+
+	c.help = flags.Usage(help, c.flags)
+}
+
+// The types of the compared items, in the order they are reported.
+const (
+	TypeKV              = "kv"
+	TypeService         = "service"
+	TypeServiceInstance = "service-instance"
+	TypeConfigEntry     = "config-entry"
+	TypeIntention       = "intention"
+	TypeACLToken        = "acl-token"
+	TypeACLPolicy       = "acl-policy"
+	TypeACLRole         = "acl-role"
+	TypeACLBindingRule  = "acl-binding-rule"
+	TypeACLAuthMethod   = "acl-auth-method"
+)
+
+var typeOrder = []string{
+	TypeKV,
+	TypeService,
+	TypeServiceInstance,
+	TypeConfigEntry,
+	TypeIntention,
+	TypeACLToken,
+	TypeACLPolicy,
+	TypeACLRole,
+	TypeACLBindingRule,
+	TypeACLAuthMethod,
+}
+
+// The kinds of changes.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change is an item that differs between the two snapshots.
+type Change struct {
+	Type   string
+	Name   string
+	Change string
+
+	// Fields are the names of the fields that changed, the values are not
+	// reported so the output doesn't hold secrets.
+	Fields []string `json:",omitempty"`
+}
+
+// MetadataInfo is used for passing information
+// through the formatter
+type MetadataInfo struct {
+	ID    string
+	Index uint64
+	Term  uint64
+}
+
+// OutputFormat is used for passing information
+// through the formatter
+type OutputFormat struct {
+	From    MetadataInfo
+	To      MetadataInfo
+	Changes []Change
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = c.flags.Args()
+	if len(args) != 2 {
+		c.UI.Error(fmt.Sprintf("This command requires two snapshot files (got %d)", len(args)))
+		return 1
+	}
+
+	formatter, err := NewFormatter(c.format)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	keys, err := snapshotstate.ReadKeys(c.decryptKeyFile)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	var (
+		metas [2]*raft.SnapshotMeta
+		items [2]map[string]map[string]item
+	)
+	for i, file := range args {
+		store, meta, err := snapshotstate.Load(file, keys)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("%s: %s", file, err))
+			return 1
+		}
+		metas[i] = meta
+		items[i], err = collect(store)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading %s: %s", file, err))
+			return 1
+		}
+	}
+
+	out, err := formatter.Format(&OutputFormat{
+		From:    metadataInfo(metas[0]),
+		To:      metadataInfo(metas[1]),
+		Changes: compare(items[0], items[1]),
+	})
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	c.UI.Output(out)
+	return 0
+}
+
+func metadataInfo(meta *raft.SnapshotMeta) MetadataInfo {
+	return MetadataInfo{ID: meta.ID, Index: meta.Index, Term: meta.Term}
+}
+
+// item is the decoded value of an item of a snapshot.
+type item struct {
+	name  string
+	value map[string]interface{}
+}
+
+// ignoredFields are the fields updated by the servers on every write, they
+// would report changes that didn't happen.
+var ignoredFields = []string{"CreateIndex", "ModifyIndex", "Hash"}
+
+// collect returns the items of the state store that are compared, by type and
+// identifier.
+func collect(store *state.Store) (map[string]map[string]item, error) {
+	entMeta := acl.WildcardEnterpriseMeta()
+	items := make(map[string]map[string]item)
+	add := func(typ, id, name string, value interface{}, ignored ...string) error {
+		buf, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s %s: %v", typ, name, err)
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(buf, &decoded); err != nil {
+			return fmt.Errorf("failed to decode %s %s: %v", typ, name, err)
+		}
+		for _, field := range append(ignored, ignoredFields...) {
+			delete(decoded, field)
+		}
+
+		if items[typ] == nil {
+			items[typ] = make(map[string]item)
+		}
+		items[typ][id] = item{name: name, value: decoded}
+		return nil
+	}
+
+	_, entries, err := store.KVSList(nil, "", entMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := qualify(entry.Key, &entry.EnterpriseMeta)
+		if err := add(TypeKV, name, name, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	// The catalog is read like the FSM persists it.
+	snap := store.Snapshot()
+	defer snap.Close()
+	nodes, err := snap.Nodes()
+	if err != nil {
+		return nil, err
+	}
+	for raw := nodes.Next(); raw != nil; raw = nodes.Next() {
+		node := raw.(*structs.Node)
+		services, err := snap.Services(node.Node, node.GetEnterpriseMeta(), node.PeerName)
+		if err != nil {
+			return nil, err
+		}
+		for raw := services.Next(); raw != nil; raw = services.Next() {
+			sn := raw.(*structs.ServiceNode)
+			service := peered(qualify(sn.ServiceName, &sn.EnterpriseMeta), sn.PeerName)
+			if err := add(TypeService, service, service, struct{}{}); err != nil {
+				return nil, err
+			}
+			instance := peered(qualify(node.Node+"/"+sn.ServiceID, &sn.EnterpriseMeta), sn.PeerName)
+			if err := add(TypeServiceInstance, instance, instance, sn.ToNodeService()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_, configEntries, err := store.ConfigEntries(nil, entMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range configEntries {
+		name := qualify(entry.GetKind()+"/"+entry.GetName(), entry.GetEnterpriseMeta())
+		if err := add(TypeConfigEntry, name, name, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	_, intentions, _, err := store.Intentions(nil, entMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, ixn := range intentions {
+		name := intentionName(ixn)
+		// The ID and timestamps of the intentions stored in config entries
+		// are not persisted, and the precedence is computed.
+		if err := add(TypeIntention, name, name, ixn, "ID", "Precedence", "CreatedAt", "UpdatedAt"); err != nil {
+			return nil, err
+		}
+	}
+
+	_, tokens, err := store.ACLTokenList(nil, true, true, "", "", "", nil, entMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		if err := add(TypeACLToken, token.AccessorID, token.AccessorID, token); err != nil {
+			return nil, err
+		}
+	}
+
+	_, policies, err := store.ACLPolicyList(nil, entMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range policies {
+		name := qualify(policy.Name, &policy.EnterpriseMeta)
+		if err := add(TypeACLPolicy, policy.ID, name, policy); err != nil {
+			return nil, err
+		}
+	}
+
+	_, roles, err := store.ACLRoleList(nil, "", entMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		name := qualify(role.Name, &role.EnterpriseMeta)
+		if err := add(TypeACLRole, role.ID, name, role); err != nil {
+			return nil, err
+		}
+	}
+
+	_, rules, err := store.ACLBindingRuleList(nil, "", entMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if err := add(TypeACLBindingRule, rule.ID, rule.ID, rule); err != nil {
+			return nil, err
+		}
+	}
+
+	_, methods, err := store.ACLAuthMethodList(nil, entMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, method := range methods {
+		name := qualify(method.Name, &method.EnterpriseMeta)
+		if err := add(TypeACLAuthMethod, name, name, method); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// qualify prefixes the name with the partition and namespace of the item,
+// when they are set.
+func qualify(name string, entMeta *acl.EnterpriseMeta) string {
+	ns, ap := entMeta.NamespaceOrEmpty(), entMeta.PartitionOrEmpty()
+	if ns == "" && ap == "" {
+		return name
+	}
+	return ap + "/" + ns + "/" + name
+}
+
+func peered(name, peer string) string {
+	if peer == "" {
+		return name
+	}
+	return "peer(" + peer + ")/" + name
+}
+
+// intentionName identifies an intention by its source and destination.
+func intentionName(x *structs.Intention) string {
+	src := x.SourceNS + "/" + x.SourceName
+	if x.SourcePartition != "" {
+		src = x.SourcePartition + "/" + src
+	}
+	if x.SourcePeer != "" {
+		src = "peer(" + x.SourcePeer + ")/" + src
+	}
+	dst := x.DestinationNS + "/" + x.DestinationName
+	if x.DestinationPartition != "" {
+		dst = x.DestinationPartition + "/" + dst
+	}
+	return src + " => " + dst
+}
+
+// compare returns the changes between the items of two snapshots, sorted by
+// type and name.
+func compare(from, to map[string]map[string]item) []Change {
+	var changes []Change
+	for _, typ := range typeOrder {
+		var typeChanges []Change
+		for id, before := range from[typ] {
+			after, ok := to[typ][id]
+			if !ok {
+				typeChanges = append(typeChanges, Change{Type: typ, Name: before.name, Change: ChangeRemoved})
+				continue
+			}
+			if fields := changedFields(before.value, after.value); len(fields) > 0 {
+				typeChanges = append(typeChanges, Change{Type: typ, Name: after.name, Change: ChangeChanged, Fields: fields})
+			}
+		}
+		for id, after := range to[typ] {
+			if _, ok := from[typ][id]; !ok {
+				typeChanges = append(typeChanges, Change{Type: typ, Name: after.name, Change: ChangeAdded})
+			}
+		}
+		sort.Slice(typeChanges, func(i, j int) bool {
+			return typeChanges[i].Name < typeChanges[j].Name
+		})
+		changes = append(changes, typeChanges...)
+	}
+	return changes
+}
+
+// changedFields returns the sorted names of the top-level fields that differ.
+func changedFields(before, after map[string]interface{}) []string {
+	var fields []string
+	for field, value := range before {
+		if !reflect.DeepEqual(value, after[field]) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+const synopsis = "Compares the content of two Consul snapshot files"
+const help = `
+Usage: consul snapshot diff [options] FROM TO
+
+  Compares two snapshot files on disk and reports the KV keys, services and
+  service instances, config entries, intentions and ACL objects that were
+  added, removed or changed between them. Only the names of the changed fields
+  are reported, not their values.
+
+  To compare the files "before.snap" and "after.snap":
+
+    $ consul snapshot diff before.snap after.snap
+
+  For a full list of options and examples, please see the Consul documentation.
+`
@@ -0,0 +1,209 @@
+package diff
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+// update allows golden files to be updated based on the current output.
+var update = flag.Bool("update", false, "update golden files")
+
+// golden reads and optionally writes the expected data to the golden file,
+// returning the contents as a string.
+func golden(t *testing.T, name, got string) string {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name+".golden")
+	if *update && got != "" {
+		err := os.WriteFile(golden, []byte(got), 0644)
+		require.NoError(t, err)
+	}
+
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+
+	return string(expected)
+}
+
+func TestSnapshotDiffCommand_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestSnapshotDiffCommand_Validation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args   []string
+		output string
+	}{
+		"no file": {
+			[]string{},
+			"This command requires two snapshot files (got 0)",
+		},
+		"one file": {
+			[]string{"a.snap"},
+			"This command requires two snapshot files (got 1)",
+		},
+		"extra args": {
+			[]string{"a.snap", "b.snap", "c.snap"},
+			"This command requires two snapshot files (got 3)",
+		},
+		"bad format": {
+			[]string{"-format=yaml", "a.snap", "b.snap"},
+			"Unknown format: yaml",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			code := New(ui).Run(tc.args)
+			require.Equal(t, 1, code)
+			require.Contains(t, ui.ErrorWriter.String(), tc.output)
+		})
+	}
+}
+
+func TestSnapshotDiffCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+	wopts := &api.WriteOptions{Token: "root"}
+	dir := testutil.TempDir(t, "snapshot")
+
+	save := func(name string) string {
+		snap, _, err := client.Snapshot().Save(&api.QueryOptions{Token: "root"})
+		require.NoError(t, err)
+		defer snap.Close()
+
+		file := filepath.Join(dir, name)
+		f, err := os.Create(file)
+		require.NoError(t, err)
+		defer f.Close()
+		_, err = io.Copy(f, snap)
+		require.NoError(t, err)
+		return file
+	}
+
+	for _, key := range []string{"web/a", "web/b"} {
+		_, err := client.KV().Put(&api.KVPair{Key: key, Value: []byte(key)}, wopts)
+		require.NoError(t, err)
+	}
+	_, err := client.Catalog().Register(&api.CatalogRegistration{
+		Node:    "node1",
+		Address: "127.0.0.2",
+		Service: &api.AgentService{ID: "db-1", Service: "db", Port: 5432},
+	}, wopts)
+	require.NoError(t, err)
+	_, _, err = client.ConfigEntries().Set(&api.ServiceConfigEntry{
+		Kind:     api.ServiceDefaults,
+		Name:     "web",
+		Protocol: "http",
+	}, wopts)
+	require.NoError(t, err)
+	policy, _, err := client.ACL().PolicyCreate(&api.ACLPolicy{
+		Name:  "web",
+		Rules: `service "web" { policy = "write" }`,
+	}, wopts)
+	require.NoError(t, err)
+	before := save("before.snap")
+
+	// The same snapshot has no differences.
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{before, before})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "No differences")
+
+	_, err = client.KV().Delete("web/a", wopts)
+	require.NoError(t, err)
+	_, err = client.KV().Put(&api.KVPair{Key: "web/b", Value: []byte("changed"), Flags: 1}, wopts)
+	require.NoError(t, err)
+	_, err = client.KV().Put(&api.KVPair{Key: "web/c", Value: []byte("web/c")}, wopts)
+	require.NoError(t, err)
+	_, err = client.Catalog().Register(&api.CatalogRegistration{
+		Node:    "node1",
+		Address: "127.0.0.2",
+		Service: &api.AgentService{ID: "db-1", Service: "db", Port: 5433},
+	}, wopts)
+	require.NoError(t, err)
+	_, err = client.Catalog().Register(&api.CatalogRegistration{
+		Node:    "node1",
+		Address: "127.0.0.2",
+		Service: &api.AgentService{ID: "cache-1", Service: "cache", Port: 6379},
+	}, wopts)
+	require.NoError(t, err)
+	_, _, err = client.ConfigEntries().Set(&api.ServiceIntentionsConfigEntry{
+		Kind: api.ServiceIntentions,
+		Name: "db",
+		Sources: []*api.SourceIntention{
+			{Name: "web", Action: api.IntentionActionAllow},
+		},
+	}, wopts)
+	require.NoError(t, err)
+	policy.Rules = `service "web" { policy = "read" }`
+	_, _, err = client.ACL().PolicyUpdate(policy, wopts)
+	require.NoError(t, err)
+	token, _, err := client.ACL().TokenCreate(&api.ACLToken{
+		Policies: []*api.ACLTokenPolicyLink{{ID: policy.ID}},
+	}, wopts)
+	require.NoError(t, err)
+	after := save("after.snap")
+
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-format=json", before, after})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	var out OutputFormat
+	require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &out))
+	require.Greater(t, out.To.Index, out.From.Index)
+	require.Equal(t, []Change{
+		{Type: TypeKV, Name: "web/a", Change: ChangeRemoved},
+		{Type: TypeKV, Name: "web/b", Change: ChangeChanged, Fields: []string{"Flags", "Value"}},
+		{Type: TypeKV, Name: "web/c", Change: ChangeAdded},
+		{Type: TypeService, Name: "cache", Change: ChangeAdded},
+		{Type: TypeServiceInstance, Name: "node1/cache-1", Change: ChangeAdded},
+		{Type: TypeServiceInstance, Name: "node1/db-1", Change: ChangeChanged, Fields: []string{"Port"}},
+		{Type: TypeConfigEntry, Name: "service-intentions/db", Change: ChangeAdded},
+		{Type: TypeIntention, Name: "default/web => default/db", Change: ChangeAdded},
+		{Type: TypeACLToken, Name: token.AccessorID, Change: ChangeAdded},
+		{Type: TypeACLPolicy, Name: "web", Change: ChangeChanged, Fields: []string{"Rules"}},
+	}, out.Changes)
+
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{before, after})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	output := ui.OutputWriter.String()
+	require.Contains(t, output, "   ~ web/b (Flags, Value)")
+	require.Contains(t, output, "6 added, 1 removed, 3 changed")
+	require.NotContains(t, output, token.SecretID)
+}
@@ -0,0 +1,110 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul-net-rpc/go-msgpack/codec"
+
+	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/proto/private/pbpeering"
+)
+
+// recordDecoder decodes a record of a snapshot into the struct the FSM
+// restores it from.
+type recordDecoder func(dec *codec.Decoder) (interface{}, error)
+
+func decodeInto(newValue func() interface{}) recordDecoder {
+	return func(dec *codec.Decoder) (interface{}, error) {
+		v := newValue()
+		if err := dec.Decode(v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+}
+
+// recordDecoders mirrors the restorers registered in fsm/snapshot_oss.go, the
+// records of other types are decoded without a schema. The service virtual IPs
+// changed in a breaking way in 1.13.0, they are decoded without a schema so
+// both versions can be exported.
+var recordDecoders = map[structs.MessageType]recordDecoder{
+	structs.RegisterRequestType:        decodeInto(func() interface{} { return &structs.RegisterRequest{} }),
+	structs.KVSRequestType:             decodeInto(func() interface{} { return &structs.DirEntry{} }),
+	structs.TombstoneRequestType:       decodeInto(func() interface{} { return &structs.DirEntry{} }),
+	structs.SessionRequestType:         decodeInto(func() interface{} { return &structs.Session{} }),
+	structs.CoordinateBatchUpdateType:  decodeInto(func() interface{} { return &structs.Coordinates{} }),
+	structs.PreparedQueryRequestType:   decodeInto(func() interface{} { return &structs.PreparedQuery{} }),
+	structs.AutopilotRequestType:       decodeInto(func() interface{} { return &structs.AutopilotConfig{} }),
+	structs.IntentionRequestType:       decodeInto(func() interface{} { return &structs.Intention{} }),
+	structs.ConnectCARequestType:       decodeInto(func() interface{} { return &structs.CARoot{} }),
+	structs.ConnectCAProviderStateType: decodeInto(func() interface{} { return &structs.CAConsulProviderState{} }),
+	structs.ConnectCAConfigType:        decodeInto(func() interface{} { return &structs.CAConfiguration{} }),
+	structs.IndexRequestType:           decodeInto(func() interface{} { return &state.IndexEntry{} }),
+	structs.ACLTokenSetRequestType:     decodeInto(func() interface{} { return &structs.ACLToken{} }),
+	structs.ACLPolicySetRequestType:    decodeInto(func() interface{} { return &structs.ACLPolicy{} }),
+	structs.ConfigEntryRequestType: func(dec *codec.Decoder) (interface{}, error) {
+		var req structs.ConfigEntryRequest
+		if err := dec.Decode(&req); err != nil {
+			return nil, err
+		}
+		return req.Entry, nil
+	},
+	structs.ACLRoleSetRequestType:        decodeInto(func() interface{} { return &structs.ACLRole{} }),
+	structs.ACLBindingRuleSetRequestType: decodeInto(func() interface{} { return &structs.ACLBindingRule{} }),
+	structs.ACLAuthMethodSetRequestType:  decodeInto(func() interface{} { return &structs.ACLAuthMethod{} }),
+	structs.FederationStateRequestType: func(dec *codec.Decoder) (interface{}, error) {
+		var req structs.FederationStateRequest
+		if err := dec.Decode(&req); err != nil {
+			return nil, err
+		}
+		return req.State, nil
+	},
+	structs.SystemMetadataRequestType:   decodeInto(func() interface{} { return &structs.SystemMetadataEntry{} }),
+	structs.FreeVirtualIPRequestType:    decodeInto(func() interface{} { return &state.FreeVirtualIP{} }),
+	structs.PeeringWriteType:            decodeInto(func() interface{} { return &pbpeering.Peering{} }),
+	structs.PeeringTrustBundleWriteType: decodeInto(func() interface{} { return &pbpeering.PeeringTrustBundle{} }),
+	structs.PeeringSecretsWriteType:     decodeInto(func() interface{} { return &pbpeering.PeeringSecrets{} }),
+}
+
+// decodeGeneric decodes a record of a type without a known schema.
+func decodeGeneric(dec *codec.Decoder) (interface{}, error) {
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// recordTypes returns the sorted names of the record types, as reported by
+// "consul snapshot inspect".
+func recordTypes() []string {
+	names := []string{structs.MessageType(structs.ServiceVirtualIPRequestType).String()}
+	for msg := range recordDecoders {
+		names = append(names, msg.String())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseRecordTypes returns the set of record types matching the given names,
+// which are case-insensitive.
+func parseRecordTypes(names []string) (map[string]struct{}, error) {
+	known := make(map[string]string)
+	for _, name := range recordTypes() {
+		known[strings.ToLower(name)] = name
+	}
+
+	types := make(map[string]struct{})
+	for _, name := range names {
+		typ, ok := known[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("Unknown record type %q, the supported types are: %s",
+				name, strings.Join(recordTypes(), ", "))
+		}
+		types[typ] = struct{}{}
+	}
+	return types, nil
+}
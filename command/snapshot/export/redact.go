@@ -0,0 +1,51 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/lib/redact"
+)
+
+// Redacted replaces the values of secrets in the exported records.
+const Redacted = redact.Redacted
+
+// sensitiveFields are the names of the fields whose values are redacted,
+// wherever they are nested in a record. They cover the ACL token secrets,
+// including the previous secrets of rotated tokens, the peering secrets, the
+// private keys of the CA and the credentials in the configuration of the CA
+// providers and auth methods.
+var sensitiveFields = redact.NewFields(
+	"activesecretid",
+	"bearertoken",
+	"clientsecret",
+	"oidcclientsecret",
+	"password",
+	"pendingsecretid",
+	"previoussecretid",
+	"privatekey",
+	"secretid",
+	"serviceaccountjwt",
+	"signingkey",
+	"token",
+)
+
+// redactRecord returns the JSON-encoded record with the values of sensitive fields
+// replaced. The values of the KV entries are redacted too, since they
+// commonly hold secrets.
+func redactRecord(msg structs.MessageType, record []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(record))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if m, ok := v.(map[string]interface{}); ok && msg == structs.KVSRequestType {
+		if value, ok := m["Value"]; ok && value != nil && value != "" {
+			m["Value"] = Redacted
+		}
+	}
+	return encode(sensitiveFields.Value(v))
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent/structs"
+)
+
+func TestRedactRecord(t *testing.T) {
+	cases := map[string]struct {
+		msg      structs.MessageType
+		record   string
+		expected string
+	}{
+		"token": {
+			structs.ACLTokenSetRequestType,
+			`{"AccessorID":"a","SecretID":"s","Local":false}`,
+			`{"AccessorID":"a","Local":false,"SecretID":"<redacted>"}`,
+		},
+		"rotated token": {
+			structs.ACLTokenSetRequestType,
+			`{"AccessorID":"a","SecretID":"s","PreviousSecretID":"p"}`,
+			`{"AccessorID":"a","PreviousSecretID":"<redacted>","SecretID":"<redacted>"}`,
+		},
+		"nested": {
+			structs.ConnectCAConfigType,
+			`{"Provider":"vault","Config":{"Token":"s","Address":"x","RootPKIPath":"p"}}`,
+			`{"Config":{"Address":"x","RootPKIPath":"p","Token":"<redacted>"},"Provider":"vault"}`,
+		},
+		"peering secrets": {
+			structs.PeeringSecretsWriteType,
+			`{"PeerID":"p","Stream":{"active_secret_id":"a","pending_secret_id":""}}`,
+			`{"PeerID":"p","Stream":{"active_secret_id":"<redacted>","pending_secret_id":""}}`,
+		},
+		"kv value": {
+			structs.KVSRequestType,
+			`{"Key":"k","Value":"aHVudGVyMg==","Flags":1}`,
+			`{"Flags":1,"Key":"k","Value":"<redacted>"}`,
+		},
+		"empty kv value": {
+			structs.KVSRequestType,
+			`{"Key":"k","Value":null}`,
+			`{"Key":"k","Value":null}`,
+		},
+		"other values": {
+			structs.IndexRequestType,
+			`{"Key":"kvs","Value":12}`,
+			`{"Key":"kvs","Value":12}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := redactRecord(tc.msg, []byte(tc.record))
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(out))
+		})
+	}
+}
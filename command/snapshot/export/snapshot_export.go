@@ -0,0 +1,231 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/consul-net-rpc/go-msgpack/codec"
+	"github.com/hashicorp/go-hclog"
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/agent/consul/fsm"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/snapshot/snapshotstate"
+	"github.com/hashicorp/consul/snapshot"
+)
+
+const (
+	JSONFormat   string = "json"
+	NDJSONFormat string = "ndjson"
+)
+
+func GetSupportedFormats() []string {
+	return []string{JSONFormat, NDJSONFormat}
+}
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	help  string
+
+	// flags
+	format         string
+	types          flags.AppendSliceValue
+	redact         bool
+	decryptKeyFile string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(
+		&c.format,
+		"format",
+		JSONFormat,
+		fmt.Sprintf("Output format {%s}", strings.Join(GetSupportedFormats(), "|")))
+	c.flags.Var(&c.types, "type",
+		"Only export the records of this type, as reported by \"consul snapshot "+
+			"inspect\". May be specified multiple times.")
+	c.flags.BoolVar(&c.redact, "redact", true,
+		"Replace the secrets, such as ACL token secrets, private keys and KV "+
+			"values, with \""+Redacted+"\". Set to false to export them.")
+	c.flags.StringVar(&c.decryptKeyFile, "decrypt-key-file", "",
+		"Decrypt an encrypted snapshot with the matching key of this file. The "+
+			"file holds base64-encoded keys, one per line.")
+
+	c.help = flags.Usage(help, c.flags)
+}
+
+// Record is an exported record of a snapshot.
+type Record struct {
+	Type  string
+	Value json.RawMessage
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	var file string
+	args = c.flags.Args()
+	switch len(args) {
+	case 0:
+		c.UI.Error("Missing FILE argument")
+		return 1
+	case 1:
+		file = args[0]
+	default:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
+		return 1
+	}
+
+	if c.format != JSONFormat && c.format != NDJSONFormat {
+		c.UI.Error(fmt.Sprintf("Unknown format: %s", c.format))
+		return 1
+	}
+	types, err := parseRecordTypes(c.types)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	keys, err := snapshotstate.ReadKeys(c.decryptKeyFile)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error opening snapshot file: %s", err))
+		return 1
+	}
+	defer f.Close()
+
+	readFile, meta, err := snapshot.Read(hclog.NewNullLogger(), f, keys...)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading snapshot: %s", err))
+		return 1
+	}
+	defer func() {
+		readFile.Close()
+		os.Remove(readFile.Name())
+	}()
+
+	// The records are streamed as they are decoded. In the JSON format, a line
+	// is only written once the next one is known, so the last record of the
+	// array has no trailing comma.
+	var pending string
+	emit := func(line string) {
+		if c.format == NDJSONFormat {
+			c.UI.Output(line)
+			return
+		}
+		if pending != "" {
+			c.UI.Output(pending + ",")
+		}
+		pending = line
+	}
+	if c.format == JSONFormat {
+		metaBuf, err := encode(meta)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error encoding snapshot metadata: %s", err))
+			return 1
+		}
+		c.UI.Output(fmt.Sprintf("{\"Meta\":%s,\"Records\":[", metaBuf))
+	}
+
+	handler := func(_ *fsm.SnapshotHeader, msg structs.MessageType, dec *codec.Decoder) error {
+		name := msg.String()
+		decode, ok := recordDecoders[msg]
+		if !ok {
+			decode = decodeGeneric
+		}
+		v, err := decode(dec)
+		if err != nil {
+			return fmt.Errorf("failed to decode msg type %v, error %v", name, err)
+		}
+		if len(types) > 0 {
+			if _, ok := types[name]; !ok {
+				return nil
+			}
+		}
+
+		value, err := encode(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode msg type %v, error %v", name, err)
+		}
+		if c.redact {
+			value, err = redactRecord(msg, value)
+			if err != nil {
+				return fmt.Errorf("failed to redact msg type %v, error %v", name, err)
+			}
+		}
+		line, err := encode(Record{Type: name, Value: value})
+		if err != nil {
+			return err
+		}
+		emit(string(line))
+		return nil
+	}
+	if err := fsm.ReadSnapshot(readFile, handler); err != nil {
+		c.UI.Error(fmt.Sprintf("Error exporting snapshot: %s", err))
+		return 1
+	}
+
+	if c.format == JSONFormat {
+		c.UI.Output(pending + "]}")
+	}
+	return 0
+}
+
+// encode encodes the value as compact JSON without escaping HTML characters,
+// so certificates and rules are readable.
+func encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+const synopsis = "Exports the records of a Consul snapshot file as JSON"
+const help = `
+Usage: consul snapshot export [options] FILE
+
+  Exports every record of a snapshot file on disk as JSON, with the type of
+  the record and its decoded content, so the state of a cluster can be loaded
+  in other tools without a running cluster.
+
+  Secrets such as ACL token secrets, private keys and KV values are redacted
+  unless -redact=false is set.
+
+  To export the KV entries and the ACL tokens of the file "backup.snap", one
+  record per line:
+
+    $ consul snapshot export -format=ndjson -type=KVS -type=ACLToken backup.snap
+
+  For a full list of options and examples, please see the Consul documentation.
+`
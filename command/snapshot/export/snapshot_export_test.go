@@ -0,0 +1,164 @@
+package export
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestSnapshotExportCommand_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestSnapshotExportCommand_Validation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args   []string
+		output string
+	}{
+		"no file": {
+			[]string{},
+			"Missing FILE argument",
+		},
+		"extra args": {
+			[]string{"foo", "bar"},
+			"Too many arguments",
+		},
+		"bad format": {
+			[]string{"-format=yaml", "foo"},
+			"Unknown format: yaml",
+		},
+		"bad type": {
+			[]string{"-type=KV", "foo"},
+			`Unknown record type "KV", the supported types are: ACLAuthMethod, ACLBindingRule,`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			code := New(ui).Run(tc.args)
+			require.Equal(t, 1, code)
+			require.Contains(t, ui.ErrorWriter.String(), tc.output)
+		})
+	}
+}
+
+func TestSnapshotExportCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+	wopts := &api.WriteOptions{Token: "root"}
+	_, err := client.KV().Put(&api.KVPair{Key: "web/password", Value: []byte("hunter2")}, wopts)
+	require.NoError(t, err)
+	token, _, err := client.ACL().TokenCreate(&api.ACLToken{Description: "web token"}, wopts)
+	require.NoError(t, err)
+
+	snap, _, err := client.Snapshot().Save(&api.QueryOptions{Token: "root"})
+	require.NoError(t, err)
+	defer snap.Close()
+	file := filepath.Join(testutil.TempDir(t, "snapshot"), "backup.snap")
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	_, err = io.Copy(f, snap)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	type exported struct {
+		Type  string
+		Value map[string]interface{}
+	}
+	findRecord := func(t *testing.T, records []exported, typ, field, value string) map[string]interface{} {
+		for _, r := range records {
+			if r.Type == typ && r.Value[field] == value {
+				return r.Value
+			}
+		}
+		t.Fatalf("no %s record with %s %q", typ, field, value)
+		return nil
+	}
+
+	t.Run("json", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{file})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		var out struct {
+			Meta struct {
+				Index uint64
+			}
+			Records []exported
+		}
+		require.NoError(t, json.Unmarshal(ui.OutputWriter.Bytes(), &out))
+		require.NotZero(t, out.Meta.Index)
+
+		types := make(map[string]bool)
+		for _, r := range out.Records {
+			types[r.Type] = true
+		}
+		for _, typ := range []string{"Register", "KVS", "ACLToken", "ACLPolicy", "ConnectCA", "Index"} {
+			require.True(t, types[typ], "missing %s records", typ)
+		}
+
+		// The secrets are redacted.
+		kv := findRecord(t, out.Records, "KVS", "Key", "web/password")
+		require.Equal(t, Redacted, kv["Value"])
+		tok := findRecord(t, out.Records, "ACLToken", "AccessorID", token.AccessorID)
+		require.Equal(t, Redacted, tok["SecretID"])
+		require.Equal(t, "web token", tok["Description"])
+		require.NotContains(t, ui.OutputWriter.String(), token.SecretID)
+		require.NotContains(t, ui.OutputWriter.String(), "PRIVATE KEY")
+	})
+
+	t.Run("ndjson with filter", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		code := New(ui).Run([]string{"-format=ndjson", "-type=kvs", "-type=ACLToken", "-redact=false", file})
+		require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+		var records []exported
+		scanner := bufio.NewScanner(strings.NewReader(ui.OutputWriter.String()))
+		for scanner.Scan() {
+			var r exported
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+			require.Contains(t, []string{"KVS", "ACLToken"}, r.Type)
+			records = append(records, r)
+		}
+		require.NoError(t, scanner.Err())
+
+		kv := findRecord(t, records, "KVS", "Key", "web/password")
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte("hunter2")), kv["Value"])
+		tok := findRecord(t, records, "ACLToken", "AccessorID", token.AccessorID)
+		require.Equal(t, token.SecretID, tok["SecretID"])
+	})
+}
@@ -0,0 +1,448 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/mitchellh/cli"
+
+	"github.com/hashicorp/consul/acl"
+	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/agent/structs"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/command/snapshot/snapshotstate"
+)
+
+func New(ui cli.Ui) *cmd {
+	c := &cmd{UI: ui}
+	c.init()
+	return c
+}
+
+type cmd struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+	http  *flags.HTTPFlags
+	help  string
+
+	// flags
+	kvPrefixes      flags.AppendSliceValue
+	configEntryKind string
+	configEntryName string
+	intentions      bool
+	aclPolicies     bool
+	dryRun          bool
+	decryptKeyFile  string
+}
+
+func (c *cmd) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Var(&c.kvPrefixes, "kv-prefix",
+		"Restore the keys starting with this prefix. Use an empty prefix to "+
+			"restore every key. May be specified multiple times.")
+	c.flags.StringVar(&c.configEntryKind, "config-entry-kind", "",
+		"Restore the config entries of this kind.")
+	c.flags.StringVar(&c.configEntryName, "config-entry-name", "",
+		"Only restore the config entry with this name. Requires -config-entry-kind.")
+	c.flags.BoolVar(&c.intentions, "intentions", false,
+		"Restore the intentions, which are stored as service-intentions config entries.")
+	c.flags.BoolVar(&c.aclPolicies, "acl-policies", false,
+		"Restore the ACL policies. Policies are matched by name, a policy "+
+			"missing from the cluster is created with a new ID.")
+	c.flags.BoolVar(&c.dryRun, "dry-run", false,
+		"Only show the differences between the snapshot and the cluster, "+
+			"without writing anything.")
+	c.flags.StringVar(&c.decryptKeyFile, "decrypt-key-file", "",
+		"Decrypt an encrypted snapshot with the matching key of this file. The "+
+			"file holds base64-encoded keys, one per line.")
+
+	c.http = &flags.HTTPFlags{}
+	flags.Merge(c.flags, c.http.ClientFlags())
+	flags.Merge(c.flags, c.http.ServerFlags())
+	c.help = flags.Usage(help, c.flags)
+}
+
+// change is a write restoring an item of the snapshot in the cluster.
+type change struct {
+	create   bool
+	resource string
+	name     string
+	apply    func() error
+}
+
+func (ch change) String() string {
+	op := "~"
+	if ch.create {
+		op = "+"
+	}
+	return fmt.Sprintf("%s %s %s", op, ch.resource, ch.name)
+}
+
+func (c *cmd) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	var file string
+	args = c.flags.Args()
+	switch len(args) {
+	case 0:
+		c.UI.Error("Missing FILE argument")
+		return 1
+	case 1:
+		file = args[0]
+	default:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
+		return 1
+	}
+
+	if c.configEntryName != "" && c.configEntryKind == "" {
+		c.UI.Error("The -config-entry-name flag requires -config-entry-kind")
+		return 1
+	}
+	if len(c.kvPrefixes) == 0 && c.configEntryKind == "" && !c.intentions && !c.aclPolicies {
+		c.UI.Error("At least one of -kv-prefix, -config-entry-kind, -intentions or -acl-policies must be specified")
+		return 1
+	}
+
+	keys, err := snapshotstate.ReadKeys(c.decryptKeyFile)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	store, _, err := snapshotstate.Load(file, keys)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	client, err := c.http.APIClient()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error connecting to Consul agent: %s", err))
+		return 1
+	}
+
+	changes, unchanged, err := c.plan(store, client)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	var created, updated int
+	for _, ch := range changes {
+		if ch.create {
+			created++
+		} else {
+			updated++
+		}
+		c.UI.Output(ch.String())
+	}
+
+	if c.dryRun {
+		c.UI.Info(fmt.Sprintf("Dry run: %d to create, %d to update, %d unchanged", created, updated, unchanged))
+		return 0
+	}
+
+	for _, ch := range changes {
+		if err := ch.apply(); err != nil {
+			c.UI.Error(fmt.Sprintf("Error restoring %s %s: %s", ch.resource, ch.name, err))
+			return 1
+		}
+	}
+	c.UI.Info(fmt.Sprintf("Restored: %d created, %d updated, %d unchanged", created, updated, unchanged))
+	return 0
+}
+
+// plan compares the selected content of the snapshot with the cluster and
+// returns the writes restoring what differs, and the number of unchanged
+// items.
+func (c *cmd) plan(store *state.Store, client *api.Client) ([]change, int, error) {
+	var (
+		changes   []change
+		unchanged int
+	)
+	add := func(chs []change, same int, err error) error {
+		if err != nil {
+			return err
+		}
+		changes = append(changes, chs...)
+		unchanged += same
+		return nil
+	}
+
+	for _, prefix := range c.kvPrefixes {
+		if err := add(planKV(store, client, prefix)); err != nil {
+			return nil, 0, err
+		}
+	}
+	if c.configEntryKind != "" {
+		if err := add(planConfigEntries(store, client, c.configEntryKind, c.configEntryName)); err != nil {
+			return nil, 0, err
+		}
+	}
+	if c.intentions && c.configEntryKind != structs.ServiceIntentions {
+		if err := add(planConfigEntries(store, client, structs.ServiceIntentions, "")); err != nil {
+			return nil, 0, err
+		}
+	}
+	if c.aclPolicies {
+		if err := add(planACLPolicies(store, client)); err != nil {
+			return nil, 0, err
+		}
+	}
+	return changes, unchanged, nil
+}
+
+func planKV(store *state.Store, client *api.Client, prefix string) ([]change, int, error) {
+	_, entries, err := store.KVSList(nil, prefix, acl.WildcardEnterpriseMeta())
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error reading keys from snapshot: %s", err)
+	}
+
+	var (
+		changes   []change
+		unchanged int
+	)
+	for _, entry := range entries {
+		pair := &api.KVPair{
+			Key:       entry.Key,
+			Flags:     entry.Flags,
+			Value:     entry.Value,
+			Namespace: entry.NamespaceOrEmpty(),
+			Partition: entry.PartitionOrEmpty(),
+		}
+		live, _, err := client.KV().Get(pair.Key, &api.QueryOptions{
+			Namespace: pair.Namespace,
+			Partition: pair.Partition,
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error reading key %q: %s", pair.Key, err)
+		}
+		if live != nil && live.Flags == pair.Flags && bytes.Equal(live.Value, pair.Value) {
+			unchanged++
+			continue
+		}
+		changes = append(changes, change{
+			create:   live == nil,
+			resource: "kv",
+			name:     pair.Key,
+			apply: func() error {
+				_, err := client.KV().Put(pair, nil)
+				return err
+			},
+		})
+	}
+	return changes, unchanged, nil
+}
+
+func planConfigEntries(store *state.Store, client *api.Client, kind, name string) ([]change, int, error) {
+	_, entries, err := store.ConfigEntriesByKind(nil, kind, acl.WildcardEnterpriseMeta())
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error reading config entries from snapshot: %s", err)
+	}
+
+	var (
+		changes   []change
+		unchanged int
+	)
+	for _, entry := range entries {
+		if name != "" && entry.GetName() != name {
+			continue
+		}
+		restored, err := toAPIConfigEntry(entry)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error converting config entry %s/%s: %s", kind, entry.GetName(), err)
+		}
+
+		live, _, err := client.ConfigEntries().Get(kind, entry.GetName(), &api.QueryOptions{
+			Namespace: restored.GetNamespace(),
+			Partition: restored.GetPartition(),
+		})
+		var statusErr api.StatusError
+		if errors.As(err, &statusErr) && statusErr.Code == http.StatusNotFound {
+			live, err = nil, nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error reading config entry %s/%s: %s", kind, entry.GetName(), err)
+		}
+
+		if live != nil {
+			same, err := sameConfigEntry(live, restored)
+			if err != nil {
+				return nil, 0, err
+			}
+			if same {
+				unchanged++
+				continue
+			}
+		}
+		changes = append(changes, change{
+			create:   live == nil,
+			resource: "config-entry",
+			name:     kind + "/" + entry.GetName(),
+			apply: func() error {
+				_, _, err := client.ConfigEntries().Set(restored, nil)
+				return err
+			},
+		})
+	}
+	return changes, unchanged, nil
+}
+
+// toAPIConfigEntry converts a config entry of the state store to its API
+// counterpart, their JSON encodings are compatible.
+func toAPIConfigEntry(entry structs.ConfigEntry) (api.ConfigEntry, error) {
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	return api.DecodeConfigEntryFromJSON(buf)
+}
+
+// sameConfigEntry compares two config entries, ignoring their Raft indexes.
+func sameConfigEntry(a, b api.ConfigEntry) (bool, error) {
+	normalize := func(entry api.ConfigEntry) (map[string]interface{}, error) {
+		buf, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		var out map[string]interface{}
+		if err := json.Unmarshal(buf, &out); err != nil {
+			return nil, err
+		}
+		delete(out, "CreateIndex")
+		delete(out, "ModifyIndex")
+		return out, nil
+	}
+
+	na, err := normalize(a)
+	if err != nil {
+		return false, err
+	}
+	nb, err := normalize(b)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(na, nb), nil
+}
+
+func planACLPolicies(store *state.Store, client *api.Client) ([]change, int, error) {
+	_, policies, err := store.ACLPolicyList(nil, acl.WildcardEnterpriseMeta())
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error reading ACL policies from snapshot: %s", err)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	var (
+		changes   []change
+		unchanged int
+	)
+	for _, policy := range policies {
+		// The builtin policies are managed by the servers.
+		if policy.ID == structs.ACLPolicyGlobalManagementID {
+			continue
+		}
+
+		restored := &api.ACLPolicy{
+			Name:              policy.Name,
+			Description:       policy.Description,
+			Rules:             policy.Rules,
+			Datacenters:       policy.Datacenters,
+			TemplateVariables: policy.TemplateVariables,
+			Namespace:         policy.NamespaceOrEmpty(),
+			Partition:         policy.PartitionOrEmpty(),
+		}
+		live, _, err := client.ACL().PolicyReadByName(policy.Name, &api.QueryOptions{
+			Namespace: restored.Namespace,
+			Partition: restored.Partition,
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error reading ACL policy %q: %s", policy.Name, err)
+		}
+
+		if live != nil {
+			if live.Description == restored.Description &&
+				live.Rules == restored.Rules &&
+				sameStrings(live.Datacenters, restored.Datacenters) &&
+				sameStrings(live.TemplateVariables, restored.TemplateVariables) {
+				unchanged++
+				continue
+			}
+			// The policy is updated in place, so the tokens and roles linked
+			// to it keep their link.
+			restored.ID = live.ID
+		}
+
+		changes = append(changes, change{
+			create:   live == nil,
+			resource: "acl-policy",
+			name:     policy.Name,
+			apply: func() error {
+				var err error
+				if restored.ID == "" {
+					_, _, err = client.ACL().PolicyCreate(restored, nil)
+				} else {
+					_, _, err = client.ACL().PolicyUpdate(restored, nil)
+				}
+				return err
+			},
+		})
+	}
+	return changes, unchanged, nil
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cmd) Synopsis() string {
+	return synopsis
+}
+
+func (c *cmd) Help() string {
+	return c.help
+}
+
+const synopsis = "Restores selected data of a snapshot in a live cluster"
+const help = `
+Usage: consul snapshot extract [options] FILE
+
+  Restores selected data of a snapshot in a live cluster through the HTTP API,
+  instead of replacing the whole state of the servers like "consul snapshot
+  restore" does. The keys of a KV prefix, the config entries of a kind, the
+  intentions and the ACL policies can be restored.
+
+  The items of the snapshot missing from the cluster are created and the ones
+  that differ are updated. Nothing is deleted. The changes are listed with a
+  "+" for the items to create and a "~" for the items to update.
+
+  The token must have the permissions to read and write the restored data.
+
+  To show what restoring the keys under "web/" would change:
+
+    $ consul snapshot extract -kv-prefix=web/ -dry-run backup.snap
+
+  To restore the service-defaults config entry of the "web" service:
+
+    $ consul snapshot extract -config-entry-kind=service-defaults \
+        -config-entry-name=web backup.snap
+
+  For a full list of options and examples, please see the Consul documentation.
+`
@@ -0,0 +1,203 @@
+package extract
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/agent"
+	"github.com/hashicorp/consul/api"
+	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/testrpc"
+)
+
+func TestSnapshotExtractCommand_noTabs(t *testing.T) {
+	t.Parallel()
+	if strings.ContainsRune(New(cli.NewMockUi()).Help(), '\t') {
+		t.Fatal("help has tabs")
+	}
+}
+
+func TestSnapshotExtractCommand_Validation(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args   []string
+		output string
+	}{
+		"no file": {
+			[]string{"-kv-prefix=web/"},
+			"Missing FILE argument",
+		},
+		"extra args": {
+			[]string{"-kv-prefix=web/", "foo", "bar"},
+			"Too many arguments",
+		},
+		"nothing selected": {
+			[]string{"foo"},
+			"At least one of -kv-prefix, -config-entry-kind, -intentions or -acl-policies must be specified",
+		},
+		"name without kind": {
+			[]string{"-config-entry-name=web", "foo"},
+			"The -config-entry-name flag requires -config-entry-kind",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			code := New(ui).Run(tc.args)
+			require.Equal(t, 1, code)
+			require.Contains(t, ui.ErrorWriter.String(), tc.output)
+		})
+	}
+}
+
+func TestSnapshotExtractCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, `
+	primary_datacenter = "dc1"
+	acl {
+		enabled = true
+		tokens {
+			initial_management = "root"
+		}
+	}`)
+	defer a.Shutdown()
+	testrpc.WaitForLeader(t, a.RPC, "dc1")
+
+	client := a.Client()
+	wopts := &api.WriteOptions{Token: "root"}
+	qopts := &api.QueryOptions{Token: "root"}
+
+	// Write the data and take a snapshot of it.
+	for _, key := range []string{"web/a", "web/b", "db/a"} {
+		_, err := client.KV().Put(&api.KVPair{Key: key, Value: []byte(key), Flags: 42}, wopts)
+		require.NoError(t, err)
+	}
+	for _, name := range []string{"web", "db"} {
+		_, _, err := client.ConfigEntries().Set(&api.ServiceConfigEntry{
+			Kind:     api.ServiceDefaults,
+			Name:     name,
+			Protocol: "http",
+		}, wopts)
+		require.NoError(t, err)
+	}
+	_, _, err := client.ConfigEntries().Set(&api.ServiceIntentionsConfigEntry{
+		Kind: api.ServiceIntentions,
+		Name: "db",
+		Sources: []*api.SourceIntention{
+			{Name: "web", Action: api.IntentionActionAllow},
+		},
+	}, wopts)
+	require.NoError(t, err)
+	policy, _, err := client.ACL().PolicyCreate(&api.ACLPolicy{
+		Name:  "web",
+		Rules: `service "web" { policy = "write" }`,
+	}, wopts)
+	require.NoError(t, err)
+
+	dir := testutil.TempDir(t, "snapshot")
+	file := filepath.Join(dir, "backup.snap")
+	snap, _, err := client.Snapshot().Save(qopts)
+	require.NoError(t, err)
+	defer snap.Close()
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	_, err = io.Copy(f, snap)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Change the data after the snapshot.
+	_, err = client.KV().Delete("web/a", wopts)
+	require.NoError(t, err)
+	_, err = client.KV().Put(&api.KVPair{Key: "web/b", Value: []byte("changed")}, wopts)
+	require.NoError(t, err)
+	_, err = client.KV().Delete("db/a", wopts)
+	require.NoError(t, err)
+	_, err = client.ConfigEntries().Delete(api.ServiceDefaults, "web", wopts)
+	require.NoError(t, err)
+	_, err = client.ConfigEntries().Delete(api.ServiceIntentions, "db", wopts)
+	require.NoError(t, err)
+	policy.Rules = `service "web" { policy = "read" }`
+	_, _, err = client.ACL().PolicyUpdate(policy, wopts)
+	require.NoError(t, err)
+
+	args := []string{
+		"-http-addr=" + a.HTTPAddr(),
+		"-token=root",
+		"-kv-prefix=web/",
+		"-config-entry-kind=service-defaults",
+		"-intentions",
+		"-acl-policies",
+	}
+
+	// A dry run only lists the changes.
+	ui := cli.NewMockUi()
+	code := New(ui).Run(append(args, "-dry-run", file))
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	output := ui.OutputWriter.String()
+	for _, line := range []string{
+		"+ kv web/a",
+		"~ kv web/b",
+		"+ config-entry service-defaults/web",
+		"+ config-entry service-intentions/db",
+		"~ acl-policy web",
+		"Dry run: 3 to create, 2 to update, 1 unchanged",
+	} {
+		require.Contains(t, output, line)
+	}
+	require.NotContains(t, output, "db/a")
+	require.NotContains(t, output, "service-defaults/db")
+
+	pair, _, err := client.KV().Get("web/a", qopts)
+	require.NoError(t, err)
+	require.Nil(t, pair)
+
+	// Restore the data.
+	ui = cli.NewMockUi()
+	code = New(ui).Run(append(args, file))
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "Restored: 3 created, 2 updated, 1 unchanged")
+
+	for _, key := range []string{"web/a", "web/b"} {
+		pair, _, err := client.KV().Get(key, qopts)
+		require.NoError(t, err)
+		require.NotNil(t, pair)
+		require.Equal(t, key, string(pair.Value))
+		require.Equal(t, uint64(42), pair.Flags)
+	}
+	pair, _, err = client.KV().Get("db/a", qopts)
+	require.NoError(t, err)
+	require.Nil(t, pair)
+
+	entry, _, err := client.ConfigEntries().Get(api.ServiceDefaults, "web", qopts)
+	require.NoError(t, err)
+	require.Equal(t, "http", entry.(*api.ServiceConfigEntry).Protocol)
+
+	entry, _, err = client.ConfigEntries().Get(api.ServiceIntentions, "db", qopts)
+	require.NoError(t, err)
+	sources := entry.(*api.ServiceIntentionsConfigEntry).Sources
+	require.Len(t, sources, 1)
+	require.Equal(t, "web", sources[0].Name)
+	require.Equal(t, api.IntentionActionAllow, sources[0].Action)
+
+	restored, _, err := client.ACL().PolicyRead(policy.ID, qopts)
+	require.NoError(t, err)
+	require.Equal(t, `service "web" { policy = "write" }`, restored.Rules)
+
+	// Everything is restored, running again doesn't change anything.
+	ui = cli.NewMockUi()
+	code = New(ui).Run(append(args, "-dry-run", file))
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "Dry run: 0 to create, 0 to update, 6 unchanged")
+}
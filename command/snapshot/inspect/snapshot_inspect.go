@@ -36,6 +36,8 @@ type cmd struct {
 	kvDetails bool
 	kvDepth   int
 	kvFilter  string
+
+	decryptKeyFile string
 }
 
 func (c *cmd) init() {
@@ -46,6 +48,9 @@ func (c *cmd) init() {
 		"Can only be used with -kvdetails. The key prefix depth used to breakdown KV store data. Defaults to 2.")
 	c.flags.StringVar(&c.kvFilter, "kvfilter", "",
 		"Can only be used with -kvdetails. Limits KV key breakdown using this prefix filter.")
+	c.flags.StringVar(&c.decryptKeyFile, "decrypt-key-file", "",
+		"Decrypt an encrypted snapshot with the matching key of this file. The "+
+			"file holds base64-encoded keys, one per line.")
 	c.flags.StringVar(
 		&c.format,
 		"format",
@@ -105,6 +110,16 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	var keys []*snapshot.EncryptionKey
+	if c.decryptKeyFile != "" {
+		var err error
+		keys, err = snapshot.ReadEncryptionKeyFile(c.decryptKeyFile)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading decryption keys: %s", err))
+			return 1
+		}
+	}
+
 	// Open the file.
 	f, err := os.Open(file)
 	if err != nil {
@@ -135,7 +150,7 @@ func (c *cmd) Run(args []string) int {
 		}
 		meta = &metaDecoded
 	} else {
-		readFile, meta, err = snapshot.Read(hclog.New(nil), f)
+		readFile, meta, err = snapshot.Read(hclog.New(nil), f, keys...)
 		if err != nil {
 			c.UI.Error(fmt.Sprintf("Error reading snapshot: %s", err))
 			return 1
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/hashicorp/consul/command/flags"
+	"github.com/hashicorp/consul/snapshot"
 	"github.com/mitchellh/cli"
 )
 
@@ -20,10 +21,15 @@ type cmd struct {
 	flags *flag.FlagSet
 	http  *flags.HTTPFlags
 	help  string
+
+	decryptKeyFile string
 }
 
 func (c *cmd) init() {
 	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.decryptKeyFile, "decrypt-key-file", "",
+		"Decrypt an encrypted snapshot with the matching key of this file. The "+
+			"file holds base64-encoded keys, one per line.")
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.http.ClientFlags())
 	flags.Merge(c.flags, c.http.ServerFlags())
@@ -49,6 +55,16 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	var keys []*snapshot.EncryptionKey
+	if c.decryptKeyFile != "" {
+		var err error
+		keys, err = snapshot.ReadEncryptionKeyFile(c.decryptKeyFile)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading decryption keys: %s", err))
+			return 1
+		}
+	}
+
 	// Create and test the HTTP client
 	client, err := c.http.APIClient()
 	if err != nil {
@@ -64,8 +80,16 @@ func (c *cmd) Run(args []string) int {
 	}
 	defer f.Close()
 
+	// The servers only restore unencrypted snapshots, an encrypted snapshot
+	// is decrypted as it is uploaded.
+	in, err := snapshot.Decrypt(f, keys)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error decrypting snapshot: %s", err))
+		return 1
+	}
+
 	// Restore the snapshot.
-	err = client.Snapshot().Restore(nil, f)
+	err = client.Snapshot().Restore(nil, in)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error restoring snapshot: %s", err))
 		return 1
@@ -103,5 +127,9 @@ Usage: consul snapshot restore [options] FILE
 
     $ consul snapshot restore backup.snap
 
+  To restore a snapshot encrypted with one of the keys of "snapshot.key":
+
+    $ consul snapshot restore -decrypt-key-file=snapshot.key backup.snap
+
   For a full list of options and examples, please see the Consul documentation.
 `
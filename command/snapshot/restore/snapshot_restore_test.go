@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/consul/agent"
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/sdk/testutil"
+	"github.com/hashicorp/consul/snapshot"
 	"github.com/mitchellh/cli"
 	"github.com/stretchr/testify/require"
 )
@@ -107,6 +108,43 @@ func TestSnapshotRestoreCommand(t *testing.T) {
 	}
 }
 
+func TestSnapshotRestoreCommand_Encrypted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+	client := a.Client()
+
+	dir := testutil.TempDir(t, "snapshot")
+	file := filepath.Join(dir, "backup.tgz")
+	keyFile := filepath.Join(dir, "snapshot.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("pUqJrVyVRj5jsiYEkM/tFQYfWyJIv4s3XkvDwy7Cu5s=\n"), 0600))
+	keys, err := snapshot.ReadEncryptionKeyFile(keyFile)
+	require.NoError(t, err)
+
+	snap, _, err := client.Snapshot().Save(nil)
+	require.NoError(t, err)
+	defer snap.Close()
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.Encrypt(f, snap, keys[0]))
+	require.NoError(t, f.Close())
+
+	// the key is required
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), file})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "a decryption key is required")
+
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-decrypt-key-file=" + keyFile, file})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+	require.Contains(t, ui.OutputWriter.String(), "Restored snapshot")
+}
+
 func TestSnapshotRestoreCommand_TruncatedSnapshot(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
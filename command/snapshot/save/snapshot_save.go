@@ -3,6 +3,7 @@ package save
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/mitchellh/cli"
@@ -24,10 +25,16 @@ type cmd struct {
 	flags *flag.FlagSet
 	http  *flags.HTTPFlags
 	help  string
+
+	encryptKeyFile string
 }
 
 func (c *cmd) init() {
 	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.StringVar(&c.encryptKeyFile, "encrypt-key-file", "",
+		"Encrypt the snapshot with the first key of this file. The file holds "+
+			"base64-encoded 32-byte keys, one per line, like the ones generated "+
+			"by \"consul keygen\".")
 	c.http = &flags.HTTPFlags{}
 	flags.Merge(c.flags, c.http.ClientFlags())
 	flags.Merge(c.flags, c.http.ServerFlags())
@@ -53,6 +60,16 @@ func (c *cmd) Run(args []string) int {
 		return 1
 	}
 
+	var keys []*snapshot.EncryptionKey
+	if c.encryptKeyFile != "" {
+		all, err := snapshot.ReadEncryptionKeyFile(c.encryptKeyFile)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading encryption key: %s", err))
+			return 1
+		}
+		keys = all[:1]
+	}
+
 	// Create and test the HTTP client
 	client, err := c.http.APIClient()
 	if err != nil {
@@ -70,9 +87,20 @@ func (c *cmd) Run(args []string) int {
 	}
 	defer snap.Close()
 
+	// Encrypt the snapshot as it is written so it never reaches the disk in
+	// clear text.
+	var src io.Reader = snap
+	if len(keys) > 0 {
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(snapshot.Encrypt(pw, snap, keys[0]))
+		}()
+		src = pr
+	}
+
 	// Save the file first.
 	unverifiedFile := file + ".unverified"
-	if _, err := safeio.WriteToFile(snap, unverifiedFile, 0600); err != nil {
+	if _, err := safeio.WriteToFile(src, unverifiedFile, 0600); err != nil {
 		c.UI.Error(fmt.Sprintf("Error writing unverified snapshot file: %s", err))
 		return 1
 	}
@@ -84,7 +112,7 @@ func (c *cmd) Run(args []string) int {
 		c.UI.Error(fmt.Sprintf("Error opening snapshot file for verify: %s", err))
 		return 1
 	}
-	if _, err := snapshot.Verify(f); err != nil {
+	if _, err := snapshot.Verify(f, keys...); err != nil {
 		f.Close()
 		c.UI.Error(fmt.Sprintf("Error verifying snapshot file: %s", err))
 		return 1
@@ -131,5 +159,10 @@ Usage: consul snapshot save [options] FILE
 
     $ consul snapshot save -stale backup.snap
 
+  To encrypt the snapshot with a key generated by "consul keygen":
+
+    $ consul keygen > snapshot.key
+    $ consul snapshot save -encrypt-key-file=snapshot.key backup.snap
+
   For a full list of options and examples, please see the Consul documentation.
 `
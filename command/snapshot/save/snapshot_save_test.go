@@ -19,6 +19,7 @@ import (
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/sdk/testutil"
 	"github.com/hashicorp/consul/sdk/testutil/retry"
+	"github.com/hashicorp/consul/snapshot"
 )
 
 func TestSnapshotSaveCommand_noTabs(t *testing.T) {
@@ -109,6 +110,45 @@ func TestSnapshotSaveCommand(t *testing.T) {
 	}
 }
 
+func TestSnapshotSaveCommand_Encrypted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	t.Parallel()
+	a := agent.NewTestAgent(t, ``)
+	defer a.Shutdown()
+
+	dir := testutil.TempDir(t, "snapshot")
+	file := filepath.Join(dir, "backup.tgz")
+	keyFile := filepath.Join(dir, "snapshot.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("HS5lJ+XuTlYKWaeGYyG+/A==\n"), 0600))
+
+	ui := cli.NewMockUi()
+	code := New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-encrypt-key-file=" + keyFile, file})
+	require.Equal(t, 1, code)
+	require.Contains(t, ui.ErrorWriter.String(), "encryption key must be 32 bytes, got 16")
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("pUqJrVyVRj5jsiYEkM/tFQYfWyJIv4s3XkvDwy7Cu5s=\n"), 0600))
+	ui = cli.NewMockUi()
+	code = New(ui).Run([]string{"-http-addr=" + a.HTTPAddr(), "-encrypt-key-file=" + keyFile, file})
+	require.Equal(t, 0, code, ui.ErrorWriter.String())
+
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = snapshot.Verify(f)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "a decryption key is required")
+
+	keys, err := snapshot.ReadEncryptionKeyFile(keyFile)
+	require.NoError(t, err)
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	_, err = snapshot.Verify(f, keys...)
+	require.NoError(t, err)
+}
+
 func TestSnapshotSaveCommand_TruncatedStream(t *testing.T) {
 	if testing.Short() {
 		t.Skip("too slow for testing.Short")
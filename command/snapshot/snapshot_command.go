@@ -47,8 +47,7 @@ Usage: consul snapshot <subcommand> [options] [args]
 
       $ consul snapshot inspect backup.snap
 
-  Run a daemon process that locally saves a snapshot every hour (available only in
-  Consul Enterprise) :
+  Run a daemon process that locally saves a snapshot every hour:
 
       $ consul snapshot agent
 
@@ -0,0 +1,59 @@
+// Package snapshotstate loads a snapshot archive in a state store, so the
+// snapshot commands can read its content with the same queries the servers
+// use.
+package snapshotstate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+
+	"github.com/hashicorp/consul/agent/consul/fsm"
+	"github.com/hashicorp/consul/agent/consul/state"
+	"github.com/hashicorp/consul/snapshot"
+)
+
+// Load decodes the snapshot archive in the file with the snapshot decoders
+// of the FSM and returns the restored state store. An encrypted archive is
+// decrypted with the matching key of the given keys.
+func Load(file string, keys []*snapshot.EncryptionKey) (*state.Store, *raft.SnapshotMeta, error) {
+	logger := hclog.NewNullLogger()
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error opening snapshot file: %s", err)
+	}
+	defer f.Close()
+
+	snap, meta, err := snapshot.Read(logger, f, keys...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error reading snapshot: %s", err)
+	}
+	defer os.Remove(snap.Name())
+
+	restored, err := fsm.New(nil, logger)
+	if err != nil {
+		snap.Close()
+		return nil, nil, fmt.Errorf("Error creating state store: %s", err)
+	}
+	// Restore closes the snapshot.
+	if err := restored.Restore(snap); err != nil {
+		return nil, nil, fmt.Errorf("Error decoding snapshot: %s", err)
+	}
+	return restored.State(), meta, nil
+}
+
+// ReadKeys reads the decryption keys of the -decrypt-key-file flag, it
+// returns no key when the flag is not set.
+func ReadKeys(path string) ([]*snapshot.EncryptionKey, error) {
+	if path == "" {
+		return nil, nil
+	}
+	keys, err := snapshot.ReadEncryptionKeyFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading decryption keys: %s", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,57 @@
+// Package redact replaces the values of sensitive fields in JSON documents,
+// such as the request bodies recorded in the audit log or the records of an
+// exported snapshot.
+package redact
+
+import "strings"
+
+// Redacted replaces the values of sensitive fields.
+const Redacted = "<redacted>"
+
+var normalizer = strings.NewReplacer("_", "", "-", "")
+
+// Fields is a set of sensitive field names. Names are matched regardless of
+// their case, underscores and dashes, so "SecretID" and "secret_id" are the
+// same field.
+type Fields map[string]struct{}
+
+// NewFields returns the set of the given field names.
+func NewFields(names ...string) Fields {
+	fields := make(Fields, len(names))
+	for _, name := range names {
+		fields[normalize(name)] = struct{}{}
+	}
+	return fields
+}
+
+// Contains returns whether name is one of the sensitive fields.
+func (f Fields) Contains(name string) bool {
+	_, ok := f[normalize(name)]
+	return ok
+}
+
+// Value replaces the values of the sensitive fields of v, wherever they are
+// nested, and returns it. v is modified in place and must be the result of
+// decoding a JSON document into an interface{}. Empty values are kept so they
+// aren't mistaken for set ones.
+func (f Fields) Value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if f.Contains(k) && field != nil && field != "" {
+				v[k] = Redacted
+				continue
+			}
+			v[k] = f.Value(field)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = f.Value(elem)
+		}
+	}
+	return v
+}
+
+func normalize(name string) string {
+	return normalizer.Replace(strings.ToLower(name))
+}
@@ -0,0 +1,48 @@
+package redact
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFields_Value(t *testing.T) {
+	fields := NewFields("SecretID", "private_key")
+
+	cases := map[string]struct {
+		doc      string
+		expected string
+	}{
+		"no secrets": {
+			doc:      `{"Name":"web","Port":8080}`,
+			expected: `{"Name":"web","Port":8080}`,
+		},
+		"normalized names": {
+			doc:      `{"secret_id":"s1","PrivateKey":"pk","private-key":"pk"}`,
+			expected: `{"secret_id":"<redacted>","PrivateKey":"<redacted>","private-key":"<redacted>"}`,
+		},
+		"nested": {
+			doc:      `{"Config":{"SecretID":"s1","Issuer":"i"},"List":[{"SecretID":"s2"},"SecretID"]}`,
+			expected: `{"Config":{"SecretID":"<redacted>","Issuer":"i"},"List":[{"SecretID":"<redacted>"},"SecretID"]}`,
+		},
+		"empty values": {
+			doc:      `{"SecretID":"","PrivateKey":null}`,
+			expected: `{"SecretID":"","PrivateKey":null}`,
+		},
+		"scalar": {
+			doc:      `"SecretID"`,
+			expected: `"SecretID"`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var v interface{}
+			require.NoError(t, json.Unmarshal([]byte(tc.doc), &v))
+			out, err := json.Marshal(fields.Value(v))
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, string(out))
+		})
+	}
+}
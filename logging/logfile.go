@@ -41,10 +41,41 @@ type LogFile struct {
 	// Max rotated files to keep before removing them.
 	MaxFiles int
 
+	// mode is the permission of new log files, 0640 if unset.
+	mode os.FileMode
+
 	//acquire is the mutex utilized to ensure we have no concurrency issues
 	acquire sync.Mutex
 }
 
+// NewLogFile creates a LogFile writing to config.LogFilePath, rotated
+// according to the LogRotate options of the config. New files are created
+// with the given mode, or 0640 if it's zero.
+func NewLogFile(config Config, mode os.FileMode) (*LogFile, error) {
+	dir, fileName := filepath.Split(config.LogFilePath)
+	if fileName == "" {
+		return nil, fmt.Errorf("log file path %q must include a file name", config.LogFilePath)
+	}
+	if config.LogRotateDuration == 0 {
+		config.LogRotateDuration = defaultRotateDuration
+	}
+	logFile := &LogFile{
+		fileName: fileName,
+		logPath:  dir,
+		duration: config.LogRotateDuration,
+		MaxBytes: config.LogRotateBytes,
+		MaxFiles: config.LogRotateMaxFiles,
+		mode:     mode,
+	}
+	if err := logFile.pruneFiles(); err != nil {
+		return nil, fmt.Errorf("Failed to prune log files: %w", err)
+	}
+	if err := logFile.openNew(); err != nil {
+		return nil, err
+	}
+	return logFile, nil
+}
+
 func (l *LogFile) fileNamePattern() string {
 	// Extract the file extension
 	fileExt := filepath.Ext(l.fileName)
@@ -63,8 +94,13 @@ func (l *LogFile) openNew() error {
 	newfileName := fmt.Sprintf(fileNamePattern, strconv.FormatInt(createTime.UnixNano(), 10))
 	newfilePath := filepath.Join(l.logPath, newfileName)
 
+	mode := l.mode
+	if mode == 0 {
+		mode = 0640
+	}
+
 	// Try creating a file. We truncate the file because we are the only authority to write the logs
-	filePointer, err := os.OpenFile(newfilePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0640)
+	filePointer, err := os.OpenFile(newfilePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
 	if err != nil {
 		return err
 	}
@@ -139,3 +175,16 @@ func (l *LogFile) Write(b []byte) (n int, err error) {
 	l.BytesWritten += int64(len(b))
 	return l.FileInfo.Write(b)
 }
+
+// Close closes the current log file.
+func (l *LogFile) Close() error {
+	l.acquire.Lock()
+	defer l.acquire.Unlock()
+
+	if l.FileInfo == nil {
+		return nil
+	}
+	err := l.FileInfo.Close()
+	l.FileInfo = nil
+	return err
+}
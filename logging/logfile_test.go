@@ -48,6 +48,25 @@ func TestLogFile_openNew(t *testing.T) {
 	require.Contains(t, string(content), msg)
 }
 
+func TestNewLogFile(t *testing.T) {
+	tempDir := testutil.TempDir(t, "")
+
+	_, err := NewLogFile(Config{LogFilePath: tempDir + "/"}, 0)
+	require.Error(t, err)
+
+	logFile, err := NewLogFile(Config{LogFilePath: filepath.Join(tempDir, "audit.json")}, 0600)
+	require.NoError(t, err)
+	name := logFile.FileInfo.Name()
+	_, err = logFile.Write([]byte("{}\n"))
+	require.NoError(t, err)
+	require.NoError(t, logFile.Close())
+
+	info, err := os.Stat(name)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	require.Len(t, listDir(t, tempDir), 1)
+}
+
 func TestLogFile_Rotation_MaxBytes(t *testing.T) {
 	tempDir := testutil.TempDir(t, "LogWriterBytes")
 	logFile := LogFile{
@@ -0,0 +1,336 @@
+// The encryption utilities wrap a snapshot archive in an authenticated
+// encrypted envelope with the following format:
+//
+// magic    - the "CSNAPENC" bytes identifying an encrypted snapshot
+// length   - the length of the header as a big-endian uint32
+// header   - JSON-encoded encryptionHeader with the ID of the key
+// chunks   - the encrypted archive, split in chunks
+//
+// Each chunk is made of a flag byte set for the last chunk, the length of the
+// ciphertext as a big-endian uint32, and the ciphertext. Chunks are sealed
+// with AES-256-GCM, using the header as additional data and a nonce made of a
+// random prefix, the index of the chunk and the last chunk flag, so chunks
+// can't be reordered, truncated or moved to another snapshot.
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	encryptionVersion = 1
+	encryptionCipher  = "aes-256-gcm"
+
+	// encryptionKeySize is the size of the keys, the same as the gossip
+	// encryption keys so "consul keygen" can generate them.
+	encryptionKeySize = 32
+
+	// encryptionChunkSize is the size of the chunks written by Encrypt. It's
+	// also the largest chunk size accepted by Decrypt, since the chunks are
+	// allocated before they can be authenticated.
+	encryptionChunkSize = 64 * 1024
+
+	// maxHeaderSize limits the memory allocated for the header when reading
+	// a corrupt snapshot.
+	maxHeaderSize = 64 * 1024
+
+	noncePrefixSize = 7
+)
+
+var encryptedMagic = []byte("CSNAPENC")
+
+// encryptionHeader is the metadata of an encrypted snapshot.
+type encryptionHeader struct {
+	Version     int
+	Cipher      string
+	KeyID       string
+	ChunkSize   int
+	NoncePrefix []byte
+}
+
+// EncryptionKey is a key used to encrypt and decrypt snapshots.
+type EncryptionKey struct {
+	// ID identifies the key in the metadata of the snapshots it encrypted,
+	// it is derived from the key.
+	ID string
+
+	key []byte
+}
+
+// NewEncryptionKey returns an encryption key from 32 bytes of key material.
+func NewEncryptionKey(key []byte) (*EncryptionKey, error) {
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", encryptionKeySize, len(key))
+	}
+	sum := sha256.Sum256(key)
+	return &EncryptionKey{ID: hex.EncodeToString(sum[:8]), key: key}, nil
+}
+
+// ParseEncryptionKeys parses base64-encoded keys, one per line. Empty lines
+// and lines starting with # are ignored.
+func ParseEncryptionKeys(data string) ([]*EncryptionKey, error) {
+	var keys []*EncryptionKey
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key on line %d: %v", i+1, err)
+		}
+		key, err := NewEncryptionKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key on line %d: %v", i+1, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no encryption key found")
+	}
+	return keys, nil
+}
+
+// ReadEncryptionKeyFile reads the keys of the given file, see
+// ParseEncryptionKeys for the format.
+func ReadEncryptionKeyFile(path string) ([]*EncryptionKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := ParseEncryptionKeys(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return keys, nil
+}
+
+func newAEAD(key *EncryptionKey) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, index uint32, last bool) []byte {
+	nonce := make([]byte, noncePrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], index)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+// Encrypt reads a snapshot archive from the reader and writes it encrypted
+// with the given key.
+func Encrypt(out io.Writer, in io.Reader, key *EncryptionKey) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return fmt.Errorf("failed to create cipher: %v", err)
+	}
+
+	header := encryptionHeader{
+		Version:     encryptionVersion,
+		Cipher:      encryptionCipher,
+		KeyID:       key.ID,
+		ChunkSize:   encryptionChunkSize,
+		NoncePrefix: make([]byte, noncePrefixSize),
+	}
+	if _, err := io.ReadFull(rand.Reader, header.NoncePrefix); err != nil {
+		return fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	headerBuf, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to encode encryption header: %v", err)
+	}
+
+	var lengthBuf [4]byte
+	binary.BigEndian.PutUint32(lengthBuf[:], uint32(len(headerBuf)))
+	for _, b := range [][]byte{encryptedMagic, lengthBuf[:], headerBuf} {
+		if _, err := out.Write(b); err != nil {
+			return fmt.Errorf("failed to write encryption header: %v", err)
+		}
+	}
+
+	// The reader is buffered to find out whether a chunk is the last one
+	// before sealing it.
+	br := bufio.NewReaderSize(in, encryptionChunkSize)
+	plaintext := make([]byte, encryptionChunkSize)
+	var ciphertext []byte
+	for index := uint32(0); ; index++ {
+		n, err := io.ReadFull(br, plaintext)
+		switch err {
+		case nil, io.EOF, io.ErrUnexpectedEOF:
+		default:
+			return fmt.Errorf("failed to read snapshot: %v", err)
+		}
+		last := err != nil
+		if !last {
+			if _, err := br.Peek(1); err == io.EOF {
+				last = true
+			}
+		}
+
+		ciphertext = aead.Seal(ciphertext[:0], chunkNonce(header.NoncePrefix, index, last), plaintext[:n], headerBuf)
+
+		var frame [5]byte
+		if last {
+			frame[0] = 1
+		}
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(ciphertext)))
+		if _, err := out.Write(frame[:]); err != nil {
+			return fmt.Errorf("failed to write encrypted snapshot: %v", err)
+		}
+		if _, err := out.Write(ciphertext); err != nil {
+			return fmt.Errorf("failed to write encrypted snapshot: %v", err)
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// Decrypt returns a reader of the decrypted archive when the snapshot read
+// from in is encrypted with one of the given keys. Archives that are not
+// encrypted are returned unchanged, so it can be called on any snapshot.
+//
+// Every chunk is authenticated as it is read, and the reader returns an error
+// instead of io.EOF if the encrypted snapshot is truncated.
+func Decrypt(in io.Reader, keys []*EncryptionKey) (io.Reader, error) {
+	br := bufio.NewReader(in)
+	magic, err := br.Peek(len(encryptedMagic))
+	if err != nil || !bytes.Equal(magic, encryptedMagic) {
+		// Not encrypted, reading the archive will report any error.
+		return br, nil
+	}
+	if _, err := br.Discard(len(encryptedMagic)); err != nil {
+		return nil, err
+	}
+
+	var lengthBuf [4]byte
+	if _, err := io.ReadFull(br, lengthBuf[:]); err != nil {
+		return nil, fmt.Errorf("failed to read encryption header: %v", err)
+	}
+	length := binary.BigEndian.Uint32(lengthBuf[:])
+	if length > maxHeaderSize {
+		return nil, fmt.Errorf("encryption header too large: %d bytes", length)
+	}
+	headerBuf := make([]byte, length)
+	if _, err := io.ReadFull(br, headerBuf); err != nil {
+		return nil, fmt.Errorf("failed to read encryption header: %v", err)
+	}
+	var header encryptionHeader
+	if err := json.Unmarshal(headerBuf, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode encryption header: %v", err)
+	}
+	if header.Version != encryptionVersion || header.Cipher != encryptionCipher {
+		return nil, fmt.Errorf("unsupported snapshot encryption: version %d, cipher %q", header.Version, header.Cipher)
+	}
+	if len(header.NoncePrefix) != noncePrefixSize || header.ChunkSize <= 0 || header.ChunkSize > encryptionChunkSize {
+		return nil, fmt.Errorf("invalid encryption header")
+	}
+
+	var key *EncryptionKey
+	for _, k := range keys {
+		if k.ID == header.KeyID {
+			key = k
+			break
+		}
+	}
+	if key == nil {
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("snapshot is encrypted with key %s, a decryption key is required", header.KeyID)
+		}
+		return nil, fmt.Errorf("snapshot is encrypted with key %s which is not one of the decryption keys", header.KeyID)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+	return &decryptReader{
+		in:        br,
+		aead:      aead,
+		header:    header,
+		headerBuf: headerBuf,
+	}, nil
+}
+
+// decryptReader decrypts the chunks of an encrypted snapshot as they are read.
+type decryptReader struct {
+	in        io.Reader
+	aead      cipher.AEAD
+	header    encryptionHeader
+	headerBuf []byte
+
+	index     uint32
+	plaintext []byte
+	done      bool
+	err       error
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plaintext) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.next()
+	}
+	n := copy(p, d.plaintext)
+	d.plaintext = d.plaintext[n:]
+	return n, nil
+}
+
+// next reads and decrypts the next chunk.
+func (d *decryptReader) next() error {
+	var frame [5]byte
+	if _, err := io.ReadFull(d.in, frame[:]); err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("encrypted snapshot is truncated")
+		}
+		return fmt.Errorf("failed to read encrypted snapshot: %v", err)
+	}
+	last := frame[0] == 1
+	length := binary.BigEndian.Uint32(frame[1:])
+	if frame[0] > 1 || int(length) > d.header.ChunkSize+d.aead.Overhead() {
+		return fmt.Errorf("invalid chunk in encrypted snapshot")
+	}
+
+	ciphertext := make([]byte, length)
+	if _, err := io.ReadFull(d.in, ciphertext); err != nil {
+		return fmt.Errorf("failed to read encrypted snapshot: %v", err)
+	}
+	plaintext, err := d.aead.Open(ciphertext[:0], chunkNonce(d.header.NoncePrefix, d.index, last), ciphertext, d.headerBuf)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt snapshot: %v", err)
+	}
+	d.index++
+	d.plaintext = plaintext
+
+	if last {
+		d.done = true
+		var extra [1]byte
+		if n, _ := io.ReadFull(d.in, extra[:]); n != 0 {
+			return fmt.Errorf("unexpected data after the end of the encrypted snapshot")
+		}
+	}
+	return nil
+}
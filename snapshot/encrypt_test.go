@@ -0,0 +1,203 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hashicorp/consul/sdk/testutil"
+)
+
+func testEncryptionKey(t *testing.T) *EncryptionKey {
+	raw := make([]byte, encryptionKeySize)
+	_, err := rand.Read(raw)
+	require.NoError(t, err)
+	key, err := NewEncryptionKey(raw)
+	require.NoError(t, err)
+	return key
+}
+
+func TestParseEncryptionKeys(t *testing.T) {
+	raw := make([]byte, encryptionKeySize)
+	_, err := rand.Read(raw)
+	require.NoError(t, err)
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	other := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, encryptionKeySize))
+
+	keys, err := ParseEncryptionKeys("# current key\n" + encoded + "\n\n  " + other + "  \n")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Len(t, keys[0].ID, 16)
+	require.NotEqual(t, keys[0].ID, keys[1].ID)
+
+	// the ID only depends on the key
+	again, err := ParseEncryptionKeys(encoded)
+	require.NoError(t, err)
+	require.Equal(t, keys[0].ID, again[0].ID)
+
+	_, err = ParseEncryptionKeys("# no key\n")
+	require.EqualError(t, err, "no encryption key found")
+
+	_, err = ParseEncryptionKeys("not base64!")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to decode key on line 1")
+
+	_, err = ParseEncryptionKeys(base64.StdEncoding.EncodeToString([]byte("short")))
+	require.EqualError(t, err, "invalid key on line 1: encryption key must be 32 bytes, got 5")
+}
+
+func TestEncrypt(t *testing.T) {
+	key := testEncryptionKey(t)
+
+	sizes := []int{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 3*encryptionChunkSize + 42}
+	for _, size := range sizes {
+		plaintext := make([]byte, size)
+		_, err := rand.Read(plaintext)
+		require.NoError(t, err)
+
+		var encrypted bytes.Buffer
+		require.NoError(t, Encrypt(&encrypted, bytes.NewReader(plaintext), key))
+		require.True(t, bytes.HasPrefix(encrypted.Bytes(), encryptedMagic))
+		if size > 16 {
+			require.False(t, bytes.Contains(encrypted.Bytes(), plaintext))
+		}
+
+		r, err := Decrypt(bytes.NewReader(encrypted.Bytes()), []*EncryptionKey{testEncryptionKey(t), key})
+		require.NoError(t, err)
+		decrypted, err := io.ReadAll(r)
+		require.NoError(t, err, "size %d", size)
+		require.Equal(t, plaintext, decrypted, "size %d", size)
+	}
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	key := testEncryptionKey(t)
+	plaintext := make([]byte, 2*encryptionChunkSize+10)
+	_, err := rand.Read(plaintext)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Encrypt(&buf, bytes.NewReader(plaintext), key))
+	encrypted := buf.Bytes()
+
+	decrypt := func(data []byte, keys ...*EncryptionKey) error {
+		r, err := Decrypt(bytes.NewReader(data), keys)
+		if err != nil {
+			return err
+		}
+		_, err = io.ReadAll(r)
+		return err
+	}
+
+	t.Run("no key", func(t *testing.T) {
+		err := decrypt(encrypted)
+		require.EqualError(t, err, "snapshot is encrypted with key "+key.ID+", a decryption key is required")
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		err := decrypt(encrypted, testEncryptionKey(t))
+		require.EqualError(t, err, "snapshot is encrypted with key "+key.ID+" which is not one of the decryption keys")
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[len(tampered)-encryptionChunkSize] ^= 1
+		err := decrypt(tampered, key)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decrypt snapshot")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		// drop the last chunk
+		lastChunk := 5 + 10 + 16
+		err := decrypt(encrypted[:len(encrypted)-lastChunk], key)
+		require.EqualError(t, err, "encrypted snapshot is truncated")
+	})
+
+	t.Run("trailing data", func(t *testing.T) {
+		err := decrypt(append(append([]byte{}, encrypted...), 0), key)
+		require.EqualError(t, err, "unexpected data after the end of the encrypted snapshot")
+	})
+
+	t.Run("chunk size too large", func(t *testing.T) {
+		headerStart := len(encryptedMagic) + 4
+		length := int(binary.BigEndian.Uint32(encrypted[len(encryptedMagic):headerStart]))
+
+		var header encryptionHeader
+		require.NoError(t, json.Unmarshal(encrypted[headerStart:headerStart+length], &header))
+		header.ChunkSize = 1 << 30
+		headerBuf, err := json.Marshal(header)
+		require.NoError(t, err)
+
+		var tampered bytes.Buffer
+		tampered.Write(encryptedMagic)
+		require.NoError(t, binary.Write(&tampered, binary.BigEndian, uint32(len(headerBuf))))
+		tampered.Write(headerBuf)
+		tampered.Write(encrypted[headerStart+length:])
+
+		err = decrypt(tampered.Bytes(), key)
+		require.EqualError(t, err, "invalid encryption header")
+	})
+
+	t.Run("not encrypted", func(t *testing.T) {
+		r, err := Decrypt(bytes.NewReader(plaintext), nil)
+		require.NoError(t, err)
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, out)
+	})
+}
+
+func TestSnapshot_Encrypted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("too slow for testing.Short")
+	}
+
+	dir := testutil.TempDir(t, "snapshot")
+	before, _ := makeRaft(t, filepath.Join(dir, "before"))
+	defer before.Shutdown()
+	for i := 0; i < 16; i++ {
+		future := before.Apply([]byte("data"), time.Second)
+		require.NoError(t, future.Error())
+	}
+
+	logger := testutil.Logger(t)
+	snap, err := New(logger, before)
+	require.NoError(t, err)
+	defer snap.Close()
+
+	key := testEncryptionKey(t)
+	var encrypted bytes.Buffer
+	require.NoError(t, Encrypt(&encrypted, snap, key))
+
+	// Verify and Read decrypt the snapshot with the matching key.
+	metadata, err := Verify(bytes.NewReader(encrypted.Bytes()), key)
+	require.NoError(t, err)
+	require.Equal(t, uint64(18), metadata.Index)
+
+	f, metadata, err := Read(logger, bytes.NewReader(encrypted.Bytes()), testEncryptionKey(t), key)
+	require.NoError(t, err)
+	f.Close()
+	os.Remove(f.Name())
+	require.Equal(t, uint64(18), metadata.Index)
+
+	_, err = Verify(bytes.NewReader(encrypted.Bytes()))
+	require.EqualError(t, err, "snapshot is encrypted with key "+key.ID+", a decryption key is required")
+
+	// A server can't restore an encrypted snapshot, it must be decrypted
+	// first.
+	after, _ := makeRaft(t, filepath.Join(dir, "after"))
+	defer after.Shutdown()
+	err = Restore(logger, bytes.NewReader(encrypted.Bytes()), after)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "a decryption key is required")
+}
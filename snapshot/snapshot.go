@@ -1,6 +1,7 @@
 // snapshot manages the interactions between Consul and Raft in order to take
 // and restore snapshots for disaster recovery. The internal format of a
-// snapshot is simply a tar file, as described in archive.go.
+// snapshot is simply a tar file, as described in archive.go, which can be
+// encrypted as described in encrypt.go.
 package snapshot
 
 import (
@@ -122,8 +123,14 @@ func (s *Snapshot) Close() error {
 	return os.Remove(s.file.Name())
 }
 
-// Verify takes the snapshot from the reader and verifies its contents.
-func Verify(in io.Reader) (*raft.SnapshotMeta, error) {
+// Verify takes the snapshot from the reader and verifies its contents. An
+// encrypted snapshot is decrypted with the matching key of the given keys.
+func Verify(in io.Reader, keys ...*EncryptionKey) (*raft.SnapshotMeta, error) {
+	in, err := Decrypt(in, keys)
+	if err != nil {
+		return nil, err
+	}
+
 	// Wrap the reader in a gzip decompressor.
 	decomp, err := gzip.NewReader(in)
 	if err != nil {
@@ -160,7 +167,13 @@ func concludeGzipRead(decomp *gzip.Reader) error {
 }
 
 // Read a snapshot into a temporary file. The caller is responsible for removing the file.
-func Read(logger hclog.Logger, in io.Reader) (*os.File, *raft.SnapshotMeta, error) {
+// An encrypted snapshot is decrypted with the matching key of the given keys.
+func Read(logger hclog.Logger, in io.Reader, keys ...*EncryptionKey) (*os.File, *raft.SnapshotMeta, error) {
+	in, err := Decrypt(in, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Wrap the reader in a gzip decompressor.
 	decomp, err := gzip.NewReader(in)
 	if err != nil {